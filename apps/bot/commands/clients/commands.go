@@ -9,7 +9,9 @@ import (
 	"sushitana/internal/cart"
 	"sushitana/internal/client"
 	"sushitana/internal/keyboards"
+	"sushitana/internal/referral"
 	"sushitana/internal/structs"
+	"sushitana/internal/support"
 	"sushitana/internal/texts"
 	"sushitana/pkg/logger"
 	"sushitana/pkg/tgrouter"
@@ -30,6 +32,8 @@ type Params struct {
 	CategoryCmd category.Commands
 	ProductCmd  productcmd.Commands
 	CartSvc     cart.Service
+	SupportSvc  support.Service
+	ReferralSvc referral.Service
 }
 
 type Commands struct {
@@ -38,6 +42,8 @@ type Commands struct {
 	CategoryCmd category.Commands
 	ProductCmd  productcmd.Commands
 	CartSvc     cart.Service
+	SupportSvc  support.Service
+	ReferralSvc referral.Service
 }
 
 func New(p Params) Commands {
@@ -47,6 +53,8 @@ func New(p Params) Commands {
 		CategoryCmd: p.CategoryCmd,
 		CartSvc:     p.CartSvc,
 		ProductCmd:  p.ProductCmd,
+		SupportSvc:  p.SupportSvc,
+		ReferralSvc: p.ReferralSvc,
 	}
 }
 
@@ -60,6 +68,15 @@ func (c *Commands) Start(ctx *tgrouter.Ctx) {
 		return
 	}
 
+	// deep link (p_, c_, promo_, ref_): onboarding tugaguncha state'da saqlanadi
+	payload := ""
+	if link, ok := utils.ParseDeepLink(ctx.Update().Message.CommandArguments()); ok {
+		payload = link.String()
+	}
+	if payload != "" && (account.Language == "" || account.Name == "" || account.Phone == "") {
+		_ = ctx.UpdateState("start", map[string]string{startPayloadKey: payload})
+	}
+
 	if account.Language == "" {
 		c.logger.Info(ctx.Context, "Language empty → asking language...")
 
@@ -70,7 +87,7 @@ func (c *Commands) Start(ctx *tgrouter.Ctx) {
 			c.logger.Error(ctx.Context, "failed to send language keyboard", zap.Error(err))
 		}
 
-		_ = ctx.UpdateState("waiting_change_language", map[string]string{"last_action": "edit_language"})
+		_ = ctx.UpdateState("waiting_change_language", c.onboardingData(ctx, "edit_language"))
 		return
 	}
 
@@ -88,6 +105,7 @@ func (c *Commands) Start(ctx *tgrouter.Ctx) {
 
 	_ = ctx.UpdateState("show_main_menu", map[string]string{"last_action": "start_bot"})
 	c.ShowMainMenu(ctx)
+	c.openDeepLink(ctx, payload)
 }
 
 func (c *Commands) AskName(ctx *tgrouter.Ctx) {
@@ -107,7 +125,7 @@ func (c *Commands) AskName(ctx *tgrouter.Ctx) {
 		return
 	}
 
-	_ = ctx.UpdateState("waiting_for_name", map[string]string{"last_action": "ask_name"})
+	_ = ctx.UpdateState("waiting_for_name", c.onboardingData(ctx, "ask_name"))
 }
 
 func (c *Commands) SaveName(ctx *tgrouter.Ctx) {
@@ -143,8 +161,7 @@ func (c *Commands) SaveName(ctx *tgrouter.Ctx) {
 		return
 	}
 
-	_ = ctx.UpdateState("show_main_menu", map[string]string{"last_action": "name_saved"})
-	c.ShowMainMenu(ctx)
+	c.finishOnboarding(ctx, "name_saved")
 }
 
 func (c *Commands) RequestPhone(ctx *tgrouter.Ctx) {
@@ -166,7 +183,7 @@ func (c *Commands) RequestPhone(ctx *tgrouter.Ctx) {
 		return
 	}
 
-	_ = ctx.UpdateState("waiting_for_phone", map[string]string{"last_action": "request_phone"})
+	_ = ctx.UpdateState("waiting_for_phone", c.onboardingData(ctx, "request_phone"))
 }
 
 func (c *Commands) ChangePhone(ctx *tgrouter.Ctx) {
@@ -211,8 +228,7 @@ func (c *Commands) ChangePhone(ctx *tgrouter.Ctx) {
 		c.logger.Error(ctx.Context, "failed to send phone confirm", zap.Error(err))
 	}
 
-	_ = ctx.UpdateState("show_main_menu", map[string]string{"last_action": "phone_saved"})
-	c.ShowMainMenu(ctx)
+	c.finishOnboarding(ctx, "phone_saved")
 }
 
 func (c *Commands) MainMenuHandler(ctx *tgrouter.Ctx) {
@@ -237,6 +253,10 @@ func (c *Commands) MainMenuHandler(ctx *tgrouter.Ctx) {
 	case texts.Get(lang, texts.ContactButton):
 		_ = ctx.UpdateState("contact", nil)
 		c.Contact(ctx)
+	case texts.Get(lang, texts.SupportButton), texts.Get(lang, texts.FeedbackButton):
+		c.OpenSupport(ctx)
+	case texts.Get(lang, texts.ReferralButton):
+		c.ShowReferral(ctx)
 	case texts.Get(lang, texts.MenuButton):
 		_ = ctx.UpdateState("show_category", map[string]string{"last_action": "show_main_menu"})
 		c.CategoryCmd.MenuCategoryHandler(ctx)
@@ -282,6 +302,9 @@ func (c *Commands) Contact(ctx *tgrouter.Ctx) {
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.MenuButton)),
 		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.SupportButton)),
+		),
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.ContactButton)),
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.LanguageButton)),
@@ -346,8 +369,7 @@ func (c *Commands) ChangeLanguage(ctx *tgrouter.Ctx) {
 		return
 	}
 
-	_ = ctx.UpdateState("show_main_menu", map[string]string{"last_action": "language_changed"})
-	c.ShowMainMenu(ctx)
+	c.finishOnboarding(ctx, "language_changed")
 }
 
 func (c *Commands) ShowMainMenu(ctx *tgrouter.Ctx) {
@@ -375,6 +397,13 @@ func (c *Commands) ShowMainMenu(ctx *tgrouter.Ctx) {
 		))
 	}
 	rows = append(rows,
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.MyOrdersButton)),
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.SearchButton)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.ReferralButton)),
+		),
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.ContactButton)),
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.LanguageButton)),
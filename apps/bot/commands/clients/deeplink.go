@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+// /start payload til/ism/telefon so'ralayotganda shu kalitda yuradi
+const startPayloadKey = "start_payload"
+
+// onboardingData - onboarding state'lari uchun data, start_payload yo'qolmasin
+func (c *Commands) onboardingData(ctx *tgrouter.Ctx, lastAction string) map[string]string {
+	data := map[string]string{"last_action": lastAction}
+	if payload, _ := ctx.GetStateData(startPayloadKey); payload != "" {
+		data[startPayloadKey] = payload
+	}
+	return data
+}
+
+// finishOnboarding - bosh menyu, keyin /start'dagi deep link (bo'lsa) ochiladi
+func (c *Commands) finishOnboarding(ctx *tgrouter.Ctx, lastAction string) {
+	payload, _ := ctx.GetStateData(startPayloadKey)
+
+	_ = ctx.UpdateState("show_main_menu", map[string]string{"last_action": lastAction})
+	c.ShowMainMenu(ctx)
+	c.openDeepLink(ctx, payload)
+}
+
+// openDeepLink - p_<productId> -> mahsulot kartasi, c_<categoryId> -> kategoriya,
+// promo_<code> -> promo-kod keyingi buyurtmaga saqlanadi. ref_ faqat attribution (AccountMw).
+func (c *Commands) openDeepLink(ctx *tgrouter.Ctx, payload string) {
+	link, ok := utils.ParseDeepLink(payload)
+	if !ok {
+		return
+	}
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	chatID := ctx.Update().FromChat().ID
+	lang := account.Language
+
+	c.logger.Info(ctx.Context, "open deep link",
+		zap.Int64("tg_id", account.TgID),
+		zap.String("payload", payload),
+	)
+
+	switch link.Kind {
+	case utils.DeepLinkProduct:
+		if err := c.ProductCmd.OpenProduct(ctx, chatID, lang, link.Value); err != nil {
+			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.DeepLinkNotFound)))
+		}
+
+	case utils.DeepLinkCategory:
+		if err := c.ProductCmd.OpenCategory(ctx, chatID, account, link.Value); err != nil {
+			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.DeepLinkNotFound)))
+		}
+
+	case utils.DeepLinkPromo:
+		if err := c.ClientSvc.UpdatePromoCode(ctx.Context, account.TgID, link.Value); err != nil {
+			c.logger.Error(ctx.Context, "save promo code failed", zap.Error(err))
+			return
+		}
+		account.PromoCode = link.Value
+
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(texts.Get(lang, texts.DeepLinkPromo), link.Value))
+		_, _ = ctx.Bot().Send(msg)
+	}
+}
@@ -0,0 +1,63 @@
+package clients
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+// ShowReferral - shaxsiy ref_<tgId> havola, statistika va olingan promo-kodlar
+func (c *Commands) ShowReferral(ctx *tgrouter.Ctx) {
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	chatID := ctx.Update().FromChat().ID
+	lang := account.Language
+
+	stats, err := c.ReferralSvc.Stats(ctx.Context, account.TgID)
+	if err != nil {
+		c.logger.Error(ctx.Context, "->ReferralSvc.Stats", zap.Error(err))
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s", ctx.Bot().Self.UserName,
+		utils.DeepLink{Kind: utils.DeepLinkRef, Value: strconv.FormatInt(account.TgID, 10)}.String())
+
+	text := fmt.Sprintf(texts.Get(lang, texts.ReferralInfo),
+		formatAmount(c.ReferralSvc.RewardAmount()), texts.Get(lang, texts.CurrencyUzs),
+		link, stats.Invited, stats.Rewarded, stats.Pending,
+	)
+	if len(stats.Codes) > 0 {
+		text += "\n\n" + fmt.Sprintf(texts.Get(lang, texts.ReferralCodes), strings.Join(stats.Codes, ", "))
+	}
+
+	share := "https://t.me/share/url?url=" + url.QueryEscape(link) + "&text=" + url.QueryEscape(texts.Get(lang, texts.ReferralShareText))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL(texts.Get(lang, texts.ReferralShareBtn), share),
+	))
+	if _, err := ctx.Bot().Send(msg); err != nil {
+		c.logger.Error(ctx.Context, "failed to send referral info", zap.Error(err))
+	}
+}
+
+func formatAmount(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
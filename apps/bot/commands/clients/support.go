@@ -0,0 +1,80 @@
+package clients
+
+import (
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const stateSupportChat = "support_chat"
+
+// OpenSupport - "💬 Operatorga yozish": keyingi xabarlar operator guruhiga ko'chiriladi
+func (c *Commands) OpenSupport(ctx *tgrouter.Ctx) {
+	chatID := ctx.Update().FromChat().ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	if c.SupportSvc.ChatID() == 0 {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.SupportUnavailable)))
+		return
+	}
+
+	_ = ctx.UpdateState(stateSupportChat, map[string]string{
+		"last_action": "support",
+	})
+
+	kb := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.SupportExitButton)),
+		),
+	)
+	kb.ResizeKeyboard = true
+
+	msg := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.SupportWelcome))
+	msg.ReplyMarkup = kb
+	_, _ = ctx.Bot().Send(msg)
+}
+
+// SupportHandler - support_chat state: matn, rasm, ovozli xabar va h.k. operatorga
+func (c *Commands) SupportHandler(ctx *tgrouter.Ctx) {
+	msg := ctx.Update().Message
+	if msg == nil {
+		return
+	}
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	text := strings.TrimSpace(msg.Text)
+	if text == texts.Get(lang, texts.SupportExitButton) || text == texts.Get(lang, texts.BackButton) {
+		c.ShowMainMenu(ctx)
+		return
+	}
+
+	if err := c.SupportSvc.FromClient(ctx.Context, *account, msg); err != nil {
+		c.logger.Error(ctx.Context, "support: relay from client failed", zap.Int64("tgId", account.TgID), zap.Error(err))
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(msg.Chat.ID, texts.Get(lang, texts.SupportSendFailed)))
+	}
+}
+
+// SupportOperatorHandler - support guruhidagi operator javobi mijozga (AccountMw'siz)
+func (c *Commands) SupportOperatorHandler(ctx *tgrouter.Ctx) {
+	if _, err := c.SupportSvc.FromOperator(ctx.Context, ctx.Update().Message); err != nil {
+		c.logger.Error(ctx.Context, "support: relay from operator failed", zap.Error(err))
+	}
+}
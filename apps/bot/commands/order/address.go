@@ -0,0 +1,124 @@
+package order
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+)
+
+// state'dagi strukturali manzil kalitlari (zakaz yaratishda structs.Address'ga qaytadi)
+const (
+	stAddressStreet    = "addressStreet"
+	stAddressHouse     = "addressHouse"
+	stAddressFlat      = "addressFlat"
+	stAddressEntrance  = "addressEntrance"
+	stAddressFloor     = "addressFloor"
+	stAddressDoorphone = "addressDoorphone"
+	stAddressComment   = "addressComment"
+)
+
+// savedAddressLabel - reply tugma matni: "📍 Uy", nomi bo'lmasa manzil matni yoki koordinata
+func savedAddressLabel(a structs.ClientAddress) string {
+	name := strings.TrimSpace(a.Name)
+	if name == "" {
+		name = strings.TrimSpace(a.Address.Name)
+	}
+	if name == "" || name == "geo" {
+		name = fmt.Sprintf("%.4f, %.4f", a.Address.Lat, a.Address.Lng)
+	}
+	if r := []rune(name); len(r) > 40 {
+		name = string(r[:40]) + "…"
+	}
+	return "📍 " + name
+}
+
+func (c *Commands) savedAddresses(ctx *tgrouter.Ctx, tgID int64) []structs.ClientAddress {
+	list, err := c.clientsCmd.ClientSvc.GetAddresses(ctx.Context, tgID)
+	if err != nil {
+		c.logger.Error(ctx.Context, "get saved addresses failed", zap.Error(err), zap.Int64("tg_id", tgID))
+		return nil
+	}
+	return list
+}
+
+// addressKeyboard - saqlangan manzillar (har biri alohida qatorda) + lokatsiya + orqaga
+func addressKeyboard(lang utils.Lang, saved []structs.ClientAddress) tgbotapi.ReplyKeyboardMarkup {
+	if len(saved) == 0 {
+		return locationKeyboard(lang)
+	}
+
+	var rows [][]tgbotapi.KeyboardButton
+	for _, a := range saved {
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(savedAddressLabel(a))))
+	}
+	rows = append(rows,
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonLocation(texts.Get(lang, texts.SendLocationBtn))),
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(texts.Get(lang, texts.BackButton))),
+	)
+
+	kb := tgbotapi.NewReplyKeyboard(rows...)
+	kb.ResizeKeyboard = true
+	return kb
+}
+
+// useDeliveryPoint - lokatsiya yoki saqlangan manzil: zona/narx hisoblanadi.
+// askDetails bo'lsa (yangi lokatsiya) uy/kv/podyezd... so'raladi, aks holda to'g'ri checkout preview.
+func (c *Commands) useDeliveryPoint(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, a structs.Address, askDetails bool) {
+	info := utils.GetDeliveryInfo(c.zones, a.Lat, a.Lng, a.Name)
+	if !info.Available {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.DeliveryZonesNotConfigured)))
+		return
+	}
+
+	data := keepData(ctx)
+	data["deliveryType"] = "DELIVERY"
+	data["addressLat"] = strconv.FormatFloat(a.Lat, 'f', 6, 64)
+	data["addressLng"] = strconv.FormatFloat(a.Lng, 'f', 6, 64)
+	data["addressText"] = a.Name
+	data["deliveryPrice"] = strconv.FormatInt(info.Price, 10)
+	data["distanceKm"] = strconv.FormatFloat(info.DistanceKm, 'f', 2, 64)
+	data[stAddressStreet] = a.Street
+	data[stAddressHouse] = a.House
+	data[stAddressFlat] = a.Flat
+	data[stAddressEntrance] = a.Entrance
+	data[stAddressFloor] = a.Floor
+	data[stAddressDoorphone] = a.Doorphone
+	data[stAddressComment] = a.Comment
+
+	if askDetails {
+		c.startAddressDetails(ctx, chatID, lang, data)
+		return
+	}
+
+	if err := ctx.UpdateState("checkout_preview", data); err != nil {
+		c.logger.Error(ctx.Context, "UpdateState checkout_preview failed", zap.Error(err))
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	c.ShowCheckoutPreview(ctx)
+}
+
+// addressFromState - zakaz uchun manzil (koordinata state'da tekshirilgan bo'lishi kerak)
+func addressFromState(st map[string]string, lat, lng float64) *structs.Address {
+	return &structs.Address{
+		Lat:       lat,
+		Lng:       lng,
+		Name:      strings.TrimSpace(st["addressText"]),
+		Street:    strings.TrimSpace(st[stAddressStreet]),
+		House:     strings.TrimSpace(st[stAddressHouse]),
+		Flat:      strings.TrimSpace(st[stAddressFlat]),
+		Entrance:  strings.TrimSpace(st[stAddressEntrance]),
+		Floor:     strings.TrimSpace(st[stAddressFloor]),
+		Doorphone: strings.TrimSpace(st[stAddressDoorphone]),
+		Comment:   strings.TrimSpace(st[stAddressComment]),
+	}
+}
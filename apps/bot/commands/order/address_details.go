@@ -0,0 +1,142 @@
+package order
+
+import (
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const (
+	stateAddressDetails = "address_details"
+	addrStepKey         = "addrStep"
+)
+
+// addressDetailStep - lokatsiyadan keyin so'raladigan ixtiyoriy maydonlar (tartib bilan)
+type addressDetailStep struct {
+	key    string // state kaliti
+	prompt texts.TextKey
+	max    int // rune
+}
+
+var addressDetailSteps = []addressDetailStep{
+	{key: stAddressHouse, prompt: texts.AddressHouseAsk, max: 16},
+	{key: stAddressFlat, prompt: texts.AddressFlatAsk, max: 16},
+	{key: stAddressEntrance, prompt: texts.AddressEntranceAsk, max: 16},
+	{key: stAddressFloor, prompt: texts.AddressFloorAsk, max: 16},
+	{key: stAddressDoorphone, prompt: texts.AddressDoorphoneAsk, max: 16},
+	{key: stAddressComment, prompt: texts.AddressCommentAsk, max: 255},
+}
+
+func addressStepIndex(key string) int {
+	for i, s := range addressDetailSteps {
+		if s.key == key {
+			return i
+		}
+	}
+	return 0
+}
+
+func addressDetailsKeyboard(lang utils.Lang) tgbotapi.ReplyKeyboardMarkup {
+	kb := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.AddressSkipBtn)),
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.AddressDoneBtn)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.BackButton)),
+		),
+	)
+	kb.ResizeKeyboard = true
+	return kb
+}
+
+// startAddressDetails - birinchi savol (uy raqami); data'da zona/narx allaqachon bor
+func (c *Commands) startAddressDetails(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, data map[string]string) {
+	c.askAddressDetail(ctx, chatID, lang, data, 0)
+}
+
+func (c *Commands) askAddressDetail(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, data map[string]string, idx int) {
+	if idx >= len(addressDetailSteps) {
+		c.finishAddressDetails(ctx, chatID, lang, data)
+		return
+	}
+
+	step := addressDetailSteps[idx]
+	data[addrStepKey] = step.key
+	if err := ctx.UpdateState(stateAddressDetails, data); err != nil {
+		c.logger.Error(ctx.Context, "UpdateState address_details failed", zap.Error(err))
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, texts.Get(lang, step.prompt))
+	msg.ReplyMarkup = addressDetailsKeyboard(lang)
+	_, _ = ctx.Bot().Send(msg)
+}
+
+func (c *Commands) finishAddressDetails(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, data map[string]string) {
+	delete(data, addrStepKey)
+	if err := ctx.UpdateState("checkout_preview", data); err != nil {
+		c.logger.Error(ctx.Context, "UpdateState checkout_preview failed", zap.Error(err))
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+	c.ShowCheckoutPreview(ctx)
+}
+
+// AddressDetailsHandler - address_details state: javob saqlanadi yoki o'tkazib yuboriladi,
+// "✅ Tayyor" qolganlarini o'tkazib preview'ga o'tadi.
+func (c *Commands) AddressDetailsHandler(ctx *tgrouter.Ctx) {
+	msg := ctx.Update().Message
+	if msg == nil {
+		return
+	}
+	chatID := msg.Chat.ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		return
+	}
+	lang := account.Language
+
+	data := keepData(ctx)
+	idx := addressStepIndex(data[addrStepKey])
+	txt := strings.TrimSpace(msg.Text)
+
+	switch txt {
+	case texts.Get(lang, texts.BackButton):
+		// lokatsiyani qayta tanlash
+		delete(data, addrStepKey)
+		if err := ctx.UpdateState("wait_address", data); err != nil {
+			c.logger.Error(ctx.Context, "UpdateState wait_address failed", zap.Error(err))
+		}
+		c.AskLocationOrAddress(ctx)
+		return
+
+	case texts.Get(lang, texts.AddressDoneBtn):
+		c.finishAddressDetails(ctx, chatID, lang, data)
+		return
+
+	case texts.Get(lang, texts.AddressSkipBtn):
+		data[addressDetailSteps[idx].key] = ""
+
+	case "":
+		c.askAddressDetail(ctx, chatID, lang, data, idx)
+		return
+
+	default:
+		if r := []rune(txt); len(r) > addressDetailSteps[idx].max {
+			txt = string(r[:addressDetailSteps[idx].max])
+		}
+		data[addressDetailSteps[idx].key] = txt
+	}
+
+	c.askAddressDetail(ctx, chatID, lang, data, idx+1)
+}
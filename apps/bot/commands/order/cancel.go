@@ -0,0 +1,185 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/order"
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const (
+	stateWaitCancelReason = "wait_cancel_reason"
+	cancelOrderIDKey      = "cancel_order_id"
+)
+
+// tayyor sabablar (callback'da index yuboriladi)
+var cancelReasons = []texts.TextKey{
+	texts.OrderCancelReasonMind,
+	texts.OrderCancelReasonLong,
+	texts.OrderCancelReasonWrong,
+}
+
+// cancelAskCallback - "❌ Bekor qilish" bosilganda sabab so'raymiz.
+// Tayyor sababni tanlash yoki o'zi yozib yuborish mumkin (wait_cancel_reason state).
+func (c *Commands) cancelAskCallback(ctx *tgrouter.Ctx, account *structs.Client, orderID string) {
+	cb := ctx.Update().CallbackQuery
+	lang := account.Language
+
+	ord, ok := c.getOwnOrder(ctx, account, orderID)
+	if !ok {
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+	if !order.IsCancellableByClient(ord.Order) {
+		c.answerCb(ctx, texts.Get(lang, texts.OrderCancelNotAllowed))
+		return
+	}
+
+	data := keepData(ctx)
+	data[cancelOrderIDKey] = ord.Order.ID
+	if err := ctx.UpdateState(stateWaitCancelReason, data); err != nil {
+		c.logger.Error(ctx.Context, "cancel: update state failed", zap.Error(err))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, key := range cancelReasons {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, key), fmt.Sprintf("order_cancel_r:%d:%s", i, ord.Order.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.BackButton), "order_view:"+ord.Order.ID),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID,
+		fmt.Sprintf(texts.Get(lang, texts.OrderCancelAskReason), ord.Order.OrderNumber))
+	edit.ReplyMarkup = &kb
+	_, _ = ctx.Bot().Request(edit)
+	c.answerCb(ctx, "")
+}
+
+// cancelReasonCallback - tayyor sabab tanlandi: order_cancel_r:<idx>:<orderID>
+func (c *Commands) cancelReasonCallback(ctx *tgrouter.Ctx, account *structs.Client, payload string) {
+	cb := ctx.Update().CallbackQuery
+	lang := account.Language
+
+	parts := strings.SplitN(payload, ":", 2)
+	if len(parts) != 2 {
+		c.answerCb(ctx, "")
+		return
+	}
+	idx, err := strconv.Atoi(parts[0])
+	if err != nil || idx < 0 || idx >= len(cancelReasons) {
+		c.answerCb(ctx, "")
+		return
+	}
+	orderID := parts[1]
+
+	// sababni UZ'da saqlaymiz - operator bir xil ko'rsin
+	reason := texts.Get(utils.UZ, cancelReasons[idx])
+	ok, msg := c.cancelOrder(ctx, account, orderID, reason)
+	c.answerCb(ctx, msg)
+	if !ok {
+		return
+	}
+
+	ord, found := c.getOwnOrder(ctx, account, orderID)
+	if !found {
+		return
+	}
+	kb := orderDetailKeyboard(lang, ord.Order)
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, orderDetailHTML(lang, ord.Order))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = &kb
+	_, _ = ctx.Bot().Request(edit)
+}
+
+// CancelReasonHandler - wait_cancel_reason state: mijoz sababni o'zi yozdi.
+func (c *Commands) CancelReasonHandler(ctx *tgrouter.Ctx) {
+	if ctx.Update().Message == nil {
+		return
+	}
+	chatID := ctx.Update().FromChat().ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	orderID, _ := ctx.GetStateData(cancelOrderIDKey)
+	txt := strings.TrimSpace(ctx.Update().Message.Text)
+
+	if orderID == "" || eqBtn(txt, texts.Get(lang, texts.BackButton)) {
+		c.leaveCancelState(ctx)
+		c.clientsCmd.ShowMainMenu(ctx)
+		return
+	}
+	if txt == "" {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	ok, msg := c.cancelOrder(ctx, account, orderID, txt)
+	if !ok {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, msg))
+		return
+	}
+
+	ord, found := c.getOwnOrder(ctx, account, orderID)
+	if !found {
+		return
+	}
+	out := tgbotapi.NewMessage(chatID, orderDetailHTML(lang, ord.Order))
+	out.ParseMode = "HTML"
+	out.ReplyMarkup = orderDetailKeyboard(lang, ord.Order)
+	_, _ = ctx.Bot().Send(out)
+}
+
+// cancelOrder - servisni chaqiradi va mijozga ko'rsatiladigan matnni qaytaradi.
+func (c *Commands) cancelOrder(ctx *tgrouter.Ctx, account *structs.Client, orderID, reason string) (bool, string) {
+	lang := account.Language
+
+	err := c.orderSvc.CancelByClient(ctx.Context, structs.CancelOrderByClient{
+		OrderId: orderID,
+		TgID:    account.TgID,
+		Reason:  reason,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, structs.ErrNotCancellable):
+		c.leaveCancelState(ctx)
+		return false, texts.Get(lang, texts.OrderCancelNotAllowed)
+	case errors.Is(err, structs.ErrTooManyRequests):
+		c.leaveCancelState(ctx)
+		return false, texts.Get(lang, texts.OrderCancelTooMany)
+	default:
+		c.logger.Error(ctx.Context, "cancel: CancelByClient failed", zap.Error(err), zap.String("order_id", orderID))
+		return false, texts.Get(lang, texts.Retry)
+	}
+
+	c.leaveCancelState(ctx)
+	return true, texts.Get(lang, texts.OrderCancelDone)
+}
+
+func (c *Commands) leaveCancelState(ctx *tgrouter.Ctx) {
+	st, _, _ := ctx.GetState()
+	data := keepData(ctx)
+	delete(data, cancelOrderIDKey)
+	if st == stateWaitCancelReason {
+		st = "show_main_menu"
+	}
+	_ = ctx.UpdateState(st, data)
+}
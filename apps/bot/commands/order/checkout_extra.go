@@ -0,0 +1,130 @@
+package order
+
+import (
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"github.com/spf13/cast"
+)
+
+// yetkazish zakazidagi qo'shimcha qadam: naqdda qaytim summasi, online'da kuryerga choy puli
+type checkoutExtra struct {
+	ChangeFrom int64
+	TipAmount  int64
+}
+
+var (
+	changePresets = []int64{100000, 200000, 500000}
+	tipPresets    = []int64{5000, 10000, 20000}
+)
+
+func (c *Commands) askCheckoutExtra(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, online bool) {
+	var (
+		key     = texts.OrderAskChange
+		skip    = texts.OrderNoChangeBtn
+		presets = changePresets
+	)
+	if online {
+		key, skip, presets = texts.OrderAskTip, texts.OrderNoTipBtn, tipPresets
+	}
+
+	row := make([]tgbotapi.KeyboardButton, 0, len(presets))
+	for _, p := range presets {
+		row = append(row, tgbotapi.NewKeyboardButton(formatSom(p)))
+	}
+
+	m := tgbotapi.NewMessage(chatID, texts.Get(lang, key))
+	m.ReplyMarkup = tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(texts.Get(lang, skip))),
+		tgbotapi.NewKeyboardButtonRow(row...),
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(texts.Get(lang, texts.BackButton))),
+	)
+	_, _ = ctx.Bot().Send(m)
+}
+
+// CheckoutExtraHandler - "checkout_extra" state: summa (tugma yoki matn) -> zakaz yaratish
+func (c *Commands) CheckoutExtraHandler(ctx *tgrouter.Ctx) {
+	if ctx.Update().Message == nil {
+		return
+	}
+
+	chatID := ctx.Update().FromChat().ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		return
+	}
+	lang := account.Language
+
+	txt := strings.TrimSpace(ctx.Update().Message.Text)
+
+	_, st, _ := ctx.GetState()
+	if st == nil {
+		st = map[string]string{}
+	}
+
+	paymentMethod := st["paymentMethod"]
+	enabled := c.payments.Enabled(c.paymentBranch(st))
+
+	// Back -> to'lov usulini qayta tanlash
+	if eqBtn(txt, texts.Get(lang, texts.BackButton)) || paymentMethod == "" {
+		delete(st, "paymentMethod")
+		delete(st, "cardId")
+		_ = ctx.UpdateState("select_payment_method", st)
+
+		m := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.OrderChoosePaymentMethod))
+		m.ReplyMarkup = paymentMethodKeyboard(lang, enabled, c.savedCards(ctx, account.TgID, enabled))
+		_, _ = ctx.Bot().Send(m)
+		return
+	}
+
+	online := c.payments.Online(paymentMethod)
+
+	var amount int64
+	skip := texts.OrderNoChangeBtn
+	if online {
+		skip = texts.OrderNoTipBtn
+	}
+	if !eqBtn(txt, texts.Get(lang, skip)) {
+		amount = cast.ToInt64(digitsOnly(txt))
+		if amount <= 0 {
+			c.askCheckoutExtra(ctx, chatID, lang, online)
+			return
+		}
+	}
+
+	var extra checkoutExtra
+	if online {
+		extra.TipAmount = amount
+	} else {
+		extra.ChangeFrom = amount
+	}
+
+	// saqlangan karta tanlangan bo'lsa - qayta topamiz (o'chirilgan bo'lsa oddiy havola)
+	var card structs.ClientCard
+	if cardID := cast.ToInt64(st["cardId"]); cardID != 0 {
+		for _, sc := range c.savedCards(ctx, account.TgID, enabled) {
+			if sc.ID == cardID {
+				card = sc
+				break
+			}
+		}
+	}
+
+	c.placeOrder(ctx, account, st, paymentMethod, card, extra)
+}
+
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -15,9 +15,13 @@ import (
 
 	"sushitana/apps/bot/commands/clients"
 	"sushitana/internal/cart"
+	"sushitana/internal/operator"
 	"sushitana/internal/order"
+	"sushitana/internal/payment/cards"
 	"sushitana/internal/payment/click"
 	"sushitana/internal/payment/payme"
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/rating"
 	"sushitana/internal/structs"
 	"sushitana/internal/texts"
 	"sushitana/pkg/logger"
@@ -36,6 +40,11 @@ type Commands struct {
 	paymeSvc   payme.Service
 	clientsCmd clients.Commands
 	zones      *utils.ZoneChecker
+
+	operatorSvc operator.Service
+	ratingSvc   rating.Service
+	payments    provider.Registry
+	cardSvc     cards.Service
 }
 
 type Params struct {
@@ -47,6 +56,11 @@ type Params struct {
 	PaymeSvc   payme.Service
 	ClientsCmd clients.Commands
 	Zones      *utils.ZoneChecker
+
+	OperatorSvc operator.Service
+	RatingSvc   rating.Service
+	Payments    provider.Registry
+	CardSvc     cards.Service
 }
 
 func New(p Params) Commands {
@@ -58,6 +72,11 @@ func New(p Params) Commands {
 		paymeSvc:   p.PaymeSvc,
 		clientsCmd: p.ClientsCmd,
 		zones:      p.Zones,
+
+		operatorSvc: p.OperatorSvc,
+		ratingSvc:   p.RatingSvc,
+		payments:    p.Payments,
+		cardSvc:     p.CardSvc,
 	}
 }
 
@@ -184,8 +203,14 @@ func (c *Commands) AskLocationOrAddress(ctx *tgrouter.Ctx) {
 	}
 	lang := account.Language
 
-	msg := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.AskSendLocation))
-	msg.ReplyMarkup = locationKeyboard(lang)
+	saved := c.savedAddresses(ctx, account.TgID)
+	prompt := texts.AskSendLocation
+	if len(saved) > 0 {
+		prompt = texts.AskSavedAddress
+	}
+
+	msg := tgbotapi.NewMessage(chatID, texts.Get(lang, prompt))
+	msg.ReplyMarkup = addressKeyboard(lang, saved)
 	_, _ = ctx.Bot().Send(msg)
 }
 
@@ -225,36 +250,28 @@ func (c *Commands) WaitAddressHandler(ctx *tgrouter.Ctx) {
 	}
 
 	if msg.Location != nil {
-		lat := msg.Location.Latitude
-		lng := msg.Location.Longitude
-
 		addressText := strings.TrimSpace(st["addressText"])
 		if addressText == "" {
 			addressText = "geo"
 		}
 
-		info := utils.GetDeliveryInfo(c.zones, lat, lng, addressText)
-		if !info.Available {
-			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.DeliveryZonesNotConfigured)))
-			return
-		}
+		// yangi lokatsiya -> eski saqlangan manzil maydonlari kerak emas, tafsilotlar qayta so'raladi
+		c.useDeliveryPoint(ctx, chatID, lang, structs.Address{
+			Lat:  msg.Location.Latitude,
+			Lng:  msg.Location.Longitude,
+			Name: addressText,
+		}, true)
+		return
+	}
 
-		data := keepData(ctx)
-		data["deliveryType"] = "DELIVERY"
-		data["addressLat"] = strconv.FormatFloat(lat, 'f', 6, 64)
-		data["addressLng"] = strconv.FormatFloat(lng, 'f', 6, 64)
-		data["addressText"] = addressText
-		data["deliveryPrice"] = strconv.FormatInt(info.Price, 10)
-		data["distanceKm"] = strconv.FormatFloat(info.DistanceKm, 'f', 2, 64)
-
-		if err := ctx.UpdateState("checkout_preview", data); err != nil {
-			c.logger.Error(ctx.Context, "UpdateState checkout_preview failed", zap.Error(err))
-			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
-			return
+	// saqlangan manzil tugmasi
+	if strings.HasPrefix(txt, "📍 ") {
+		for _, a := range c.savedAddresses(ctx, account.TgID) {
+			if savedAddressLabel(a) == txt {
+				c.useDeliveryPoint(ctx, chatID, lang, a.Address, false)
+				return
+			}
 		}
-
-		c.ShowCheckoutPreview(ctx)
-		return
 	}
 
 	if txt == "" {
@@ -468,7 +485,8 @@ func (c *Commands) CheckoutPreviewHandler(ctx *tgrouter.Ctx) {
 		_ = ctx.UpdateState("select_payment_method", data)
 
 		m := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.OrderChoosePaymentMethod))
-		m.ReplyMarkup = paymentMethodKeyboard(lang)
+		methods := c.payments.Enabled(c.paymentBranch(data))
+		m.ReplyMarkup = paymentMethodKeyboard(lang, methods, c.savedCards(ctx, account.TgID, methods))
 		_, _ = ctx.Bot().Send(m)
 		return
 	}
@@ -506,20 +524,54 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 		return
 	}
 
-	// payment method parse (button text -> enum)
-	var paymentMethod string
-	switch txt {
-	case "💵 Naqt":
-		paymentMethod = "CASH"
-	case "💳 Payme":
-		paymentMethod = "PAYME"
-	case "💳 Click":
-		paymentMethod = "CLICK"
-	default:
-		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.OrderChoosePaymentMethod)))
+	// payment method parse (button text -> enum), faqat filialda yoqilganlari
+	var (
+		paymentMethod string
+		card          structs.ClientCard
+	)
+	enabled := c.payments.Enabled(c.paymentBranch(st))
+	for _, m := range enabled {
+		if txt == paymentMethodLabel(m) {
+			paymentMethod = m
+			break
+		}
+	}
+	savedCards := c.savedCards(ctx, account.TgID, enabled)
+	if paymentMethod == "" {
+		// "💳 •••• 1234" - saqlangan karta, Payme Subscribe orqali
+		for _, sc := range savedCards {
+			if txt == savedCardLabel(sc) {
+				paymentMethod, card = structs.PaymentMethodPayme, sc
+				break
+			}
+		}
+	}
+	if paymentMethod == "" {
+		m := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.OrderChoosePaymentMethod))
+		m.ReplyMarkup = paymentMethodKeyboard(lang, enabled, savedCards)
+		_, _ = ctx.Bot().Send(m)
+		return
+	}
+
+	// yetkazishda: naqd - qaytim summasi, online - kuryerga choy puli (checkout_extra)
+	if strings.ToUpper(strings.TrimSpace(st["deliveryType"])) != "PICKUP" {
+		st["paymentMethod"] = paymentMethod
+		st["cardId"] = cast.ToString(card.ID)
+		_ = ctx.UpdateState("checkout_extra", st)
+		c.askCheckoutExtra(ctx, chatID, lang, c.payments.Online(paymentMethod))
 		return
 	}
 
+	c.placeOrder(ctx, account, st, paymentMethod, card, checkoutExtra{})
+}
+
+// placeOrder - savatdan zakaz yaratadi va to'lov usuliga qarab keyingi qadamni yuboradi
+func (c *Commands) placeOrder(ctx *tgrouter.Ctx, account *structs.Client, st map[string]string, paymentMethod string, card structs.ClientCard, extra checkoutExtra) {
+	var (
+		chatID = ctx.Update().FromChat().ID
+		lang   = account.Language
+	)
+
 	// cart
 	crt, err := c.cartSvc.GetByUserTgID(ctx.Context, account.TgID)
 	if err != nil || len(crt.Cart.Products) == 0 {
@@ -544,7 +596,6 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 	if deliveryType == "DELIVERY" {
 		latStr := strings.TrimSpace(st["addressLat"])
 		lngStr := strings.TrimSpace(st["addressLng"])
-		name := strings.TrimSpace(st["addressText"])
 
 		if latStr == "" || lngStr == "" {
 			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, "Lokatsiyani yuboring 📍"))
@@ -558,7 +609,14 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 			return
 		}
 
-		addr = &structs.Address{Lat: lat, Lng: lng, Name: name}
+		addr = addressFromState(st, lat, lng)
+	}
+
+	// promo_<code> deep link'dan kelgan kod operator ko'rishi uchun izohga qo'shiladi
+	comment := strings.TrimSpace(st["comment"])
+	if account.PromoCode != "" {
+		promo := fmt.Sprintf(texts.Get(utils.UZ, texts.PromoOrderComment), account.PromoCode)
+		comment = strings.TrimSpace(comment + "\n" + promo)
 	}
 
 	// create order (Create() MUST return orderID)
@@ -567,9 +625,13 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 		DeliveryType:  deliveryType,
 		PaymentMethod: paymentMethod,
 		Address:       addr,
-		Comment:       strings.TrimSpace(st["comment"]),
+		Comment:       comment,
 		DeliveryPrice: deliveryPrice,
 		Products:      toOrderProducts(crt.Cart.Products),
+		CardID:        card.ID,
+		ChangeFrom:    extra.ChangeFrom,
+		TipAmount:     extra.TipAmount,
+		PromoCode:     account.PromoCode,
 	}
 
 	payURL, orderID, err := c.orderSvc.Create(ctx.Context, req)
@@ -594,13 +656,38 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, msg))
 			return
 		}
+		// referal kodi boshqa zakazda band - kodni olib tashlaymiz, mijoz qayta tasdiqlaydi
+		if errors.Is(err, structs.ErrPromoCodeUsed) {
+			if err := c.clientsCmd.ClientSvc.UpdatePromoCode(ctx.Context, account.TgID, ""); err != nil {
+				c.logger.Error(ctx.Context, "clear promo code failed", zap.Error(err), zap.Int64("tg_id", account.TgID))
+			}
+			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(texts.Get(lang, texts.PromoCodeUsed), account.PromoCode)))
+			return
+		}
+		// qaytim / choy puli summasi noto'g'ri - shu qadamda qayta so'raymiz
+		if errors.Is(err, structs.ErrInvalidChange) || errors.Is(err, structs.ErrInvalidTip) {
+			key := texts.OrderChangeTooSmall
+			if errors.Is(err, structs.ErrInvalidTip) {
+				key = texts.OrderTipInvalid
+			}
+			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, key)))
+			c.askCheckoutExtra(ctx, chatID, lang, c.payments.Online(paymentMethod))
+			return
+		}
 
 		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
 		return
 	}
 
-	// CASH: clear cart and finish
-	if paymentMethod == "CASH" {
+	// promo bir marta ishlatiladi (zakaz bekor/rad etilsa order servisi kodni qaytaradi)
+	if account.PromoCode != "" {
+		if err := c.clientsCmd.ClientSvc.UpdatePromoCode(ctx.Context, account.TgID, ""); err != nil {
+			c.logger.Error(ctx.Context, "clear promo code failed", zap.Error(err), zap.Int64("tg_id", account.TgID))
+		}
+	}
+
+	// CASH (offline): clear cart and finish
+	if !c.payments.Online(paymentMethod) {
 		_ = c.cartSvc.Clear(ctx.Context, account.TgID)
 
 		m := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.OrderAcceptedWaitOperator))
@@ -611,7 +698,7 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 		return
 	}
 
-	// PAYME / CLICK: take payment_url from order
+	// online: take payment_url from order
 	ord, err := c.orderSvc.GetByID(ctx.Context, orderID)
 	if err != nil {
 		c.logger.Error(ctx.Context, "get order after create failed", zap.Error(err), zap.String("order_id", orderID))
@@ -619,6 +706,40 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 		return
 	}
 
+	// TELEGRAM: link yo'q, to'g'ridan-to'g'ri invoice yuboramiz
+	if paymentMethod == "TELEGRAM" {
+		if err := c.sendTelegramInvoice(ctx, chatID, lang, ord.Order); err != nil {
+			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+			return
+		}
+
+		rm := tgbotapi.NewMessage(chatID, "\u200b")
+		rm.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+		_, _ = ctx.Bot().Send(rm)
+
+		if err := c.cartSvc.Clear(ctx.Context, account.TgID); err != nil {
+			c.logger.Error(ctx.Context, "cart clear failed", zap.Error(err), zap.Int64("tg_id", account.TgID))
+		}
+		_ = ctx.UpdateState("waiting_payment", map[string]string{"order_id": orderID})
+		return
+	}
+
+	// saqlangan karta: receipts.pay paytida Payme PerformTransaction'ni chaqiradi, zakaz allaqachon PAID
+	if card.ID != 0 {
+		if ord.Order.PaymentStatus == structs.PaymentStatusPaid {
+			m := tgbotapi.NewMessage(chatID, fmt.Sprintf(texts.Get(lang, texts.SavedCardPaid), card.Last4()))
+			m.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+			_, _ = ctx.Bot().Send(m)
+
+			if err := c.cartSvc.Clear(ctx.Context, account.TgID); err != nil {
+				c.logger.Error(ctx.Context, "cart clear failed", zap.Error(err), zap.Int64("tg_id", account.TgID))
+			}
+			_ = ctx.UpdateState("show_main_menu", nil)
+			return
+		}
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(texts.Get(lang, texts.SavedCardFailed), card.Last4())))
+	}
+
 	payURL = strings.TrimSpace(ord.Order.PaymentUrl)
 	if payURL == "" {
 		c.logger.Error(ctx.Context, "payment_url is empty after create", zap.String("order_id", orderID), zap.String("pm", paymentMethod))
@@ -629,10 +750,13 @@ func (c *Commands) SelectPaymentMethodHandler(ctx *tgrouter.Ctx) {
 	text := paymentDetailsHTML(ord)
 
 	btnText := "To‘lash"
-	if paymentMethod == "PAYME" {
+	switch paymentMethod {
+	case structs.PaymentMethodPayme:
 		btnText = "Payme orqali to‘lash"
-	} else {
+	case structs.PaymentMethodClick:
 		btnText = "Click orqali to‘lash"
+	case structs.PaymentMethodUzum:
+		btnText = "Uzum orqali to‘lash"
 	}
 
 	kb := tgbotapi.NewInlineKeyboardMarkup(
@@ -701,22 +825,103 @@ func (c *Commands) previewKeyboard(lang utils.Lang) tgbotapi.ReplyKeyboardMarkup
 	return kb
 }
 
-func paymentMethodKeyboard(lang utils.Lang) tgbotapi.ReplyKeyboardMarkup {
-	btnPayme := tgbotapi.NewKeyboardButton("💳 Payme")
-	btnClick := tgbotapi.NewKeyboardButton("💳 Click")
-	btnCash := tgbotapi.NewKeyboardButton("💵 Naqt")
-	btnBack := tgbotapi.NewKeyboardButton(texts.Get(lang, texts.BackButton))
+// paymentMethodLabel - reply tugma matni (SelectPaymentMethodHandler shu bo'yicha qaytarib topadi)
+func paymentMethodLabel(method string) string {
+	switch method {
+	case structs.PaymentMethodPayme:
+		return "💳 Payme"
+	case structs.PaymentMethodClick:
+		return "💳 Click"
+	case structs.PaymentMethodUzum:
+		return "💳 Uzum"
+	case structs.PaymentMethodTelegram:
+		return telegramPayBtn
+	case structs.PaymentMethodCash:
+		return "💵 Naqt"
+	}
+	return "💳 " + method
+}
 
-	kb := tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(btnPayme, btnClick),
-		tgbotapi.NewKeyboardButtonRow(btnCash),
-		tgbotapi.NewKeyboardButtonRow(btnBack),
+// savedCardLabel - "💳 •••• 1234 (03/27)"
+func savedCardLabel(card structs.ClientCard) string {
+	label := "💳 •••• " + card.Last4()
+	if card.Expire != "" {
+		label += " (" + card.Expire + ")"
+	}
+	return label
+}
+
+// savedCards - Payme yoqilgan filialda mijozning tasdiqlangan kartalari
+func (c *Commands) savedCards(ctx *tgrouter.Ctx, tgID int64, methods []string) []structs.ClientCard {
+	if !c.cardSvc.Enabled() {
+		return nil
+	}
+	for _, m := range methods {
+		if m != structs.PaymentMethodPayme {
+			continue
+		}
+		list, err := c.cardSvc.List(ctx.Context, tgID)
+		if err != nil {
+			c.logger.Error(ctx.Context, "saved cards list failed", zap.Error(err), zap.Int64("tg_id", tgID))
+			return nil
+		}
+		return list
+	}
+	return nil
+}
+
+// paymentMethodKeyboard - saqlangan kartalar birinchi, online usullar 2 tadan qatorda, naqd alohida
+func paymentMethodKeyboard(lang utils.Lang, methods []string, savedCards []structs.ClientCard) tgbotapi.ReplyKeyboardMarkup {
+	var (
+		rows    [][]tgbotapi.KeyboardButton
+		row     []tgbotapi.KeyboardButton
+		hasCash bool
 	)
+	for _, sc := range savedCards {
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(savedCardLabel(sc))))
+	}
+	for _, m := range methods {
+		if m == structs.PaymentMethodCash {
+			hasCash = true
+			continue
+		}
+		row = append(row, tgbotapi.NewKeyboardButton(paymentMethodLabel(m)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if hasCash {
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(paymentMethodLabel(structs.PaymentMethodCash))))
+	}
+	rows = append(rows, tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(texts.Get(lang, texts.BackButton))))
+
+	kb := tgbotapi.NewReplyKeyboard(rows...)
 	kb.ResizeKeyboard = true
 	kb.OneTimeKeyboard = true
 	return kb
 }
 
+// paymentBranch - state'dagi yetkazish turi/lokatsiya bo'yicha filial (payment_methods_<branch>)
+func (c *Commands) paymentBranch(st map[string]string) string {
+	if strings.ToUpper(strings.TrimSpace(st["deliveryType"])) == "PICKUP" {
+		return provider.BranchPickup
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(st["addressLat"]), 64)
+	lng, err2 := strconv.ParseFloat(strings.TrimSpace(st["addressLng"]), 64)
+	if err1 != nil || err2 != nil {
+		return provider.BranchOlmaliq
+	}
+	ok, idx, err := c.zones.ContainsAnyWithIndex(lat, lng)
+	if err != nil || !ok {
+		return provider.BranchOlmaliq
+	}
+	return provider.BranchByZone(idx)
+}
+
 func nameByLang(n structs.Name, lang string) string {
 	switch strings.ToLower(lang) {
 	case "uz":
@@ -806,6 +1011,13 @@ func (c *Commands) WaitingPaymentHandler(ctx *tgrouter.Ctx) {
 		return
 	}
 
+	// TELEGRAM bo'lsa invoice'ni qayta yuboramiz
+	if strings.ToUpper(ord.Order.PaymentMethod) == "TELEGRAM" {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.TgPayResendInvoice)))
+		_ = c.sendTelegramInvoice(ctx, chatID, lang, ord.Order)
+		return
+	}
+
 	// Aks holda linkni qayta ko'rsatamiz
 	payURL := strings.TrimSpace(ord.Order.PaymentUrl)
 	if payURL == "" {
@@ -832,7 +1044,7 @@ func paymentDetailsHTML(ord structs.GetListPrimaryKeyResponse) string {
 		pm = "PAYME"
 	}
 
-	totalStr := formatSom(ord.Order.TotalPrice)
+	totalStr := formatSom(ord.Order.PayAmount)
 	link := strings.TrimSpace(ord.Order.PaymentUrl)
 	linkEsc := html.EscapeString(link)
 
@@ -0,0 +1,362 @@
+package order
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/order"
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const (
+	myOrdersLimit = 10
+
+	// ETA taxminiy: tayyorlash + yo'l (km ga qarab)
+	etaCookingMin   = 30
+	etaDeliveryBase = 15
+	etaPerKmMin     = 3
+)
+
+var tashkentTZ = time.FixedZone("Asia/Tashkent", 5*60*60)
+
+// =====================
+// MY ORDERS (LIST)
+// =====================
+
+// MyOrders - "📋 Buyurtmalarim": oxirgi buyurtmalar ro'yxati (inline tugmalar bilan).
+func (c *Commands) MyOrders(ctx *tgrouter.Ctx) {
+	chatID := ctx.Update().FromChat().ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	text, kb, err := c.buildMyOrdersView(ctx, account.TgID, lang)
+	if err != nil {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if kb != nil {
+		msg.ReplyMarkup = *kb
+	}
+	_, _ = ctx.Bot().Send(msg)
+}
+
+func (c *Commands) buildMyOrdersView(ctx *tgrouter.Ctx, tgID int64, lang utils.Lang) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	resp, err := c.orderSvc.GetByTgId(ctx.Context, tgID)
+	if err != nil {
+		c.logger.Error(ctx.Context, "my orders: GetByTgId failed", zap.Error(err), zap.Int64("tg_id", tgID))
+		return "", nil, err
+	}
+
+	orders := resp.Orders
+	if len(orders) == 0 {
+		return texts.Get(lang, texts.MyOrdersEmpty), nil, nil
+	}
+	if len(orders) > myOrdersLimit {
+		orders = orders[:myOrdersLimit]
+	}
+
+	cur := texts.Get(lang, texts.CurrencySymbol)
+
+	var (
+		b    strings.Builder
+		rows [][]tgbotapi.InlineKeyboardButton
+	)
+	b.WriteString(texts.Get(lang, texts.MyOrdersTitle) + "\n\n")
+
+	for _, o := range orders {
+		fmt.Fprintf(&b, "<b>#%d</b> · %s\n%s · %s %s\n\n",
+			o.OrderNumber,
+			html.EscapeString(o.CreatedAt.In(tashkentTZ).Format("02.01.2006 15:04")),
+			html.EscapeString(texts.OrderStatus(lang, o.Status)),
+			utils.FCurrency(float64(o.TotalPrice)),
+			cur,
+		)
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("#%d · %s", o.OrderNumber, texts.OrderStatus(lang, o.Status)),
+				"order_view:"+o.ID,
+			),
+		))
+	}
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return strings.TrimSpace(b.String()), &kb, nil
+}
+
+// =====================
+// ORDER DETAIL (TRACKING)
+// =====================
+
+// OrderCallback - my orders bo'limidagi inline tugmalar.
+func (c *Commands) OrderCallback(ctx *tgrouter.Ctx) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil || cb.Message == nil {
+		return
+	}
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+
+	data := cb.Data
+	switch {
+	case strings.HasPrefix(data, "my_orders:"):
+		c.myOrdersBackCallback(ctx, account)
+	case strings.HasPrefix(data, "order_view:"):
+		c.orderDetailCallback(ctx, account, strings.TrimPrefix(data, "order_view:"), false)
+	case strings.HasPrefix(data, "order_refresh:"):
+		c.orderDetailCallback(ctx, account, strings.TrimPrefix(data, "order_refresh:"), true)
+	case strings.HasPrefix(data, "order_reorder:"):
+		c.reorderCallback(ctx, account, strings.TrimPrefix(data, "order_reorder:"))
+	case strings.HasPrefix(data, "order_cancel:"):
+		c.cancelAskCallback(ctx, account, strings.TrimPrefix(data, "order_cancel:"))
+	case strings.HasPrefix(data, "order_cancel_r:"):
+		c.cancelReasonCallback(ctx, account, strings.TrimPrefix(data, "order_cancel_r:"))
+	case strings.HasPrefix(data, "order_paym:"):
+		c.changePaymentAskCallback(ctx, account, strings.TrimPrefix(data, "order_paym:"))
+	case strings.HasPrefix(data, "order_paym_set:"):
+		c.changePaymentSetCallback(ctx, account, strings.TrimPrefix(data, "order_paym_set:"))
+	default:
+		c.answerCb(ctx, "")
+	}
+}
+
+func (c *Commands) answerCb(ctx *tgrouter.Ctx, text string) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil {
+		return
+	}
+	_, _ = ctx.Bot().Request(tgbotapi.NewCallback(cb.ID, text))
+}
+
+// getOwnOrder - faqat shu mijozning buyurtmasi (begona id bilan ochib bo'lmasin).
+func (c *Commands) getOwnOrder(ctx *tgrouter.Ctx, account *structs.Client, orderID string) (structs.GetListPrimaryKeyResponse, bool) {
+	orderID = strings.TrimSpace(orderID)
+	if orderID == "" {
+		return structs.GetListPrimaryKeyResponse{}, false
+	}
+
+	ord, err := c.orderSvc.GetByID(ctx.Context, orderID)
+	if err != nil {
+		c.logger.Error(ctx.Context, "order detail: GetByID failed", zap.Error(err), zap.String("order_id", orderID))
+		return structs.GetListPrimaryKeyResponse{}, false
+	}
+	if ord.Order.TgID != account.TgID {
+		c.logger.Warn(ctx.Context, "order detail: foreign order", zap.String("order_id", orderID), zap.Int64("tg_id", account.TgID))
+		return structs.GetListPrimaryKeyResponse{}, false
+	}
+	return ord, true
+}
+
+func (c *Commands) myOrdersBackCallback(ctx *tgrouter.Ctx, account *structs.Client) {
+	cb := ctx.Update().CallbackQuery
+	lang := account.Language
+
+	text, kb, err := c.buildMyOrdersView(ctx, account.TgID, lang)
+	if err != nil {
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = kb
+	_, _ = ctx.Bot().Request(edit)
+	c.answerCb(ctx, "")
+}
+
+func (c *Commands) orderDetailCallback(ctx *tgrouter.Ctx, account *structs.Client, orderID string, refresh bool) {
+	cb := ctx.Update().CallbackQuery
+	lang := account.Language
+
+	ord, ok := c.getOwnOrder(ctx, account, orderID)
+	if !ok {
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+
+	text := orderDetailHTML(lang, ord.Order)
+	kb := orderDetailKeyboard(lang, ord.Order)
+
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = &kb
+	if _, err := ctx.Bot().Request(edit); err != nil {
+		// refresh'da o'zgarish bo'lmasa telegram "message is not modified" qaytaradi
+		if !strings.Contains(err.Error(), "message is not modified") {
+			c.logger.Error(ctx.Context, "order detail: edit failed", zap.Error(err))
+		}
+	}
+
+	if refresh {
+		c.answerCb(ctx, texts.Get(lang, texts.OrderRefreshed))
+		return
+	}
+	c.answerCb(ctx, "")
+}
+
+func (c *Commands) reorderCallback(ctx *tgrouter.Ctx, account *structs.Client, orderID string) {
+	lang := account.Language
+
+	ord, ok := c.getOwnOrder(ctx, account, orderID)
+	if !ok {
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+
+	added := 0
+	for _, p := range ord.Order.Products {
+		if strings.TrimSpace(p.ID) == "" || p.Quantity <= 0 {
+			continue
+		}
+		if err := c.cartSvc.Create(ctx.Context, structs.CreateCart{
+			TGID:      account.TgID,
+			ProductID: p.ID,
+			Count:     p.Quantity,
+		}); err != nil {
+			// mahsulot o'chirilgan / nofaol bo'lishi mumkin - qolganlarini qo'shaveramiz
+			c.logger.Warn(ctx.Context, "reorder: add to cart failed", zap.Error(err), zap.String("product_id", p.ID))
+			continue
+		}
+		added++
+	}
+
+	if added == 0 {
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+
+	c.answerCb(ctx, texts.Get(lang, texts.OrderReorderDone))
+	c.clientsCmd.ProductCmd.GetCartInfo(ctx)
+}
+
+func orderDetailKeyboard(lang utils.Lang, o structs.Order) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.OrderRefreshBtn), "order_refresh:"+o.ID),
+	))
+
+	payURL := strings.TrimSpace(o.PaymentUrl)
+	if o.Status == structs.OrderStatusWaitingPayment && o.PaymentStatus != "PAID" && payURL != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(texts.Get(lang, texts.OrderPayAgainBtn), payURL),
+		))
+	}
+
+	if canChangePayment(o) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.OrderChangePaymentBtn), "order_paym:"+o.ID),
+		))
+	}
+
+	if order.IsCancellableByClient(o) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.OrderCancelBtn), "order_cancel:"+o.ID),
+		))
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.OrderReorderBtn), "order_reorder:"+o.ID),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.BackButton), "my_orders:"),
+		),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func orderDetailHTML(lang utils.Lang, o structs.Order) string {
+	cur := texts.Get(lang, texts.CurrencySymbol)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderDetailTitle), o.OrderNumber)))
+	fmt.Fprintf(&b, "%s\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderDetailDate), o.CreatedAt.In(tashkentTZ).Format("02.01.2006 15:04"))))
+	fmt.Fprintf(&b, "%s\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderNotifyStatusLine), texts.OrderStatus(lang, o.Status))))
+	fmt.Fprintf(&b, "%s\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderDetailPayment), o.PaymentMethod, texts.PaymentStatus(lang, o.PaymentStatus))))
+
+	if o.DeliveryType == structs.DeliveryTypePickup {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(texts.Get(lang, texts.OrderTypePickup)))
+	} else {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(texts.Get(lang, texts.OrderTypeDelivery)))
+		if o.Address != nil && strings.TrimSpace(o.Address.Name) != "" {
+			fmt.Fprintf(&b, "%s\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderDetailAddress), o.Address.Name)))
+		}
+	}
+
+	if eta, ok := estimateEta(o); ok {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderDetailEta), eta.In(tashkentTZ).Format("15:04"))))
+	}
+
+	b.WriteString("\n")
+	for i, p := range o.Products {
+		name := nameByLang(p.ProductName, string(lang))
+		fmt.Fprintf(&b, "%d. %s\n   %d x %s = %s\n",
+			i+1,
+			html.EscapeString(name),
+			p.Quantity,
+			utils.FCurrency(float64(p.ProductPrice)),
+			utils.FCurrency(float64(p.ProductPrice*p.Quantity)),
+		)
+		if p.BoxID != "" && p.BoxPrice > 0 {
+			fmt.Fprintf(&b, "   📦 %s: %d x %s\n",
+				html.EscapeString(nameByLang(p.BoxName, string(lang))),
+				p.Quantity,
+				utils.FCurrency(float64(p.BoxPrice)),
+			)
+		}
+	}
+
+	if o.DeliveryType != structs.DeliveryTypePickup && o.DeliveryPrice > 0 {
+		fmt.Fprintf(&b, "\n%s\n", html.EscapeString(fmt.Sprintf(texts.Get(lang, texts.OrderDetailDelivery), utils.FCurrency(float64(o.DeliveryPrice)))))
+	}
+
+	fmt.Fprintf(&b, "\n<b>%s: %s %s</b>", html.EscapeString(texts.Get(lang, texts.CartTotal)), utils.FCurrency(float64(o.TotalPrice)), html.EscapeString(cur))
+	return b.String()
+}
+
+// estimateEta - taxminiy tayyor/yetkazish vaqti. Yakunlangan/bekor qilingan buyurtmalar uchun yo'q.
+func estimateEta(o structs.Order) (time.Time, bool) {
+	switch o.Status {
+	case structs.OrderStatusDelivered, structs.OrderStatusCompleted,
+		structs.OrderStatusCancelled, structs.OrderStatusRejected,
+		structs.OrderStatusWaitingPayment:
+		return time.Time{}, false
+	}
+
+	minutes := etaCookingMin
+	if o.DeliveryType != structs.DeliveryTypePickup {
+		minutes += etaDeliveryBase
+		if o.Address != nil && o.Address.DistanceKm > 0 {
+			minutes += int(o.Address.DistanceKm * etaPerKmMin)
+		}
+	}
+
+	eta := o.CreatedAt.Add(time.Duration(minutes) * time.Minute)
+	if eta.Before(time.Now()) {
+		return time.Time{}, false
+	}
+	return eta, true
+}
@@ -0,0 +1,125 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/operator"
+	"sushitana/internal/structs"
+	"sushitana/pkg/tgrouter"
+)
+
+// OperatorCallback - admin_chat_id guruhidagi zakaz posti tugmalari (op_*).
+// AccountMw'siz ishlaydi: bosgan odam mijoz emas, employees.tg_id orqali xodim.
+func (c *Commands) OperatorCallback(ctx *tgrouter.Ctx) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil || cb.From == nil {
+		return
+	}
+	data := cb.Data
+
+	// qo'ng'iroq: raqamni ko'rsatish uchun xodim bo'lish shart emas, lekin guruh ichida
+	if strings.HasPrefix(data, operator.CbCall) {
+		c.operatorCall(ctx, strings.TrimPrefix(data, operator.CbCall))
+		return
+	}
+
+	emp, err := c.operatorSvc.Employee(ctx.Context, cb.From.ID)
+	if err != nil {
+		c.answerAlert(ctx, "⛔ Ваш Telegram не привязан к сотруднику")
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(data, operator.CbConfirm):
+		orderID := strings.TrimPrefix(data, operator.CbConfirm)
+		if err := c.orderSvc.ConfirmByOperator(ctx.Context, orderID); err != nil {
+			c.operatorFailed(ctx, orderID, err)
+			return
+		}
+		// boshqa operator ulgurgan bo'lsa (ErrNotWaitingOperator) uning nomi ustiga yozilmaydi
+		c.operatorSvc.MarkAction(ctx.Context, orderID, emp.Id, structs.OperatorActionConfirmed, "")
+		c.operatorSvc.SyncOrder(ctx.Context, orderID)
+		c.answerCb(ctx, "✅ Подтверждено")
+
+	case strings.HasPrefix(data, operator.CbReject):
+		orderID := strings.TrimPrefix(data, operator.CbReject)
+		if cb.Message != nil {
+			edit := tgbotapi.NewEditMessageReplyMarkup(cb.Message.Chat.ID, cb.Message.MessageID, c.operatorSvc.RejectKeyboard(orderID))
+			_, _ = ctx.Bot().Request(edit)
+		}
+		c.answerCb(ctx, "Выберите причину")
+
+	case strings.HasPrefix(data, operator.CbRejectReason):
+		orderID, code, _ := strings.Cut(strings.TrimPrefix(data, operator.CbRejectReason), ":")
+		reason, ok := operator.RejectReasonText(code)
+		if !ok {
+			c.answerCb(ctx, "")
+			return
+		}
+		if err := c.orderSvc.RejectByOperator(ctx.Context, structs.RejectOrderByOperator{
+			OrderId:    orderID,
+			EmployeeID: emp.Id,
+			Reason:     reason,
+		}); err != nil {
+			c.operatorFailed(ctx, orderID, err)
+			return
+		}
+		// kim rad etgani faqat status o'zgargandan keyin yoziladi, post shundan keyin yangilanadi
+		c.operatorSvc.MarkAction(ctx.Context, orderID, emp.Id, structs.OperatorActionRejected, reason)
+		c.operatorSvc.SyncOrder(ctx.Context, orderID)
+		c.answerCb(ctx, "❌ Отклонено")
+
+	case strings.HasPrefix(data, operator.CbBack):
+		c.operatorSvc.SyncOrder(ctx.Context, strings.TrimPrefix(data, operator.CbBack))
+		c.answerCb(ctx, "")
+
+	default:
+		c.answerCb(ctx, "")
+	}
+}
+
+func (c *Commands) operatorCall(ctx *tgrouter.Ctx, orderID string) {
+	ord, err := c.orderSvc.GetByID(ctx.Context, orderID)
+	if err != nil {
+		c.answerAlert(ctx, "Заказ не найден")
+		return
+	}
+	phone := strings.TrimSpace(ord.Phone)
+	if phone == "" {
+		phone = strings.TrimSpace(ord.Order.Phone)
+	}
+	if phone == "" {
+		c.answerAlert(ctx, "У клиента нет номера")
+		return
+	}
+	c.answerAlert(ctx, fmt.Sprintf("📞 %s\n👤 %s", phone, strings.TrimSpace(ord.Order.Name)))
+}
+
+// operatorFailed - status allaqachon o'zgargan bo'lsa postni yangilab qo'yamiz
+func (c *Commands) operatorFailed(ctx *tgrouter.Ctx, orderID string, err error) {
+	if errors.Is(err, structs.ErrNotWaitingOperator) {
+		c.operatorSvc.SyncOrder(ctx.Context, orderID)
+		c.answerAlert(ctx, "Заказ уже обработан")
+		return
+	}
+	if errors.Is(err, structs.ErrOrderPaid) {
+		c.operatorSvc.SyncOrder(ctx.Context, orderID)
+		c.answerAlert(ctx, "Заказ оплачен онлайн: отклонить нельзя. Подтвердите и оформите возврат в панели")
+		return
+	}
+	c.logger.Error(ctx.Context, "operator action failed", zap.String("orderId", orderID), zap.Error(err))
+	c.answerAlert(ctx, "Ошибка, попробуйте ещё раз")
+}
+
+func (c *Commands) answerAlert(ctx *tgrouter.Ctx, text string) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil {
+		return
+	}
+	_, _ = ctx.Bot().Request(tgbotapi.NewCallbackWithAlert(cb.ID, text))
+}
@@ -0,0 +1,111 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+)
+
+// canChangePayment - to'lanmagan WAITING_PAYMENT zakazda usulni almashtirish mumkin
+func canChangePayment(o structs.Order) bool {
+	return o.Status == structs.OrderStatusWaitingPayment && o.PaymentStatus != structs.PaymentStatusPaid
+}
+
+// changePaymentAskCallback - "🔀 To'lov usulini o'zgartirish": filialda yoqilgan usullar ro'yxati.
+// Joriy usul ham ko'rsatiladi - tanlansa havola qayta yaratiladi.
+func (c *Commands) changePaymentAskCallback(ctx *tgrouter.Ctx, account *structs.Client, orderID string) {
+	cb := ctx.Update().CallbackQuery
+	lang := account.Language
+
+	ord, ok := c.getOwnOrder(ctx, account, orderID)
+	if !ok {
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+	if !canChangePayment(ord.Order) {
+		c.answerCb(ctx, texts.Get(lang, texts.OrderPaymentNotAllowed))
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, m := range c.payments.Enabled(c.paymentBranch(orderBranchState(ord.Order))) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(paymentMethodLabel(m), fmt.Sprintf("order_paym_set:%s:%s", m, ord.Order.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.BackButton), "order_view:"+ord.Order.ID),
+	))
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID,
+		fmt.Sprintf(texts.Get(lang, texts.OrderChangePaymentAsk), ord.Order.OrderNumber))
+	edit.ReplyMarkup = &kb
+	_, _ = ctx.Bot().Request(edit)
+	c.answerCb(ctx, "")
+}
+
+// changePaymentSetCallback - usul tanlandi: order_paym_set:<METHOD>:<orderID>
+func (c *Commands) changePaymentSetCallback(ctx *tgrouter.Ctx, account *structs.Client, payload string) {
+	cb := ctx.Update().CallbackQuery
+	lang := account.Language
+
+	parts := strings.SplitN(payload, ":", 2)
+	if len(parts) != 2 {
+		c.answerCb(ctx, "")
+		return
+	}
+	method, orderID := parts[0], parts[1]
+
+	_, err := c.orderSvc.ChangePaymentMethod(ctx.Context, structs.ChangePaymentMethod{
+		OrderId:       orderID,
+		TgID:          account.TgID,
+		PaymentMethod: method,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, structs.ErrOrderNotPayable), errors.Is(err, structs.ErrPaymentMethodDisabled):
+		c.answerCb(ctx, texts.Get(lang, texts.OrderPaymentNotAllowed))
+		return
+	default:
+		c.logger.Error(ctx.Context, "change payment: ChangePaymentMethod failed", zap.Error(err), zap.String("order_id", orderID))
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+	c.answerCb(ctx, texts.Get(lang, texts.OrderPaymentChanged))
+
+	ord, found := c.getOwnOrder(ctx, account, orderID)
+	if !found {
+		return
+	}
+	// yangi havola "💳 To'lash" tugmasida; TELEGRAM'da invoice alohida xabar
+	kb := orderDetailKeyboard(lang, ord.Order)
+	edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, orderDetailHTML(lang, ord.Order))
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = &kb
+	_, _ = ctx.Bot().Request(edit)
+
+	if ord.Order.PaymentMethod == structs.PaymentMethodTelegram {
+		if err := c.sendTelegramInvoice(ctx, cb.Message.Chat.ID, lang, ord.Order); err != nil {
+			_, _ = ctx.Bot().Send(tgbotapi.NewMessage(cb.Message.Chat.ID, texts.Get(lang, texts.Retry)))
+		}
+	}
+}
+
+// orderBranchState - paymentBranch checkout state'dan ishlaydi, zakazdan shunga o'giramiz
+func orderBranchState(o structs.Order) map[string]string {
+	st := map[string]string{"deliveryType": o.DeliveryType}
+	if o.Address != nil {
+		st["addressLat"] = cast.ToString(o.Address.Lat)
+		st["addressLng"] = cast.ToString(o.Address.Lng)
+	}
+	return st
+}
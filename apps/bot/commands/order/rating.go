@@ -0,0 +1,175 @@
+package order
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/rating"
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const (
+	stateWaitRatingComment = "wait_rating_comment"
+	ratingOrderIDKey       = "rating_order_id"
+	maxRatingCommentLen    = 1000
+)
+
+// RatingCallback - yetkazilgandan keyingi so'rov tugmalari (rate_*):
+// ovqat yulduzlari -> yetkazish yulduzlari (DELIVERY bo'lsa) -> ixtiyoriy izoh.
+func (c *Commands) RatingCallback(ctx *tgrouter.Ctx) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil || cb.Message == nil {
+		return
+	}
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+	data := cb.Data
+
+	switch {
+	case strings.HasPrefix(data, rating.CbFood):
+		orderID, score, ok := parseRatingScore(strings.TrimPrefix(data, rating.CbFood))
+		if !ok {
+			c.answerCb(ctx, "")
+			return
+		}
+		r, err := c.ratingSvc.RateFood(ctx.Context, account.TgID, orderID, score)
+		if err != nil {
+			c.ratingFailed(ctx, lang, orderID, err)
+			return
+		}
+		if r.Delivery == nil && strings.ToUpper(r.DeliveryType) != "PICKUP" {
+			kb := rating.StarsKeyboard(rating.CbDelivery, orderID)
+			edit := tgbotapi.NewEditMessageTextAndMarkup(cb.Message.Chat.ID, cb.Message.MessageID, texts.Get(lang, texts.RatingAskDelivery), kb)
+			_, _ = ctx.Bot().Request(edit)
+			c.answerCb(ctx, "")
+			return
+		}
+		c.askRatingComment(ctx, lang, orderID)
+
+	case strings.HasPrefix(data, rating.CbDelivery):
+		orderID, score, ok := parseRatingScore(strings.TrimPrefix(data, rating.CbDelivery))
+		if !ok {
+			c.answerCb(ctx, "")
+			return
+		}
+		if _, err := c.ratingSvc.RateDelivery(ctx.Context, account.TgID, orderID, score); err != nil {
+			c.ratingFailed(ctx, lang, orderID, err)
+			return
+		}
+		c.askRatingComment(ctx, lang, orderID)
+
+	case strings.HasPrefix(data, rating.CbSkip):
+		c.leaveRatingState(ctx)
+		edit := tgbotapi.NewEditMessageText(cb.Message.Chat.ID, cb.Message.MessageID, texts.Get(lang, texts.RatingThanks))
+		_, _ = ctx.Bot().Request(edit)
+		c.answerCb(ctx, "")
+
+	default:
+		c.answerCb(ctx, "")
+	}
+}
+
+// RatingCommentHandler - wait_rating_comment state: mijoz izoh yozdi.
+func (c *Commands) RatingCommentHandler(ctx *tgrouter.Ctx) {
+	if ctx.Update().Message == nil {
+		return
+	}
+	chatID := ctx.Update().FromChat().ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	orderID, _ := ctx.GetStateData(ratingOrderIDKey)
+	txt := strings.TrimSpace(ctx.Update().Message.Text)
+
+	if orderID == "" || eqBtn(txt, texts.Get(lang, texts.BackButton)) {
+		c.leaveRatingState(ctx)
+		c.clientsCmd.ShowMainMenu(ctx)
+		return
+	}
+	if txt == "" {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.RatingAskComment)))
+		return
+	}
+	if r := []rune(txt); len(r) > maxRatingCommentLen {
+		txt = string(r[:maxRatingCommentLen])
+	}
+
+	if err := c.ratingSvc.Comment(ctx.Context, account.TgID, orderID, txt); err != nil {
+		c.logger.Error(ctx.Context, "rating: comment failed", zap.String("orderId", orderID), zap.Error(err))
+	}
+
+	c.leaveRatingState(ctx)
+	_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.RatingThanks)))
+	c.clientsCmd.ShowMainMenu(ctx)
+}
+
+func (c *Commands) askRatingComment(ctx *tgrouter.Ctx, lang utils.Lang, orderID string) {
+	cb := ctx.Update().CallbackQuery
+
+	data := keepData(ctx)
+	data[ratingOrderIDKey] = orderID
+	if err := ctx.UpdateState(stateWaitRatingComment, data); err != nil {
+		c.logger.Error(ctx.Context, "rating: update state failed", zap.Error(err))
+	}
+
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.RatingSkipBtn), rating.CbSkip+orderID),
+	))
+	edit := tgbotapi.NewEditMessageTextAndMarkup(cb.Message.Chat.ID, cb.Message.MessageID, texts.Get(lang, texts.RatingAskComment), kb)
+	_, _ = ctx.Bot().Request(edit)
+	c.answerCb(ctx, "")
+}
+
+// ratingFailed - eski/begona so'rov bo'lsa tugmalarni olib tashlaymiz
+func (c *Commands) ratingFailed(ctx *tgrouter.Ctx, lang utils.Lang, orderID string, err error) {
+	cb := ctx.Update().CallbackQuery
+	if !errors.Is(err, structs.ErrNotFound) {
+		c.logger.Error(ctx.Context, "rating: save failed", zap.String("orderId", orderID), zap.Error(err))
+		c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+	edit := tgbotapi.NewEditMessageReplyMarkup(cb.Message.Chat.ID, cb.Message.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	_, _ = ctx.Bot().Request(edit)
+	c.answerCb(ctx, "")
+}
+
+func (c *Commands) leaveRatingState(ctx *tgrouter.Ctx) {
+	st, _, _ := ctx.GetState()
+	data := keepData(ctx)
+	delete(data, ratingOrderIDKey)
+	if st == stateWaitRatingComment {
+		st = "show_main_menu"
+	}
+	_ = ctx.UpdateState(st, data)
+}
+
+// parseRatingScore - "<orderID>:<1..5>"
+func parseRatingScore(payload string) (string, int, bool) {
+	orderID, raw, ok := strings.Cut(payload, ":")
+	if !ok || orderID == "" {
+		return "", 0, false
+	}
+	score, err := strconv.Atoi(raw)
+	if err != nil || score < 1 || score > 5 {
+		return "", 0, false
+	}
+	return orderID, score, true
+}
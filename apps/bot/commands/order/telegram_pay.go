@@ -0,0 +1,194 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/order"
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const telegramPayBtn = "💳 Telegram"
+
+// telegramProviderToken - BotFather'dan olingan provider token (bo'sh bo'lsa Telegram to'lov o'chiq)
+func telegramProviderToken() string {
+	return strings.TrimSpace(os.Getenv("TELEGRAM_PAYMENT_PROVIDER_TOKEN"))
+}
+
+// telegramInvoicePrices - invoice ichida har bir mahsulot/box/yetkazish alohida qatorda.
+// Qatorlar yig'indisi PayAmount'ga (mahsulotlar + yetkazish + choy puli - chegirma) teng bo'lmasa bitta umumiy qator qaytaramiz.
+func telegramInvoicePrices(lang utils.Lang, o structs.Order) []tgbotapi.LabeledPrice {
+	var (
+		prices []tgbotapi.LabeledPrice
+		sum    int64
+	)
+
+	for _, p := range o.Products {
+		if p.Quantity <= 0 {
+			continue
+		}
+		amount := p.ProductPrice * p.Quantity
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  fmt.Sprintf("%s x %d", nameByLang(p.ProductName, string(lang)), p.Quantity),
+			Amount: int(order.TelegramAmount(amount)),
+		})
+		sum += amount
+
+		if p.BoxID != "" && p.BoxPrice > 0 {
+			boxAmount := p.BoxPrice * p.Quantity
+			prices = append(prices, tgbotapi.LabeledPrice{
+				Label:  fmt.Sprintf("📦 %s x %d", nameByLang(p.BoxName, string(lang)), p.Quantity),
+				Amount: int(order.TelegramAmount(boxAmount)),
+			})
+			sum += boxAmount
+		}
+	}
+
+	if o.DeliveryType != structs.DeliveryTypePickup && o.DeliveryPrice > 0 {
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  texts.Get(lang, texts.TgInvoiceDelivery),
+			Amount: int(order.TelegramAmount(o.DeliveryPrice)),
+		})
+		sum += o.DeliveryPrice
+	}
+	if o.TipAmount > 0 {
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  texts.Get(lang, texts.TgInvoiceTip),
+			Amount: int(order.TelegramAmount(o.TipAmount)),
+		})
+		sum += o.TipAmount
+	}
+	// Telegram manfiy qatorni chegirma sifatida ko'rsatadi
+	if o.DiscountAmount > 0 {
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  fmt.Sprintf(texts.Get(lang, texts.TgInvoiceDiscount), o.PromoCode),
+			Amount: -int(order.TelegramAmount(o.DiscountAmount)),
+		})
+		sum -= o.DiscountAmount
+	}
+
+	if sum != o.PayAmount || len(prices) == 0 {
+		return []tgbotapi.LabeledPrice{{
+			Label:  fmt.Sprintf(texts.Get(lang, texts.TgInvoiceTitle), o.OrderNumber),
+			Amount: int(order.TelegramAmount(o.PayAmount)),
+		}}
+	}
+	return prices
+}
+
+// sendTelegramInvoice - zakaz uchun sendInvoice. payload = order id (pre-checkout'da tekshiriladi)
+func (c *Commands) sendTelegramInvoice(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, o structs.Order) error {
+	inv := tgbotapi.NewInvoice(
+		chatID,
+		fmt.Sprintf(texts.Get(lang, texts.TgInvoiceTitle), o.OrderNumber),
+		texts.Get(lang, texts.TgInvoiceDescription),
+		o.ID,
+		telegramProviderToken(),
+		"",
+		order.TelegramCurrency,
+		telegramInvoicePrices(lang, o),
+		nil,
+	)
+	// nil slice "null" bo'lib ketadi, telegram buni qabul qilmaydi
+	inv.SuggestedTipAmounts = []int{}
+
+	if _, err := ctx.Bot().Send(inv); err != nil {
+		c.logger.Error(ctx.Context, "telegram sendInvoice failed", zap.Error(err), zap.String("order_id", o.ID))
+		return err
+	}
+	return nil
+}
+
+// PreCheckoutHandler - pre_checkout_query: 10 soniya ichida javob berish shart.
+// AccountMw'siz ishlaydi (bu update'da chat yo'q).
+func (c *Commands) PreCheckoutHandler(ctx *tgrouter.Ctx) {
+	q := ctx.Update().PreCheckoutQuery
+	if q == nil || q.From == nil {
+		return
+	}
+
+	lang := utils.UZ
+	switch strings.ToLower(q.From.LanguageCode) {
+	case "ru":
+		lang = utils.RU
+	case "en":
+		lang = utils.EN
+	}
+
+	err := c.orderSvc.CheckTelegramPreCheckout(ctx.Context, structs.TelegramPayment{
+		OrderID:     q.InvoicePayload,
+		TgID:        q.From.ID,
+		Currency:    q.Currency,
+		TotalAmount: int64(q.TotalAmount),
+	})
+
+	answer := tgbotapi.PreCheckoutConfig{PreCheckoutQueryID: q.ID, OK: err == nil}
+	if err != nil {
+		c.logger.Warn(ctx.Context, "telegram pre-checkout rejected",
+			zap.Error(err),
+			zap.String("order_id", q.InvoicePayload),
+			zap.Int64("tg_id", q.From.ID),
+		)
+		if errors.Is(err, structs.ErrAmountMismatch) {
+			answer.ErrorMessage = texts.Get(lang, texts.TgPayAmountChanged)
+		} else {
+			answer.ErrorMessage = texts.Get(lang, texts.TgPayOrderClosed)
+		}
+	}
+
+	if _, err := ctx.Bot().Request(answer); err != nil {
+		c.logger.Error(ctx.Context, "answerPreCheckoutQuery failed", zap.Error(err))
+	}
+}
+
+// SuccessfulPaymentHandler - successful_payment xabari: zakaz PAID bo'ladi va iiko'ga ketadi.
+func (c *Commands) SuccessfulPaymentHandler(ctx *tgrouter.Ctx) {
+	msg := ctx.Update().Message
+	if msg == nil || msg.SuccessfulPayment == nil {
+		return
+	}
+	chatID := msg.Chat.ID
+	sp := msg.SuccessfulPayment
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	err := c.orderSvc.ConfirmTelegramPayment(ctx.Context, structs.TelegramPayment{
+		OrderID:                 sp.InvoicePayload,
+		TgID:                    account.TgID,
+		Currency:                sp.Currency,
+		TotalAmount:             int64(sp.TotalAmount),
+		TelegramPaymentChargeID: sp.TelegramPaymentChargeID,
+		ProviderPaymentChargeID: sp.ProviderPaymentChargeID,
+	})
+	if err != nil {
+		c.logger.Error(ctx.Context, "ConfirmTelegramPayment failed",
+			zap.Error(err),
+			zap.String("order_id", sp.InvoicePayload),
+			zap.String("charge_id", sp.TelegramPaymentChargeID),
+		)
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	_ = c.cartSvc.Clear(ctx.Context, account.TgID)
+
+	m := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.TgPaySuccess))
+	m.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	_, _ = ctx.Bot().Send(m)
+
+	_ = ctx.UpdateState("show_main_menu", nil)
+}
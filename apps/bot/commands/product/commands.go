@@ -25,6 +25,9 @@ import (
 
 var Module = fx.Provide(New)
 
+// rasmi yo'q mahsulotlar uchun
+const defaultProductImg = "https://sushitana.s3.us-east-1.amazonaws.com/40446061-66cb-4eb2-871f-c01a3f431789.png"
+
 type Params struct {
 	fx.In
 	Logger      logger.Logger
@@ -115,6 +118,10 @@ func (c *Commands) ShowMainMenu(ctx *tgrouter.Ctx) {
 	}
 
 	rows = append(rows,
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.MyOrdersButton)),
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.SearchButton)),
+		),
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.ContactButton)),
 			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.LanguageButton)),
@@ -348,6 +355,21 @@ func (c *Commands) ProductInfo(ctx *tgrouter.Ctx) {
 		return
 	}
 
+	// categoryName RU bo'lib state'da turadi
+	_, data, _ := ctx.GetState()
+	categoryName := ""
+	if data != nil {
+		categoryName = strings.TrimSpace(data["category_name"])
+	}
+	if categoryName == "" {
+		categoryName = "menu"
+	}
+
+	c.sendProductCard(ctx, chatID, lang, resp, categoryName)
+}
+
+// sendProductCard - rasm + nom/tarkib/narx + ➖/➕, savatga, ortga (qidiruvdan ham shu karta)
+func (c *Commands) sendProductCard(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, resp structs.Product, categoryName string) {
 	// RU nom/desc
 	name := getProductNameByLang(utils.RU, resp.Name)
 	description := getProductDescriptionByLang(utils.RU, resp.Description)
@@ -375,16 +397,6 @@ func (c *Commands) ProductInfo(ctx *tgrouter.Ctx) {
 	fmt.Fprintf(&b, "\n*%s %s*", priceStr, texts.Get(lang, texts.CurrencySymbol))
 	caption := b.String()
 
-	// categoryName RU bo'lib state'da turadi
-	_, data, _ := ctx.GetState()
-	categoryName := ""
-	if data != nil {
-		categoryName = strings.TrimSpace(data["category_name"])
-	}
-	if categoryName == "" {
-		categoryName = "menu"
-	}
-
 	// -------- inline keyboard (BIR xil builder) ----------
 	qty := 1
 	keyboard := buildProductInlineKeyboard(lang, resp.ID, qty, categoryName)
@@ -392,7 +404,7 @@ func (c *Commands) ProductInfo(ctx *tgrouter.Ctx) {
 	// -------- photo ----------
 	imgSource := strings.TrimSpace(resp.ImgUrl)
 	if imgSource == "" {
-		imgSource = "https://sushitana.s3.us-east-1.amazonaws.com/40446061-66cb-4eb2-871f-c01a3f431789.png"
+		imgSource = defaultProductImg
 	}
 
 	photoMsg := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(imgSource))
@@ -445,6 +457,9 @@ func (c *Commands) Callback(ctx *tgrouter.Ctx) {
 	case strings.HasPrefix(data, "open_cart:"):
 		c.OpenCartCallback(ctx)
 		return
+	case strings.HasPrefix(data, cart.CbRemindersOff):
+		c.CartRemindersOffCallback(ctx)
+		return
 	case strings.HasPrefix(data, "cart_inc:"):
 		c.CartQtyChangeCallback(ctx, +1)
 		return
@@ -460,6 +475,12 @@ func (c *Commands) Callback(ctx *tgrouter.Ctx) {
 	case strings.HasPrefix(data, "cart_back:"):
 		c.CartBackCallback(ctx)
 		return
+	case strings.HasPrefix(data, "search_pick:"):
+		c.SearchPickCallback(ctx)
+		return
+	case strings.HasPrefix(data, "inl_add:"):
+		c.InlineAddCallback(ctx)
+		return
 	default:
 		_ = c.answerCb(ctx, "")
 	}
@@ -483,6 +504,28 @@ func (c *Commands) OpenCartCallback(ctx *tgrouter.Ctx) {
 	c.GetCartInfo(ctx)
 }
 
+// CartRemindersOffCallback - savat eslatmasidagi "🔕" tugmasi
+func (c *Commands) CartRemindersOffCallback(ctx *tgrouter.Ctx) {
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		return
+	}
+	if err := c.CartSvc.DisableReminders(ctx.Context, account.TgID); err != nil {
+		c.logger.Error(ctx.Context, "->CartSvc.DisableReminders", zap.Error(err))
+		_ = c.answerCb(ctx, "")
+		return
+	}
+	_ = c.answerCb(ctx, texts.Get(account.Language, texts.CartReminderOffDone))
+
+	// tugmani olib tashlaymiz, "oformit" qoladi
+	if msg := ctx.Update().CallbackQuery.Message; msg != nil {
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(account.Language, texts.CartReminderCompleteBtn), cart.CbOpenCart),
+		))
+		_, _ = ctx.Bot().Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, kb))
+	}
+}
+
 // =====================
 // FIXED: QTY CALLBACK (NO DELTA-BASED PARSING)
 // =====================
@@ -732,6 +775,15 @@ func (c *Commands) CategoryByProductMenuCallback(ctx *tgrouter.Ctx) {
 		c.logger.Error(ctx.Context, "failed to delete product message", zap.Error(err))
 	}
 
+	c.sendCategoryProducts(ctx, chatID, account, name, products)
+
+	_ = c.answerCb(ctx, "")
+}
+
+// sendCategoryProducts - kategoriya mahsulotlari reply keyboard'da (savat, mahsulotlar, ortga)
+func (c *Commands) sendCategoryProducts(ctx *tgrouter.Ctx, chatID int64, account *structs.Client, name string, products []structs.Product) {
+	lang := account.Language
+
 	var keyboardRows [][]tgbotapi.KeyboardButton
 	var row []tgbotapi.KeyboardButton
 
@@ -777,8 +829,6 @@ func (c *Commands) CategoryByProductMenuCallback(ctx *tgrouter.Ctx) {
 		"last_action":   "show_products",
 		"category_name": name,
 	})
-
-	_ = c.answerCb(ctx, "")
 }
 
 // =====================
@@ -0,0 +1,60 @@
+package product
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+)
+
+// productCategoryName - "Ortga" uchun mahsulot kategoriyasining RU nomi (topilmasa "menu")
+func (c *Commands) productCategoryName(ctx *tgrouter.Ctx, p structs.Product) string {
+	if p.ParentGroup == "" {
+		return "menu"
+	}
+	cat, err := c.CategorySvc.GetByID(ctx.Context, p.ParentGroup)
+	if err != nil || strings.TrimSpace(cat.Name.Ru) == "" {
+		return "menu"
+	}
+	return strings.TrimSpace(cat.Name.Ru)
+}
+
+// OpenProduct - id bo'yicha mahsulot kartasi (qidiruv, p_<id> deep link)
+func (c *Commands) OpenProduct(ctx *tgrouter.Ctx, chatID int64, lang utils.Lang, productID string) error {
+	resp, err := c.ProductSvc.GetByID(ctx.Context, strings.TrimSpace(productID))
+	if err != nil {
+		c.logger.Error(ctx.Context, "open product failed", zap.Error(err), zap.String("product_id", productID))
+		return err
+	}
+	if !resp.IsActive {
+		return structs.ErrNotFound
+	}
+
+	c.sendProductCard(ctx, chatID, lang, resp, c.productCategoryName(ctx, resp))
+	return nil
+}
+
+// OpenCategory - c_<id> deep link: kategoriya mahsulotlari ro'yxati (product_selected state)
+func (c *Commands) OpenCategory(ctx *tgrouter.Ctx, chatID int64, account *structs.Client, categoryID string) error {
+	cat, err := c.CategorySvc.GetByID(ctx.Context, strings.TrimSpace(categoryID))
+	if err != nil {
+		c.logger.Error(ctx.Context, "open category failed", zap.Error(err), zap.String("category_id", categoryID))
+		return err
+	}
+
+	// mahsulotlar RU kategoriya nomi bo'yicha olinadi (state ham shunday)
+	name := strings.TrimSpace(cat.Name.Ru)
+	products, err := c.ProductSvc.GetListCategoryName(ctx.Context, name)
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return structs.ErrNotFound
+	}
+
+	c.sendCategoryProducts(ctx, chatID, account, name, products)
+	return nil
+}
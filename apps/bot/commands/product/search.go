@@ -0,0 +1,260 @@
+package product
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const (
+	stateProductSearch = "product_search"
+
+	searchBotLimit    = 10
+	searchInlineLimit = 20
+)
+
+// searchName - tanlangan tildagi nom, bo'sh bo'lsa RU (iiko faqat RU nom beradi)
+func searchName(lang utils.Lang, n structs.Name) string {
+	if name := strings.TrimSpace(getProductNameByLang(lang, n)); name != "" {
+		return name
+	}
+	return n.Ru
+}
+
+func searchPrice(lang utils.Lang, p structs.Product) string {
+	price := 0.0
+	if len(p.SizePrices) > 0 {
+		price = p.SizePrices[0].Price.CurrentPrice
+	}
+	return fmt.Sprintf("%s %s", utils.FCurrency(price), texts.Get(lang, texts.CurrencySymbol))
+}
+
+// AskSearch - bosh menyudagi "🔍 Qidirish": keyingi matn qidiruv so'rovi bo'ladi
+func (c *Commands) AskSearch(ctx *tgrouter.Ctx) {
+	chatID := ctx.Update().FromChat().ID
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	_ = ctx.UpdateState(stateProductSearch, map[string]string{
+		"last_action": "search",
+	})
+
+	kb := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(texts.Get(lang, texts.BackButton)),
+		),
+	)
+	kb.ResizeKeyboard = true
+
+	msg := tgbotapi.NewMessage(chatID, texts.Get(lang, texts.SearchPrompt))
+	msg.ReplyMarkup = kb
+	_, _ = ctx.Bot().Send(msg)
+}
+
+// SearchHandler - product_search state: har bir matn yangi qidiruv
+func (c *Commands) SearchHandler(ctx *tgrouter.Ctx) {
+	if ctx.Update().Message == nil {
+		return
+	}
+	chatID := ctx.Update().FromChat().ID
+	text := strings.TrimSpace(ctx.Update().Message.Text)
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	if text == texts.Get(lang, texts.BackButton) {
+		c.ShowMainMenu(ctx)
+		return
+	}
+	if text == "" {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.SearchPrompt)))
+		return
+	}
+
+	products, err := c.ProductSvc.Search(ctx.Context, text, searchBotLimit)
+	if err != nil {
+		c.logger.Error(ctx.Context, "product search failed", zap.Error(err), zap.String("query", text))
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, texts.Get(lang, texts.Retry)))
+		return
+	}
+
+	if len(products) == 0 {
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, fmt.Sprintf(texts.Get(lang, texts.SearchEmpty), text)))
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, p := range products {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s · %s", searchName(lang, p.Name), searchPrice(lang, p)),
+				"search_pick:"+p.ID,
+			),
+		))
+	}
+	// shu so'rovni inline rejimda (rasmlar bilan) ochish
+	query := text
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.InlineKeyboardButton{
+		Text:                         texts.Get(lang, texts.SearchInlineBtn),
+		SwitchInlineQueryCurrentChat: &query,
+	}))
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(texts.Get(lang, texts.SearchResults), text))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, _ = ctx.Bot().Send(msg)
+}
+
+// SearchPickCallback - search_pick:<productID> -> oddiy mahsulot kartasi (➖/➕, savatga)
+func (c *Commands) SearchPickCallback(ctx *tgrouter.Ctx) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil || cb.Message == nil {
+		return
+	}
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	productID := strings.TrimSpace(strings.TrimPrefix(cb.Data, "search_pick:"))
+	if err := c.OpenProduct(ctx, cb.Message.Chat.ID, lang, productID); err != nil {
+		_ = c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+	_ = c.answerCb(ctx, "")
+}
+
+// InlineQueryHandler - "@bot phila": rasm, narx va "savatga" tugmasi bilan natijalar.
+// Inline query'da chat yo'q -> AccountMw'siz, til Telegram'dagi language_code'dan.
+func (c *Commands) InlineQueryHandler(ctx *tgrouter.Ctx) {
+	q := ctx.Update().InlineQuery
+	if q == nil {
+		return
+	}
+
+	lang := utils.UZ
+	if q.From != nil {
+		if l, ok := utils.ParseLang(strings.ToLower(q.From.LanguageCode)); ok {
+			lang = l
+		}
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: q.ID,
+		CacheTime:     60,
+		Results:       []interface{}{},
+	}
+
+	if query := strings.TrimSpace(q.Query); query != "" {
+		products, err := c.ProductSvc.Search(ctx.Context, query, searchInlineLimit)
+		if err != nil {
+			c.logger.Error(ctx.Context, "inline search failed", zap.Error(err), zap.String("query", query))
+		}
+		for _, p := range products {
+			answer.Results = append(answer.Results, c.inlineProductResult(ctx, lang, p))
+		}
+	}
+
+	if _, err := ctx.Bot().Request(answer); err != nil {
+		c.logger.Error(ctx.Context, "answerInlineQuery failed", zap.Error(err))
+	}
+}
+
+func (c *Commands) inlineProductResult(ctx *tgrouter.Ctx, lang utils.Lang, p structs.Product) tgbotapi.InlineQueryResultArticle {
+	name := searchName(lang, p.Name)
+	price := searchPrice(lang, p)
+
+	img := strings.TrimSpace(p.ImgUrl)
+	if img == "" {
+		img = defaultProductImg
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(name))
+	if desc := strings.TrimSpace(getProductDescriptionByLang(utils.RU, p.Description)); desc != "" {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(desc))
+	}
+	fmt.Fprintf(&b, "\n💰 <b>%s</b>", price)
+
+	res := tgbotapi.NewInlineQueryResultArticleHTML(p.ID, name, b.String())
+	res.Description = price
+	res.ThumbURL = img
+	res.InputMessageContent = tgbotapi.InputTextMessageContent{
+		Text:      b.String(),
+		ParseMode: "HTML",
+		// rasm link preview sifatida chiqadi
+		LinkPreviewOptions: &tgbotapi.LinkPreviewOptions{
+			URL:              img,
+			PreferLargeMedia: true,
+		},
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.AddToCart), "inl_add:"+p.ID),
+		),
+	}
+	if username := ctx.Bot().Self.UserName; username != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(texts.Get(lang, texts.SearchInlineOpen), "https://t.me/"+username+"?start=p_"+p.ID),
+		))
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	res.ReplyMarkup = &kb
+
+	return res
+}
+
+// InlineAddCallback - inline xabardagi "savatga" (inl_add:<productID>).
+// Bunday callback'da Message yo'q (inline_message_id), shuning uchun faqat toast bilan javob.
+func (c *Commands) InlineAddCallback(ctx *tgrouter.Ctx) {
+	cb := ctx.Update().CallbackQuery
+	if cb == nil {
+		return
+	}
+
+	account, _ := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+	if account == nil {
+		c.logger.Error(ctx.Context, "account not found")
+		return
+	}
+	lang := account.Language
+
+	productID := strings.TrimSpace(strings.TrimPrefix(cb.Data, "inl_add:"))
+	if productID == "" {
+		_ = c.answerCb(ctx, "")
+		return
+	}
+
+	if err := c.CartSvc.Create(ctx.Context, structs.CreateCart{
+		TGID:      account.TgID,
+		ProductID: productID,
+		Count:     1,
+	}); err != nil {
+		c.logger.Error(ctx.Context, "inline: add to cart failed", zap.Error(err), zap.String("product_id", productID))
+		_ = c.answerCb(ctx, texts.Get(lang, texts.Retry))
+		return
+	}
+
+	_ = c.answerCb(ctx, texts.Get(lang, texts.SearchAdded))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sushitana/internal/client"
+	"sushitana/internal/referral"
 	"sushitana/internal/structs"
 	"sushitana/internal/texts"
 	"sushitana/pkg/logger"
@@ -20,8 +21,9 @@ var Module = fx.Provide(New)
 
 type Params struct {
 	fx.In
-	Logger    logger.Logger
-	ClientSvc client.Service
+	Logger      logger.Logger
+	ClientSvc   client.Service
+	ReferralSvc referral.Service
 }
 
 type Middleware interface {
@@ -29,21 +31,33 @@ type Middleware interface {
 }
 
 type mw struct {
-	logger    logger.Logger
-	clientSvc client.Service
+	logger      logger.Logger
+	clientSvc   client.Service
+	referralSvc referral.Service
 }
 
 func New(p Params) Middleware {
 	return &mw{
-		clientSvc: p.ClientSvc,
-		logger:    p.Logger,
+		clientSvc:   p.ClientSvc,
+		referralSvc: p.ReferralSvc,
+		logger:      p.Logger,
 	}
 }
 
 func (m *mw) AccountMw(next tgrouter.Handler) tgrouter.Handler {
 	return func(c *tgrouter.Ctx) {
 
-		tgID := c.Update().FromChat().ID
+		// inline xabardagi callback'da chat yo'q -> yuboruvchidan olamiz
+		var tgID int64
+		chat := c.Update().FromChat()
+		switch {
+		case chat != nil:
+			tgID = chat.ID
+		case c.Update().SentFrom() != nil:
+			tgID = c.Update().SentFrom().ID
+		default:
+			return
+		}
 
 		account, err := m.clientSvc.GetByTgID(c.Context, tgID)
 		if err != nil {
@@ -51,13 +65,20 @@ func (m *mw) AccountMw(next tgrouter.Handler) tgrouter.Handler {
 
 				m.logger.Info(c.Context, "User not found, creating new", zap.Int64("tgid", tgID))
 
-				account, err = m.clientSvc.Create(c.Context, structs.CreateClient{TgID: tgID})
+				source := startSource(c.Update())
+				account, err = m.clientSvc.Create(c.Context, structs.CreateClient{
+					TgID:   tgID,
+					Source: source,
+				})
 				if err != nil {
 					m.logger.Error(c.Context, "failed to create account", zap.Error(err))
 					_, _ = c.Bot().Send(tgbotapi.NewMessage(tgID, "Xatolik, keyinroq urinib ko‘ring"))
 					return
 				}
 
+				// ref_<tgId> - faqat yangi mijoz uchun, mavjudlar qayta taklif qilinmaydi
+				m.referralSvc.Record(c.Context, tgID, source)
+
 			} else {
 				m.logger.Error(c.Context, "failed to get account", zap.Error(err))
 				_, _ = c.Bot().Send(tgbotapi.NewMessage(tgID, texts.Get(utils.UZ, texts.Retry)))
@@ -65,11 +86,32 @@ func (m *mw) AccountMw(next tgrouter.Handler) tgrouter.Handler {
 			}
 		}
 
+		// broadcast'da bloklagan deb belgilangan mijoz qaytib keldi
+		if !account.IsActive {
+			if err := m.clientSvc.SetActive(c.Context, tgID, true); err == nil {
+				account.IsActive = true
+			}
+		}
+
 		c.Context = context.WithValue(c.Context, ctxman.AccountKey{}, &account)
 
-		typing := tgbotapi.NewChatAction(tgID, tgbotapi.ChatTyping)
-		c.Bot().Send(typing)
+		if chat != nil {
+			typing := tgbotapi.NewChatAction(tgID, tgbotapi.ChatTyping)
+			c.Bot().Send(typing)
+		}
 
 		next(c)
 	}
 }
+
+// startSource - yangi mijozni olib kelgan /start payload (p_, c_, promo_, ref_), attribution uchun
+func startSource(u *tgbotapi.Update) string {
+	if u.Message == nil || !u.Message.IsCommand() || u.Message.Command() != "start" {
+		return ""
+	}
+	link, ok := utils.ParseDeepLink(u.Message.CommandArguments())
+	if !ok {
+		return ""
+	}
+	return link.String()
+}
@@ -0,0 +1,223 @@
+package bot
+
+import (
+	"strings"
+
+	"sushitana/apps/bot/commands/category"
+	"sushitana/apps/bot/commands/clients"
+	"sushitana/apps/bot/commands/order"
+	"sushitana/apps/bot/commands/product"
+	"sushitana/apps/bot/middleware"
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/utils/ctxman"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+)
+
+// Handlers - route'lar uchun kerakli command'lar.
+// NewBot ham, offline harness (pkg/tgrouter/tgtest) ham shu orqali route'larni ulaydi.
+type Handlers struct {
+	Logger     logger.Logger
+	Middleware middleware.Middleware
+
+	ClientsCmd  clients.Commands
+	CategoryCmd category.Commands
+	ProductCmd  product.Commands
+	OrderCmd    order.Commands
+}
+
+func RegisterRoutes(r *tgrouter.Router, h Handlers) {
+	// pre_checkout_query'da chat yo'q -> AccountMw'siz
+	payments := r.Group()
+	tgrouter.On(payments, tgrouter.PreCheckout(), h.OrderCmd.PreCheckoutHandler)
+
+	// inline qidiruv (@bot phila) - bunda ham chat yo'q
+	inline := r.Group()
+	tgrouter.On(inline, tgrouter.InlineQuery(), h.ProductCmd.InlineQueryHandler)
+
+	// operator guruhi (admin_chat_id) zakaz posti tugmalari: bosgan odam mijoz emas -> AccountMw'siz
+	operators := r.Group()
+	tgrouter.On(operators, tgrouter.CallbackPrefix("op_"), h.OrderCmd.OperatorCallback)
+
+	// support guruhi: operator javoblari mijozga (guruh a'zolari uchun account yaratilmasin)
+	support := r.Group()
+	tgrouter.On(support, tgrouter.Chat(h.ClientsCmd.SupportSvc.ChatID()), h.ClientsCmd.SupportOperatorHandler)
+
+	bot := r.Group()
+	bot.Use(h.Middleware.AccountMw)
+
+	// commands
+	tgrouter.On(bot, tgrouter.Cmd("start"), h.ClientsCmd.Start)
+
+	// telegram payments: successful_payment har qanday state'da keladi
+	tgrouter.On(bot, tgrouter.OnPayment(), h.OrderCmd.SuccessfulPaymentHandler)
+
+	// states (clients)
+	tgrouter.On(bot, tgrouter.State("show_main_menu"), func(ctx *tgrouter.Ctx) {
+		if ctx.Update().Message != nil {
+			account, ok := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+			if ok && account != nil && eqBtn(ctx.Update().Message.Text, texts.Get(account.Language, texts.MyOrdersButton)) {
+				h.OrderCmd.MyOrders(ctx)
+				return
+			}
+			if ok && account != nil && eqBtn(ctx.Update().Message.Text, texts.Get(account.Language, texts.SearchButton)) {
+				h.ProductCmd.AskSearch(ctx)
+				return
+			}
+		}
+
+		h.ClientsCmd.MainMenuHandler(ctx)
+	})
+	tgrouter.On(bot, tgrouter.State("waiting_change_language"), h.ClientsCmd.ChangeLanguage)
+	tgrouter.On(bot, tgrouter.State("waiting_for_name"), h.ClientsCmd.SaveName)
+	tgrouter.On(bot, tgrouter.State("waiting_for_phone"), h.ClientsCmd.ChangePhone)
+
+	// cart state wrapper: ✅ Подтвердить! -> select_delivery_type, aks holda oddiy cart
+	tgrouter.On(bot, tgrouter.State("get_cart"), func(ctx *tgrouter.Ctx) {
+		if ctx.Update().Message != nil {
+			account, ok := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+			if ok && account != nil {
+				lang := account.Language
+				txt := ctx.Update().Message.Text
+
+				if eqBtn(txt, texts.Get(lang, texts.CartConfirm)) {
+					_, data, _ := ctx.GetState()
+					if data == nil {
+						data = map[string]string{}
+					}
+
+					_ = ctx.UpdateState("select_delivery_type", data)
+					h.OrderCmd.Confirm(ctx)
+					return
+				}
+			}
+		}
+
+		h.ProductCmd.GetCartInfoHandler(ctx)
+	})
+
+	// catalog
+	tgrouter.On(bot, tgrouter.State("category_selected"), h.ProductCmd.CategoryByProductMenu)
+	tgrouter.On(bot, tgrouter.State("product_selected"), h.ProductCmd.ProductInfoHandler)
+	tgrouter.On(bot, tgrouter.State("product_search"), h.ProductCmd.SearchHandler)
+
+	// support chat (mijoz -> operator)
+	tgrouter.On(bot, tgrouter.State("support_chat"), h.ClientsCmd.SupportHandler)
+
+	// delivery type
+	tgrouter.On(bot, tgrouter.State("select_delivery_type"), func(ctx *tgrouter.Ctx) {
+		if ctx.Update().Message != nil {
+			account, ok := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+			if ok && account != nil {
+				lang := account.Language
+				txt := ctx.Update().Message.Text
+
+				if txt == texts.Get(lang, texts.BackButton) {
+					_, data, _ := ctx.GetState()
+					if data == nil {
+						data = map[string]string{}
+					}
+
+					_ = ctx.UpdateState("get_cart", data)
+					h.ProductCmd.ShowCartView(ctx)
+					return
+				}
+			}
+		}
+
+		h.OrderCmd.DeliveryTypeHandler(ctx)
+	})
+
+	// delivery address (location/text)
+	tgrouter.On(bot, tgrouter.State("wait_address"), h.OrderCmd.WaitAddressHandler)
+	// lokatsiyadan keyin uy/kv/podyezd/qavat/domofon/izoh (ixtiyoriy)
+	tgrouter.On(bot, tgrouter.State("address_details"), h.OrderCmd.AddressDetailsHandler)
+
+	// pickup branch (placeholder)
+	tgrouter.On(bot, tgrouter.State("wait_pickup_branch"), func(ctx *tgrouter.Ctx) {
+		if ctx.Update().Message == nil {
+			return
+		}
+
+		chatID := ctx.Update().FromChat().ID
+
+		account, ok := ctx.Context.Value(ctxman.AccountKey{}).(*structs.Client)
+		if !ok || account == nil {
+			h.Logger.Error(ctx.Context, "account not found")
+			return
+		}
+
+		lang := account.Language
+		txt := strings.TrimSpace(ctx.Update().Message.Text)
+
+		if txt == texts.Get(lang, texts.BackButton) {
+			_, data, _ := ctx.GetState()
+			if data == nil {
+				data = map[string]string{}
+			}
+
+			_ = ctx.UpdateState("select_delivery_type", data)
+			h.OrderCmd.Confirm(ctx)
+			return
+		}
+
+		_, _ = ctx.Bot().Send(tgbotapi.NewMessage(chatID, "Филиал tanlash hali yozilmagan. ⬅️ Назад bosing."))
+	})
+
+	// checkout preview (tasdiqlash/cancel/back logikasi order paketida)
+	tgrouter.On(bot, tgrouter.State("checkout_preview"), h.OrderCmd.CheckoutPreviewHandler)
+
+	// ✅ payment method: real handler
+	tgrouter.On(bot, tgrouter.State("select_payment_method"), h.OrderCmd.SelectPaymentMethodHandler)
+	tgrouter.On(bot, tgrouter.State("checkout_extra"), h.OrderCmd.CheckoutExtraHandler)
+	tgrouter.On(bot, tgrouter.State("waiting_payment"), h.OrderCmd.WaitingPaymentHandler)
+
+	// my orders -> bekor qilish sababi (matn)
+	tgrouter.On(bot, tgrouter.State("wait_cancel_reason"), h.OrderCmd.CancelReasonHandler)
+
+	// yetkazilgandan keyingi baho -> ixtiyoriy izoh (matn)
+	tgrouter.On(bot, tgrouter.State("wait_rating_comment"), h.OrderCmd.RatingCommentHandler)
+
+	// callbacks
+	tgrouter.On(bot, tgrouter.Callback(""), func(ctx *tgrouter.Ctx) {
+		if ctx.Update().CallbackQuery == nil {
+			return
+		}
+		data := ctx.Update().CallbackQuery.Data
+
+		switch {
+		case strings.HasPrefix(data, "back_to_menu:"),
+			strings.HasPrefix(data, "qty_inc:"),
+			strings.HasPrefix(data, "qty_dec:"),
+			strings.HasPrefix(data, "add_to_cart:"),
+			strings.HasPrefix(data, "open_cart:"),
+			strings.HasPrefix(data, "cart_rem_off:"),
+			strings.HasPrefix(data, "cart_inc:"),
+			strings.HasPrefix(data, "cart_dec:"),
+			strings.HasPrefix(data, "cart_del:"),
+			strings.HasPrefix(data, "cart_clear:"),
+			strings.HasPrefix(data, "cart_back:"),
+			strings.HasPrefix(data, "search_pick:"),
+			strings.HasPrefix(data, "inl_add:"),
+			strings.HasPrefix(data, "noop:"),
+			data == "noop":
+			h.ProductCmd.Callback(ctx)
+
+		case strings.HasPrefix(data, "my_orders:"),
+			strings.HasPrefix(data, "order_view:"),
+			strings.HasPrefix(data, "order_refresh:"),
+			strings.HasPrefix(data, "order_reorder:"),
+			strings.HasPrefix(data, "order_cancel:"),
+			strings.HasPrefix(data, "order_cancel_r:"),
+			strings.HasPrefix(data, "order_paym:"),
+			strings.HasPrefix(data, "order_paym_set:"):
+			h.OrderCmd.OrderCallback(ctx)
+
+		case strings.HasPrefix(data, "rate_"):
+			h.OrderCmd.RatingCallback(ctx)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"sushitana/apps/bot/commands/clients"
+	"sushitana/apps/bot/commands/order"
+	"sushitana/internal/cart"
+	"sushitana/internal/client"
+	"sushitana/internal/payment/cards"
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/structs"
+	"sushitana/internal/support"
+	"sushitana/internal/texts"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/tgrouter"
+	"sushitana/pkg/tgrouter/tgtest"
+	"sushitana/pkg/utils"
+	"sushitana/pkg/utils/ctxman"
+)
+
+const testTgID = int64(1001)
+
+// olmaliqZone - saqlangan manzilni o'z ichiga oluvchi kvadrat (koordinata [lng, lat])
+var olmaliqZone = []byte(`{"type":"Polygon","coordinates":[[[69.55,40.80],[69.70,40.80],[69.70,40.90],[69.55,40.90],[69.55,40.80]]]}`)
+
+// accountMw - DB'siz AccountMw: tayyor mijozni ctx'ga qo'yadi
+type accountMw struct{ account structs.Client }
+
+func (m accountMw) AccountMw(next tgrouter.Handler) tgrouter.Handler {
+	return func(c *tgrouter.Ctx) {
+		account := m.account
+		c.Context = context.WithValue(c.Context, ctxman.AccountKey{}, &account)
+		next(c)
+	}
+}
+
+type memCart struct {
+	cart.Service
+	crt structs.GetCartByTgID
+}
+
+func (s memCart) GetByUserTgID(context.Context, int64) (structs.GetCartByTgID, error) {
+	return s.crt, nil
+}
+
+type memClients struct {
+	client.Service
+	addresses []structs.ClientAddress
+}
+
+func (s memClients) GetAddresses(context.Context, int64) ([]structs.ClientAddress, error) {
+	return s.addresses, nil
+}
+
+// noSupport - support guruhi sozlanmagan (ChatID 0 -> route hech narsaga mos kelmaydi)
+type noSupport struct{ support.Service }
+
+func (noSupport) ChatID() int64 { return 0 }
+
+// branchPayments - filial bo'yicha yoqilgan usullar; qaysi filial so'ralganini eslab qoladi
+type branchPayments struct {
+	provider.Registry
+	methods map[string][]string
+	asked   *[]string
+}
+
+func (r branchPayments) Enabled(branch string) []string {
+	*r.asked = append(*r.asked, branch)
+	return r.methods[branch]
+}
+
+type noCards struct{ cards.Service }
+
+func (noCards) Enabled() bool { return false }
+
+func TestCheckoutFlowSavedAddress(t *testing.T) {
+	log := logger.New("error")
+	lang := utils.RU
+
+	home := structs.ClientAddress{
+		TgID: testTgID,
+		Name: "Uy",
+		Address: structs.Address{
+			Lat:  40.8501,
+			Lng:  69.6002,
+			Name: "Olmaliq, Navoiy 12",
+		},
+	}
+	clientSvc := memClients{addresses: []structs.ClientAddress{home}}
+
+	var asked []string
+	payments := branchPayments{
+		methods: map[string][]string{
+			provider.BranchOlmaliq: {structs.PaymentMethodClick, structs.PaymentMethodPayme, structs.PaymentMethodCash},
+		},
+		asked: &asked,
+	}
+
+	clientsCmd := clients.Commands{ClientSvc: clientSvc, SupportSvc: noSupport{}}
+	h := Handlers{
+		Logger:     log,
+		Middleware: accountMw{account: structs.Client{TgID: testTgID, Language: lang, Name: "Ali", Phone: "+998901234567"}},
+		ClientsCmd: clientsCmd,
+		OrderCmd: order.New(order.Params{
+			Logger: log,
+			CartSvc: memCart{crt: structs.GetCartByTgID{
+				TGID: testTgID,
+				Cart: structs.CartInfo{
+					TotalPrice: 90000,
+					Products: []structs.ProductCart{
+						{Id: "p1", Count: 2, Price: 45000, Name: structs.Name{Uz: "Filadelfiya", Ru: "Филадельфия"}},
+					},
+				},
+			}},
+			ClientsCmd: clientsCmd,
+			Zones:      utils.NewZoneChecker(olmaliqZone),
+			Payments:   payments,
+			CardSvc:    noCards{},
+		}),
+	}
+
+	hr, err := tgtest.New(log, func(r *tgrouter.Router) { RegisterRoutes(r, h) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hr.State.Set(context.Background(), int(testTgID), int(testTgID), "get_cart", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	err = hr.Play(
+		tgtest.Step{
+			Name: "cart confirm",
+			Do:   tgtest.SendText(testTgID, texts.Get(lang, texts.CartConfirm)),
+			Expect: []tgtest.Expectation{
+				tgtest.ExpectState(testTgID, "select_delivery_type"),
+				tgtest.ExpectText(texts.Get(lang, texts.OrderDeliveryTypeChoose)),
+				tgtest.ExpectButton(texts.Get(lang, texts.DeliveryBtn)),
+				tgtest.ExpectButton(texts.Get(lang, texts.PickupBtn)),
+			},
+		},
+		tgtest.Step{
+			Name: "delivery",
+			Do:   tgtest.SendText(testTgID, texts.Get(lang, texts.DeliveryBtn)),
+			Expect: []tgtest.Expectation{
+				tgtest.ExpectState(testTgID, "wait_address"),
+				tgtest.ExpectText(texts.Get(lang, texts.AskSavedAddress)),
+				tgtest.ExpectButton("📍 Uy"),
+			},
+		},
+		tgtest.Step{
+			Name: "saved address",
+			Do:   tgtest.SendText(testTgID, "📍 Uy"),
+			Expect: []tgtest.Expectation{
+				tgtest.ExpectState(testTgID, "checkout_preview"),
+				tgtest.ExpectText("Филадельфия"),
+				tgtest.ExpectText("2 x " + utils.FCurrency(45000) + " = " + utils.FCurrency(90000)),
+				tgtest.ExpectButton(texts.Get(lang, texts.CartConfirm)),
+			},
+		},
+		tgtest.Step{
+			Name: "preview confirm",
+			Do:   tgtest.SendText(testTgID, texts.Get(lang, texts.CartConfirm)),
+			Expect: []tgtest.Expectation{
+				tgtest.ExpectState(testTgID, "select_payment_method"),
+				tgtest.ExpectText(texts.Get(lang, texts.OrderChoosePaymentMethod)),
+				tgtest.ExpectButton("💳 Click"),
+				tgtest.ExpectButton("💳 Payme"),
+				tgtest.ExpectButton("💵 Naqt"),
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, data := hr.CurrentState(testTgID)
+	if data["deliveryType"] != "DELIVERY" || data["addressText"] != home.Address.Name {
+		t.Fatalf("state data = %v", data)
+	}
+	if data["addressLat"] != "40.850100" || data["addressLng"] != "69.600200" {
+		t.Fatalf("address coords = %s, %s", data["addressLat"], data["addressLng"])
+	}
+	if len(asked) != 1 || asked[0] != provider.BranchOlmaliq {
+		t.Fatalf("payment branch = %v, want [%s]", asked, provider.BranchOlmaliq)
+	}
+}
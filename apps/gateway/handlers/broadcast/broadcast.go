@@ -0,0 +1,236 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"sushitana/internal/broadcast"
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/reply"
+	"sushitana/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	Module = fx.Provide(New)
+)
+
+type (
+	Handler interface {
+		CreateBroadcast(c *gin.Context)
+		GetListBroadcast(c *gin.Context)
+		GetByIDBroadcast(c *gin.Context)
+		PreviewBroadcast(c *gin.Context)
+		TestBroadcast(c *gin.Context)
+		StartBroadcast(c *gin.Context)
+		PauseBroadcast(c *gin.Context)
+		ResumeBroadcast(c *gin.Context)
+		CancelBroadcast(c *gin.Context)
+	}
+	Params struct {
+		fx.In
+		Logger           logger.Logger
+		BroadcastService broadcast.Service
+	}
+
+	handler struct {
+		logger           logger.Logger
+		broadcastService broadcast.Service
+	}
+)
+
+func New(p Params) Handler {
+	return &handler{
+		logger:           p.Logger,
+		broadcastService: p.BroadcastService,
+	}
+}
+
+func (h *handler) CreateBroadcast(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.CreateBroadcast
+		ctx      = c.Request.Context()
+	)
+
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	if me, ok := c.Get("me"); ok {
+		if m, ok := me.(structs.GetMeResponse); ok {
+			request.CreatedBy = m.ID
+		}
+	}
+
+	resp, err := h.broadcastService.Create(ctx, request)
+	if err != nil {
+		if errors.Is(err, structs.ErrBadRequest) {
+			response = responses.BadRequest
+			return
+		}
+		h.logger.Error(ctx, " err on h.broadcastService.Create", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+func (h *handler) GetListBroadcast(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		filter   = structs.GetListBroadcastRequest{
+			Offset: int64(utils.StrToInt(c.Query("offset"))),
+			Limit:  int64(utils.StrToInt(c.Query("limit"))),
+			Status: c.Query("status"),
+		}
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	list, err := h.broadcastService.GetList(ctx, filter)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.broadcastService.GetList", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+func (h *handler) GetByIDBroadcast(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	resp, err := h.broadcastService.GetByID(ctx, cast.ToInt64(c.Param("id")))
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.broadcastService.GetByID", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// PreviewBroadcast - POST /broadcast/preview: segment bo'yicha auditoriya soni
+func (h *handler) PreviewBroadcast(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.BroadcastSegment
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+
+	resp, err := h.broadcastService.Preview(ctx, request)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.broadcastService.Preview", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// TestBroadcast - POST /broadcast/:id/test {tg_id}: bitta odamga sinov
+func (h *handler) TestBroadcast(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.BroadcastTest
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil || request.TgID == 0 {
+		response = responses.BadRequest
+		return
+	}
+
+	if err := h.broadcastService.SendTest(ctx, cast.ToInt64(c.Param("id")), request.TgID); err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.broadcastService.SendTest", zap.Error(err))
+		response = responses.BadRequest
+		response.Message = err.Error()
+		return
+	}
+
+	response = responses.Success
+}
+
+func (h *handler) StartBroadcast(c *gin.Context) {
+	h.action(c, "Start", h.broadcastService.Start)
+}
+
+func (h *handler) PauseBroadcast(c *gin.Context) {
+	h.action(c, "Pause", h.broadcastService.Pause)
+}
+
+func (h *handler) ResumeBroadcast(c *gin.Context) {
+	h.action(c, "Resume", h.broadcastService.Resume)
+}
+
+func (h *handler) CancelBroadcast(c *gin.Context) {
+	h.action(c, "Cancel", h.broadcastService.Cancel)
+}
+
+// action - start/pause/resume/cancel: noto'g'ri holatdan o'tish -> BadRequest
+func (h *handler) action(c *gin.Context, name string, fn func(ctx context.Context, id int64) error) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		id       = cast.ToInt64(c.Param("id"))
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := fn(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, structs.ErrNotFound):
+			response = responses.NotFound
+		case errors.Is(err, structs.ErrBadRequest):
+			response = responses.BadRequest
+			response.Message = "broadcast is not in a suitable status"
+		default:
+			h.logger.Error(ctx, " err on h.broadcastService."+name, zap.Error(err))
+			response = responses.InternalErr
+		}
+		return
+	}
+
+	resp, err := h.broadcastService.GetByID(ctx, id)
+	if err != nil {
+		response = responses.Success
+		return
+	}
+	response = responses.Success
+	response.Payload = resp
+}
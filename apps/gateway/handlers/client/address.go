@@ -0,0 +1,139 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+// GetAddresses - GET /user/addresses/:tgid (Mini App manzillari, :tgid = initData'dagi user.id)
+func (h *handler) GetAddresses(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+
+	list, err := h.clientService.GetAddresses(ctx, tgID)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.clientService.GetAddresses", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// CreateAddress - POST /user/addresses/:tgid
+func (h *handler) CreateAddress(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.SaveClientAddress
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.TgID = tgID
+
+	resp, err := h.clientService.CreateAddress(ctx, request)
+	if err != nil {
+		if errors.Is(err, structs.ErrBadRequest) {
+			response = responses.BadRequest
+			return
+		}
+		h.logger.Error(ctx, " err on h.clientService.CreateAddress", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// UpdateAddress - PATCH /user/addresses/:tgid/:id (nom, kvartira, qavat, domofon...)
+func (h *handler) UpdateAddress(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.SaveClientAddress
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.TgID = tgID
+	request.ID = cast.ToInt64(c.Param("id"))
+
+	resp, err := h.clientService.UpdateAddress(ctx, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, structs.ErrBadRequest):
+			response = responses.BadRequest
+		case errors.Is(err, structs.ErrNotFound):
+			response = responses.NotFound
+		default:
+			h.logger.Error(ctx, " err on h.clientService.UpdateAddress", zap.Error(err))
+			response = responses.InternalErr
+		}
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// DeleteAddress - DELETE /user/addresses/:tgid/:id
+func (h *handler) DeleteAddress(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+
+	err := h.clientService.DeleteAddress(ctx, tgID, cast.ToInt64(c.Param("id")))
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.clientService.DeleteAddress", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+}
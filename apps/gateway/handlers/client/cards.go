@@ -0,0 +1,182 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+// cardErr - karta xatolarini javobga aylantiradi; Payme xabari mijozga ko'rsatiladi
+func (h *handler) cardErr(c *gin.Context, response *structs.Response, err error, op string) {
+	var se *structs.PaymeSubscribeError
+	switch {
+	case errors.Is(err, structs.ErrBadRequest):
+		*response = responses.BadRequest
+	case errors.Is(err, structs.ErrNotFound):
+		*response = responses.NotFound
+	case errors.Is(err, structs.ErrCardLimit):
+		*response = responses.BadRequest
+		response.Message = "Достигнут лимит сохранённых карт"
+	case errors.As(err, &se):
+		*response = responses.BadRequest
+		response.Message = se.Text()
+	default:
+		h.logger.Error(c.Request.Context(), " err on h.cardService."+op, zap.Error(err))
+		*response = responses.InternalErr
+	}
+}
+
+// webAppOwner - :tgid initData'dagi foydalanuvchiga tegishli bo'lishi shart (TgWebApp middleware)
+func webAppOwner(c *gin.Context, response *structs.Response) (int64, bool) {
+	tgID := cast.ToInt64(c.Param("tgid"))
+	if tgID == 0 {
+		*response = responses.BadRequest
+		return 0, false
+	}
+	if c.GetInt64("tg_id") != tgID {
+		*response = responses.Forbidden
+		return 0, false
+	}
+	return tgID, true
+}
+
+// GetCards - GET /user/cards/:tgid (faqat tasdiqlangan kartalar, token qaytmaydi)
+func (h *handler) GetCards(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+
+	list, err := h.cardService.List(ctx, tgID)
+	if err != nil {
+		h.cardErr(c, &response, err, "List")
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// AddCard - POST /user/cards/:tgid {"token": "..."}. cards.create front-end'da (karta raqami
+// bizga kelmaydi), bu yerda faqat token saqlanadi va mijozga SMS-kod yuboriladi
+func (h *handler) AddCard(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.AddCardRequest
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+	if !h.cardService.Enabled() {
+		response = responses.BadRequest
+		response.Message = "Сохранение карт недоступно"
+		return
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.TgID = tgID
+
+	resp, err := h.cardService.Add(ctx, request)
+	if err != nil {
+		h.cardErr(c, &response, err, "Add")
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// VerifyCard - POST /user/cards/:tgid/:id/verify {"code": "666666"}
+func (h *handler) VerifyCard(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.VerifyCardRequest
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.TgID = tgID
+	request.ID = cast.ToInt64(c.Param("id"))
+
+	resp, err := h.cardService.Verify(ctx, request)
+	if err != nil {
+		h.cardErr(c, &response, err, "Verify")
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// ResendCardCode - POST /user/cards/:tgid/:id/resend
+func (h *handler) ResendCardCode(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+
+	resp, err := h.cardService.ResendCode(ctx, tgID, cast.ToInt64(c.Param("id")))
+	if err != nil {
+		h.cardErr(c, &response, err, "ResendCode")
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// DeleteCard - DELETE /user/cards/:tgid/:id
+func (h *handler) DeleteCard(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tgID, ok := webAppOwner(c, &response)
+	if !ok {
+		return
+	}
+
+	if err := h.cardService.Remove(ctx, tgID, cast.ToInt64(c.Param("id"))); err != nil {
+		h.cardErr(c, &response, err, "Remove")
+		return
+	}
+
+	response = responses.Success
+}
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	client "sushitana/internal/client"
+	"sushitana/internal/payment/cards"
 	"sushitana/internal/responses"
 	"sushitana/internal/structs"
+	"sushitana/internal/support"
 	"sushitana/pkg/logger"
 	"sushitana/pkg/reply"
 	"sushitana/pkg/utils"
@@ -29,23 +31,42 @@ type (
 		GetListClient(c *gin.Context)
 		GetByIDClient(c *gin.Context)
 		DeleteClient(c *gin.Context)
+
+		GetAddresses(c *gin.Context)
+		CreateAddress(c *gin.Context)
+		UpdateAddress(c *gin.Context)
+		DeleteAddress(c *gin.Context)
+
+		GetSupportConversation(c *gin.Context)
+
+		GetCards(c *gin.Context)
+		AddCard(c *gin.Context)
+		VerifyCard(c *gin.Context)
+		ResendCardCode(c *gin.Context)
+		DeleteCard(c *gin.Context)
 	}
 	Params struct {
 		fx.In
-		Logger        logger.Logger
-		ClientService client.Service
+		Logger         logger.Logger
+		ClientService  client.Service
+		SupportService support.Service
+		CardService    cards.Service
 	}
 
 	handler struct {
-		logger        logger.Logger
-		clientService client.Service
+		logger         logger.Logger
+		clientService  client.Service
+		supportService support.Service
+		cardService    cards.Service
 	}
 )
 
 func New(p Params) Handler {
 	return &handler{
-		logger:        p.Logger,
-		clientService: p.ClientService,
+		logger:         p.Logger,
+		clientService:  p.ClientService,
+		supportService: p.SupportService,
+		cardService:    p.CardService,
 	}
 }
 
@@ -111,6 +132,7 @@ func (h *handler) GetListClient(c *gin.Context) {
 
 		phoneNumber      = c.Query("phone_number")
 		name             = c.Query("name")
+		source           = c.Query("source")
 		offset           = c.Query("offset")
 		limit            = c.Query("limit")
 		createdAtFromStr = c.Query("created_at_from")
@@ -119,6 +141,7 @@ func (h *handler) GetListClient(c *gin.Context) {
 
 	filter.PhoneNumber = phoneNumber
 	filter.Name = name
+	filter.Source = source
 	filter.Limit = int64(utils.StrToInt(limit))
 	filter.Offset = int64(utils.StrToInt(offset))
 	if strings.TrimSpace(createdAtFromStr) != "" {
@@ -182,3 +205,32 @@ func (h *handler) DeleteClient(c *gin.Context) {
 
 	response = responses.Success
 }
+
+// GetSupportConversation - GET /client/:id/support: mijoz kartasida operator bilan yozishma
+func (h *handler) GetSupportConversation(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		req      = structs.GetSupportMessagesRequest{
+			TgID:   cast.ToInt64(c.Param("id")),
+			Offset: cast.ToInt64(c.Query("offset")),
+			Limit:  cast.ToInt64(c.Query("limit")),
+		}
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if req.TgID == 0 {
+		response = responses.BadRequest
+		return
+	}
+
+	resp, err := h.supportService.GetConversation(ctx, req)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.supportService.GetConversation", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"sushitana/internal/control/user"
 	"sushitana/internal/responses"
@@ -27,6 +28,9 @@ type (
 		CheckAuth() gin.HandlerFunc
 		Perm(requiredPermission string) gin.HandlerFunc
 		Ctx() gin.HandlerFunc
+		// TgWebApp - Mini App initData'ni tekshirib "tg_id"ni kontekstga qo'yadi.
+		// required=false bo'lsa initData yo'q/noto'g'ri so'rov ham o'tadi, faqat tg_id o'rnatilmaydi
+		TgWebApp(required bool) gin.HandlerFunc
 	}
 
 	Params struct {
@@ -139,6 +143,46 @@ func (m *mw) Ctx() gin.HandlerFunc {
 	}
 }
 
+// initData necha vaqtgacha haqiqiy hisoblanadi
+const tgInitDataMaxAge = 24 * time.Hour
+
+func (m *mw) TgWebApp(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			response structs.Response
+			ctx      = c.Request.Context()
+		)
+
+		tgID, err := utils.ValidateWebAppInitData(extractInitData(c), m.config.GetString("bot_token_sushitana"), tgInitDataMaxAge)
+		if err != nil {
+			if !required {
+				c.Next()
+				return
+			}
+			m.logger.Warn(ctx, " invalid telegram init data", zap.Error(err))
+			response = responses.Unauthorized
+			c.Abort()
+			reply.Json(c.Writer, responses.UnauthorizedCode, &response)
+			return
+		}
+
+		c.Set("tg_id", tgID)
+		c.Next()
+	}
+}
+
+// extractInitData - "X-Telegram-Init-Data" yoki "Authorization: tma <initData>"
+func extractInitData(c *gin.Context) string {
+	if v := strings.TrimSpace(c.GetHeader("X-Telegram-Init-Data")); v != "" {
+		return v
+	}
+	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(authHeader) > 4 && strings.EqualFold(authHeader[:4], "tma ") {
+		return strings.TrimSpace(authHeader[4:])
+	}
+	return ""
+}
+
 func EndpointPermissionMiddleware(m Middleware) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requiredPermission := getRequiredPermission(c.FullPath(), c.Request.Method)
@@ -170,6 +214,14 @@ func getRequiredPermission(endpoint string, method string) string {
 		resource = "order"
 	} else if strings.Contains(endpoint, "/courier") {
 		resource = "courier"
+	} else if strings.Contains(endpoint, "/broadcast") {
+		resource = "broadcast"
+	} else if strings.Contains(endpoint, "/referral") {
+		resource = "referral"
+	} else if strings.Contains(endpoint, "/reconciliation") {
+		resource = "reconciliation"
+	} else if strings.Contains(endpoint, "/payment/") {
+		resource = "payment"
 	} else {
 		return ""
 	}
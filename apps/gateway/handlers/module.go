@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"sushitana/apps/gateway/handlers/broadcast"
 	"sushitana/apps/gateway/handlers/cart"
 	"sushitana/apps/gateway/handlers/category"
 	"sushitana/apps/gateway/handlers/client"
@@ -13,8 +14,12 @@ import (
 	"sushitana/apps/gateway/handlers/order"
 	"sushitana/apps/gateway/handlers/payment/click"
 	"sushitana/apps/gateway/handlers/payment/payme"
+	"sushitana/apps/gateway/handlers/payment/records"
 	shopapi "sushitana/apps/gateway/handlers/payment/shop_api"
+	"sushitana/apps/gateway/handlers/payment/uzum"
 	"sushitana/apps/gateway/handlers/product"
+	"sushitana/apps/gateway/handlers/reconciliation"
+	"sushitana/apps/gateway/handlers/referral"
 	"sushitana/apps/gateway/handlers/role"
 	"sushitana/apps/gateway/handlers/ws"
 
@@ -34,8 +39,13 @@ var Module = fx.Options(
 	iiko.Module,
 	menu.Module,
 	order.Module,
+	broadcast.Module,
+	referral.Module,
+	reconciliation.Module,
 	click.Module,
 	payme.Module,
+	records.Module,
 	shopapi.Module,
+	uzum.Module,
 	ws.Module,
 )
@@ -0,0 +1,76 @@
+package order
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SendClickInvoice - POST /order/:id/click-invoice {"phone"} - operator mijoz telefoniga Click invoice yuboradi
+func (h *handler) SendClickInvoice(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.SendClickInvoiceRequest
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	// body ixtiyoriy - telefon berilmasa zakazdagi raqam olinadi
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.OrderID = c.Param("id")
+
+	inv, err := h.clickService.SendPhoneInvoice(ctx, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, structs.ErrNotFound):
+			response = responses.NotFound
+		case errors.Is(err, structs.ErrBadRequest):
+			response = responses.BadRequest
+			response.Message = "Нужен заказ с оплатой Click и корректный номер телефона"
+		case errors.Is(err, structs.ErrOrderNotPayable):
+			response = responses.BadRequest
+			response.Message = "Заказ уже оплачен или не ожидает оплаты"
+		default:
+			h.logger.Error(ctx, " err on h.clickService.SendPhoneInvoice", zap.Error(err))
+			response = responses.InternalErr
+		}
+		return
+	}
+
+	response = responses.Success
+	response.Payload = inv
+}
+
+// GetClickInvoice - GET /order/:id/click-invoice - yuborilgan invoice holati
+func (h *handler) GetClickInvoice(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	inv, err := h.clickService.GetPhoneInvoice(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.clickService.GetPhoneInvoice", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = inv
+}
@@ -2,9 +2,12 @@ package order
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"sushitana/internal/order"
+	"sushitana/internal/payment/click"
+	"sushitana/internal/rating"
 	"sushitana/internal/responses"
 	"sushitana/internal/structs"
 	"sushitana/pkg/logger"
@@ -29,26 +32,43 @@ type (
 		GetByIDOrder(c *gin.Context)
 		GetListOrder(c *gin.Context)
 		DeleteOrder(c *gin.Context)
+		CancelOrder(c *gin.Context)
+		ChangePaymentMethod(c *gin.Context)
+		ChangePaymentMethodByOperator(c *gin.Context)
+		RemoveItems(c *gin.Context)
+		GetRefunds(c *gin.Context)
+		MarkRefundDone(c *gin.Context)
 		UpdateStatusOrder(c *gin.Context)
 		UpdateStatusPayment(c *gin.Context)
 		DeliveryMapFound(c *gin.Context)
+		GetRatings(c *gin.Context)
+		GetRatingSummary(c *gin.Context)
+		GetTipSummary(c *gin.Context)
+		SendClickInvoice(c *gin.Context)
+		GetClickInvoice(c *gin.Context)
 	}
 	Params struct {
 		fx.In
-		Logger       logger.Logger
-		OrderService order.Service
+		Logger        logger.Logger
+		OrderService  order.Service
+		RatingService rating.Service
+		ClickService  click.Service
 	}
 
 	handler struct {
-		logger       logger.Logger
-		orderService order.Service
+		logger        logger.Logger
+		orderService  order.Service
+		ratingService rating.Service
+		clickService  click.Service
 	}
 )
 
 func New(p Params) Handler {
 	return &handler{
-		logger:       p.Logger,
-		orderService: p.OrderService,
+		logger:        p.Logger,
+		orderService:  p.OrderService,
+		ratingService: p.RatingService,
+		clickService:  p.ClickService,
 	}
 }
 
@@ -67,11 +87,37 @@ func (h *handler) CreateOrder(c *gin.Context) {
 		response = responses.BadRequest
 		return
 	}
+	// saqlangan karta va referal promo-kodi faqat Mini App initData'dagi egasiga (TgWebApp middleware)
+	if request.CardID != 0 || request.PromoCode != "" {
+		switch tgID := c.GetInt64("tg_id"); {
+		case tgID == 0:
+			response = responses.Unauthorized
+			return
+		case tgID != request.TgID:
+			response = responses.Forbidden
+			return
+		}
+	}
 
 	pay_url, _, err := h.orderService.Create(c, request)
 	if err != nil {
-		if errors.Is(err, structs.ErrUniqueViolation) {
+		if errors.Is(err, structs.ErrUniqueViolation) || errors.Is(err, structs.ErrPaymentMethodDisabled) || errors.Is(err, structs.ErrBadRequest) {
+			response = responses.BadRequest
+			return
+		}
+		if errors.Is(err, structs.ErrInvalidChange) {
+			response = responses.BadRequest
+			response.Message = "Сумма для сдачи меньше суммы заказа"
+			return
+		}
+		if errors.Is(err, structs.ErrPromoCodeUsed) {
+			response = responses.BadRequest
+			response.Message = "Промокод уже использован"
+			return
+		}
+		if errors.Is(err, structs.ErrInvalidTip) {
 			response = responses.BadRequest
+			response.Message = "Неверная сумма чаевых"
 			return
 		}
 		h.logger.Error(ctx, " err on h.orderService.Create", zap.Error(err))
@@ -192,6 +238,47 @@ func (h *handler) DeleteOrder(c *gin.Context) {
 	response = responses.Success
 }
 
+// CancelOrder - Mini App: mijoz zakazni o'zi bekor qiladi (COOKING'gacha), egasi initData'dan
+func (h *handler) CancelOrder(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.CancelOrderByClient
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	// body ixtiyoriy (faqat reason); tg_id body'dan olinmaydi
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.OrderId = c.Param("id")
+	request.TgID = c.GetInt64("tg_id")
+
+	err := h.orderService.CancelByClient(ctx, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, structs.ErrBadRequest):
+			response = responses.BadRequest
+		case errors.Is(err, structs.ErrNotFound):
+			response = responses.NotFound
+		case errors.Is(err, structs.ErrForbidden):
+			response = responses.Forbidden
+		case errors.Is(err, structs.ErrNotCancellable):
+			response = responses.NotCancellable
+		case errors.Is(err, structs.ErrTooManyRequests):
+			response = responses.TooManyRequests
+		default:
+			h.logger.Error(ctx, " err on h.orderService.CancelByClient", zap.Error(err))
+			response = responses.InternalErr
+		}
+		return
+	}
+
+	response = responses.Success
+}
+
 func (h *handler) GetListOrder(c *gin.Context) {
 	var (
 		response structs.Response
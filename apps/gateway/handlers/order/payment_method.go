@@ -0,0 +1,82 @@
+package order
+
+import (
+	"errors"
+	"net/http"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ChangePaymentMethod - Mini App: POST /order/:id/payment-method {"paymentMethod": "CLICK"}.
+// Egasi initData'dan (TgWebApp); faqat o'z zakazi va WAITING_PAYMENT'da, o'sha usul yuborilsa havola qayta yaratiladi.
+func (h *handler) ChangePaymentMethod(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.ChangePaymentMethod
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.OrderId = c.Param("id")
+	request.TgID = c.GetInt64("tg_id")
+
+	resp, err := h.orderService.ChangePaymentMethod(ctx, request)
+	h.changePaymentMethodReply(c, &response, resp, err)
+}
+
+// ChangePaymentMethodByOperator - panel: PUT /order/:id/payment-method {"paymentMethod": "CASH"}
+func (h *handler) ChangePaymentMethodByOperator(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.ChangePaymentMethod
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+
+	resp, err := h.orderService.ChangePaymentMethodByOperator(ctx, c.Param("id"), request.PaymentMethod)
+	h.changePaymentMethodReply(c, &response, resp, err)
+}
+
+func (h *handler) changePaymentMethodReply(c *gin.Context, response *structs.Response, resp structs.ChangePaymentMethodResponse, err error) {
+	ctx := c.Request.Context()
+
+	if err != nil {
+		switch {
+		case errors.Is(err, structs.ErrBadRequest):
+			*response = responses.BadRequest
+		case errors.Is(err, structs.ErrNotFound):
+			*response = responses.NotFound
+		case errors.Is(err, structs.ErrForbidden):
+			*response = responses.Forbidden
+		case errors.Is(err, structs.ErrPaymentMethodDisabled):
+			*response = responses.BadRequest
+			response.Message = "Способ оплаты недоступен"
+		case errors.Is(err, structs.ErrOrderNotPayable):
+			*response = responses.BadRequest
+			response.Message = "Заказ уже оплачен или не ожидает оплаты"
+		default:
+			h.logger.Error(ctx, " err on h.orderService.ChangePaymentMethod", zap.Error(err))
+			*response = responses.InternalErr
+		}
+		return
+	}
+
+	*response = responses.Success
+	response.Payload = resp
+}
@@ -0,0 +1,96 @@
+package order
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+	"sushitana/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetRatings - GET /order/ratings: mijoz baholari (kuryer, past baho, davr bo'yicha filtr)
+func (h *handler) GetRatings(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		req      = structs.GetListRatingRequest{
+			Offset:    int64(utils.StrToInt(c.Query("offset"))),
+			Limit:     int64(utils.StrToInt(c.Query("limit"))),
+			CourierID: c.Query("courier_id"),
+			MaxScore:  utils.StrToInt(c.Query("max_score")),
+		}
+		err error
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid from (RFC3339 expected)"
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid to (RFC3339 expected)"
+		return
+	}
+
+	list, err := h.ratingService.GetList(ctx, req)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.ratingService.GetList", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// GetRatingSummary - GET /order/ratings/summary: o'rtacha baholar, umumiy va har kuryer
+func (h *handler) GetRatingSummary(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid from (RFC3339 expected)"
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid to (RFC3339 expected)"
+		return
+	}
+
+	summary, err := h.ratingService.Summary(ctx, from, to)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.ratingService.Summary", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = summary
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,114 @@
+package order
+
+import (
+	"errors"
+	"net/http"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+// RemoveItems - panel: POST /order/:id/refund {"items": [{"productId": "...", "quantity": 1}], "reason": "..."}.
+// quantity 0 - qator butunlay olib tashlanadi.
+func (h *handler) RemoveItems(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.RemoveOrderItemsRequest
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+	request.OrderID = c.Param("id")
+	request.EmployeeID = c.GetInt("employee_id")
+
+	resp, err := h.orderService.RemoveItems(ctx, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, structs.ErrBadRequest):
+			response = responses.BadRequest
+			response.Message = "Неверный список позиций"
+		case errors.Is(err, structs.ErrNotFound):
+			response = responses.NotFound
+		case errors.Is(err, structs.ErrNotRefundable):
+			response = responses.BadRequest
+			response.Message = "Заказ не оплачен онлайн или уже закрыт"
+		case errors.Is(err, structs.ErrOrderChanged):
+			response = responses.BadRequest
+			response.Message = "Заказ изменён другим оператором, обновите и повторите"
+		default:
+			h.logger.Error(ctx, " err on h.orderService.RemoveItems", zap.Error(err))
+			response = responses.InternalErr
+		}
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// GetRefunds - panel: GET /order/:id/refunds
+func (h *handler) GetRefunds(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	list, err := h.orderService.GetRefunds(ctx, c.Param("id"))
+	if err != nil {
+		h.logger.Error(ctx, " err on h.orderService.GetRefunds", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// MarkRefundDone - panel: PUT /order/:id/refunds/:refundId {"note": "..."}; MANUAL/FAILED qo'lda qaytarilgandan keyin
+func (h *handler) MarkRefundDone(c *gin.Context) {
+	var (
+		response structs.Response
+		request  structs.MarkRefundDoneRequest
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			h.logger.Warn(ctx, " error parse request", zap.Error(err))
+			response = responses.BadRequest
+			return
+		}
+	}
+	request.OrderID = c.Param("id")
+	request.RefundID = cast.ToInt64(c.Param("refundId"))
+	if request.RefundID == 0 {
+		response = responses.BadRequest
+		return
+	}
+
+	refund, err := h.orderService.MarkRefundDone(ctx, request)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.orderService.MarkRefundDone", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = refund
+}
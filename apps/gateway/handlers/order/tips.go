@@ -0,0 +1,44 @@
+package order
+
+import (
+	"net/http"
+
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/reply"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetTipSummary - GET /order/tips/summary: kuryerlarga choy puli, umumiy va har kuryer
+func (h *handler) GetTipSummary(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid from (RFC3339 expected)"
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid to (RFC3339 expected)"
+		return
+	}
+
+	summary, err := h.orderService.TipSummary(ctx, from, to)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.orderService.TipSummary", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = summary
+}
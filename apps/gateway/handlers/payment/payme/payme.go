@@ -234,6 +234,22 @@ func (h *handler) Handle(c *gin.Context) {
 		}
 		ok(c, req.ID, res)
 
+	case "SetFiscalData":
+		var p structs.PaymeSetFiscalDataParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			fail(c, req.ID, structs.RPCError{
+				Code:    -32600,
+				Message: "Invalid params",
+			})
+			return
+		}
+		res, e := h.paymeSv.SetFiscalData(ctx, p)
+		if e.Code != 0 {
+			fail(c, req.ID, e)
+			return
+		}
+		ok(c, req.ID, res)
+
 	default:
 		fail(c, req.ID, structs.RPCError{
 			Code:    -32601,
@@ -0,0 +1,201 @@
+package records
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"sushitana/internal/payment/click"
+	"sushitana/internal/payment/payme"
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/reply"
+	"sushitana/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	Module = fx.Provide(New)
+)
+
+type (
+	// Handler - support uchun Click invoice va Payme tranzaksiyalarni ko'rish (payment-read)
+	Handler interface {
+		GetListClickInvoices(c *gin.Context)
+		GetClickInvoice(c *gin.Context)
+		GetListPaymeTransactions(c *gin.Context)
+		GetPaymeTransaction(c *gin.Context)
+	}
+	Params struct {
+		fx.In
+		Logger       logger.Logger
+		ClickService click.Service
+		PaymeService payme.Service
+	}
+
+	handler struct {
+		logger       logger.Logger
+		clickService click.Service
+		paymeService payme.Service
+	}
+)
+
+func New(p Params) Handler {
+	return &handler{
+		logger:       p.Logger,
+		clickService: p.ClickService,
+		paymeService: p.PaymeService,
+	}
+}
+
+// GetListClickInvoices - GET /payment/click/invoices?status=&order_number=&phone_number=&created_at_from=&created_at_to=
+func (h *handler) GetListClickInvoices(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		req      = structs.GetListClickInvoiceRequest{
+			Offset:      int64(utils.StrToInt(c.Query("offset"))),
+			Limit:       int64(utils.StrToInt(c.Query("limit"))),
+			Status:      c.Query("status"),
+			OrderNumber: cast.ToInt64(c.Query("order_number")),
+			Phone:       c.Query("phone_number"),
+		}
+		err error
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if req.From, req.To, err = parsePeriod(c); err != nil {
+		response = responses.BadRequest
+		response.Message = err.Error()
+		return
+	}
+
+	list, err := h.clickService.GetInvoices(ctx, req)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.clickService.GetInvoices", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// GetClickInvoice - GET /payment/click/invoices/:id (invoices.id)
+func (h *handler) GetClickInvoice(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	id := cast.ToInt64(c.Param("id"))
+	if id <= 0 {
+		response = responses.BadRequest
+		return
+	}
+
+	inv, err := h.clickService.GetInvoice(ctx, id)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.clickService.GetInvoice", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = inv
+}
+
+// GetListPaymeTransactions - GET /payment/payme/transactions?state=&order_number=&phone_number=&created_at_from=&created_at_to=
+// state: 1 yaratilgan, 2 to'langan, -1/-2 bekor qilingan
+func (h *handler) GetListPaymeTransactions(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		req      = structs.GetListPaymeTransactionRequest{
+			Offset:      int64(utils.StrToInt(c.Query("offset"))),
+			Limit:       int64(utils.StrToInt(c.Query("limit"))),
+			OrderNumber: cast.ToInt64(c.Query("order_number")),
+			Phone:       c.Query("phone_number"),
+		}
+		err error
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if v := strings.TrimSpace(c.Query("state")); v != "" {
+		state, err := cast.ToIntE(v)
+		if err != nil {
+			response = responses.BadRequest
+			response.Message = "invalid state"
+			return
+		}
+		req.State = &state
+	}
+	if req.From, req.To, err = parsePeriod(c); err != nil {
+		response = responses.BadRequest
+		response.Message = err.Error()
+		return
+	}
+
+	list, err := h.paymeService.GetTransactions(ctx, req)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.paymeService.GetTransactions", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// GetPaymeTransaction - GET /payment/payme/transactions/:id (payme_transactions.id)
+func (h *handler) GetPaymeTransaction(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	tx, err := h.paymeService.GetTransaction(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.paymeService.GetTransaction", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = tx
+}
+
+// parsePeriod - created_at_from / created_at_to (RFC3339), zakazlar ro'yxatidagidek
+func parsePeriod(c *gin.Context) (from, to *time.Time, err error) {
+	if v := strings.TrimSpace(c.Query("created_at_from")); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, nil, errors.New("invalid created_at_from (RFC3339 expected)")
+		}
+		from = &t
+	}
+	if v := strings.TrimSpace(c.Query("created_at_to")); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, nil, errors.New("invalid created_at_to (RFC3339 expected)")
+		}
+		to = &t
+	}
+	return from, to, nil
+}
@@ -0,0 +1,135 @@
+package uzum
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"strings"
+	"sushitana/internal/payment/uzum"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Handler interface {
+		Check(c *gin.Context)
+		Create(c *gin.Context)
+		Confirm(c *gin.Context)
+		Reverse(c *gin.Context)
+		Status(c *gin.Context)
+	}
+
+	Params struct {
+		fx.In
+		Logger  logger.Logger
+		UzumSvc uzum.Service
+	}
+
+	handler struct {
+		logger  logger.Logger
+		uzumSvc uzum.Service
+	}
+)
+
+func New(p Params) Handler {
+	return &handler{
+		logger:  p.Logger,
+		uzumSvc: p.UzumSvc,
+	}
+}
+
+// checkUzumAuth - Basic auth: UZUM_LOGIN:UZUM_PASSWORD (Uzum kabinetida ko'rsatilgan)
+func checkUzumAuth(c *gin.Context) bool {
+	login := os.Getenv("UZUM_LOGIN")
+	password := os.Getenv("UZUM_PASSWORD")
+	if login == "" || password == "" {
+		return false
+	}
+
+	h := c.GetHeader("Authorization")
+	if h == "" || !strings.HasPrefix(h, "Basic ") {
+		return false
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(h, "Basic "))
+	if err != nil {
+		return false
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return false
+	}
+
+	okLogin := subtle.ConstantTimeCompare([]byte(parts[0]), []byte(login)) == 1
+	okPass := subtle.ConstantTimeCompare([]byte(parts[1]), []byte(password)) == 1
+	return okLogin && okPass
+}
+
+// handle - auth + parse, keyin service; Uzum xatoni ham 200 bilan kutadi (400 - parse/auth)
+func (h *handler) handle(c *gin.Context, action string, fn func(req structs.UzumRequest) structs.UzumResponse) {
+	var req structs.UzumRequest
+
+	if !checkUzumAuth(c) {
+		h.logger.Warn(c.Request.Context(), "uzum: unauthorized", zap.String("action", action))
+		c.JSON(http.StatusBadRequest, structs.UzumResponse{
+			Status:    structs.UzumStatusFailed,
+			ErrorCode: structs.UzumErrAccessDenied,
+		})
+		return
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, structs.UzumResponse{
+			Status:    structs.UzumStatusFailed,
+			ErrorCode: structs.UzumErrJSONParse,
+		})
+		return
+	}
+
+	resp := fn(req)
+	if resp.Status == structs.UzumStatusFailed {
+		h.logger.Warn(c.Request.Context(), "uzum: failed",
+			zap.String("action", action),
+			zap.String("trans_id", req.TransID),
+			zap.String("error_code", resp.ErrorCode),
+		)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *handler) Check(c *gin.Context) {
+	h.handle(c, "check", func(req structs.UzumRequest) structs.UzumResponse {
+		return h.uzumSvc.Check(c.Request.Context(), req)
+	})
+}
+
+func (h *handler) Create(c *gin.Context) {
+	h.handle(c, "create", func(req structs.UzumRequest) structs.UzumResponse {
+		return h.uzumSvc.Create(c.Request.Context(), req)
+	})
+}
+
+func (h *handler) Confirm(c *gin.Context) {
+	h.handle(c, "confirm", func(req structs.UzumRequest) structs.UzumResponse {
+		return h.uzumSvc.Confirm(c.Request.Context(), req)
+	})
+}
+
+func (h *handler) Reverse(c *gin.Context) {
+	h.handle(c, "reverse", func(req structs.UzumRequest) structs.UzumResponse {
+		return h.uzumSvc.Reverse(c.Request.Context(), req)
+	})
+}
+
+func (h *handler) Status(c *gin.Context) {
+	h.handle(c, "status", func(req structs.UzumRequest) structs.UzumResponse {
+		return h.uzumSvc.Status(c.Request.Context(), req)
+	})
+}
@@ -0,0 +1,501 @@
+package uzum
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"sushitana/internal/orderflow"
+	"sushitana/internal/payment/uzum"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	testServiceID = int64(498614)
+	testLogin     = "uzum"
+	testPassword  = "secret"
+)
+
+// memUzumRepo - uzum_transactions jadvali; unique index'lar Postgres kabi *pgconn.PgError qaytaradi
+type memUzumRepo struct {
+	mu  sync.Mutex
+	seq int
+	txs map[string]*structs.UzumTransaction
+}
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: constraint}
+}
+
+func (r *memUzumRepo) Create(_ context.Context, tx structs.UzumTransaction) (structs.UzumTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.txs[tx.TransID]; ok {
+		return structs.UzumTransaction{}, uniqueViolation("uzum_transactions_trans_id_key")
+	}
+	for _, t := range r.txs {
+		if t.OrderID == tx.OrderID && (t.Status == structs.UzumStatusCreated || t.Status == structs.UzumStatusConfirmed) {
+			return structs.UzumTransaction{}, uniqueViolation("uzum_one_active_per_order_uq")
+		}
+	}
+	r.seq++
+	tx.ID = fmt.Sprintf("tx-%d", r.seq)
+	tx.Status = structs.UzumStatusCreated
+	r.txs[tx.TransID] = &tx
+	return tx, nil
+}
+
+func (r *memUzumRepo) GetByTransID(_ context.Context, transID string) (structs.UzumTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.txs[transID]
+	if !ok {
+		return structs.UzumTransaction{}, structs.ErrNotFound
+	}
+	return *t, nil
+}
+
+func (r *memUzumRepo) GetLastByOrderID(_ context.Context, orderID string) (structs.UzumTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.txs {
+		if t.OrderID == orderID {
+			return *t, nil
+		}
+	}
+	return structs.UzumTransaction{}, structs.ErrNotFound
+}
+
+func (r *memUzumRepo) MarkConfirmed(_ context.Context, transID string, confirmTime int64, paymentSource, phone string) (structs.UzumTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.txs[transID]
+	if !ok || t.Status != structs.UzumStatusCreated {
+		return structs.UzumTransaction{}, structs.ErrNotFound
+	}
+	t.Status = structs.UzumStatusConfirmed
+	t.ConfirmTime = sql.NullInt64{Int64: confirmTime, Valid: true}
+	t.PaymentSource = sql.NullString{String: paymentSource, Valid: paymentSource != ""}
+	t.Phone = sql.NullString{String: phone, Valid: phone != ""}
+	return *t, nil
+}
+
+func (r *memUzumRepo) MarkReversed(_ context.Context, transID string, reverseTime int64) (structs.UzumTransaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.txs[transID]
+	if !ok || t.Status == structs.UzumStatusReversed {
+		return structs.UzumTransaction{}, structs.ErrNotFound
+	}
+	t.Status = structs.UzumStatusReversed
+	t.ReverseTime = sql.NullInt64{Int64: reverseTime, Valid: true}
+	return *t, nil
+}
+
+func (r *memUzumRepo) ReverseCreatedByOrderID(_ context.Context, orderID string, reverseTime int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, t := range r.txs {
+		if t.OrderID == orderID && t.Status == structs.UzumStatusCreated {
+			t.Status = structs.UzumStatusReversed
+			t.ReverseTime = sql.NullInt64{Int64: reverseTime, Valid: true}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// fakeOrders - Uzum webhook'lari ishlatadigan orders metodlari
+type fakeOrders struct {
+	orderrepo.Repo
+	mu       sync.Mutex
+	orders   map[int64]*structs.Order
+	failPaid error // UpdatePaymentStatus shu xatoni qaytaradi
+}
+
+func (r *fakeOrders) byID(id string) *structs.Order {
+	for _, o := range r.orders {
+		if o.ID == id {
+			return o
+		}
+	}
+	return nil
+}
+
+func (r *fakeOrders) GetByOrderNumber(_ context.Context, number int64) (structs.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orders[number]
+	if !ok {
+		return structs.Order{}, structs.ErrNotFound
+	}
+	return *o, nil
+}
+
+func (r *fakeOrders) GetByID(_ context.Context, id string) (structs.GetListPrimaryKeyResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o := r.byID(id)
+	if o == nil {
+		return structs.GetListPrimaryKeyResponse{}, structs.ErrNotFound
+	}
+	return structs.GetListPrimaryKeyResponse{Order: *o}, nil
+}
+
+func (r *fakeOrders) UpdateStatus(_ context.Context, req structs.UpdateStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if o := r.byID(req.OrderId); o != nil {
+		o.Status = req.Status
+	}
+	return nil
+}
+
+func (r *fakeOrders) UpdatePaymentStatus(_ context.Context, req structs.UpdateStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failPaid != nil {
+		return r.failPaid
+	}
+	if o := r.byID(req.OrderId); o != nil {
+		o.PaymentStatus = req.Status
+	}
+	return nil
+}
+
+func (r *fakeOrders) get(number int64) structs.Order {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.orders[number]
+}
+
+// fakeFlow - iiko'ga yuborilgan zakazlar
+type fakeFlow struct {
+	orderflow.Service
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeFlow) SendToIikoIfAllowed(_ context.Context, orderID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, orderID)
+	return nil
+}
+
+func (f *fakeFlow) NotifyOrderStatusIfNeeded(context.Context, string, string) {}
+
+type testEnv struct {
+	srv    *httptest.Server
+	orders *fakeOrders
+	txs    *memUzumRepo
+	flow   *fakeFlow
+}
+
+// newTestEnv - gateway'dagi /payments/uzum/* marshrutlari lokal serverda; test Uzum rolini o'ynaydi
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	t.Setenv("UZUM_SERVICE_ID", fmt.Sprint(testServiceID))
+	t.Setenv("UZUM_LOGIN", testLogin)
+	t.Setenv("UZUM_PASSWORD", testPassword)
+
+	env := &testEnv{
+		orders: &fakeOrders{orders: map[int64]*structs.Order{
+			1042: {ID: "order-1042", OrderNumber: 1042, PayAmount: 85000, Status: "WAITING_PAYMENT", PaymentMethod: structs.PaymentMethodUzum, PaymentStatus: "UNPAID"},
+			1043: {ID: "order-1043", OrderNumber: 1043, PayAmount: 50000, Status: "WAITING_PAYMENT", PaymentMethod: structs.PaymentMethodCash, PaymentStatus: "UNPAID"},
+		}},
+		txs:  &memUzumRepo{txs: map[string]*structs.UzumTransaction{}},
+		flow: &fakeFlow{},
+	}
+
+	lg := logger.New("error")
+	h := New(Params{
+		Logger: lg,
+		UzumSvc: uzum.New(uzum.Params{
+			Logger:    lg,
+			OrderRepo: env.orders,
+			UzumRepo:  env.txs,
+			OrderFlow: env.flow,
+		}),
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/payments/uzum/check", h.Check)
+	r.POST("/payments/uzum/create", h.Create)
+	r.POST("/payments/uzum/confirm", h.Confirm)
+	r.POST("/payments/uzum/reverse", h.Reverse)
+	r.POST("/payments/uzum/status", h.Status)
+
+	env.srv = httptest.NewServer(r)
+	t.Cleanup(env.srv.Close)
+	return env
+}
+
+func (e *testEnv) call(t *testing.T, action string, req structs.UzumRequest) structs.UzumResponse {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	httpReq, err := http.NewRequest(http.MethodPost, e.srv.URL+"/payments/uzum/"+action, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpReq.SetBasicAuth(testLogin, testPassword)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.srv.Client().Do(httpReq)
+	if err != nil {
+		t.Fatalf("%s: %v", action, err)
+	}
+	defer resp.Body.Close()
+
+	var out structs.UzumResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("%s: decode: %v", action, err)
+	}
+	return out
+}
+
+func createReq(transID string, number string, amount int64) structs.UzumRequest {
+	return structs.UzumRequest{
+		ServiceID: testServiceID,
+		TransID:   transID,
+		Params:    map[string]string{"order_id": number},
+		Amount:    amount,
+	}
+}
+
+func TestCheckCreateConfirmStatus(t *testing.T) {
+	env := newTestEnv(t)
+
+	check := env.call(t, "check", structs.UzumRequest{ServiceID: testServiceID, Params: map[string]string{"order_id": "1042"}})
+	if check.Status != structs.UzumStatusOK {
+		t.Fatalf("check = %+v", check)
+	}
+
+	created := env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+	if created.Status != structs.UzumStatusCreated || created.Amount != 85000*100 {
+		t.Fatalf("create = %+v", created)
+	}
+
+	confirmed := env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1", PaymentSource: "UZCARD"})
+	if confirmed.Status != structs.UzumStatusConfirmed || confirmed.ConfirmTime == 0 {
+		t.Fatalf("confirm = %+v", confirmed)
+	}
+	if o := env.orders.get(1042); o.PaymentStatus != structs.PaymentStatusPaid || o.Status != "COOKING" {
+		t.Fatalf("order = %s/%s, want PAID/COOKING", o.PaymentStatus, o.Status)
+	}
+
+	// takroriy confirm - iiko'ga ikkinchi marta ketmaydi
+	again := env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if again.Status != structs.UzumStatusConfirmed {
+		t.Fatalf("repeated confirm = %+v", again)
+	}
+	if len(env.flow.sent) != 1 {
+		t.Fatalf("iiko sent %d times, want 1", len(env.flow.sent))
+	}
+
+	status := env.call(t, "status", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if status.Status != structs.UzumStatusConfirmed {
+		t.Fatalf("status = %+v", status)
+	}
+
+	paidCheck := env.call(t, "check", structs.UzumRequest{ServiceID: testServiceID, Params: map[string]string{"order_id": "1042"}})
+	if paidCheck.ErrorCode != structs.UzumErrAlreadyPaid {
+		t.Fatalf("check after payment = %+v", paidCheck)
+	}
+}
+
+func TestCheckRejects(t *testing.T) {
+	env := newTestEnv(t)
+
+	cases := []struct {
+		name string
+		req  structs.UzumRequest
+		code string
+	}{
+		{"wrong service", structs.UzumRequest{ServiceID: 1, Params: map[string]string{"order_id": "1042"}}, structs.UzumErrInvalidServiceID},
+		{"no order_id", structs.UzumRequest{ServiceID: testServiceID}, structs.UzumErrMissingParams},
+		{"unknown order", structs.UzumRequest{ServiceID: testServiceID, Params: map[string]string{"order_id": "9999"}}, structs.UzumErrAccountNotFound},
+		{"cash order", structs.UzumRequest{ServiceID: testServiceID, Params: map[string]string{"order_id": "1043"}}, structs.UzumErrInvalidOperation},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := env.call(t, "check", tc.req)
+			if resp.Status != structs.UzumStatusFailed || resp.ErrorCode != tc.code {
+				t.Fatalf("check = %+v, want %s", resp, tc.code)
+			}
+		})
+	}
+}
+
+func TestCreateWrongAmount(t *testing.T) {
+	env := newTestEnv(t)
+
+	resp := env.call(t, "create", createReq("uzum-1", "1042", 100))
+	if resp.ErrorCode != structs.UzumErrInvalidAmount {
+		t.Fatalf("create = %+v, want %s", resp, structs.UzumErrInvalidAmount)
+	}
+}
+
+func TestCreateRepeatedTransID(t *testing.T) {
+	env := newTestEnv(t)
+
+	first := env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+	second := env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+	if second.Status != structs.UzumStatusCreated || second.TransTime != first.TransTime {
+		t.Fatalf("repeated create = %+v, first = %+v", second, first)
+	}
+}
+
+func TestCreateSecondActiveTransaction(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+	resp := env.call(t, "create", createReq("uzum-2", "1042", 85000*100))
+	if resp.Status != structs.UzumStatusFailed || resp.ErrorCode != structs.UzumErrAlreadyPaid {
+		t.Fatalf("second create = %+v, want %s", resp, structs.UzumErrAlreadyPaid)
+	}
+}
+
+func TestReverseConfirmed(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+	env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+
+	reversed := env.call(t, "reverse", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if reversed.Status != structs.UzumStatusReversed || reversed.ReverseTime == 0 {
+		t.Fatalf("reverse = %+v", reversed)
+	}
+	if o := env.orders.get(1042); o.Status != "CANCELLED" {
+		t.Fatalf("order status = %s, want CANCELLED", o.Status)
+	}
+
+	again := env.call(t, "reverse", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if again.Status != structs.UzumStatusReversed {
+		t.Fatalf("repeated reverse = %+v", again)
+	}
+	confirm := env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if confirm.ErrorCode != structs.UzumErrPaymentCancelled {
+		t.Fatalf("confirm after reverse = %+v", confirm)
+	}
+}
+
+func TestReverseCreatedFreesOrder(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+	env.call(t, "reverse", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+
+	if o := env.orders.get(1042); o.Status != "WAITING_PAYMENT" {
+		t.Fatalf("order status = %s, want WAITING_PAYMENT", o.Status)
+	}
+	resp := env.call(t, "create", createReq("uzum-2", "1042", 85000*100))
+	if resp.Status != structs.UzumStatusCreated {
+		t.Fatalf("create after reverse = %+v", resp)
+	}
+}
+
+func TestConfirmPaidUpdateFails(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+
+	env.orders.mu.Lock()
+	env.orders.failPaid = fmt.Errorf("db down")
+	env.orders.mu.Unlock()
+
+	resp := env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if resp.ErrorCode != structs.UzumErrDataVerification {
+		t.Fatalf("confirm = %+v, want %s", resp, structs.UzumErrDataVerification)
+	}
+	if len(env.flow.sent) != 0 {
+		t.Fatalf("iiko sent %d times, want 0", len(env.flow.sent))
+	}
+
+	// Uzum qayta yuboradi: endi zakaz PAID bo'lishi kerak
+	env.orders.mu.Lock()
+	env.orders.failPaid = nil
+	env.orders.mu.Unlock()
+
+	retry := env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if retry.ErrorCode != "" || retry.Status != structs.UzumStatusConfirmed {
+		t.Fatalf("retry confirm = %+v", retry)
+	}
+	if o := env.orders.get(1042); o.PaymentStatus != structs.PaymentStatusPaid || o.Status != "COOKING" {
+		t.Fatalf("order = %s/%s, want PAID/COOKING", o.PaymentStatus, o.Status)
+	}
+	if len(env.flow.sent) != 1 {
+		t.Fatalf("iiko sent %d times, want 1", len(env.flow.sent))
+	}
+}
+
+func TestConfirmAfterMethodChanged(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.call(t, "create", createReq("uzum-1", "1042", 85000*100))
+
+	// mijoz create'dan keyin naqdga o'tdi, eski tranzaksiya bekor qilinmay qoldi
+	env.orders.mu.Lock()
+	o := env.orders.orders[1042]
+	o.PaymentMethod, o.Status = structs.PaymentMethodCash, structs.OrderStatusWaitingOperator
+	env.orders.mu.Unlock()
+
+	resp := env.call(t, "confirm", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"})
+	if resp.ErrorCode != structs.UzumErrPaymentCancelled {
+		t.Fatalf("confirm = %+v, want %s", resp, structs.UzumErrPaymentCancelled)
+	}
+	if o := env.orders.get(1042); o.PaymentStatus == structs.PaymentStatusPaid || o.Status != structs.OrderStatusWaitingOperator {
+		t.Fatalf("order = %s/%s, want UNPAID/WAITING_OPERATOR", o.PaymentStatus, o.Status)
+	}
+	if len(env.flow.sent) != 0 {
+		t.Fatalf("iiko sent %d times, want 0", len(env.flow.sent))
+	}
+	if st := env.call(t, "status", structs.UzumRequest{ServiceID: testServiceID, TransID: "uzum-1"}); st.Status != structs.UzumStatusReversed {
+		t.Fatalf("status = %+v, want REVERSED", st)
+	}
+}
+
+func TestUnknownTransaction(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, action := range []string{"confirm", "reverse", "status"} {
+		resp := env.call(t, action, structs.UzumRequest{ServiceID: testServiceID, TransID: "missing"})
+		if resp.ErrorCode != structs.UzumErrTransNotFound {
+			t.Fatalf("%s = %+v, want %s", action, resp, structs.UzumErrTransNotFound)
+		}
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	env := newTestEnv(t)
+
+	body, _ := json.Marshal(structs.UzumRequest{ServiceID: testServiceID})
+	req, _ := http.NewRequest(http.MethodPost, env.srv.URL+"/payments/uzum/check", bytes.NewReader(body))
+	req.SetBasicAuth(testLogin, "wrong")
+
+	resp, err := env.srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out structs.UzumResponse
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	if resp.StatusCode != http.StatusBadRequest || out.ErrorCode != structs.UzumErrAccessDenied {
+		t.Fatalf("status = %d, resp = %+v", resp.StatusCode, out)
+	}
+}
@@ -222,6 +222,10 @@ func (h *handler) PatchProduct(c *gin.Context) {
 		response = responses.BadRequest
 		return
 	}
+	if request.VatPercent != nil && (*request.VatPercent < 0 || *request.VatPercent > 100) {
+		response = responses.BadRequest
+		return
+	}
 
 	rowsAffected, err := h.productService.Patch(c, request)
 	if err != nil {
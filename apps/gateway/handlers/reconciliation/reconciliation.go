@@ -0,0 +1,129 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"sushitana/internal/reconciliation"
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/reply"
+	"sushitana/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	Module = fx.Provide(New)
+)
+
+type (
+	Handler interface {
+		GetListReconciliation(c *gin.Context)
+		GetByIDReconciliation(c *gin.Context)
+		RunReconciliation(c *gin.Context)
+	}
+	Params struct {
+		fx.In
+		Logger                logger.Logger
+		ReconciliationService reconciliation.Service
+	}
+
+	handler struct {
+		logger                logger.Logger
+		reconciliationService reconciliation.Service
+	}
+)
+
+func New(p Params) Handler {
+	return &handler{
+		logger:                p.Logger,
+		reconciliationService: p.ReconciliationService,
+	}
+}
+
+// GetListReconciliation - GET /reconciliation: oxirgi sverkalar (yangilari birinchi)
+func (h *handler) GetListReconciliation(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		req      = structs.GetListReconciliationRequest{
+			Offset: int64(utils.StrToInt(c.Query("offset"))),
+			Limit:  int64(utils.StrToInt(c.Query("limit"))),
+		}
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	list, err := h.reconciliationService.GetList(ctx, req)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.reconciliationService.GetList", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// GetByIDReconciliation - GET /reconciliation/:id: hisobot va nomuvofiqliklar ro'yxati
+func (h *handler) GetByIDReconciliation(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	resp, err := h.reconciliationService.GetByID(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			response = responses.NotFound
+			return
+		}
+		h.logger.Error(ctx, " err on h.reconciliationService.GetByID", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = resp
+}
+
+// RunReconciliation - POST /reconciliation/run {"from","to"} (RFC3339), qo'lda ishga tushirish
+func (h *handler) RunReconciliation(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		request  structs.RunReconciliationRequest
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.logger.Warn(ctx, " error parse request", zap.Error(err))
+		response = responses.BadRequest
+		return
+	}
+
+	// admin sahifani yopsa ham sverka oxirigacha ishlasin
+	rec, err := h.reconciliationService.Run(context.WithoutCancel(ctx), request.From, request.To, structs.ReconcileTriggerManual)
+	if err != nil {
+		switch {
+		case errors.Is(err, structs.ErrBadRequest):
+			response = responses.BadRequest
+			response.Message = "Неверный период (максимум 31 день)"
+		case errors.Is(err, structs.ErrReconcileRunning):
+			response = responses.BadRequest
+			response.Message = "Сверка уже выполняется"
+		default:
+			h.logger.Error(ctx, " err on h.reconciliationService.Run", zap.Error(err))
+			response = responses.InternalErr
+		}
+		return
+	}
+
+	response = responses.Success
+	response.Payload = rec
+}
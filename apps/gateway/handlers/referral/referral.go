@@ -0,0 +1,128 @@
+package referral
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"sushitana/internal/referral"
+	"sushitana/internal/responses"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/reply"
+	"sushitana/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	Module = fx.Provide(New)
+)
+
+type (
+	Handler interface {
+		GetListReferral(c *gin.Context)
+		GetReferralSummary(c *gin.Context)
+	}
+	Params struct {
+		fx.In
+		Logger          logger.Logger
+		ReferralService referral.Service
+	}
+
+	handler struct {
+		logger          logger.Logger
+		referralService referral.Service
+	}
+)
+
+func New(p Params) Handler {
+	return &handler{
+		logger:          p.Logger,
+		referralService: p.ReferralService,
+	}
+}
+
+// GetListReferral - GET /referral: status, referrer_tg_id, from/to (RFC3339) bo'yicha
+func (h *handler) GetListReferral(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+		req      = structs.GetListReferralRequest{
+			Offset:       int64(utils.StrToInt(c.Query("offset"))),
+			Limit:        int64(utils.StrToInt(c.Query("limit"))),
+			Status:       c.Query("status"),
+			ReferrerTgID: cast.ToInt64(c.Query("referrer_tg_id")),
+		}
+		err error
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	if req.From, err = parseTimeQuery(c, "from"); err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid from (RFC3339 expected)"
+		return
+	}
+	if req.To, err = parseTimeQuery(c, "to"); err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid to (RFC3339 expected)"
+		return
+	}
+
+	list, err := h.referralService.GetList(ctx, req)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.referralService.GetList", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = list
+}
+
+// GetReferralSummary - GET /referral/summary: statuslar bo'yicha son, berilgan summa va top taklif qilganlar
+func (h *handler) GetReferralSummary(c *gin.Context) {
+	var (
+		response structs.Response
+		ctx      = c.Request.Context()
+	)
+	defer reply.Json(c.Writer, http.StatusOK, &response)
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid from (RFC3339 expected)"
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		response = responses.BadRequest
+		response.Message = "invalid to (RFC3339 expected)"
+		return
+	}
+
+	summary, err := h.referralService.Summary(ctx, from, to)
+	if err != nil {
+		h.logger.Error(ctx, " err on h.referralService.Summary", zap.Error(err))
+		response = responses.InternalErr
+		return
+	}
+
+	response = responses.Success
+	response.Payload = summary
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"sushitana/apps/gateway/handlers/broadcast"
 	"sushitana/apps/gateway/handlers/cart"
 	"sushitana/apps/gateway/handlers/category"
 	"sushitana/apps/gateway/handlers/client"
@@ -13,8 +14,12 @@ import (
 	"sushitana/apps/gateway/handlers/order"
 	"sushitana/apps/gateway/handlers/payment/click"
 	"sushitana/apps/gateway/handlers/payment/payme"
+	"sushitana/apps/gateway/handlers/payment/records"
 	shopapi "sushitana/apps/gateway/handlers/payment/shop_api"
+	"sushitana/apps/gateway/handlers/payment/uzum"
 	"sushitana/apps/gateway/handlers/product"
+	"sushitana/apps/gateway/handlers/reconciliation"
+	"sushitana/apps/gateway/handlers/referral"
 	"sushitana/apps/gateway/handlers/role"
 	"sushitana/apps/gateway/handlers/ws"
 
@@ -53,9 +58,14 @@ type Params struct {
 	Iiko      iiko.Handler
 	Menu      menu.Handler
 	Order     order.Handler
+	Broadcast broadcast.Handler
+	Referral  referral.Handler
+	Reconcile reconciliation.Handler
 	Click     click.Handler
 	Payme     payme.Handler
+	Payments  records.Handler
 	Shopapi   shopapi.Handler
+	Uzum      uzum.Handler
 	WsHandler ws.Handler
 }
 
@@ -87,11 +97,17 @@ func NewRouter(params Params) {
 		out.POST("/payments/click/complete", params.Click.Complete)
 		out.POST("/payments/click/prepare", params.Click.Prepare)
 		r.POST("/payments/payme", params.Payme.Handle)
+		out.POST("/payments/uzum/check", params.Uzum.Check)
+		out.POST("/payments/uzum/create", params.Uzum.Create)
+		out.POST("/payments/uzum/confirm", params.Uzum.Confirm)
+		out.POST("/payments/uzum/reverse", params.Uzum.Reverse)
+		out.POST("/payments/uzum/status", params.Uzum.Status)
 	}
 	clientGroup := api.Group("/client")
 	{
 		clientGroup.GET("/", params.Client.GetListClient)
 		clientGroup.GET("/:id", params.Client.GetByIDClient)
+		clientGroup.GET("/:id/support", params.Client.GetSupportConversation)
 
 	}
 
@@ -145,6 +161,25 @@ func NewRouter(params Params) {
 		userGroup.GET("/me/:id", params.Cart.GetByUserTgID)
 	}
 
+	// saqlangan manzillar (domofon/podyezd ham) - faqat Mini App initData bilan, :tgid = initData'dagi user.id
+	addressGroup := out.Group("/user/addresses", params.TgWebApp(true))
+	{
+		addressGroup.GET("/:tgid", params.Client.GetAddresses)
+		addressGroup.POST("/:tgid", params.Client.CreateAddress)
+		addressGroup.PATCH("/:tgid/:id", params.Client.UpdateAddress)
+		addressGroup.DELETE("/:tgid/:id", params.Client.DeleteAddress)
+	}
+
+	// saqlangan kartalar (Payme Subscribe) - faqat Mini App initData bilan, :tgid = initData'dagi user.id
+	cardGroup := out.Group("/user/cards", params.TgWebApp(true))
+	{
+		cardGroup.GET("/:tgid", params.Client.GetCards)
+		cardGroup.POST("/:tgid", params.Client.AddCard)
+		cardGroup.POST("/:tgid/:id/verify", params.Client.VerifyCard)
+		cardGroup.POST("/:tgid/:id/resend", params.Client.ResendCardCode)
+		cardGroup.DELETE("/:tgid/:id", params.Client.DeleteCard)
+	}
+
 	cartGroup := out.Group("/cart")
 	{
 		cartGroup.POST("/", params.Cart.CreateCart)
@@ -157,12 +192,23 @@ func NewRouter(params Params) {
 	}
 	orderGroup := out.Group("/order")
 	{
-		orderGroup.POST("/", params.Order.CreateOrder)
+		orderGroup.POST("/", params.TgWebApp(false), params.Order.CreateOrder) // cardId faqat initData bilan
 		orderGroup.GET("/user/:id", params.Order.GetByTgIdOrder)
 		orderGroup.GET("/:id", params.Order.GetByIDOrder)
 		api.GET("/order/", params.Order.GetListOrder)      //yopiq
 		api.PUT("/order/", params.Order.UpdateStatusOrder) //yopiq
+		api.GET("/order/ratings", params.Order.GetRatings)
+		api.GET("/order/ratings/summary", params.Order.GetRatingSummary)
+		api.GET("/order/tips/summary", params.Order.GetTipSummary)
+		api.POST("/order/:id/click-invoice", params.Order.SendClickInvoice) // operator: telefon orqali to'lov
+		api.GET("/order/:id/click-invoice", params.Order.GetClickInvoice)
+		api.PUT("/order/:id/payment-method", params.Order.ChangePaymentMethodByOperator)
+		api.POST("/order/:id/refund", params.Order.RemoveItems) // to'langan zakazdan qator olib tashlash + qaytarish
+		api.GET("/order/:id/refunds", params.Order.GetRefunds)
+		api.PUT("/order/:id/refunds/:refundId", params.Order.MarkRefundDone)
 		orderGroup.DELETE("/:id", params.Order.DeleteOrder)
+		orderGroup.POST("/:id/cancel", params.TgWebApp(true), params.Order.CancelOrder)                 // tg_id faqat initData'dan
+		orderGroup.POST("/:id/payment-method", params.TgWebApp(true), params.Order.ChangePaymentMethod) // to'lov usulini almashtirish / yangi havola
 		orderGroup.POST("/delivery/conculation", params.Order.DeliveryMapFound)
 	}
 	courierGroup := api.Group("/courier/order")
@@ -172,6 +218,36 @@ func NewRouter(params Params) {
 		courierGroup.PUT("/", params.Order.UpdateStatusOrder)
 		out.GET("/ws/orders", params.WsHandler.OrdersWS)
 	}
+	broadcastGroup := api.Group("/broadcast")
+	{
+		broadcastGroup.POST("/", params.Broadcast.CreateBroadcast)
+		broadcastGroup.GET("/", params.Broadcast.GetListBroadcast)
+		broadcastGroup.GET("/:id", params.Broadcast.GetByIDBroadcast)
+		broadcastGroup.POST("/preview", params.Broadcast.PreviewBroadcast)
+		broadcastGroup.POST("/:id/test", params.Broadcast.TestBroadcast)
+		broadcastGroup.POST("/:id/start", params.Broadcast.StartBroadcast)
+		broadcastGroup.POST("/:id/pause", params.Broadcast.PauseBroadcast)
+		broadcastGroup.POST("/:id/resume", params.Broadcast.ResumeBroadcast)
+		broadcastGroup.POST("/:id/cancel", params.Broadcast.CancelBroadcast)
+	}
+	referralGroup := api.Group("/referral")
+	{
+		referralGroup.GET("/", params.Referral.GetListReferral)
+		referralGroup.GET("/summary", params.Referral.GetReferralSummary)
+	}
+	reconciliationGroup := api.Group("/reconciliation")
+	{
+		reconciliationGroup.GET("/", params.Reconcile.GetListReconciliation)
+		reconciliationGroup.GET("/:id", params.Reconcile.GetByIDReconciliation)
+		reconciliationGroup.POST("/run", params.Reconcile.RunReconciliation)
+	}
+	paymentGroup := api.Group("/payment")
+	{
+		paymentGroup.GET("/click/invoices", params.Payments.GetListClickInvoices)
+		paymentGroup.GET("/click/invoices/:id", params.Payments.GetClickInvoice)
+		paymentGroup.GET("/payme/transactions", params.Payments.GetListPaymeTransactions)
+		paymentGroup.GET("/payme/transactions/:id", params.Payments.GetPaymeTransaction)
+	}
 	wsGroup := api.Group("/ws")
 	{
 		wsGroup.GET("/admin/orders", params.WsHandler.AdminOrdersWS)
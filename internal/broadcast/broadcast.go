@@ -0,0 +1,399 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	broadcastrepo "sushitana/pkg/repository/postgres/broadcast_repo"
+	clientrepo "sushitana/pkg/repository/postgres/client_repo"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+const (
+	// Telegram: ~30 xabar/sek umumiy limit; zakaz statuslari uchun zaxira qoldiramiz
+	sendInterval = time.Second / 20
+	batchSize    = 100
+	idleInterval = 30 * time.Second
+	maxButtons   = 6
+	maxCaption   = 1024
+	maxText      = 4096
+)
+
+type (
+	Params struct {
+		fx.In
+		fx.Lifecycle
+
+		Logger        logger.Logger
+		BroadcastRepo broadcastrepo.Repo
+		ClientRepo    clientrepo.Repo
+		Zones         *utils.ZoneChecker
+		Bot           *tgbotapi.BotAPI `optional:"true"`
+	}
+
+	Service interface {
+		Create(ctx context.Context, req structs.CreateBroadcast) (structs.Broadcast, error)
+		GetByID(ctx context.Context, id int64) (structs.Broadcast, error)
+		GetList(ctx context.Context, req structs.GetListBroadcastRequest) (structs.GetListBroadcastResponse, error)
+		// Preview - segment bo'yicha nechta mijozga boradi
+		Preview(ctx context.Context, seg structs.BroadcastSegment) (structs.BroadcastPreview, error)
+		// SendTest - bitta tg_id'ga (odatda admin o'ziga) ko'rinishini tekshirish uchun
+		SendTest(ctx context.Context, id, tgID int64) error
+
+		Start(ctx context.Context, id int64) error
+		Pause(ctx context.Context, id int64) error
+		Resume(ctx context.Context, id int64) error
+		Cancel(ctx context.Context, id int64) error
+	}
+
+	service struct {
+		logger        logger.Logger
+		broadcastRepo broadcastrepo.Repo
+		clientRepo    clientrepo.Repo
+		zones         *utils.ZoneChecker
+		bot           *tgbotapi.BotAPI
+
+		wake chan struct{}
+	}
+)
+
+func New(p Params) Service {
+	s := &service{
+		logger:        p.Logger,
+		broadcastRepo: p.BroadcastRepo,
+		clientRepo:    p.ClientRepo,
+		zones:         p.Zones,
+		bot:           p.Bot,
+		wake:          make(chan struct{}, 1),
+	}
+
+	if s.bot != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go s.run(ctx)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+	}
+	return s
+}
+
+func (s *service) Create(ctx context.Context, req structs.CreateBroadcast) (structs.Broadcast, error) {
+	req.Text = strings.TrimSpace(req.Text)
+	req.Photo = strings.TrimSpace(req.Photo)
+	if err := validate(req); err != nil {
+		return structs.Broadcast{}, err
+	}
+
+	id, err := s.broadcastRepo.Create(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->broadcastRepo.Create", zap.Error(err))
+		return structs.Broadcast{}, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+func validate(req structs.CreateBroadcast) error {
+	if req.Text == "" && req.Photo == "" {
+		return structs.ErrBadRequest
+	}
+	limit := maxText
+	if req.Photo != "" {
+		limit = maxCaption
+	}
+	if len([]rune(req.Text)) > limit || len(req.Buttons) > maxButtons {
+		return structs.ErrBadRequest
+	}
+	for _, b := range req.Buttons {
+		if strings.TrimSpace(b.Text) == "" || !strings.HasPrefix(b.URL, "https://") && !strings.HasPrefix(b.URL, "http://") && !strings.HasPrefix(b.URL, "tg://") {
+			return structs.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (structs.Broadcast, error) {
+	resp, err := s.broadcastRepo.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->broadcastRepo.GetByID", zap.Error(err))
+		}
+		return structs.Broadcast{}, err
+	}
+	return resp, nil
+}
+
+func (s *service) GetList(ctx context.Context, req structs.GetListBroadcastRequest) (structs.GetListBroadcastResponse, error) {
+	resp, err := s.broadcastRepo.GetList(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->broadcastRepo.GetList", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) Preview(ctx context.Context, seg structs.BroadcastSegment) (structs.BroadcastPreview, error) {
+	ids, err := s.audience(ctx, seg)
+	if err != nil {
+		return structs.BroadcastPreview{}, err
+	}
+	return structs.BroadcastPreview{Count: int64(len(ids))}, nil
+}
+
+func (s *service) SendTest(ctx context.Context, id, tgID int64) error {
+	if s.bot == nil {
+		return fmt.Errorf("bot is not configured")
+	}
+	b, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.send(b, tgID)
+}
+
+// Start - auditoriya shu paytdagi segment bo'yicha qotiriladi, keyin worker yuboradi
+func (s *service) Start(ctx context.Context, id int64) error {
+	b, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b.Status != structs.BroadcastStatusDraft {
+		return structs.ErrBadRequest
+	}
+
+	ids, err := s.audience(ctx, b.Segment)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(ids); i += 1000 {
+		end := min(i+1000, len(ids))
+		if err := s.broadcastRepo.AddRecipients(ctx, id, ids[i:end]); err != nil {
+			s.logger.Error(ctx, "->broadcastRepo.AddRecipients", zap.Int64("id", id), zap.Error(err))
+			return err
+		}
+	}
+
+	return s.transition(ctx, id, structs.BroadcastStatusRunning, structs.BroadcastStatusDraft)
+}
+
+func (s *service) Pause(ctx context.Context, id int64) error {
+	return s.transition(ctx, id, structs.BroadcastStatusPaused, structs.BroadcastStatusRunning)
+}
+
+func (s *service) Resume(ctx context.Context, id int64) error {
+	return s.transition(ctx, id, structs.BroadcastStatusRunning, structs.BroadcastStatusPaused)
+}
+
+func (s *service) Cancel(ctx context.Context, id int64) error {
+	return s.transition(ctx, id, structs.BroadcastStatusCancelled,
+		structs.BroadcastStatusDraft, structs.BroadcastStatusRunning, structs.BroadcastStatusPaused)
+}
+
+func (s *service) transition(ctx context.Context, id int64, to string, from ...string) error {
+	ok, err := s.broadcastRepo.SetStatus(ctx, id, to, from...)
+	if err != nil {
+		s.logger.Error(ctx, "->broadcastRepo.SetStatus", zap.Int64("id", id), zap.String("to", to), zap.Error(err))
+		return err
+	}
+	if !ok {
+		return structs.ErrBadRequest
+	}
+	if to == structs.BroadcastStatusRunning {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// audience - SQL segment + zona (oxirgi yetkazish manzili) filtri
+func (s *service) audience(ctx context.Context, seg structs.BroadcastSegment) ([]int64, error) {
+	candidates, err := s.broadcastRepo.Candidates(ctx, seg)
+	if err != nil {
+		s.logger.Error(ctx, "->broadcastRepo.Candidates", zap.Error(err))
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		if len(seg.Zones) > 0 && !s.inZones(c, seg.Zones) {
+			continue
+		}
+		ids = append(ids, c.TgID)
+	}
+	return ids, nil
+}
+
+func (s *service) inZones(c structs.BroadcastCandidate, zones []string) bool {
+	if s.zones == nil || c.LastLat == 0 || c.LastLng == 0 {
+		return false
+	}
+	info := utils.GetDeliveryInfo(s.zones, c.LastLat, c.LastLng, "")
+	if !info.Available {
+		return false
+	}
+	for _, z := range zones {
+		if strings.EqualFold(strings.TrimSpace(z), info.ZoneName) {
+			return true
+		}
+	}
+	return false
+}
+
+// run - RUNNING broadcast'larni ketma-ket yuboradi (bitta goroutine -> umumiy tezlik limiti)
+func (s *service) run(ctx context.Context) {
+	limiter := time.NewTicker(sendInterval)
+	defer limiter.Stop()
+
+	for {
+		running, err := s.broadcastRepo.GetRunning(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error(ctx, "->broadcastRepo.GetRunning", zap.Error(err))
+		}
+		if len(running) > 0 {
+			s.processLocked(ctx, running, limiter.C)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-time.After(idleInterval):
+		}
+	}
+}
+
+// processLocked - bir nechta gateway nusxasi bo'lsa ham bitta worker yuboradi:
+// mijozga xabar ikki marta bormaydi, sekundlik limit ko'paymaydi. Band bo'lsa keyingi aylanishda
+func (s *service) processLocked(ctx context.Context, running []structs.Broadcast, tick <-chan time.Time) {
+	unlock, ok, err := s.broadcastRepo.TryLock(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error(ctx, "->broadcastRepo.TryLock", zap.Error(err))
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	for _, b := range running {
+		s.process(ctx, b, tick)
+	}
+}
+
+// process - pauza/bekor qilish har batch oldidan tekshiriladi
+func (s *service) process(ctx context.Context, b structs.Broadcast, tick <-chan time.Time) {
+	for {
+		cur, err := s.broadcastRepo.GetByID(ctx, b.ID)
+		if err != nil || cur.Status != structs.BroadcastStatusRunning {
+			return
+		}
+
+		pending, err := s.broadcastRepo.NextPending(ctx, b.ID, batchSize)
+		if err != nil {
+			s.logger.Error(ctx, "->broadcastRepo.NextPending", zap.Int64("id", b.ID), zap.Error(err))
+			return
+		}
+		if len(pending) == 0 {
+			_, _ = s.broadcastRepo.SetStatus(ctx, b.ID, structs.BroadcastStatusDone, structs.BroadcastStatusRunning)
+			s.logger.Info(ctx, "broadcast done", zap.Int64("id", b.ID))
+			return
+		}
+
+		for _, tgID := range pending {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+			s.deliver(ctx, cur, tgID)
+		}
+	}
+}
+
+func (s *service) deliver(ctx context.Context, b structs.Broadcast, tgID int64) {
+	err := s.send(b, tgID)
+
+	// 429: Telegram aytgan vaqtcha kutib, bir marta qayta urinamiz
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.Code == http.StatusTooManyRequests {
+		wait := time.Duration(tgErr.RetryAfter) * time.Second
+		if wait <= 0 {
+			wait = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		err = s.send(b, tgID)
+	}
+
+	status, errText := structs.RecipientSent, ""
+	switch {
+	case err == nil:
+	case errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden:
+		status, errText = structs.RecipientBlocked, err.Error()
+		if err := s.clientRepo.SetActive(ctx, tgID, false); err != nil {
+			s.logger.Error(ctx, "->clientRepo.SetActive", zap.Int64("tgId", tgID), zap.Error(err))
+		}
+	default:
+		status, errText = structs.RecipientFailed, err.Error()
+	}
+
+	if err := s.broadcastRepo.MarkRecipient(ctx, b.ID, tgID, status, errText); err != nil {
+		s.logger.Error(ctx, "->broadcastRepo.MarkRecipient", zap.Int64("id", b.ID), zap.Int64("tgId", tgID), zap.Error(err))
+	}
+}
+
+func (s *service) send(b structs.Broadcast, tgID int64) error {
+	var markup any
+	if len(b.Buttons) > 0 {
+		rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(b.Buttons))
+		for _, btn := range b.Buttons {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL(btn.Text, btn.URL)))
+		}
+		markup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+
+	var c tgbotapi.Chattable
+	if b.Photo != "" {
+		var file tgbotapi.RequestFileData = tgbotapi.FileID(b.Photo)
+		if strings.HasPrefix(b.Photo, "http://") || strings.HasPrefix(b.Photo, "https://") {
+			file = tgbotapi.FileURL(b.Photo)
+		}
+		photo := tgbotapi.NewPhoto(tgID, file)
+		photo.Caption = b.Text
+		photo.ParseMode = tgbotapi.ModeHTML
+		photo.ReplyMarkup = markup
+		c = photo
+	} else {
+		msg := tgbotapi.NewMessage(tgID, b.Text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = markup
+		c = msg
+	}
+
+	_, err := s.bot.Send(c)
+	return err
+}
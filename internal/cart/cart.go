@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"sushitana/internal/structs"
+	"sushitana/pkg/config"
 	"sushitana/pkg/logger"
 	cartRepo "sushitana/pkg/repository/postgres/cart_repo"
+	clientrepo "sushitana/pkg/repository/postgres/client_repo"
+	"time"
 
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -18,8 +22,13 @@ var (
 type (
 	Params struct {
 		fx.In
-		CartRepo cartRepo.Repo
-		Logger   logger.Logger
+		fx.Lifecycle
+
+		Config     config.IConfig
+		CartRepo   cartRepo.Repo
+		ClientRepo clientrepo.Repo
+		Logger     logger.Logger
+		Bot        *tgbotapi.BotAPI `optional:"true"`
 	}
 
 	Service interface {
@@ -30,18 +39,49 @@ type (
 		GetByUserTgID(ctx context.Context, tgID int64) (structs.GetCartByTgID, error)
 		GetByTgID(ctx context.Context, tgID int64) (structs.CartInfo, error)
 		ChangeCountDelta(ctx context.Context, tgid int64, productID string, delta int64) (newCount int64, err error)
+		// DisableReminders - mijoz "🔕" bosdi, boshqa savat eslatmasi yuborilmaydi
+		DisableReminders(ctx context.Context, tgID int64) error
 	}
 	service struct {
-		cartRepo cartRepo.Repo
-		logger   logger.Logger
+		cartRepo    cartRepo.Repo
+		clientRepo  clientrepo.Repo
+		logger      logger.Logger
+		bot         *tgbotapi.BotAPI
+		idle        time.Duration
+		recentOrder time.Duration
 	}
 )
 
 func New(p Params) Service {
-	return &service{
-		cartRepo: p.CartRepo,
-		logger:   p.Logger,
+	s := &service{
+		cartRepo:    p.CartRepo,
+		clientRepo:  p.ClientRepo,
+		logger:      p.Logger,
+		bot:         p.Bot,
+		idle:        time.Duration(p.Config.GetInt("cart_reminder_idle_minutes")) * time.Minute,
+		recentOrder: time.Duration(p.Config.GetInt("cart_reminder_recent_order_hours")) * time.Hour,
+	}
+	if s.idle <= 0 {
+		s.idle = defaultReminderIdle
+	}
+	if s.recentOrder <= 0 {
+		s.recentOrder = defaultRecentOrder
+	}
+
+	if s.bot != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go s.runReminders(ctx)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
 	}
+	return s
 }
 
 func (s *service) Create(ctx context.Context, req structs.CreateCart) error {
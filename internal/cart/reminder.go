@@ -0,0 +1,160 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+)
+
+// savat eslatmasi tugmalari (callback prefix'lari)
+const (
+	CbOpenCart     = "open_cart:"    // mavjud savat ko'rinishi -> buyurtmani rasmiylashtirish
+	CbRemindersOff = "cart_rem_off:" // boshqa eslatmasin
+)
+
+const (
+	defaultReminderIdle = 2 * time.Hour
+	defaultRecentOrder  = 24 * time.Hour
+	maxCartAge          = 72 * time.Hour // 3 kundan eski savatni eslatmaymiz
+	reminderPoll        = 5 * time.Minute
+	reminderBatch       = 50
+	reminderSendGap     = time.Second / 20
+
+	// kechasi bezovta qilmaymiz (Toshkent vaqti)
+	quietFrom = 22
+	quietTo   = 10
+)
+
+var tashkent = time.FixedZone("UTC+5", 5*60*60)
+
+func (s *service) DisableReminders(ctx context.Context, tgID int64) error {
+	if err := s.clientRepo.SetCartReminders(ctx, tgID, false); err != nil {
+		s.logger.Error(ctx, "->clientRepo.SetCartReminders", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// runReminders - har 5 daqiqada tashlab ketilgan savatlarni tekshiradi
+func (s *service) runReminders(ctx context.Context) {
+	ticker := time.NewTicker(reminderPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if isQuietHour(time.Now()) {
+				continue
+			}
+			s.remindAbandoned(ctx)
+		}
+	}
+}
+
+func isQuietHour(t time.Time) bool {
+	h := t.In(tashkent).Hour()
+	return h >= quietFrom || h < quietTo
+}
+
+func (s *service) remindAbandoned(ctx context.Context) {
+	now := time.Now()
+	carts, err := s.cartRepo.Abandoned(ctx, structs.AbandonedCartsRequest{
+		IdleBefore:   now.Add(-s.idle),
+		NotOlderThan: now.Add(-maxCartAge),
+		OrderAfter:   now.Add(-s.recentOrder),
+		Limit:        reminderBatch,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "->cartRepo.Abandoned", zap.Error(err))
+		return
+	}
+
+	for _, a := range carts {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reminderSendGap):
+		}
+		s.remind(ctx, a)
+	}
+}
+
+func (s *service) remind(ctx context.Context, a structs.AbandonedCart) {
+	// avval belgilaymiz: xato bo'lsa ham ikkinchi marta yubormaymiz (bitta savat - bitta eslatma)
+	ok, err := s.cartRepo.MarkReminded(ctx, a.TgID, a.StartedAt)
+	if err != nil {
+		s.logger.Error(ctx, "->cartRepo.MarkReminded", zap.Int64("tgId", a.TgID), zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	info, err := s.cartRepo.GetByTgID(ctx, a.TgID)
+	if err != nil || len(info.Products) == 0 {
+		return
+	}
+
+	lang := utils.Lang(a.Language)
+	msg := tgbotapi.NewMessage(a.TgID, reminderText(lang, info))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.CartReminderCompleteBtn), CbOpenCart),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(texts.Get(lang, texts.CartReminderOffBtn), CbRemindersOff),
+		),
+	)
+
+	if _, err := s.bot.Send(msg); err != nil {
+		var tgErr *tgbotapi.Error
+		if errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden {
+			_ = s.clientRepo.SetActive(ctx, a.TgID, false)
+			return
+		}
+		s.logger.Warn(ctx, "cart reminder send failed", zap.Int64("tgId", a.TgID), zap.Error(err))
+	}
+}
+
+func reminderText(lang utils.Lang, info structs.CartInfo) string {
+	var b strings.Builder
+	b.WriteString(texts.Get(lang, texts.CartReminderTitle))
+	b.WriteString("\n\n")
+	for _, p := range info.Products {
+		fmt.Fprintf(&b, "• %s × %d\n", productName(lang, p.Name), p.Count)
+	}
+	fmt.Fprintf(&b, "\n💰 %s %s", formatMoney(info.TotalPrice), texts.Get(lang, texts.CurrencyUzs))
+	return b.String()
+}
+
+func productName(lang utils.Lang, name structs.Name) string {
+	switch lang {
+	case utils.RU:
+		return name.Ru
+	case utils.EN:
+		return name.En
+	default:
+		return name.Uz
+	}
+}
+
+func formatMoney(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
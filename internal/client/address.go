@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"sushitana/internal/structs"
+
+	"go.uber.org/zap"
+)
+
+const addressNameMaxLen = 64
+
+func validateAddress(req *structs.SaveClientAddress) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.TgID == 0 || req.Name == "" || utf8.RuneCountInString(req.Name) > addressNameMaxLen {
+		return structs.ErrBadRequest
+	}
+	// kuryer lokatsiyasiz topa olmaydi, iiko ham koordinata talab qiladi
+	if req.Address.Lat == 0 || req.Address.Lng == 0 {
+		return structs.ErrBadRequest
+	}
+	req.Address.House = strings.TrimSpace(req.Address.House)
+	req.Address.Flat = strings.TrimSpace(req.Address.Flat)
+	req.Address.Entrance = strings.TrimSpace(req.Address.Entrance)
+	req.Address.Floor = strings.TrimSpace(req.Address.Floor)
+	req.Address.Doorphone = strings.TrimSpace(req.Address.Doorphone)
+	return nil
+}
+
+func (s service) GetAddresses(ctx context.Context, tgID int64) ([]structs.ClientAddress, error) {
+	resp, err := s.addressRepo.GetListByTgID(ctx, tgID)
+	if err != nil {
+		s.logger.Error(ctx, "->addressRepo.GetListByTgID", zap.Error(err))
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s service) GetAddress(ctx context.Context, tgID, id int64) (structs.ClientAddress, error) {
+	resp, err := s.addressRepo.GetByID(ctx, tgID, id)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return structs.ClientAddress{}, err
+		}
+		s.logger.Error(ctx, "->addressRepo.GetByID", zap.Error(err))
+		return structs.ClientAddress{}, err
+	}
+	return resp, nil
+}
+
+func (s service) CreateAddress(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error) {
+	if err := validateAddress(&req); err != nil {
+		return structs.ClientAddress{}, err
+	}
+	resp, err := s.addressRepo.Create(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->addressRepo.Create", zap.Error(err))
+		return structs.ClientAddress{}, err
+	}
+	return resp, nil
+}
+
+func (s service) UpdateAddress(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error) {
+	if err := validateAddress(&req); err != nil {
+		return structs.ClientAddress{}, err
+	}
+	resp, err := s.addressRepo.Update(ctx, req)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return structs.ClientAddress{}, err
+		}
+		s.logger.Error(ctx, "->addressRepo.Update", zap.Error(err))
+		return structs.ClientAddress{}, err
+	}
+	return resp, nil
+}
+
+func (s service) DeleteAddress(ctx context.Context, tgID, id int64) error {
+	err := s.addressRepo.Delete(ctx, tgID, id)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return err
+		}
+		s.logger.Error(ctx, "->addressRepo.Delete", zap.Error(err))
+		return err
+	}
+	return nil
+}
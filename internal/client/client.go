@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sushitana/internal/structs"
 	"sushitana/pkg/logger"
+	addressrepo "sushitana/pkg/repository/postgres/address_repo"
 	clientRepo "sushitana/pkg/repository/postgres/client_repo"
 	"sushitana/pkg/utils"
 
@@ -19,8 +20,9 @@ var (
 type (
 	Params struct {
 		fx.In
-		ClientRepo clientRepo.Repo
-		Logger     logger.Logger
+		ClientRepo  clientRepo.Repo
+		AddressRepo addressrepo.Repo
+		Logger      logger.Logger
 	}
 
 	Service interface {
@@ -32,18 +34,29 @@ type (
 		UpdateLanguage(ctx context.Context, tgID int64, lang utils.Lang) error
 		UpdatePhone(ctx context.Context, tgID int64, phone string) error
 		UpdateName(ctx context.Context, tgID int64, name string) error
+		UpdatePromoCode(ctx context.Context, tgID int64, code string) error
+		SetActive(ctx context.Context, tgID int64, active bool) error
 		GetLanguageByTgID(ctx context.Context, tgID int64) (string, error)
+
+		// saqlangan manzillar
+		GetAddresses(ctx context.Context, tgID int64) ([]structs.ClientAddress, error)
+		GetAddress(ctx context.Context, tgID, id int64) (structs.ClientAddress, error)
+		CreateAddress(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error)
+		UpdateAddress(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error)
+		DeleteAddress(ctx context.Context, tgID, id int64) error
 	}
 	service struct {
-		clientRepo clientRepo.Repo
-		logger     logger.Logger
+		clientRepo  clientRepo.Repo
+		addressRepo addressrepo.Repo
+		logger      logger.Logger
 	}
 )
 
 func New(p Params) Service {
 	return &service{
-		clientRepo: p.ClientRepo,
-		logger:     p.Logger,
+		clientRepo:  p.ClientRepo,
+		addressRepo: p.AddressRepo,
+		logger:      p.Logger,
 	}
 }
 
@@ -151,3 +164,23 @@ func (s service) UpdateName(ctx context.Context, tgID int64, name string) error
 	}
 	return err
 }
+
+func (s service) SetActive(ctx context.Context, tgID int64, active bool) error {
+	if err := s.clientRepo.SetActive(ctx, tgID, active); err != nil {
+		s.logger.Error(ctx, " err on s.clientRepo.SetActive", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s service) UpdatePromoCode(ctx context.Context, tgID int64, code string) error {
+	err := s.clientRepo.UpdatePromoCode(ctx, tgID, code)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return err
+		}
+		s.logger.Error(ctx, " err on s.clientRepo.UpdatePromoCode", zap.Error(err))
+		return err
+	}
+	return nil
+}
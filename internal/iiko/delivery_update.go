@@ -0,0 +1,83 @@
+package iiko
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"sushitana/internal/structs"
+
+	"go.uber.org/zap"
+)
+
+const deliveriesBaseURL = "https://api-ru.iiko.services/api/1/deliveries/"
+
+func (s *service) ChangeDeliveryPayments(ctx context.Context, req structs.IikoChangePaymentsRequest) error {
+	if req.OrganizationId == "" || req.OrderId == "" || len(req.Payments) == 0 {
+		return fmt.Errorf("iiko change_payments: organizationId/orderId/payments empty")
+	}
+	return s.postDelivery(ctx, "change_payments", req)
+}
+
+func (s *service) ChangeDeliveryComment(ctx context.Context, req structs.IikoChangeCommentRequest) error {
+	if req.OrganizationId == "" || req.OrderId == "" {
+		return fmt.Errorf("iiko change_comment: organizationId/orderId empty")
+	}
+	return s.postDelivery(ctx, "change_comment", req)
+}
+
+// postDelivery - deliveries/<method>; 401 bo'lsa token yangilanib bir marta qayta yuboriladi
+func (s *service) postDelivery(ctx context.Context, method string, in any) error {
+	token, err := s.EnsureValidIikoToken(ctx)
+	if err != nil {
+		return fmt.Errorf("EnsureValidIikoToken: %w", err)
+	}
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	do := func(tok string) (int, []byte, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, deliveriesBaseURL+method, bytes.NewReader(payload))
+		if err != nil {
+			return 0, nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Accept", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+tok)
+
+		client := &http.Client{Timeout: 20 * time.Second}
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, body, nil
+	}
+
+	status, body, err := do(token)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusUnauthorized {
+		tr, err := s.GetIikoAccessToken(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to refresh token after 401: %w", err)
+		}
+		if status, body, err = do(tr.Token); err != nil {
+			return err
+		}
+	}
+	if status < 200 || status >= 300 {
+		s.logger.Error(ctx, "IIKO deliveries update failed", zap.String("method", method), zap.Int("status", status), zap.ByteString("body", body))
+		return fmt.Errorf("iiko deliveries/%s returned %d: %s", method, status, string(body))
+	}
+
+	s.logger.Info(ctx, "IIKO deliveries update SUCCESS", zap.String("method", method))
+	return nil
+}
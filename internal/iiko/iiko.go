@@ -39,6 +39,10 @@ type (
 		UpdateIIKO(ctx context.Context, id int64, token string) (int64, error)
 		CreatePickup(ctx context.Context, req structs.IikoCreateDeliveryRequest) (structs.IikoCreateDeliveryResponse, error) // NEW
 		EnsureValidIikoToken(ctx context.Context) (string, error)
+
+		// yaratilgan delivery'ni o'zgartirish (qisman qaytarishdan keyin)
+		ChangeDeliveryPayments(ctx context.Context, req structs.IikoChangePaymentsRequest) error
+		ChangeDeliveryComment(ctx context.Context, req structs.IikoChangeCommentRequest) error
 	}
 
 	service struct {
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"sushitana/internal/broadcast"
 	"sushitana/internal/cart"
 	category "sushitana/internal/category"
 	client "sushitana/internal/client"
@@ -9,14 +10,26 @@ import (
 	"sushitana/internal/file"
 	"sushitana/internal/iiko"
 	"sushitana/internal/menu"
+	"sushitana/internal/operator"
 	"sushitana/internal/order"
 	"sushitana/internal/orderflow"
+	"sushitana/internal/payment/cards"
+	"sushitana/internal/payment/cash"
 	"sushitana/internal/payment/click"
+	"sushitana/internal/payment/fiscal"
 	"sushitana/internal/payment/payme"
+	"sushitana/internal/payment/payme/subscribe"
+	"sushitana/internal/payment/provider"
 	shopapi "sushitana/internal/payment/shop-api"
+	"sushitana/internal/payment/telegram"
 	"sushitana/internal/payment/usecase"
+	"sushitana/internal/payment/uzum"
 	"sushitana/internal/product"
+	"sushitana/internal/rating"
+	"sushitana/internal/reconciliation"
+	"sushitana/internal/referral"
 	"sushitana/internal/role"
+	"sushitana/internal/support"
 	"sushitana/internal/ws"
 
 	"go.uber.org/fx"
@@ -33,11 +46,27 @@ var Module = fx.Options(
 	cart.Module,
 	iiko.Module,
 	menu.Module,
+	operator.Module,
 	order.Module,
 	orderflow.Module,
 	click.Module,
+	fiscal.Module,
 	payme.Module,
+	subscribe.Module,
+	cards.Module,
+	provider.Module,
+	cash.Module,
+	click.ProviderModule,
+	payme.ProviderModule,
+	telegram.Module,
+	uzum.Module,
+	uzum.ProviderModule,
 	shopapi.Module,
 	usecase.Module,
+	rating.Module,
+	broadcast.Module,
+	referral.Module,
+	reconciliation.Module,
+	support.Module,
 	ws.Module,
 )
@@ -0,0 +1,353 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+	employeerepo "sushitana/pkg/repository/postgres/employee_repo"
+	operatorrepo "sushitana/pkg/repository/postgres/operator_repo"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+// operator guruhi tugmalari (callback prefix'lari)
+const (
+	CbConfirm      = "op_ok:"   // op_ok:<orderID>
+	CbReject       = "op_rej:"  // op_rej:<orderID> -> sabablar ro'yxati
+	CbRejectReason = "op_rr:"   // op_rr:<orderID>:<code>
+	CbBack         = "op_back:" // op_back:<orderID>
+	CbCall         = "op_call:" // op_call:<orderID>
+)
+
+// RejectReason - rad etish sababi: callback'da kod, mijozga va postga matn
+type RejectReason struct {
+	Code string
+	Text string
+}
+
+// operatorlar ruscha ishlaydi
+var RejectReasons = []RejectReason{
+	{Code: "stop", Text: "Нет в наличии"},
+	{Code: "zone", Text: "Вне зоны доставки"},
+	{Code: "noanswer", Text: "Клиент не отвечает"},
+	{Code: "closed", Text: "Филиал не работает"},
+	{Code: "dup", Text: "Дубликат заказа"},
+}
+
+func RejectReasonText(code string) (string, bool) {
+	for _, r := range RejectReasons {
+		if r.Code == code {
+			return r.Text, true
+		}
+	}
+	return "", false
+}
+
+type (
+	Params struct {
+		fx.In
+
+		Config       config.IConfig
+		Logger       logger.Logger
+		OrderRepo    orderrepo.Repo
+		EmployeeRepo employeerepo.Repo
+		OperatorRepo operatorrepo.Repo
+		Bot          *tgbotapi.BotAPI `optional:"true"`
+	}
+
+	Service interface {
+		// SyncOrder - yangi (CASH) yoki to'langan zakazni guruhga yuboradi, keyin har status'da postni edit qiladi
+		SyncOrder(ctx context.Context, orderID string)
+		// Employee - tugmani bosgan Telegram foydalanuvchisiga bog'langan aktiv xodim
+		Employee(ctx context.Context, tgID int64) (structs.Employee, error)
+		MarkAction(ctx context.Context, orderID string, employeeID int64, action, reason string)
+		RejectKeyboard(orderID string) tgbotapi.InlineKeyboardMarkup
+	}
+
+	service struct {
+		chatID       int64
+		logger       logger.Logger
+		orderRepo    orderrepo.Repo
+		employeeRepo employeerepo.Repo
+		operatorRepo operatorrepo.Repo
+		bot          *tgbotapi.BotAPI
+	}
+)
+
+func New(p Params) Service {
+	return &service{
+		chatID:       p.Config.GetInt64("admin_chat_id"),
+		logger:       p.Logger,
+		orderRepo:    p.OrderRepo,
+		employeeRepo: p.EmployeeRepo,
+		operatorRepo: p.OperatorRepo,
+		bot:          p.Bot,
+	}
+}
+
+func (s *service) SyncOrder(ctx context.Context, orderID string) {
+	if s.bot == nil || s.chatID == 0 || strings.TrimSpace(orderID) == "" {
+		return
+	}
+
+	ord, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", orderID), zap.Error(err))
+		return
+	}
+
+	post, err := s.operatorRepo.Get(ctx, orderID)
+	switch {
+	case errors.Is(err, structs.ErrNotFound):
+		s.post(ctx, ord)
+	case err != nil:
+		s.logger.Error(ctx, "->operatorRepo.Get", zap.String("orderId", orderID), zap.Error(err))
+	case post.MessageID != 0:
+		s.edit(ctx, ord, post)
+	}
+}
+
+// post - birinchi marta: faqat operator ko'rishi kerak bo'lgan zakazlar (to'lov kutilayotganlari emas)
+func (s *service) post(ctx context.Context, ord structs.GetListPrimaryKeyResponse) {
+	status := strings.ToUpper(ord.Order.Status)
+	if status == structs.OrderStatusWaitingPayment || status == structs.OrderStatusCancelled {
+		return
+	}
+
+	claimed, err := s.operatorRepo.Claim(ctx, ord.Order.ID, s.chatID)
+	if err != nil || !claimed {
+		return
+	}
+
+	msg := tgbotapi.NewMessage(s.chatID, s.render(ctx, ord, structs.OperatorPost{}))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.LinkPreviewOptions = tgbotapi.LinkPreviewOptions{IsDisabled: true}
+	if kb, ok := keyboard(ord.Order); ok {
+		msg.ReplyMarkup = kb
+	}
+
+	sent, err := s.bot.Send(msg)
+	if err != nil {
+		s.logger.Error(ctx, "operator post send failed", zap.String("orderId", ord.Order.ID), zap.Error(err))
+		_ = s.operatorRepo.Release(ctx, ord.Order.ID)
+		return
+	}
+	if err := s.operatorRepo.SetMessageID(ctx, ord.Order.ID, sent.MessageID); err != nil {
+		s.logger.Error(ctx, "->operatorRepo.SetMessageID", zap.String("orderId", ord.Order.ID), zap.Error(err))
+	}
+
+	// manzil pin'i postga reply qilib
+	if a := ord.Order.Address; a != nil && a.Lat != 0 && a.Lng != 0 {
+		loc := tgbotapi.NewLocation(s.chatID, a.Lat, a.Lng)
+		loc.ReplyParameters = tgbotapi.ReplyParameters{MessageID: sent.MessageID}
+		if _, err := s.bot.Send(loc); err != nil {
+			s.logger.Warn(ctx, "operator location send failed", zap.String("orderId", ord.Order.ID), zap.Error(err))
+		}
+	}
+}
+
+func (s *service) edit(ctx context.Context, ord structs.GetListPrimaryKeyResponse, post structs.OperatorPost) {
+	var cfg tgbotapi.EditMessageTextConfig
+	if kb, ok := keyboard(ord.Order); ok {
+		cfg = tgbotapi.NewEditMessageTextAndMarkup(post.ChatID, post.MessageID, s.render(ctx, ord, post), kb)
+	} else {
+		cfg = tgbotapi.NewEditMessageText(post.ChatID, post.MessageID, s.render(ctx, ord, post))
+	}
+	cfg.ParseMode = tgbotapi.ModeHTML
+	cfg.LinkPreviewOptions = tgbotapi.LinkPreviewOptions{IsDisabled: true}
+
+	if _, err := s.bot.Send(cfg); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		s.logger.Error(ctx, "operator post edit failed", zap.String("orderId", ord.Order.ID), zap.Error(err))
+	}
+}
+
+func (s *service) Employee(ctx context.Context, tgID int64) (structs.Employee, error) {
+	emp, err := s.employeeRepo.GetByTgID(ctx, tgID)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->employeeRepo.GetByTgID", zap.Int64("tgId", tgID), zap.Error(err))
+		}
+		return structs.Employee{}, err
+	}
+	if !emp.IsActive {
+		return structs.Employee{}, structs.ErrForbidden
+	}
+	return emp, nil
+}
+
+func (s *service) MarkAction(ctx context.Context, orderID string, employeeID int64, action, reason string) {
+	if err := s.operatorRepo.SetAction(ctx, orderID, employeeID, action, reason); err != nil {
+		s.logger.Error(ctx, "->operatorRepo.SetAction", zap.String("orderId", orderID), zap.Error(err))
+	}
+}
+
+func (s *service) RejectKeyboard(orderID string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, r := range RejectReasons {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ "+r.Text, CbRejectReason+orderID+":"+r.Code),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", CbBack+orderID),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// keyboard - WAITING_OPERATOR: tasdiqlash/rad etish (to'langanda faqat tasdiqlash);
+// aktiv zakaz: faqat qo'ng'iroq; yopilgan: tugmasiz
+func keyboard(o structs.Order) (tgbotapi.InlineKeyboardMarkup, bool) {
+	call := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("📞 Позвонить", CbCall+o.ID))
+
+	switch strings.ToUpper(o.Status) {
+	case structs.OrderStatusWaitingOperator:
+		actions := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("✅ Подтвердить", CbConfirm+o.ID))
+		if strings.ToUpper(o.PaymentStatus) != structs.PaymentStatusPaid {
+			actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", CbReject+o.ID))
+		}
+		return tgbotapi.NewInlineKeyboardMarkup(actions, call), true
+	case structs.OrderStatusCancelled, structs.OrderStatusRejected,
+		structs.OrderStatusCompleted, structs.OrderStatusDelivered:
+		return tgbotapi.InlineKeyboardMarkup{}, false
+	default:
+		return tgbotapi.NewInlineKeyboardMarkup(call), true
+	}
+}
+
+var paymentMethods = map[string]string{
+	"CASH":     "💵 Наличные",
+	"CLICK":    "💳 Click",
+	"PAYME":    "💳 Payme",
+	"TELEGRAM": "💳 Telegram",
+	"UZUM":     "💳 Uzum",
+}
+
+func (s *service) render(ctx context.Context, ord structs.GetListPrimaryKeyResponse, post structs.OperatorPost) string {
+	o := ord.Order
+	lang := utils.RU
+	cur := texts.Get(lang, texts.CurrencySymbol)
+
+	var b strings.Builder
+
+	kind := "🚚 Доставка"
+	if strings.ToUpper(o.DeliveryType) == "PICKUP" {
+		kind = "🚶 Самовывоз"
+	}
+	fmt.Fprintf(&b, "🧾 <b>Заказ #%d</b> · %s\n", o.OrderNumber, kind)
+	fmt.Fprintf(&b, "Статус: <b>%s</b>\n", html.EscapeString(texts.OrderStatus(lang, strings.ToUpper(o.Status))))
+
+	pm := strings.ToUpper(o.PaymentMethod)
+	if label, ok := paymentMethods[pm]; ok {
+		pm = label
+	}
+	fmt.Fprintf(&b, "Оплата: %s · %s\n\n", pm, html.EscapeString(texts.PaymentStatus(lang, strings.ToUpper(o.PaymentStatus))))
+
+	if name := strings.TrimSpace(o.Name); name != "" {
+		fmt.Fprintf(&b, "👤 %s\n", html.EscapeString(name))
+	}
+	phone := strings.TrimSpace(ord.Phone)
+	if phone == "" {
+		phone = strings.TrimSpace(o.Phone)
+	}
+	if phone != "" {
+		fmt.Fprintf(&b, "📞 %s\n", html.EscapeString(phone))
+	}
+
+	if a := o.Address; a != nil && strings.ToUpper(o.DeliveryType) != "PICKUP" {
+		if line := addressLine(*a); line != "" {
+			fmt.Fprintf(&b, "📍 %s\n", html.EscapeString(line))
+		}
+		if c := strings.TrimSpace(a.Comment); c != "" {
+			fmt.Fprintf(&b, "🛵 %s\n", html.EscapeString(c))
+		}
+		if a.Lat != 0 && a.Lng != 0 {
+			fmt.Fprintf(&b, "🗺 <a href=\"https://maps.google.com/?q=%.6f,%.6f\">Открыть на карте</a>\n", a.Lat, a.Lng)
+		}
+	}
+	if c := strings.TrimSpace(o.Comment); c != "" {
+		fmt.Fprintf(&b, "💬 %s\n", html.EscapeString(c))
+	}
+	b.WriteString("\n")
+
+	for i, p := range o.Products {
+		fmt.Fprintf(&b, "%d. %s × %d = %s\n",
+			i+1,
+			html.EscapeString(p.ProductName.Ru),
+			p.Quantity,
+			utils.FCurrency(float64(p.ProductPrice*p.Quantity)),
+		)
+		if p.BoxID != "" && p.BoxPrice > 0 {
+			fmt.Fprintf(&b, "   📦 %s × %d = %s\n",
+				html.EscapeString(p.BoxName.Ru),
+				p.Quantity,
+				utils.FCurrency(float64(p.BoxPrice*p.Quantity)),
+			)
+		}
+	}
+	if o.DeliveryPrice > 0 {
+		fmt.Fprintf(&b, "🚚 Доставка: %s\n", utils.FCurrency(float64(o.DeliveryPrice)))
+	}
+	if o.DiscountAmount > 0 {
+		fmt.Fprintf(&b, "🎁 Скидка (%s): −%s\n", o.PromoCode, utils.FCurrency(float64(o.DiscountAmount)))
+	}
+	fmt.Fprintf(&b, "💰 <b>Итого: %s %s</b>\n", utils.FCurrency(float64(o.TotalPrice)), cur)
+	if o.TipAmount > 0 {
+		fmt.Fprintf(&b, "🛵 Чаевые курьеру: %s %s (оплачено онлайн)\n", utils.FCurrency(float64(o.TipAmount)), cur)
+	}
+	if o.ChangeFrom > 0 {
+		fmt.Fprintf(&b, "💵 Сдача с: %s %s\n", utils.FCurrency(float64(o.ChangeFrom)), cur)
+	}
+
+	if post.EmployeeID != nil && post.Action != "" {
+		who := fmt.Sprintf("#%d", *post.EmployeeID)
+		if emp, err := s.employeeRepo.GetById(ctx, *post.EmployeeID); err == nil {
+			who = strings.TrimSpace(emp.Name + " " + emp.Surname)
+		}
+		switch post.Action {
+		case structs.OperatorActionConfirmed:
+			fmt.Fprintf(&b, "\n✅ Подтвердил: %s", html.EscapeString(who))
+		case structs.OperatorActionRejected:
+			fmt.Fprintf(&b, "\n❌ Отклонил: %s", html.EscapeString(who))
+			if post.Reason != "" {
+				fmt.Fprintf(&b, " — %s", html.EscapeString(post.Reason))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// addressLine - "Chilonzor 9, д. 12, кв. 5, под. 2, эт. 3, домофон 15"
+func addressLine(a structs.Address) string {
+	var parts []string
+	if n := strings.TrimSpace(a.Street); n != "" {
+		parts = append(parts, n)
+	} else if n := strings.TrimSpace(a.Name); n != "" && n != "geo" {
+		parts = append(parts, n)
+	}
+	for _, f := range []struct{ label, v string }{
+		{"д.", a.House},
+		{"кв.", a.Flat},
+		{"под.", a.Entrance},
+		{"эт.", a.Floor},
+		{"домофон", a.Doorphone},
+	} {
+		if v := strings.TrimSpace(f.v); v != "" {
+			parts = append(parts, f.label+" "+v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -5,28 +5,37 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/url"
-	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"sushitana/internal/iiko"
+	"sushitana/internal/operator"
+	"sushitana/internal/orderflow"
 	"sushitana/internal/payment/click"
 	"sushitana/internal/payment/payme"
+	"sushitana/internal/payment/provider"
 	shopapi "sushitana/internal/payment/shop-api"
+	"sushitana/internal/rating"
+	"sushitana/internal/referral"
 	"sushitana/internal/structs"
 	"sushitana/internal/texts"
 	rtws "sushitana/internal/ws"
 	"sushitana/pkg/logger"
 	"sushitana/pkg/utils"
 
+	addressrepo "sushitana/pkg/repository/postgres/address_repo"
 	clientrepo "sushitana/pkg/repository/postgres/client_repo"
 	orderrepo "sushitana/pkg/repository/postgres/order_repo"
 	clickrepo "sushitana/pkg/repository/postgres/payment_repo/click_repo"
+	paymerepo "sushitana/pkg/repository/postgres/payment_repo/payme_repo"
+	telegramrepo "sushitana/pkg/repository/postgres/payment_repo/telegram_repo"
+	uzumrepo "sushitana/pkg/repository/postgres/payment_repo/uzum_repo"
+	refundrepo "sushitana/pkg/repository/postgres/refund_repo"
 
 	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
-	"github.com/spf13/cast"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -34,6 +43,12 @@ import (
 const (
 	ohangaronMin = int64(400000)
 	olmaliqMin   = int64(60000)
+
+	// mijoz o'zi bekor qilishi uchun limit: soatiga 3 ta
+	clientCancelLimit  = 3
+	clientCancelWindow = time.Hour
+	// Payme reason 3 - "ошибка выполнения транзакции" (zakaz bekor bo'lgan)
+	paymeReasonOrderCancelled = 3
 )
 
 var (
@@ -44,30 +59,53 @@ type (
 	Params struct {
 		fx.In
 
-		OrderRepo  orderrepo.Repo
-		ClickRepo  clickrepo.Repo
-		ClientRepo clientrepo.Repo
-		Bot        *tgbotapi.BotAPI `optional:"true"`
-		Hub        *rtws.Hub        `optional:"true"`
-		Zones      *utils.ZoneChecker
+		OrderRepo   orderrepo.Repo
+		ClickRepo   clickrepo.Repo
+		PaymeRepo   paymerepo.Repo
+		TgPayRepo   telegramrepo.Repo
+		UzumRepo    uzumrepo.Repo
+		RefundRepo  refundrepo.Repo
+		ClientRepo  clientrepo.Repo
+		AddressRepo addressrepo.Repo
+		Bot         *tgbotapi.BotAPI `optional:"true"`
+		Hub         *rtws.Hub        `optional:"true"`
+		Zones       *utils.ZoneChecker
 
 		ClickSvc click.Service
 		ShopSvc  shopapi.Service
 		PaymeSvc payme.Service
 		IikoSvc  iiko.Service
 
+		OperatorSvc operator.Service
+		RatingSvc   rating.Service
+		ReferralSvc referral.Service
+
+		Payments provider.Registry
+
 		Logger logger.Logger
 	}
 
 	Service interface {
 		Create(ctx context.Context, req structs.CreateOrder) (string, string, error)
 		ConfirmByOperator(ctx context.Context, orderID string) error
+		RejectByOperator(ctx context.Context, req structs.RejectOrderByOperator) error
 		GetByTgId(ctx context.Context, tgId int64) (structs.GetListOrderByTgIDResponse, error)
 		GetByID(ctx context.Context, id string) (structs.GetListPrimaryKeyResponse, error)
 		GetList(ctx context.Context, req structs.GetListOrderRequest) (structs.GetListOrderResponse, error)
 		Delete(ctx context.Context, order_id string) error
 		UpdateStatus(ctx context.Context, req structs.UpdateStatus) error
 		UpdatePaymentStatus(ctx context.Context, req structs.UpdateStatus) error
+		CancelByClient(ctx context.Context, req structs.CancelOrderByClient) error
+		ChangePaymentMethod(ctx context.Context, req structs.ChangePaymentMethod) (structs.ChangePaymentMethodResponse, error)
+		ChangePaymentMethodByOperator(ctx context.Context, orderID, paymentMethod string) (structs.ChangePaymentMethodResponse, error)
+		RemoveItems(ctx context.Context, req structs.RemoveOrderItemsRequest) (structs.RemoveOrderItemsResponse, error)
+		GetRefunds(ctx context.Context, orderID string) ([]structs.OrderRefund, error)
+		MarkRefundDone(ctx context.Context, req structs.MarkRefundDoneRequest) (structs.OrderRefund, error)
+
+		// TipSummary - yetkazilgan zakazlardagi choy puli, umumiy va har kuryer bo'yicha
+		TipSummary(ctx context.Context, from, to *time.Time) (structs.TipSummary, error)
+		CheckTelegramPreCheckout(ctx context.Context, req structs.TelegramPayment) error
+		ConfirmTelegramPayment(ctx context.Context, req structs.TelegramPayment) error
 		DeliveryMapFound(ctx context.Context, req structs.MapFoundRequest) (int64, bool, error)
 
 		HandleIikoDeliveryOrderUpdate(ctx context.Context, evt structs.IikoWebhookEvent) error
@@ -77,12 +115,17 @@ type (
 	}
 
 	service struct {
-		orderRepo  orderrepo.Repo
-		clickRepo  clickrepo.Repo
-		clientRepo clientrepo.Repo
-		bot        *tgbotapi.BotAPI `optional:"true"`
-		hub        *rtws.Hub        `optional:"true"`
-		zones      *utils.ZoneChecker
+		orderRepo   orderrepo.Repo
+		clickRepo   clickrepo.Repo
+		paymeRepo   paymerepo.Repo
+		tgPayRepo   telegramrepo.Repo
+		uzumRepo    uzumrepo.Repo
+		refundRepo  refundrepo.Repo
+		clientRepo  clientrepo.Repo
+		addressRepo addressrepo.Repo
+		bot         *tgbotapi.BotAPI `optional:"true"`
+		hub         *rtws.Hub        `optional:"true"`
+		zones       *utils.ZoneChecker
 
 		logger logger.Logger
 
@@ -90,14 +133,25 @@ type (
 		paymeSvc payme.Service
 		shopSvc  shopapi.Service
 		iikoSvc  iiko.Service
+
+		operatorSvc operator.Service
+		ratingSvc   rating.Service
+		referralSvc referral.Service
+
+		payments provider.Registry
 	}
 )
 
 func New(p Params) Service {
 	return &service{
-		orderRepo:  p.OrderRepo,
-		clickRepo:  p.ClickRepo,
-		clientRepo: p.ClientRepo,
+		orderRepo:   p.OrderRepo,
+		clickRepo:   p.ClickRepo,
+		paymeRepo:   p.PaymeRepo,
+		tgPayRepo:   p.TgPayRepo,
+		uzumRepo:    p.UzumRepo,
+		refundRepo:  p.RefundRepo,
+		clientRepo:  p.ClientRepo,
+		addressRepo: p.AddressRepo,
 
 		logger:   p.Logger,
 		clickSvc: p.ClickSvc,
@@ -107,6 +161,12 @@ func New(p Params) Service {
 		zones:    p.Zones,
 		hub:      p.Hub,
 		bot:      p.Bot,
+
+		operatorSvc: p.OperatorSvc,
+		ratingSvc:   p.RatingSvc,
+		referralSvc: p.ReferralSvc,
+
+		payments: p.Payments,
 	}
 }
 
@@ -130,38 +190,23 @@ func NormalizeDeliveryType(v string) (string, error) {
 	return "", structs.ErrBadRequest
 }
 
-func NormalizePaymentMethod(v string) (string, error) {
-	s := strings.TrimSpace(strings.ToUpper(v))
-	switch s {
-	case "CASH", "CLICK", "PAYME":
-		return s, nil
-	}
-
-	sl := strings.TrimSpace(strings.ToLower(v))
-	switch sl {
-	case "cash":
-		return "CASH", nil
-	case "click":
-		return "CLICK", nil
-	case "payme":
-		return "PAYME", nil
-	}
-
-	return "", structs.ErrBadRequest
-}
-
 func (s *service) Create(ctx context.Context, req structs.CreateOrder) (string, string, error) {
 	// 0) normalize
 	dt, err := NormalizeDeliveryType(req.DeliveryType)
 	if err != nil {
 		return "", "", err
 	}
-	pm, err := NormalizePaymentMethod(req.PaymentMethod)
+	prov, err := s.payments.Get(req.PaymentMethod)
 	if err != nil {
 		return "", "", err
 	}
 	req.DeliveryType = dt
-	req.PaymentMethod = pm
+	req.PaymentMethod = prov.Method()
+	req.OnlinePayment = prov.Online()
+	// saqlangan karta faqat Payme Subscribe orqali
+	if req.CardID != 0 && req.PaymentMethod != structs.PaymentMethodPayme {
+		return "", "", structs.ErrBadRequest
+	}
 
 	// 1) validate products
 	if len(req.Products) == 0 {
@@ -200,6 +245,15 @@ func (s *service) Create(ctx context.Context, req structs.CreateOrder) (string,
 		return "", "", structs.ErrBadRequest
 	}
 
+	// filialda shu to'lov usuli yoqilganmi (payment_methods_<branch>)
+	branch := provider.BranchPickup
+	if req.DeliveryType == "DELIVERY" {
+		branch = provider.BranchByZone(zoneIdx)
+	}
+	if !s.payments.IsEnabled(branch, req.PaymentMethod) {
+		return "", "", structs.ErrPaymentMethodDisabled
+	}
+
 	// 3) productsTotal'ni DB’dan hisoblaymiz (box ham qo‘shiladi)
 	//    (min order DELIVERY uchun faqat mahsulotlar/box summasi, delivery kirmaydi)
 	var (
@@ -287,6 +341,37 @@ func (s *service) Create(ctx context.Context, req structs.CreateOrder) (string,
 		}
 	}
 
+	// referal promo-kodi mijozniki bo'lsa chegirma; boshqa kodlar faqat izohda qoladi
+	// (bir nechta mijozda bo'lishi mumkin, orders.promo_code'ga yozilmaydi)
+	code := strings.ToUpper(strings.TrimSpace(req.PromoCode))
+	req.PromoCode, req.DiscountAmount = "", 0
+	if code != "" && s.referralSvc != nil {
+		amount, err := s.referralSvc.Discount(ctx, req.TgID, code)
+		switch {
+		case err == nil:
+			req.PromoCode = code
+			req.DiscountAmount = structs.AppliedDiscount(amount, productsTotal)
+		case errors.Is(err, structs.ErrPromoCodeUsed):
+			return "", "", err
+		case !errors.Is(err, structs.ErrNotFound):
+			return "", "", err
+		}
+	}
+
+	// qaytim faqat naqd, choy puli faqat online - ikkalasi ham yetkazishda (kuryer uchun)
+	if req.DeliveryType != "DELIVERY" || req.OnlinePayment {
+		req.ChangeFrom = 0
+	}
+	if req.DeliveryType != "DELIVERY" || !req.OnlinePayment {
+		req.TipAmount = 0
+	}
+	if req.ChangeFrom != 0 && req.ChangeFrom < productsTotal+req.DeliveryPrice-req.DiscountAmount {
+		return "", "", structs.ErrInvalidChange
+	}
+	if req.TipAmount < 0 || req.TipAmount > productsTotal {
+		return "", "", structs.ErrInvalidTip
+	}
+
 	// 5) Create order in DB (repo status/paysni payment method bo'yicha o'zi qo'yadi)
 	id, err := s.orderRepo.Create(ctx, req)
 	if err != nil {
@@ -300,93 +385,100 @@ func (s *service) Create(ctx context.Context, req structs.CreateOrder) (string,
 		return "", "", err
 	}
 
-	// 6) CASH bo'lsa link yo'q
-	if req.PaymentMethod == "CASH" {
+	// 6) Offline (CASH) - link yo'q, darhol operator guruhiga (online'lar PAID bo'lganda chiqadi)
+	if !prov.Online() {
+		s.operatorSvc.SyncOrder(ctx, id)
 		return "", id, nil
 	}
 
-	// 7) Online bo'lsa (CLICK/PAYME) payment link yaratamiz (agar oldin saqlangan bo'lsa qaytaramiz)
+	// 7) Online - payment link (agar oldin saqlangan bo'lsa qaytaramiz)
 	if strings.TrimSpace(ord.Order.PaymentUrl) != "" {
 		return ord.Order.PaymentUrl, id, nil
 	}
 
-	switch req.PaymentMethod {
-	case "CLICK":
-		serviceId := strings.TrimSpace(os.Getenv("CLICK_SERVICE_ID"))
-		merchantId := strings.TrimSpace(os.Getenv("CLICK_MERCHANT_ID"))
-		if serviceId == "" || merchantId == "" {
-			return "", id, fmt.Errorf("CLICK_SERVICE_ID yoki CLICK_MERCHANT_ID env not found")
-		}
+	intent, err := prov.CreatePayment(ctx, structs.PaymentOrder{
+		OrderID:       id,
+		OrderNumber:   ord.Order.OrderNumber,
+		TgID:          ord.Order.TgID,
+		Phone:         ord.Phone,
+		Amount:        ord.Order.PayAmount,
+		PaymentStatus: ord.Order.PaymentStatus,
+		CardID:        req.CardID,
+	})
+	if err != nil {
+		return "", id, err
+	}
+	// TELEGRAM'da link yo'q - bot o'zi sendInvoice yuboradi
+	if intent.URL == "" {
+		return "", id, nil
+	}
+	if err := s.orderRepo.AddLink(ctx, intent.URL, id); err != nil {
+		return "", id, err
+	}
+	return intent.URL, id, nil
+}
 
-		merchantTransID := cast.ToString(ord.Order.OrderNumber)
-		amountInt := ord.Order.TotalPrice
+// ConfirmByOperator - operator tasdiqladi: WAITING_OPERATOR -> COOKING.
+// Status sharti SQL ichida: parallel bekor qilingan zakaz iiko'ga ketmaydi.
+func (s *service) ConfirmByOperator(ctx context.Context, orderID string) error {
+	if orderID == "" {
+		return structs.ErrBadRequest
+	}
 
-		// prepare
-		prep, err := s.clickSvc.CheckoutPrepare(ctx, structs.CheckoutPrepareRequest{
-			ServiceID:        serviceId,
-			MerchantID:       merchantId,
-			TransactionParam: merchantTransID,
-			Amount:           float64(amountInt),
-			Description:      fmt.Sprintf("Order #%d", ord.Order.OrderNumber),
-		})
-		if err != nil {
-			return "", id, fmt.Errorf("click checkout/prepare failed: %w", err)
+	if err := s.orderRepo.ConfirmByOperator(ctx, orderID); err != nil {
+		if !errors.Is(err, structs.ErrNotWaitingOperator) {
+			s.logger.Error(ctx, "->orderRepo.ConfirmByOperator", zap.Error(err))
 		}
+		return err
+	}
 
-		// orderga click info yozib qo'yish (request_id / transaction_param)
-		_ = s.orderRepo.UpdateClickInfo(ctx, id, prep.RequestId, merchantTransID)
+	s.notifyOrderStatusIfNeeded(ctx, orderID, structs.OrderStatusCooking)
+	return s.sendToIikoIfAllowed(ctx, orderID)
+}
 
-		sid := cast.ToInt64(serviceId)
-		payURL := s.BuildClickPayURL(sid, merchantId, amountInt, merchantTransID, "")
-		if payURL == "" {
-			return "", id, fmt.Errorf("click pay url empty")
-		}
-		if _, err := s.clickRepo.Create(ctx, structs.Invoice{
-			ClickInvoiceID:  0,
-			ClickTransID:    0,
-			ClickPaydocID:   0,
-			MerchantTransID: merchantTransID, // orderNumber string
-			OrderID:         sql.NullString{String: id, Valid: strings.TrimSpace(id) != ""},
-			TgID:            sql.NullInt64{Int64: ord.Order.TgID, Valid: ord.Order.TgID != 0},
-			CustomerPhone:   sql.NullString{String: ord.Phone, Valid: ord.Phone != ""},
-			Amount:          cast.ToString(amountInt), // ord.Order.TotalPrice
-			Currency:        "UZS",
-			Status:          "WAITING_PAYMENT",
-			Comment:         sql.NullString{},
-		}); err != nil {
-			return "", id, fmt.Errorf("click invoice create failed: %w", err)
-		}
-		if err := s.orderRepo.AddLink(ctx, payURL, id); err != nil {
-			return "", id, err
+// RejectByOperator - operator guruhidan rad etish (sabab mijozga ham yuboriladi).
+// To'langan zakaz rad etilmaydi (structs.ErrOrderPaid): tasdiqlab, panelda qaytarish rasmiylashtiriladi.
+func (s *service) RejectByOperator(ctx context.Context, req structs.RejectOrderByOperator) error {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.OrderId == "" {
+		return structs.ErrBadRequest
+	}
+
+	ord, err := s.orderRepo.GetByID(ctx, req.OrderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return structs.ErrNotFound
 		}
-		return payURL, id, nil
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.Error(err))
+		return err
+	}
+	if strings.ToUpper(ord.Order.PaymentStatus) == structs.PaymentStatusPaid {
+		return structs.ErrOrderPaid
+	}
 
-	case "PAYME":
-		merchantID := strings.TrimSpace(os.Getenv("PAYME_KASSA_ID"))
-		if merchantID == "" {
-			return "", id, fmt.Errorf("PAYME_KASSA_ID env not found")
+	// status/to'lov sharti SQL ichida ham (parallel PAID bo'lib qolsa)
+	if err := s.orderRepo.RejectByOperator(ctx, req); err != nil {
+		if !errors.Is(err, structs.ErrNotWaitingOperator) {
+			s.logger.Error(ctx, "->orderRepo.RejectByOperator", zap.Error(err))
 		}
+		return err
+	}
 
-		amountTiyin := ord.Order.TotalPrice * 100
-		transactionParam := cast.ToString(ord.Order.OrderNumber)
+	if n, err := s.clickRepo.CancelByOrderID(ctx, req.OrderId); err != nil {
+		s.logger.Error(ctx, "->clickRepo.CancelByOrderID", zap.String("orderId", req.OrderId), zap.Error(err))
+	} else if n > 0 {
+		s.logger.Info(ctx, "click invoices cancelled", zap.String("orderId", req.OrderId), zap.Int64("count", n))
+	}
 
-		payURL, err := s.paymeSvc.BuildPaymeCheckoutURL(merchantID, transactionParam, amountTiyin)
-		if err != nil {
-			return "", id, fmt.Errorf("build payme checkout url failed: %w", err)
-		}
+	s.notifyOrderStatusIfNeeded(ctx, req.OrderId, structs.OrderStatusRejected)
+	s.restorePromoCode(ctx, req.OrderId, structs.OrderStatusRejected)
 
-		if err := s.orderRepo.AddLink(ctx, payURL, id); err != nil {
-			return "", id, err
+	if s.hub != nil {
+		if fresh, err := s.orderRepo.GetByID(ctx, req.OrderId); err == nil {
+			s.publishUpsertToAdmins(mapOrdToDTO(fresh))
 		}
-		return payURL, id, nil
-
-	default:
-		return "", id, structs.ErrBadRequest
 	}
-}
-
-func (s *service) ConfirmByOperator(ctx context.Context, orderID string) error {
-	return s.sendToIikoIfAllowed(ctx, orderID)
+	return nil
 }
 
 func (s *service) sendToIikoIfAllowed(ctx context.Context, orderID string) error {
@@ -410,9 +502,9 @@ func (s *service) sendToIikoIfAllowed(ctx context.Context, orderID string) error
 	paymentStatus := strings.ToUpper(strings.TrimSpace(ord.Order.PaymentStatus))
 
 	// 2) to'lov sharti:
-	// CASH bo'lsa ruxsat
-	// CLICK/PAYME bo'lsa faqat PAID bo'lsa ruxsat
-	if paymentMethod == "CLICK" || paymentMethod == "PAYME" {
+	// offline (CASH) bo'lsa ruxsat
+	// online bo'lsa faqat PAID bo'lsa ruxsat
+	if s.payments.Online(paymentMethod) {
 		if paymentStatus != "PAID" {
 			s.logger.Info(ctx, "iiko: online payment not PAID, skip",
 				zap.String("order_id", orderID),
@@ -424,9 +516,9 @@ func (s *service) sendToIikoIfAllowed(ctx context.Context, orderID string) error
 	}
 
 	// 3) build request
-	iikoReq, err := buildCreateOrderForIiko(ord)
+	iikoReq, err := orderflow.BuildIikoDeliveryRequest(ord)
 	if err != nil {
-		s.logger.Error(ctx, "buildCreateOrderForIiko failed",
+		s.logger.Error(ctx, "BuildIikoDeliveryRequest failed",
 			zap.String("order_id", orderID),
 			zap.String("delivery_type", deliveryType),
 			zap.Error(err),
@@ -480,7 +572,7 @@ func (s *service) UpdatePaymentStatus(ctx context.Context, req structs.UpdateSta
 	s.notifyOrderStatusIfNeeded(ctx, req.OrderId, "WAITING_PAYMENT")
 	switch pStatus {
 	case "PAID":
-		if paymentMethod == "CLICK" || paymentMethod == "PAYME" {
+		if s.payments.Online(paymentMethod) {
 			if deliveryType == "DELIVERY" && ord.Order.Address == nil {
 				return fmt.Errorf("paid but address missing")
 			}
@@ -543,6 +635,10 @@ func (s *service) UpdateStatus(ctx context.Context, req structs.UpdateStatus) er
 		return err
 	}
 	s.notifyOrderStatusIfNeeded(ctx, req.OrderId, st)
+	s.rememberDeliveryAddress(ctx, req.OrderId, st)
+	s.scheduleRating(ctx, structs.ScheduleRating{OrderID: req.OrderId}, st)
+	s.rewardReferral(ctx, req.OrderId, st)
+	s.restorePromoCode(ctx, req.OrderId, st)
 	// COOKING bo'lsa iiko'ga yuborishni ham urinib ko'ramiz
 	if st == "COOKING" {
 		if err := s.sendToIikoIfAllowed(ctx, req.OrderId); err != nil {
@@ -562,190 +658,82 @@ func (s *service) UpdateStatus(ctx context.Context, req structs.UpdateStatus) er
 	return nil
 }
 
-func (s *service) BuildClickPayURL(serviceID int64, merchantID string, amountInt int64, orderID, returnURL string) string {
-	v := url.Values{}
-	v.Set("service_id", cast.ToString(serviceID))
-	v.Set("merchant_id", merchantID)
-	v.Set("amount", fmt.Sprintf("%d.00", amountInt))
-	v.Set("transaction_param", orderID)
-	if returnURL != "" {
-		v.Set("return_url", returnURL)
-	}
-	return "https://my.click.uz/services/pay?" + v.Encode()
-}
-
-func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.IikoCreateDeliveryRequest, error) {
-	organizationID := strings.TrimSpace(os.Getenv("IIKO_ORGANIZATION_ID"))
-	terminalGroupID := strings.TrimSpace(os.Getenv("IIKO_TERMINAL_GROUP_ID"))
-	deliveryOrderTypeID := strings.TrimSpace(os.Getenv("IIKO_DELIVERY_ORDER_TYPE_ID"))
-	pickupOrderTypeID := strings.TrimSpace(os.Getenv("IIKO_PICKUP_ORDER_TYPE_ID"))
-
-	paymentCashID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_CASH_ID"))
-	paymentClickID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_CLICK_ID"))
-	paymentPaymeID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_PAYME_ID"))
-
-	if organizationID == "" || terminalGroupID == "" {
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("IIKO_ORGANIZATION_ID/IIKO_TERMINAL_GROUP_ID empty")
-	}
-
-	deliveryType := strings.ToUpper(strings.TrimSpace(ord.Order.DeliveryType))
-	paymentMethod := strings.ToUpper(strings.TrimSpace(ord.Order.PaymentMethod))
-
-	// 1) orderTypeId
-	var orderTypeID string
-	switch deliveryType {
-	case "DELIVERY":
-		orderTypeID = deliveryOrderTypeID
-	case "PICKUP":
-		orderTypeID = pickupOrderTypeID
-	default:
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("unknown DeliveryType=%s", ord.Order.DeliveryType)
-	}
-	if strings.TrimSpace(orderTypeID) == "" {
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("orderTypeId empty for DeliveryType=%s", ord.Order.DeliveryType)
-	}
-
-	// 2) payment mapping
-	var (
-		paymentTypeID       string
-		paymentKind         string
-		processedExternally bool
-	)
-
-	switch paymentMethod {
-	case "CASH":
-		paymentKind = "Cash"
-		paymentTypeID = paymentCashID
-	case "CLICK":
-		paymentKind = "Card"       // ✅ MUHIM
-		processedExternally = true // qolsa ham bo‘ladi
-		paymentTypeID = paymentClickID
-
-	case "PAYME":
-		paymentKind = "Card" // ✅ MUHIM
-		processedExternally = true
-		paymentTypeID = paymentPaymeID
-	default:
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("unknown PaymentMethod=%s", ord.Order.PaymentMethod)
-	}
-	if strings.TrimSpace(paymentTypeID) == "" {
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("paymentTypeId empty for PaymentMethod=%s", ord.Order.PaymentMethod)
+// CancelByClient - mijoz zakazni o'zi bekor qiladi (bot yoki Mini App).
+// Faqat COOKING'gacha: WAITING_PAYMENT / WAITING_OPERATOR va hali PAID bo'lmagan.
+func (s *service) CancelByClient(ctx context.Context, req structs.CancelOrderByClient) error {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.OrderId == "" || req.TgID == 0 {
+		return structs.ErrBadRequest
 	}
 
-	// 3) items: products + box aggregation
-	boxQty := make(map[string]float64, len(ord.Order.Products))
-	for _, p := range ord.Order.Products {
-		boxID := strings.TrimSpace(p.BoxID)
-		if boxID == "" {
-			continue
+	ord, err := s.orderRepo.GetByID(ctx, req.OrderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return structs.ErrNotFound
 		}
-		boxQty[boxID] += float64(p.Quantity)
-	}
-
-	items := make([]structs.IikoOrderItem, 0, len(ord.Order.Products)+len(boxQty))
-	for _, p := range ord.Order.Products {
-		items = append(items, structs.IikoOrderItem{
-			Type:      "Product",
-			ProductId: p.ID,
-			Amount:    float64(p.Quantity),
-		})
-	}
-	for boxID, amt := range boxQty {
-		items = append(items, structs.IikoOrderItem{
-			Type:      "Product",
-			ProductId: boxID,
-			Amount:    amt,
-		})
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.Error(err))
+		return err
 	}
-	if err := addDeliveryFeeItem(&items, ord.Order.DeliveryType, ord.Order.DeliveryPrice); err != nil {
-		return structs.IikoCreateDeliveryRequest{}, err
+	if ord.Order.TgID != req.TgID {
+		return structs.ErrForbidden
 	}
-
-	// 4) payment sum (orderPriceForIIKO)
-	sum := float64(ord.Order.OrderPriceForIIKO)
-	if sum <= 0 {
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("orderPriceForIIKO is 0; cannot build iiko payment sum")
+	if !IsCancellableByClient(ord.Order) {
+		return structs.ErrNotCancellable
 	}
 
-	// 5) phone
-	phone := strings.TrimSpace(ord.Phone)
-	if phone == "" {
-		phone = strings.TrimSpace(ord.Order.Phone)
-	}
-	if phone == "" {
-		phone = "+998000000000"
+	cnt, err := s.orderRepo.CountClientCancels(ctx, req.TgID, time.Now().Add(-clientCancelWindow))
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.CountClientCancels", zap.Error(err))
+		return err
 	}
-
-	comment := strings.TrimSpace(ord.Order.Comment)
-
-	iikoOrder := structs.IikoOrder{
-		Phone:          phone,
-		ExternalNumber: fmt.Sprintf("%d", ord.Order.OrderNumber),
-		OrderTypeId:    orderTypeID,
-		Comment:        comment,
-		Items:          items,
-		Payments: []structs.IikoPayment{
-			{
-				PaymentTypeId:         paymentTypeID,
-				PaymentTypeKind:       paymentKind,
-				Sum:                   sum,
-				IsProcessedExternally: processedExternally,
-			},
-		},
+	if cnt >= clientCancelLimit {
+		return structs.ErrTooManyRequests
 	}
 
-	// 6) DELIVERY requires deliveryPoint with coordinates
-	if deliveryType == "DELIVERY" {
-		a := ord.Order.Address
-		if a == nil {
-			return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("iiko delivery requires address (nil)")
-		}
-
-		lat := a.Lat
-		lng := a.Lng
-
-		if lat == 0 || lng == 0 {
-			return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("iiko delivery requires coordinates (lat/lng), got lat=%v lng=%v", lat, lng)
-		}
-
-		house := strings.TrimSpace(a.House)
-		if house == "" {
-			house = "1"
+	// status sharti SQL ichida ham tekshiriladi (parallel PAID bo'lib qolsa)
+	if err := s.orderRepo.CancelByClient(ctx, req); err != nil {
+		if !errors.Is(err, structs.ErrNotCancellable) {
+			s.logger.Error(ctx, "->orderRepo.CancelByClient", zap.Error(err))
 		}
+		return err
+	}
 
-		streetName := strings.TrimSpace(a.Street)
-		if streetName == "" {
-			streetName = strings.TrimSpace(a.Name)
-		}
+	// zakaz allaqachon bekor: xato ichida loglanadi, Uzum confirm zakaz holatini o'zi ham tekshiradi
+	_ = s.cancelPendingPayments(ctx, req.OrderId)
 
-		var street *structs.IikoStreet
-		if streetName != "" {
-			street = &structs.IikoStreet{Name: streetName}
-		}
+	s.notifyOrderStatusIfNeeded(ctx, req.OrderId, structs.OrderStatusCancelled)
+	s.restorePromoCode(ctx, req.OrderId, structs.OrderStatusCancelled)
 
-		iikoOrder.DeliveryPoint = &structs.IikoDeliveryPoint{
-			Coordinates: &structs.IikoCoordinates{
-				Latitude:  lat,
-				Longitude: lng,
-			},
-			Address: &structs.IikoAddress{
-				Street:  street,
-				House:   house,
-				Comment: strings.TrimSpace(a.Name),
+	if s.hub != nil {
+		s.hub.BroadcastToAdmins(structs.Event{
+			Type: structs.EventOrderCancelled,
+			TS:   time.Now(),
+			Payload: structs.OrderCancelledPayload{
+				ID:          req.OrderId,
+				TgID:        req.TgID,
+				OrderNumber: ord.Order.OrderNumber,
+				Reason:      req.Reason,
+				CancelledBy: "CLIENT",
 			},
+		})
+		if fresh, err := s.orderRepo.GetByID(ctx, req.OrderId); err == nil {
+			s.publishUpsertToAdmins(mapOrdToDTO(fresh))
 		}
 	}
+	return nil
+}
+
+// IsCancellableByClient - bot/Mini App tugmani ko'rsatish uchun ham ishlatiladi
+func IsCancellableByClient(ord structs.Order) bool {
+	st := strings.ToUpper(ord.Status)
+	if st != structs.OrderStatusWaitingPayment && st != structs.OrderStatusWaitingOperator {
+		return false
+	}
+	return strings.ToUpper(ord.PaymentStatus) != "PAID"
+}
 
-	// 7) final request
-	return structs.IikoCreateDeliveryRequest{
-		OrganizationId:  organizationID,
-		TerminalGroupId: terminalGroupID,
-		CreateOrderSettings: &structs.IikoCreateSettings{
-			TransportToFrontTimeout: 300,
-			CheckStopList:           false,
-		},
-		Order: iikoOrder,
-	}, nil
+func (s *service) BuildClickPayURL(serviceID int64, merchantID string, amountInt int64, orderID, returnURL string) string {
+	return click.BuildPayURL(serviceID, merchantID, amountInt, orderID, returnURL)
 }
 
 // --- NOTIFY PART ---
@@ -853,10 +841,28 @@ func (s *service) HandleIikoDeliveryOrderUpdate(ctx context.Context, evt structs
 	}
 
 	s.notifyOrderStatusIfNeeded(ctx, ord.ID, newStatus)
+	s.rememberDeliveryAddress(ctx, ord.ID, newStatus)
+
+	courierID, courierName := extractIikoCourier(evt.EventInfo.Order)
+	if courierID != "" {
+		// choy puli hisobotida yetkazgan kuryerga bog'lanadi
+		if err := s.orderRepo.SetCourier(ctx, ord.ID, courierID, courierName); err != nil {
+			s.logger.Error(ctx, "->orderRepo.SetCourier", zap.String("orderId", ord.ID), zap.Error(err))
+		}
+	}
+	s.scheduleRating(ctx, structs.ScheduleRating{
+		OrderID:     ord.ID,
+		CourierID:   courierID,
+		CourierName: courierName,
+	}, newStatus)
+	s.rewardReferral(ctx, ord.ID, newStatus)
 	return nil
 }
 
 func (s *service) notifyOrderStatusIfNeeded(ctx context.Context, orderID string, newStatus string) {
+	// operator guruhidagi post har status'da yangilanadi
+	s.operatorSvc.SyncOrder(ctx, orderID)
+
 	if s.bot == nil {
 		return
 	}
@@ -927,6 +933,57 @@ func (s *service) notifyOrderStatusIfNeeded(ctx context.Context, orderID string,
 	}
 }
 
+// rememberDeliveryAddress - yetkazilgan zakaz manzili mijoz manzillariga qo'shiladi (keyingi safar bot'da tanlanadi)
+func (s *service) rememberDeliveryAddress(ctx context.Context, orderID string, status string) {
+	if s.addressRepo == nil || (status != structs.OrderStatusCompleted && status != structs.OrderStatusDelivered) {
+		return
+	}
+
+	ord, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", orderID), zap.Error(err))
+		return
+	}
+	if strings.ToUpper(ord.Order.DeliveryType) != "DELIVERY" || ord.Order.Address == nil {
+		return
+	}
+
+	if err := s.addressRepo.SaveFromOrder(ctx, ord.Order.TgID, *ord.Order.Address, ""); err != nil {
+		s.logger.Error(ctx, "->addressRepo.SaveFromOrder", zap.String("orderId", orderID), zap.Error(err))
+	}
+}
+
+// scheduleRating - yakunlangan zakazga kechiktirilgan baho so'rovi
+func (s *service) scheduleRating(ctx context.Context, req structs.ScheduleRating, status string) {
+	if s.ratingSvc == nil || (status != structs.OrderStatusCompleted && status != structs.OrderStatusDelivered) {
+		return
+	}
+	s.ratingSvc.Schedule(ctx, req)
+}
+
+// rewardReferral - taklif qilingan do'stning birinchi yakunlangan zakazi -> ikkalasiga mukofot
+func (s *service) rewardReferral(ctx context.Context, orderID string, status string) {
+	if s.referralSvc == nil || (status != structs.OrderStatusCompleted && status != structs.OrderStatusDelivered) {
+		return
+	}
+	s.referralSvc.OnOrderCompleted(ctx, orderID)
+}
+
+// restorePromoCode - bot kodni zakaz yaratilganda tozalaydi; zakaz bekor/rad etilsa
+// unique index kodni bo'shatadi, mijozga ham qaytaramiz
+func (s *service) restorePromoCode(ctx context.Context, orderID string, status string) {
+	if s.clientRepo == nil || (status != structs.OrderStatusCancelled && status != structs.OrderStatusRejected) {
+		return
+	}
+	ord, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || ord.Order.PromoCode == "" || ord.Order.TgID == 0 {
+		return
+	}
+	if err := s.clientRepo.RestorePromoCode(ctx, ord.Order.TgID, ord.Order.PromoCode); err != nil {
+		s.logger.Error(ctx, "->clientRepo.RestorePromoCode", zap.String("orderId", orderID), zap.Error(err))
+	}
+}
+
 func (s *service) HandleIikoDeliveryOrderError(ctx context.Context, evt structs.IikoWebhookEvent) error {
 	if strings.ToUpper(strings.TrimSpace(evt.EventType)) != "DELIVERYORDERERROR" {
 		return nil
@@ -968,6 +1025,28 @@ func (s *service) HandleIikoDeliveryOrderError(ctx context.Context, evt structs.
 	}
 	return nil
 }
+
+// extractIikoCourier - deliveryOrder payload'idagi courierInfo.courier (id, name)
+func extractIikoCourier(raw json.RawMessage) (string, string) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", ""
+	}
+
+	var m struct {
+		CourierInfo *struct {
+			Courier *struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"courier"`
+		} `json:"courierInfo"`
+	}
+	if err := json.Unmarshal(raw, &m); err != nil || m.CourierInfo == nil || m.CourierInfo.Courier == nil {
+		return "", ""
+	}
+	return strings.TrimSpace(m.CourierInfo.Courier.ID), strings.TrimSpace(m.CourierInfo.Courier.Name)
+}
+
 func extractIikoOrderStatus(raw json.RawMessage) string {
 	raw = bytes.TrimSpace(raw)
 	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
@@ -1086,47 +1165,6 @@ func toLang(s string) (utils.Lang, bool) {
 	}
 }
 
-func addDeliveryFeeItem(items *[]structs.IikoOrderItem, deliveryType string, deliveryPrice int64) error {
-	dt := strings.ToUpper(strings.TrimSpace(deliveryType))
-	if dt != "DELIVERY" {
-		return nil
-	}
-	if deliveryPrice <= 0 {
-		return nil
-	}
-
-	var envKey string
-	switch deliveryPrice {
-	case 7000:
-		envKey = ""
-	case 25000:
-		envKey = "IIKO_DELIVERY_PRODUCT_ID_25000"
-	default:
-		return fmt.Errorf("unsupported deliveryPrice=%d (only 7000 or 25000)", deliveryPrice)
-	}
-
-	productID := strings.TrimSpace(os.Getenv(envKey))
-	if productID == "" {
-		return fmt.Errorf("%s env not set", envKey)
-	}
-
-	// duplicate bo'lib ketmasin
-	for i := range *items {
-		if strings.TrimSpace((*items)[i].ProductId) == productID {
-			(*items)[i].Amount = 1
-			(*items)[i].Type = "Product"
-			return nil
-		}
-	}
-
-	*items = append(*items, structs.IikoOrderItem{
-		Type:      "Product",
-		ProductId: productID,
-		Amount:    1,
-	})
-	return nil
-}
-
 func (s *service) DeliveryMapFound(ctx context.Context, req structs.MapFoundRequest) (int64, bool, error) {
 	ok, idx, err := s.zones.ContainsAnyWithIndex(req.Lat, req.Lng)
 	if err != nil {
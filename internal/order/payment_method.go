@@ -0,0 +1,171 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/structs"
+
+	"go.uber.org/zap"
+)
+
+// ChangePaymentMethod - WAITING_PAYMENT zakazda to'lov usulini almashtiradi yoki havolani qayta yaratadi
+// (o'sha usul tanlansa). Eski Click invoice / Payme / Uzum tranzaksiya bekor qilinadi,
+// CASH tanlansa zakaz WAITING_OPERATOR'ga o'tib operator guruhiga chiqadi.
+// Mijoz (bot/Mini App) yo'li: TgID majburiy va zakaz shu mijozniki bo'lishi shart.
+func (s *service) ChangePaymentMethod(ctx context.Context, req structs.ChangePaymentMethod) (structs.ChangePaymentMethodResponse, error) {
+	if req.OrderId == "" || req.TgID == 0 {
+		return structs.ChangePaymentMethodResponse{}, structs.ErrBadRequest
+	}
+	return s.changePaymentMethod(ctx, req, true)
+}
+
+// ChangePaymentMethodByOperator - panel (yopiq api): egasi tekshirilmaydi
+func (s *service) ChangePaymentMethodByOperator(ctx context.Context, orderID, paymentMethod string) (structs.ChangePaymentMethodResponse, error) {
+	if orderID == "" {
+		return structs.ChangePaymentMethodResponse{}, structs.ErrBadRequest
+	}
+	return s.changePaymentMethod(ctx, structs.ChangePaymentMethod{OrderId: orderID, PaymentMethod: paymentMethod}, false)
+}
+
+func (s *service) changePaymentMethod(ctx context.Context, req structs.ChangePaymentMethod, checkOwner bool) (structs.ChangePaymentMethodResponse, error) {
+	prov, err := s.payments.Get(req.PaymentMethod)
+	if err != nil {
+		return structs.ChangePaymentMethodResponse{}, err
+	}
+
+	ord, err := s.orderRepo.GetByID(ctx, req.OrderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return structs.ChangePaymentMethodResponse{}, structs.ErrNotFound
+		}
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.Error(err))
+		return structs.ChangePaymentMethodResponse{}, err
+	}
+	if checkOwner && ord.Order.TgID != req.TgID {
+		return structs.ChangePaymentMethodResponse{}, structs.ErrForbidden
+	}
+	if strings.ToUpper(ord.Order.Status) != structs.OrderStatusWaitingPayment ||
+		strings.ToUpper(ord.Order.PaymentStatus) == structs.PaymentStatusPaid {
+		return structs.ChangePaymentMethodResponse{}, structs.ErrOrderNotPayable
+	}
+	if !s.payments.IsEnabled(s.orderBranch(ord.Order), prov.Method()) {
+		return structs.ChangePaymentMethodResponse{}, structs.ErrPaymentMethodDisabled
+	}
+
+	// avval eski havolalar: bekor qilolmasak usul almashmaydi (eski tranzaksiya keyin zakazni PAID qilmasin)
+	if err := s.cancelPendingPayments(ctx, req.OrderId); err != nil {
+		return structs.ChangePaymentMethodResponse{}, err
+	}
+
+	// status sharti SQL ichida ham (parallel PAID bo'lib qolsa)
+	if err := s.orderRepo.ChangePaymentMethod(ctx, req.OrderId, prov.Method(), prov.Online()); err != nil {
+		if !errors.Is(err, structs.ErrOrderNotPayable) {
+			s.logger.Error(ctx, "->orderRepo.ChangePaymentMethod", zap.Error(err))
+		}
+		return structs.ChangePaymentMethodResponse{}, err
+	}
+
+	s.logger.Info(ctx, "order payment method changed",
+		zap.String("orderId", req.OrderId),
+		zap.String("from", ord.Order.PaymentMethod),
+		zap.String("to", prov.Method()),
+	)
+
+	resp := structs.ChangePaymentMethodResponse{
+		OrderId:       req.OrderId,
+		PaymentMethod: prov.Method(),
+		PaymentStatus: structs.PaymentStatusPending,
+		Status:        structs.OrderStatusWaitingPayment,
+	}
+
+	if !prov.Online() {
+		resp.PaymentStatus = structs.PaymentStatusUnpaid
+		resp.Status = structs.OrderStatusWaitingOperator
+		// operator guruhiga shu yerda chiqadi (SyncOrder)
+		s.notifyOrderStatusIfNeeded(ctx, req.OrderId, structs.OrderStatusWaitingOperator)
+		s.publishChanged(ctx, req.OrderId)
+		return resp, nil
+	}
+
+	intent, err := prov.CreatePayment(ctx, structs.PaymentOrder{
+		OrderID:       req.OrderId,
+		OrderNumber:   ord.Order.OrderNumber,
+		TgID:          ord.Order.TgID,
+		Phone:         ord.Phone,
+		Amount:        ord.Order.PayAmount,
+		PaymentStatus: structs.PaymentStatusPending,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "->CreatePayment", zap.String("orderId", req.OrderId), zap.String("pm", prov.Method()), zap.Error(err))
+		return resp, err
+	}
+	// TELEGRAM'da link yo'q - bot o'zi sendInvoice yuboradi
+	if intent.URL != "" {
+		if err := s.orderRepo.AddLink(ctx, intent.URL, req.OrderId); err != nil {
+			return resp, err
+		}
+		resp.PaymentUrl = intent.URL
+	}
+	s.publishChanged(ctx, req.OrderId)
+	return resp, nil
+}
+
+// cancelPendingPayments - to'lanmagan Click invoice / Payme / Uzum tranzaksiyalarni bekor qiladi,
+// eski havola orqali to'lab bo'lmasin. Birortasi yiqilsa xato qaytadi
+func (s *service) cancelPendingPayments(ctx context.Context, orderID string) error {
+	n, err := s.clickRepo.CancelByOrderID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "->clickRepo.CancelByOrderID", zap.String("orderId", orderID), zap.Error(err))
+		return err
+	}
+	if n > 0 {
+		s.logger.Info(ctx, "click invoices cancelled", zap.String("orderId", orderID), zap.Int64("count", n))
+	}
+	if s.paymeRepo != nil {
+		n, err := s.paymeRepo.CancelActiveByOrderID(ctx, orderID, time.Now().UnixMilli(), paymeReasonOrderCancelled)
+		if err != nil {
+			s.logger.Error(ctx, "->paymeRepo.CancelActiveByOrderID", zap.String("orderId", orderID), zap.Error(err))
+			return err
+		}
+		if n > 0 {
+			s.logger.Info(ctx, "payme transactions cancelled", zap.String("orderId", orderID), zap.Int64("count", n))
+		}
+	}
+	if s.uzumRepo != nil {
+		n, err := s.uzumRepo.ReverseCreatedByOrderID(ctx, orderID, time.Now().UnixMilli())
+		if err != nil {
+			s.logger.Error(ctx, "->uzumRepo.ReverseCreatedByOrderID", zap.String("orderId", orderID), zap.Error(err))
+			return err
+		}
+		if n > 0 {
+			s.logger.Info(ctx, "uzum transactions reversed", zap.String("orderId", orderID), zap.Int64("count", n))
+		}
+	}
+	return nil
+}
+
+// orderBranch - Create'dagidek: olib ketish yoki yetkazish zonasi bo'yicha filial
+func (s *service) orderBranch(o structs.Order) string {
+	if strings.ToUpper(o.DeliveryType) == structs.DeliveryTypePickup || o.Address == nil {
+		return provider.BranchPickup
+	}
+	ok, idx, err := s.zones.ContainsAnyWithIndex(o.Address.Lat, o.Address.Lng)
+	if err != nil || !ok {
+		return provider.BranchOlmaliq
+	}
+	return provider.BranchByZone(idx)
+}
+
+func (s *service) publishChanged(ctx context.Context, orderID string) {
+	if s.hub == nil {
+		return
+	}
+	if fresh, err := s.orderRepo.GetByID(ctx, orderID); err == nil {
+		s.publishUpsertToAdmins(mapOrdToDTO(fresh))
+	}
+}
@@ -0,0 +1,342 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/zap"
+)
+
+// RemoveItems - to'langan zakazdan operator qatorlarni olib tashlaydi. Qatorlar va PENDING
+// ledger yozuvi provider chaqirilishidan oldin bitta tranzaksiyada saqlanadi (parallel olib
+// tashlash ErrOrderChanged oladi). Farq provider orqali qaytariladi (Click - partial_reversal,
+// Payme - chekni bekor qilib qolgan summaga qayta yechish), qo'llamasa MANUAL bo'lib
+// kabinetdan qaytariladi. iiko'ga yangi to'lov summasi va izoh yuboriladi.
+func (s *service) RemoveItems(ctx context.Context, req structs.RemoveOrderItemsRequest) (structs.RemoveOrderItemsResponse, error) {
+	if req.OrderID == "" || len(req.Items) == 0 {
+		return structs.RemoveOrderItemsResponse{}, structs.ErrBadRequest
+	}
+
+	ord, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return structs.RemoveOrderItemsResponse{}, structs.ErrNotFound
+		}
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.Error(err))
+		return structs.RemoveOrderItemsResponse{}, err
+	}
+	if !refundable(ord.Order) {
+		return structs.RemoveOrderItemsResponse{}, structs.ErrNotRefundable
+	}
+	prov, err := s.payments.Get(ord.Order.PaymentMethod)
+	if err != nil {
+		return structs.RemoveOrderItemsResponse{}, err
+	}
+
+	products, removed, _, err := removeLines(ord.Order.Products, req.Items)
+	if err != nil {
+		return structs.RemoveOrderItemsResponse{}, err
+	}
+	// promo chegirmasi qolgan mahsulotlardan oshmaydi - qaytariladigan summa yangi jami bo'yicha
+	left := productsSum(products)
+	amount := ord.Order.TotalPrice - (left + ord.Order.DeliveryPrice - structs.AppliedDiscount(ord.Order.DiscountAmount, left))
+	if amount <= 0 {
+		return structs.RemoveOrderItemsResponse{}, structs.ErrNotRefundable
+	}
+
+	refund := structs.OrderRefund{
+		OrderID:       req.OrderID,
+		PaymentMethod: prov.Method(),
+		Amount:        amount,
+		TotalBefore:   ord.Order.TotalPrice,
+		TotalAfter:    ord.Order.TotalPrice - amount,
+		Items:         removed,
+		Reason:        strings.TrimSpace(req.Reason),
+		CreatedBy:     req.EmployeeID,
+	}
+
+	// pul qaytarilishidan oldin: provider muvaffaqiyatli bo'lib keyingi qadam yiqilsa ham ledgerda iz qoladi
+	refund, err = s.refundRepo.CreatePending(ctx, refund, ord.Order.Products, products)
+	if err != nil {
+		if !errors.Is(err, structs.ErrOrderChanged) && !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->refundRepo.CreatePending", zap.String("orderId", req.OrderID), zap.Error(err))
+		}
+		return structs.RemoveOrderItemsResponse{}, err
+	}
+
+	refunded, err := s.refundRepo.SumOtherRefunds(ctx, req.OrderID, refund.ID)
+	if err != nil {
+		s.logger.Error(ctx, "->refundRepo.SumOtherRefunds", zap.String("orderId", req.OrderID), zap.Error(err))
+	}
+
+	status, note := structs.RefundStatusDone, ""
+	if err == nil {
+		err = prov.Refund(ctx, structs.PaymentOrder{
+			OrderID:       req.OrderID,
+			OrderNumber:   ord.Order.OrderNumber,
+			TgID:          ord.Order.TgID,
+			Phone:         ord.Phone,
+			Amount:        ord.Order.PayAmount,
+			PaymentStatus: ord.Order.PaymentStatus,
+			Refunded:      refunded,
+		}, amount)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, structs.ErrRefundNotSupported):
+		status = structs.RefundStatusManual
+	default:
+		s.logger.Error(ctx, "->Refund", zap.String("orderId", req.OrderID), zap.String("pm", prov.Method()), zap.Error(err))
+		status = structs.RefundStatusFailed
+		note = err.Error()
+	}
+
+	totalAfter := refund.TotalAfter
+	fresh, freshErr := s.orderRepo.GetByID(ctx, req.OrderID)
+	if freshErr != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.Error(freshErr))
+	} else {
+		totalAfter = fresh.Order.TotalPrice
+		if fresh.Order.IIKOOrderID != "" {
+			if err := s.resendIikoTotals(ctx, fresh, removed); err != nil {
+				s.logger.Error(ctx, "->resendIikoTotals", zap.String("orderId", req.OrderID), zap.Error(err))
+				note = strings.TrimSpace(note + "\niiko: " + err.Error())
+			}
+		}
+	}
+
+	saved, err := s.refundRepo.Finish(ctx, refund.ID, status, note, totalAfter)
+	if err != nil {
+		s.logger.Error(ctx, "->refundRepo.Finish", zap.String("orderId", req.OrderID), zap.Int64("refundId", refund.ID), zap.Error(err))
+		return structs.RemoveOrderItemsResponse{}, err
+	}
+	if freshErr != nil {
+		return structs.RemoveOrderItemsResponse{}, freshErr
+	}
+
+	s.logger.Info(ctx, "order items removed",
+		zap.String("orderId", req.OrderID),
+		zap.Int64("amount", amount),
+		zap.String("status", saved.Status),
+		zap.Int("employeeId", req.EmployeeID),
+	)
+
+	if s.hub != nil {
+		s.hub.BroadcastToAdmins(structs.Event{
+			Type: structs.EventOrderRefund,
+			TS:   time.Now(),
+			Payload: structs.OrderRefundPayload{
+				OrderNumber: fresh.Order.OrderNumber,
+				Refund:      saved,
+			},
+		})
+		s.publishUpsertToAdmins(mapOrdToDTO(fresh))
+	}
+	s.operatorSvc.SyncOrder(ctx, req.OrderID)
+	s.notifyItemsRemoved(ctx, fresh.Order, saved)
+
+	return structs.RemoveOrderItemsResponse{Refund: saved, Order: fresh.Order}, nil
+}
+
+func (s *service) GetRefunds(ctx context.Context, orderID string) ([]structs.OrderRefund, error) {
+	list, err := s.refundRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "->refundRepo.GetByOrderID", zap.Error(err))
+		return nil, err
+	}
+	return list, nil
+}
+
+// MarkRefundDone - MANUAL/FAILED qaytarish kabinetdan qilingandan keyin operator belgilaydi
+func (s *service) MarkRefundDone(ctx context.Context, req structs.MarkRefundDoneRequest) (structs.OrderRefund, error) {
+	refund, err := s.refundRepo.MarkDone(ctx, req.OrderID, req.RefundID, strings.TrimSpace(req.Note))
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->refundRepo.MarkDone", zap.Error(err))
+		}
+		return structs.OrderRefund{}, err
+	}
+	if s.hub != nil {
+		s.hub.BroadcastToAdmins(structs.Event{
+			Type:    structs.EventOrderRefund,
+			TS:      time.Now(),
+			Payload: structs.OrderRefundPayload{Refund: refund},
+		})
+	}
+	return refund, nil
+}
+
+// refundable - online to'langan va hali yopilmagan zakaz
+func refundable(o structs.Order) bool {
+	if strings.ToUpper(o.PaymentStatus) != structs.PaymentStatusPaid ||
+		strings.ToUpper(o.PaymentMethod) == structs.PaymentMethodCash {
+		return false
+	}
+	switch strings.ToUpper(o.Status) {
+	case structs.OrderStatusDelivered, structs.OrderStatusCompleted,
+		structs.OrderStatusCancelled, structs.OrderStatusRejected:
+		return false
+	}
+	return true
+}
+
+// removeLines - qolgan qatorlar, olib tashlanganlar va qaytariladigan summa (mahsulot + box).
+// Hamma qator olib tashlansa - bu bekor qilish, ErrBadRequest.
+func productsSum(products []structs.OrderProduct) int64 {
+	var sum int64
+	for _, p := range products {
+		sum += (p.ProductPrice + p.BoxPrice) * p.Quantity
+	}
+	return sum
+}
+
+func removeLines(products []structs.OrderProduct, items []structs.RefundItem) ([]structs.OrderProduct, []structs.RefundItem, int64, error) {
+	dec := make(map[string]int64, len(items))
+	for _, it := range items {
+		pid := strings.TrimSpace(it.ProductID)
+		if pid == "" || it.Quantity < 0 {
+			return nil, nil, 0, structs.ErrBadRequest
+		}
+		if _, ok := dec[pid]; ok && (it.Quantity == 0 || dec[pid] == 0) {
+			return nil, nil, 0, structs.ErrBadRequest
+		}
+		dec[pid] += it.Quantity
+	}
+
+	var (
+		left    = make([]structs.OrderProduct, 0, len(products))
+		removed = make([]structs.RefundItem, 0, len(dec))
+		amount  int64
+	)
+	for _, p := range products {
+		qty, ok := dec[p.ID]
+		if !ok {
+			left = append(left, p)
+			continue
+		}
+		delete(dec, p.ID)
+		if qty == 0 {
+			qty = p.Quantity
+		}
+		if qty > p.Quantity {
+			return nil, nil, 0, structs.ErrBadRequest
+		}
+
+		price := p.ProductPrice + p.BoxPrice
+		removed = append(removed, structs.RefundItem{
+			ProductID: p.ID,
+			Quantity:  qty,
+			Name:      p.ProductName,
+			Price:     price,
+		})
+		amount += price * qty
+
+		if qty < p.Quantity {
+			p.Quantity -= qty
+			left = append(left, p)
+		}
+	}
+	// zakazda yo'q mahsulot
+	if len(dec) > 0 || len(left) == 0 || amount <= 0 {
+		return nil, nil, 0, structs.ErrBadRequest
+	}
+	return left, removed, amount, nil
+}
+
+// resendIikoTotals - iiko Cloud'da qatorni o'chirish API yo'q: to'lov summasi
+// yangilanadi, olib tashlanganlar izohga yoziladi (oshxona ko'radi)
+func (s *service) resendIikoTotals(ctx context.Context, ord structs.GetListPrimaryKeyResponse, removed []structs.RefundItem) error {
+	organizationID := strings.TrimSpace(os.Getenv("IIKO_ORGANIZATION_ID"))
+	method := strings.ToUpper(strings.TrimSpace(ord.Order.PaymentMethod))
+	paymentTypeID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_" + method + "_ID"))
+	if organizationID == "" || paymentTypeID == "" {
+		return fmt.Errorf("IIKO_ORGANIZATION_ID/IIKO_PAYMENT_%s_ID empty", method)
+	}
+
+	if err := s.iikoSvc.ChangeDeliveryPayments(ctx, structs.IikoChangePaymentsRequest{
+		OrganizationId: organizationID,
+		OrderId:        ord.Order.IIKOOrderID,
+		Payments: []structs.IikoPayment{
+			{
+				PaymentTypeId:         paymentTypeID,
+				PaymentTypeKind:       "Card",
+				Sum:                   float64(ord.Order.OrderPriceForIIKO),
+				IsProcessedExternally: true,
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	parts := make([]string, 0, len(removed))
+	for _, it := range removed {
+		parts = append(parts, fmt.Sprintf("%s ×%d", it.Name.Ru, it.Quantity))
+	}
+	comment := "❗ Убрано: " + strings.Join(parts, ", ")
+	if c := strings.TrimSpace(ord.Order.Comment); c != "" {
+		comment = c + "\n" + comment
+	}
+	return s.iikoSvc.ChangeDeliveryComment(ctx, structs.IikoChangeCommentRequest{
+		OrganizationId: organizationID,
+		OrderId:        ord.Order.IIKOOrderID,
+		Comment:        comment,
+	})
+}
+
+func (s *service) notifyItemsRemoved(ctx context.Context, o structs.Order, refund structs.OrderRefund) {
+	if s.bot == nil || o.TgID == 0 {
+		return
+	}
+	lang := utils.UZ
+	if l, err := s.clientRepo.GetLanguageByTgID(ctx, o.TgID); err == nil {
+		if ll, ok := toLang(l); ok {
+			lang = ll
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, texts.Get(lang, texts.OrderItemsRemoved), o.OrderNumber)
+	b.WriteString("\n")
+	for _, it := range refund.Items {
+		fmt.Fprintf(&b, "• %s × %d\n", refundItemName(lang, it.Name), it.Quantity)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, texts.Get(lang, texts.OrderRefundAmount), formatSum(refund.Amount))
+	if refund.Status != structs.RefundStatusDone {
+		b.WriteString("\n" + texts.Get(lang, texts.OrderRefundManual))
+	}
+
+	if _, err := s.bot.Send(tgbotapi.NewMessage(o.TgID, b.String())); err != nil {
+		s.logger.Warn(ctx, "Telegram refund notify failed", zap.Int64("tg_id", o.TgID), zap.Error(err))
+	}
+}
+
+func refundItemName(lang utils.Lang, name structs.Name) string {
+	switch lang {
+	case utils.RU:
+		return name.Ru
+	case utils.EN:
+		return name.En
+	default:
+		return name.Uz
+	}
+}
+
+func formatSum(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package order
+
+import (
+	"context"
+	"strings"
+
+	"sushitana/internal/structs"
+
+	"go.uber.org/zap"
+)
+
+// Telegram native payments: UZS uchun exp=2, ya'ni summa tiyinda keladi.
+const TelegramCurrency = "UZS"
+
+// TelegramAmount - so'mni Telegram invoice birligiga (tiyin) o'tkazadi
+func TelegramAmount(som int64) int64 {
+	return som * 100
+}
+
+// CheckTelegramPreCheckout - pre_checkout_query'da zakaz hali ochiq va summa o'zgarmaganini tekshiradi.
+func (s *service) CheckTelegramPreCheckout(ctx context.Context, req structs.TelegramPayment) error {
+	ord, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", req.OrderID), zap.Error(err))
+		return structs.ErrNotFound
+	}
+
+	if ord.Order.TgID != req.TgID {
+		return structs.ErrForbidden
+	}
+	if strings.ToUpper(ord.Order.PaymentMethod) != structs.PaymentMethodTelegram ||
+		strings.ToUpper(ord.Order.Status) != structs.OrderStatusWaitingPayment ||
+		strings.ToUpper(ord.Order.PaymentStatus) == "PAID" {
+		return structs.ErrOrderNotPayable
+	}
+	if !strings.EqualFold(req.Currency, TelegramCurrency) || req.TotalAmount != TelegramAmount(ord.Order.PayAmount) {
+		s.logger.Warn(ctx, "telegram pre-checkout amount mismatch",
+			zap.String("orderId", req.OrderID),
+			zap.Int64("got", req.TotalAmount),
+			zap.Int64("want", TelegramAmount(ord.Order.PayAmount)),
+			zap.String("currency", req.Currency),
+		)
+		return structs.ErrAmountMismatch
+	}
+	return nil
+}
+
+// ConfirmTelegramPayment - successful_payment: PAID -> COOKING -> iiko (click/payme bilan bir xil oqim).
+// Charge yozuvi va PAID bitta tranzaksiyada; qayta kelgan update zakaz hali WAITING_PAYMENT bo'lsa oqimni davom ettiradi.
+func (s *service) ConfirmTelegramPayment(ctx context.Context, req structs.TelegramPayment) error {
+	created, err := s.tgPayRepo.CreatePaid(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->tgPayRepo.CreatePaid", zap.String("orderId", req.OrderID), zap.Error(err))
+		return err
+	}
+
+	ord, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", req.OrderID), zap.Error(err))
+		return err
+	}
+
+	if !created && strings.ToUpper(ord.Order.Status) != structs.OrderStatusWaitingPayment {
+		// shu charge allaqachon to'liq qayta ishlangan
+		s.logger.Info(ctx, "telegram payment already processed",
+			zap.String("orderId", req.OrderID),
+			zap.String("chargeId", req.TelegramPaymentChargeID),
+		)
+		return nil
+	}
+
+	// pre-checkout'dan keyin zakaz bekor bo'lib qolgan bo'lsa pul olingan, lekin oshxonaga yubormaymiz
+	if strings.ToUpper(ord.Order.Status) != structs.OrderStatusWaitingPayment {
+		s.logger.Error(ctx, "telegram payment for not-waiting order, needs manual refund",
+			zap.String("orderId", req.OrderID),
+			zap.String("status", ord.Order.Status),
+			zap.String("chargeId", req.TelegramPaymentChargeID),
+		)
+		if s.hub != nil {
+			if fresh, err := s.orderRepo.GetByID(ctx, req.OrderID); err == nil {
+				s.publishUpsertToAdmins(mapOrdToDTO(fresh))
+			}
+		}
+		return structs.ErrOrderNotPayable
+	}
+
+	// yiqilsa zakaz WAITING_PAYMENT'da qoladi va qayta ishlashda shu yerdan davom etadi
+	if err := s.orderRepo.UpdateStatus(ctx, structs.UpdateStatus{OrderId: req.OrderID, Status: structs.OrderStatusCooking}); err != nil {
+		s.logger.Error(ctx, "->orderRepo.UpdateStatus", zap.String("orderId", req.OrderID), zap.Error(err))
+		return err
+	}
+
+	if err := s.sendToIikoIfAllowed(ctx, req.OrderID); err != nil {
+		s.logger.Error(ctx, "sendToIikoIfAllowed failed", zap.String("orderId", req.OrderID), zap.Error(err))
+	}
+
+	s.notifyOrderStatusIfNeeded(ctx, req.OrderID, structs.OrderStatusCooking)
+
+	if s.hub != nil {
+		if fresh, err := s.orderRepo.GetByID(ctx, req.OrderID); err == nil {
+			s.publishUpsertToAdmins(mapOrdToDTO(fresh))
+		}
+	}
+	return nil
+}
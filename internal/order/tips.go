@@ -0,0 +1,19 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"sushitana/internal/structs"
+
+	"go.uber.org/zap"
+)
+
+func (s *service) TipSummary(ctx context.Context, from, to *time.Time) (structs.TipSummary, error) {
+	summary, err := s.orderRepo.TipSummary(ctx, from, to)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.TipSummary", zap.Error(err))
+		return structs.TipSummary{}, err
+	}
+	return summary, nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 	"sushitana/internal/iiko"
+	"sushitana/internal/operator"
 	"sushitana/internal/structs"
 	"sushitana/internal/texts"
 	rtws "sushitana/internal/ws"
@@ -14,6 +15,7 @@ import (
 	orderrepo "sushitana/pkg/repository/postgres/order_repo"
 	"sushitana/pkg/utils"
 
+	"github.com/dustin/go-humanize"
 	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -34,6 +36,8 @@ type Params struct {
 	Bot        *tgbotapi.BotAPI `optional:"true"`
 	Hub        *rtws.Hub        `optional:"true"`
 	IikoSvc    iiko.Service
+
+	OperatorSvc operator.Service
 }
 
 type service struct {
@@ -43,6 +47,8 @@ type service struct {
 	iikoSvc    iiko.Service
 	bot        *tgbotapi.BotAPI `optional:"true"`
 	hub        *rtws.Hub        `optional:"true"`
+
+	operatorSvc operator.Service
 }
 
 func New(p Params) Service {
@@ -53,6 +59,8 @@ func New(p Params) Service {
 		iikoSvc:    p.IikoSvc,
 		hub:        p.Hub,
 		bot:        p.Bot,
+
+		operatorSvc: p.OperatorSvc,
 	}
 }
 
@@ -78,8 +86,8 @@ func (s *service) SendToIikoIfAllowed(ctx context.Context, orderID string) error
 
 	// 2) to'lov sharti:
 	// CASH bo'lsa ruxsat
-	// CLICK/PAYME bo'lsa faqat PAID bo'lsa ruxsat
-	if paymentMethod == "CLICK" || paymentMethod == "PAYME" {
+	// qolgan (online) usullarda faqat PAID bo'lsa ruxsat
+	if paymentMethod != structs.PaymentMethodCash {
 		if paymentStatus != "PAID" {
 			s.logger.Info(ctx, "iiko: online payment not PAID, skip",
 				zap.String("order_id", orderID),
@@ -91,9 +99,9 @@ func (s *service) SendToIikoIfAllowed(ctx context.Context, orderID string) error
 	}
 
 	// 3) build request
-	iikoReq, err := buildCreateOrderForIiko(ord)
+	iikoReq, err := BuildIikoDeliveryRequest(ord)
 	if err != nil {
-		s.logger.Error(ctx, "buildCreateOrderForIiko failed",
+		s.logger.Error(ctx, "BuildIikoDeliveryRequest failed",
 			zap.String("order_id", orderID),
 			zap.String("delivery_type", deliveryType),
 			zap.Error(err),
@@ -128,16 +136,13 @@ func (s *service) SendToIikoIfAllowed(ctx context.Context, orderID string) error
 	return nil
 }
 
-func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.IikoCreateDeliveryRequest, error) {
+// BuildIikoDeliveryRequest - zakazdan iiko deliveries/create so'rovi; order va orderflow shu bitta funksiyadan foydalanadi
+func BuildIikoDeliveryRequest(ord structs.GetListPrimaryKeyResponse) (structs.IikoCreateDeliveryRequest, error) {
 	organizationID := strings.TrimSpace(os.Getenv("IIKO_ORGANIZATION_ID"))
 	terminalGroupID := strings.TrimSpace(os.Getenv("IIKO_TERMINAL_GROUP_ID"))
 	deliveryOrderTypeID := strings.TrimSpace(os.Getenv("IIKO_DELIVERY_ORDER_TYPE_ID"))
 	pickupOrderTypeID := strings.TrimSpace(os.Getenv("IIKO_PICKUP_ORDER_TYPE_ID"))
 
-	paymentCashID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_CASH_ID"))
-	paymentClickID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_CLICK_ID"))
-	paymentPaymeID := strings.TrimSpace(os.Getenv("IIKO_PAYMENT_PAYME_ID"))
-
 	if organizationID == "" || terminalGroupID == "" {
 		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("IIKO_ORGANIZATION_ID/IIKO_TERMINAL_GROUP_ID empty")
 	}
@@ -166,21 +171,16 @@ func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.Iik
 		processedExternally bool
 	)
 
-	switch paymentMethod {
-	case "CASH":
+	// IIKO_PAYMENT_<METHOD>_ID - yangi provider uchun faqat env qo'shiladi
+	if paymentMethod == "" {
+		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("unknown PaymentMethod=%s", ord.Order.PaymentMethod)
+	}
+	paymentTypeID = strings.TrimSpace(os.Getenv("IIKO_PAYMENT_" + paymentMethod + "_ID"))
+	if paymentMethod == structs.PaymentMethodCash {
 		paymentKind = "Cash"
-		paymentTypeID = paymentCashID
-	case "CLICK":
-		paymentKind = "Card"       // ✅ MUHIM
-		processedExternally = true // qolsa ham bo‘ladi
-		paymentTypeID = paymentClickID
-
-	case "PAYME":
-		paymentKind = "Card" // ✅ MUHIM
+	} else {
+		paymentKind = "Card" // ✅ MUHIM: online to'lovlar
 		processedExternally = true
-		paymentTypeID = paymentPaymeID
-	default:
-		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("unknown PaymentMethod=%s", ord.Order.PaymentMethod)
 	}
 	if strings.TrimSpace(paymentTypeID) == "" {
 		return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("paymentTypeId empty for PaymentMethod=%s", ord.Order.PaymentMethod)
@@ -232,6 +232,28 @@ func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.Iik
 
 	comment := strings.TrimSpace(ord.Order.Comment)
 
+	// naqd: iiko "Cash" to'lovida ortiqcha summa qaytim sifatida hisoblanadi; kuryer uchun izohda ham
+	if paymentKind == "Cash" && float64(ord.Order.ChangeFrom) > sum {
+		sum = float64(ord.Order.ChangeFrom)
+		comment = joinIikoComment(comment, "Сдача с "+humanize.Comma(ord.Order.ChangeFrom))
+	}
+	// choy puli online zakaz bilan to'langan, iiko summasiga kirmaydi - kuryer uchun faqat izoh
+	if ord.Order.TipAmount > 0 {
+		comment = joinIikoComment(comment, "Чаевые курьеру: "+humanize.Comma(ord.Order.TipAmount)+" (оплачено онлайн)")
+	}
+	// promo chegirmasi to'lov summasidan (orderPriceForIIKO) allaqachon ayrilgan
+	var discounts *structs.IikoDiscountsInfo
+	if ord.Order.DiscountAmount > 0 {
+		comment = joinIikoComment(comment, "Скидка по промокоду "+ord.Order.PromoCode+": "+humanize.Comma(ord.Order.DiscountAmount))
+		if typeID := strings.TrimSpace(os.Getenv("IIKO_PROMO_DISCOUNT_TYPE_ID")); typeID != "" {
+			discounts = &structs.IikoDiscountsInfo{Discounts: []structs.IikoDiscount{{
+				DiscountTypeId: typeID,
+				Sum:            float64(ord.Order.DiscountAmount),
+				Type:           "RMS",
+			}}}
+		}
+	}
+
 	iikoOrder := structs.IikoOrder{
 		Phone:          phone,
 		ExternalNumber: fmt.Sprintf("%d", ord.Order.OrderNumber),
@@ -246,6 +268,7 @@ func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.Iik
 				IsProcessedExternally: processedExternally,
 			},
 		},
+		DiscountsInfo: discounts,
 	}
 
 	// 6) DELIVERY requires deliveryPoint with coordinates
@@ -262,13 +285,8 @@ func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.Iik
 			return structs.IikoCreateDeliveryRequest{}, fmt.Errorf("iiko delivery requires coordinates (lat/lng), got lat=%v lng=%v", lat, lng)
 		}
 
-		house := strings.TrimSpace(a.House)
-		if house == "" {
-			house = "1"
-		}
-
 		streetName := strings.TrimSpace(a.Street)
-		if streetName == "" {
+		if streetName == "" && strings.TrimSpace(a.Name) != "geo" {
 			streetName = strings.TrimSpace(a.Name)
 		}
 
@@ -283,9 +301,13 @@ func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.Iik
 				Longitude: lng,
 			},
 			Address: &structs.IikoAddress{
-				Street:  street,
-				House:   house,
-				Comment: strings.TrimSpace(a.Name),
+				Street:    street,
+				House:     strings.TrimSpace(a.House),
+				Flat:      strings.TrimSpace(a.Flat),
+				Entrance:  strings.TrimSpace(a.Entrance),
+				Floor:     strings.TrimSpace(a.Floor),
+				Doorphone: strings.TrimSpace(a.Doorphone),
+				Comment:   iikoAddressComment(*a),
 			},
 		}
 	}
@@ -302,6 +324,25 @@ func buildCreateOrderForIiko(ord structs.GetListPrimaryKeyResponse) (structs.Iik
 	}, nil
 }
 
+func joinIikoComment(comment, line string) string {
+	if comment == "" {
+		return line
+	}
+	return comment + "\n" + line
+}
+
+// iikoAddressComment - mijoz yozgan manzil matni + kuryer uchun izoh ("geo" placeholder'siz)
+func iikoAddressComment(a structs.Address) string {
+	var parts []string
+	if name := strings.TrimSpace(a.Name); name != "" && name != "geo" {
+		parts = append(parts, name)
+	}
+	if c := strings.TrimSpace(a.Comment); c != "" {
+		parts = append(parts, c)
+	}
+	return strings.Join(parts, "\n")
+}
+
 func addDeliveryFeeItem(items *[]structs.IikoOrderItem, deliveryType string, deliveryPrice int64) error {
 	dt := strings.ToUpper(strings.TrimSpace(deliveryType))
 	if dt != "DELIVERY" {
@@ -344,6 +385,9 @@ func addDeliveryFeeItem(items *[]structs.IikoOrderItem, deliveryType string, del
 }
 
 func (s *service) NotifyOrderStatusIfNeeded(ctx context.Context, orderID string, newStatus string) {
+	// to'langan zakaz operator guruhiga shu yerda chiqadi / post yangilanadi
+	s.operatorSvc.SyncOrder(ctx, orderID)
+
 	if s.bot == nil {
 		return
 	}
@@ -0,0 +1,231 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sushitana/internal/payment/payme/subscribe"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	cardrepo "sushitana/pkg/repository/postgres/card_repo"
+
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+// bitta mijozda ko'pi bilan shuncha tasdiqlangan karta
+const maxCards = 5
+
+type (
+	Params struct {
+		fx.In
+		Logger    logger.Logger
+		CardRepo  cardrepo.Repo
+		Subscribe subscribe.Client
+	}
+
+	Service interface {
+		Enabled() bool
+
+		// Add - front-end cards.create bergan token + SMS-kod; karta Verify'gacha ro'yxatda ko'rinmaydi
+		Add(ctx context.Context, req structs.AddCardRequest) (structs.AddCardResponse, error)
+		ResendCode(ctx context.Context, tgID, id int64) (structs.AddCardResponse, error)
+		Verify(ctx context.Context, req structs.VerifyCardRequest) (structs.ClientCard, error)
+		List(ctx context.Context, tgID int64) ([]structs.ClientCard, error)
+		Remove(ctx context.Context, tgID, id int64) error
+
+		// Pay - receipts.create + receipts.pay. Payme to'lovni Merchant API orqali
+		// (Check/Create/PerformTransaction) odatdagidek tasdiqlaydi, PAID yo'li o'sha yerda.
+		// Qaytgan chek ID'si = Merchant API tranzaksiya ID'si
+		Pay(ctx context.Context, order structs.PaymentOrder) (structs.PaymeReceipt, error)
+		// Charge - mijozning cardID kartasidan amount (so'm) yechish; o'tmasa chek bekor qilinadi
+		Charge(ctx context.Context, tgID, cardID, amount int64, orderRef string) (structs.PaymeReceipt, error)
+		// CancelReceipt - receipts.cancel: to'langan chek bo'lsa pul kartaga qaytadi
+		CancelReceipt(ctx context.Context, receiptID string) error
+	}
+
+	service struct {
+		logger    logger.Logger
+		cardRepo  cardrepo.Repo
+		subscribe subscribe.Client
+	}
+)
+
+func New(p Params) Service {
+	return &service{
+		logger:    p.Logger,
+		cardRepo:  p.CardRepo,
+		subscribe: p.Subscribe,
+	}
+}
+
+func (s *service) Enabled() bool {
+	return s.subscribe.Configured()
+}
+
+func digits(v string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, v)
+}
+
+func (s *service) Add(ctx context.Context, req structs.AddCardRequest) (structs.AddCardResponse, error) {
+	token := strings.TrimSpace(req.Token)
+	if req.TgID == 0 || token == "" {
+		return structs.AddCardResponse{}, structs.ErrBadRequest
+	}
+
+	n, err := s.cardRepo.CountVerified(ctx, req.TgID)
+	if err != nil {
+		return structs.AddCardResponse{}, err
+	}
+	if n >= maxCards {
+		return structs.AddCardResponse{}, structs.ErrCardLimit
+	}
+
+	// oldingi tasdiqlanmagan urinishlar
+	if tokens, err := s.cardRepo.DeleteUnverified(ctx, req.TgID); err == nil {
+		for _, t := range tokens {
+			_ = s.subscribe.CardsRemove(ctx, t)
+		}
+	}
+
+	pc, err := s.subscribe.CardsCheck(ctx, token)
+	if err != nil {
+		return structs.AddCardResponse{}, err
+	}
+	if pc.Token == "" {
+		pc.Token = token
+	}
+	card, err := s.cardRepo.Create(ctx, req.TgID, pc)
+	if err != nil {
+		s.logger.Error(ctx, "->cardRepo.Create", zap.Error(err))
+		return structs.AddCardResponse{}, err
+	}
+	return s.sendCode(ctx, card)
+}
+
+func (s *service) ResendCode(ctx context.Context, tgID, id int64) (structs.AddCardResponse, error) {
+	card, err := s.cardRepo.GetByID(ctx, tgID, id)
+	if err != nil {
+		return structs.AddCardResponse{}, err
+	}
+	if card.Verified {
+		return structs.AddCardResponse{Card: card}, nil
+	}
+	return s.sendCode(ctx, card)
+}
+
+func (s *service) sendCode(ctx context.Context, card structs.ClientCard) (structs.AddCardResponse, error) {
+	code, err := s.subscribe.CardsGetVerifyCode(ctx, card.Token)
+	if err != nil {
+		return structs.AddCardResponse{}, err
+	}
+	return structs.AddCardResponse{Card: card, Phone: code.Phone, Wait: code.Wait}, nil
+}
+
+func (s *service) Verify(ctx context.Context, req structs.VerifyCardRequest) (structs.ClientCard, error) {
+	code := digits(req.Code)
+	if code == "" {
+		return structs.ClientCard{}, structs.ErrBadRequest
+	}
+	card, err := s.cardRepo.GetByID(ctx, req.TgID, req.ID)
+	if err != nil {
+		return structs.ClientCard{}, err
+	}
+
+	pc, err := s.subscribe.CardsVerify(ctx, card.Token, code)
+	if err != nil {
+		return structs.ClientCard{}, err
+	}
+	if !pc.Verify || !pc.Recurrent {
+		// recurrent=false kartadan qayta yechib bo'lmaydi (masalan, korporativ)
+		_ = s.subscribe.CardsRemove(ctx, card.Token)
+		_ = s.cardRepo.Delete(ctx, req.TgID, req.ID)
+		return structs.ClientCard{}, structs.ErrBadRequest
+	}
+	if pc.Token == "" {
+		pc.Token = card.Token
+	}
+
+	verified, err := s.cardRepo.MarkVerified(ctx, req.TgID, req.ID, pc)
+	if err != nil {
+		s.logger.Error(ctx, "->cardRepo.MarkVerified", zap.Error(err))
+		return structs.ClientCard{}, err
+	}
+	return verified, nil
+}
+
+func (s *service) List(ctx context.Context, tgID int64) ([]structs.ClientCard, error) {
+	list, err := s.cardRepo.GetListByTgID(ctx, tgID)
+	if err != nil {
+		s.logger.Error(ctx, "->cardRepo.GetListByTgID", zap.Error(err))
+		return list, err
+	}
+	return list, nil
+}
+
+func (s *service) Remove(ctx context.Context, tgID, id int64) error {
+	card, err := s.cardRepo.GetByID(ctx, tgID, id)
+	if err != nil {
+		return err
+	}
+	// Payme'dagi tokenni o'chira olmasak ham bizda saqlanmaydi
+	if err := s.subscribe.CardsRemove(ctx, card.Token); err != nil {
+		s.logger.Warn(ctx, "payme cards.remove failed", zap.Int64("cardId", id), zap.Error(err))
+	}
+	return s.cardRepo.Delete(ctx, tgID, id)
+}
+
+func (s *service) Pay(ctx context.Context, order structs.PaymentOrder) (structs.PaymeReceipt, error) {
+	return s.Charge(ctx, order.TgID, order.CardID, order.Amount, cast.ToString(order.OrderNumber))
+}
+
+func (s *service) Charge(ctx context.Context, tgID, cardID, amount int64, orderRef string) (structs.PaymeReceipt, error) {
+	card, err := s.cardRepo.GetByID(ctx, tgID, cardID)
+	if err != nil {
+		return structs.PaymeReceipt{}, err
+	}
+	if !card.Verified {
+		return structs.PaymeReceipt{}, structs.ErrBadRequest
+	}
+
+	receipt, err := s.subscribe.ReceiptsCreate(ctx, amount*100, orderRef)
+	if err != nil {
+		return structs.PaymeReceipt{}, fmt.Errorf("payme receipts.create failed: %w", err)
+	}
+
+	paid, err := s.subscribe.ReceiptsPay(ctx, receipt.ID, card.Token)
+	if err != nil {
+		// pul yechilmadi - chekni bekor qilamiz
+		if cerr := s.subscribe.ReceiptsCancel(ctx, receipt.ID); cerr != nil {
+			s.logger.Warn(ctx, "payme receipts.cancel failed", zap.String("receipt", receipt.ID), zap.Error(cerr))
+		}
+		var se *structs.PaymeSubscribeError
+		if errors.As(err, &se) {
+			s.logger.Warn(ctx, "saved card payment declined", zap.String("orderRef", orderRef), zap.Int("code", se.Code))
+		}
+		return structs.PaymeReceipt{}, fmt.Errorf("payme receipts.pay failed: %w", err)
+	}
+
+	_ = s.cardRepo.Touch(ctx, card.ID)
+	if paid.ID == "" {
+		paid.ID = receipt.ID
+	}
+	return paid, nil
+}
+
+func (s *service) CancelReceipt(ctx context.Context, receiptID string) error {
+	if err := s.subscribe.ReceiptsCancel(ctx, receiptID); err != nil {
+		return fmt.Errorf("payme receipts.cancel failed: %w", err)
+	}
+	return nil
+}
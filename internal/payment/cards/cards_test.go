@@ -0,0 +1,297 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"sushitana/internal/payment/payme/subscribe"
+	"sushitana/internal/payment/payme/subscribe/subscribetest"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+)
+
+// memRepo - cardrepo.Repo'ning xotiradagi nusxasi
+type memRepo struct {
+	mu    sync.Mutex
+	seq   int64
+	cards map[int64]structs.ClientCard
+}
+
+func newMemRepo() *memRepo { return &memRepo{cards: map[int64]structs.ClientCard{}} }
+
+func (r *memRepo) Create(_ context.Context, tgID int64, card structs.PaymeCard) (structs.ClientCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	c := structs.ClientCard{ID: r.seq, TgID: tgID, Number: card.Number, Expire: card.Expire, Token: card.Token, CreatedAt: time.Now()}
+	r.cards[c.ID] = c
+	return c, nil
+}
+
+func (r *memRepo) MarkVerified(_ context.Context, tgID, id int64, card structs.PaymeCard) (structs.ClientCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cards[id]
+	if !ok || c.TgID != tgID {
+		return structs.ClientCard{}, structs.ErrNotFound
+	}
+	c.Verified, c.Token, c.Number = true, card.Token, card.Number
+	r.cards[id] = c
+	return c, nil
+}
+
+func (r *memRepo) GetByID(_ context.Context, tgID, id int64) (structs.ClientCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cards[id]
+	if !ok || c.TgID != tgID {
+		return structs.ClientCard{}, structs.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *memRepo) GetListByTgID(_ context.Context, tgID int64) ([]structs.ClientCard, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []structs.ClientCard
+	for _, c := range r.cards {
+		if c.TgID == tgID && c.Verified {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (r *memRepo) CountVerified(ctx context.Context, tgID int64) (int64, error) {
+	list, _ := r.GetListByTgID(ctx, tgID)
+	return int64(len(list)), nil
+}
+
+func (r *memRepo) Delete(_ context.Context, tgID, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c, ok := r.cards[id]; ok && c.TgID == tgID {
+		delete(r.cards, id)
+	}
+	return nil
+}
+
+func (r *memRepo) DeleteUnverified(_ context.Context, tgID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tokens []string
+	for id, c := range r.cards {
+		if c.TgID == tgID && !c.Verified {
+			tokens = append(tokens, c.Token)
+			delete(r.cards, id)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memRepo) Touch(context.Context, int64) error { return nil }
+
+const (
+	testTgID   = int64(777000)
+	testNumber = "8600069195406311"
+)
+
+func newTestService(t *testing.T) (*subscribetest.Server, *memRepo, Service) {
+	t.Helper()
+
+	fake := subscribetest.NewServer()
+	t.Cleanup(fake.Close)
+	t.Setenv("PAYME_SUBSCRIBE_URL", fake.URL)
+	t.Setenv("PAYME_KASSA_ID", subscribetest.MerchantID)
+	t.Setenv("PAYME_SECRET_KEY", subscribetest.SecretKey)
+
+	lg := logger.New("error")
+	repo := newMemRepo()
+	svc := New(Params{
+		Logger:    lg,
+		CardRepo:  repo,
+		Subscribe: subscribe.New(subscribe.Params{Logger: lg}),
+	})
+	return fake, repo, svc
+}
+
+// addVerifiedCard - Mini App cards.create -> Add -> Verify
+func addVerifiedCard(t *testing.T, fake *subscribetest.Server, svc Service) structs.ClientCard {
+	t.Helper()
+	ctx := context.Background()
+
+	token, err := fake.CreateCard(testNumber, "0399")
+	if err != nil {
+		t.Fatalf("cards.create: %v", err)
+	}
+	added, err := svc.Add(ctx, structs.AddCardRequest{TgID: testTgID, Token: token})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	card, err := svc.Verify(ctx, structs.VerifyCardRequest{TgID: testTgID, ID: added.Card.ID, Code: subscribetest.VerifyCode})
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return card
+}
+
+func TestAddAndVerify(t *testing.T) {
+	fake, _, svc := newTestService(t)
+	ctx := context.Background()
+
+	token, err := fake.CreateCard(testNumber, "0399")
+	if err != nil {
+		t.Fatalf("cards.create: %v", err)
+	}
+
+	added, err := svc.Add(ctx, structs.AddCardRequest{TgID: testTgID, Token: token})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added.Card.Number != "860006******6311" {
+		t.Fatalf("stored number = %q, want masked", added.Card.Number)
+	}
+	if added.Phone == "" {
+		t.Fatal("verify code was not sent")
+	}
+	if list, _ := svc.List(ctx, testTgID); len(list) != 0 {
+		t.Fatalf("unverified card listed: %+v", list)
+	}
+
+	if _, err := svc.Verify(ctx, structs.VerifyCardRequest{TgID: testTgID, ID: added.Card.ID, Code: "000000"}); err == nil {
+		t.Fatal("wrong code accepted")
+	}
+	card, err := svc.Verify(ctx, structs.VerifyCardRequest{TgID: testTgID, ID: added.Card.ID, Code: subscribetest.VerifyCode})
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !card.Verified {
+		t.Fatal("card not marked verified")
+	}
+	if list, _ := svc.List(ctx, testTgID); len(list) != 1 {
+		t.Fatalf("verified cards = %d, want 1", len(list))
+	}
+
+	for _, m := range fake.Calls() {
+		if m == "cards.create" {
+			continue // faqat Mini App (fake.CreateCard) chaqiradi
+		}
+		if m != "cards.check" && m != "cards.get_verify_code" && m != "cards.verify" {
+			t.Errorf("unexpected backend call %s", m)
+		}
+	}
+}
+
+func TestAddRejectsEmptyToken(t *testing.T) {
+	_, _, svc := newTestService(t)
+
+	_, err := svc.Add(context.Background(), structs.AddCardRequest{TgID: testTgID})
+	if !errors.Is(err, structs.ErrBadRequest) {
+		t.Fatalf("err = %v, want ErrBadRequest", err)
+	}
+}
+
+func TestVerifyOtherClientsCard(t *testing.T) {
+	fake, _, svc := newTestService(t)
+	card := addVerifiedCard(t, fake, svc)
+
+	_, err := svc.Verify(context.Background(), structs.VerifyCardRequest{TgID: testTgID + 1, ID: card.ID, Code: subscribetest.VerifyCode})
+	if !errors.Is(err, structs.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPay(t *testing.T) {
+	fake, _, svc := newTestService(t)
+	card := addVerifiedCard(t, fake, svc)
+
+	paid, err := svc.Pay(context.Background(), structs.PaymentOrder{TgID: testTgID, CardID: card.ID, OrderNumber: 1042, Amount: 85000})
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if paid.State != subscribe.ReceiptStatePaid || paid.ID == "" {
+		t.Fatalf("Pay = %+v, want paid receipt", paid)
+	}
+
+	receipts := fake.Receipts()
+	if len(receipts) != 1 {
+		t.Fatalf("receipts = %d, want 1", len(receipts))
+	}
+	r := receipts[0]
+	if r.Amount != 85000*100 || r.OrderID != "1042" || r.State != subscribetest.ReceiptStatePaid {
+		t.Fatalf("receipt = %+v", r)
+	}
+}
+
+func TestPayOtherClientsCard(t *testing.T) {
+	fake, _, svc := newTestService(t)
+	card := addVerifiedCard(t, fake, svc)
+
+	_, err := svc.Pay(context.Background(), structs.PaymentOrder{TgID: testTgID + 1, CardID: card.ID, OrderNumber: 1042, Amount: 85000})
+	if !errors.Is(err, structs.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if len(fake.Receipts()) != 0 {
+		t.Fatal("receipt created for someone else's card")
+	}
+}
+
+func TestPayDeclinedCancelsReceipt(t *testing.T) {
+	fake, repo, svc := newTestService(t)
+	card := addVerifiedCard(t, fake, svc)
+	fake.SetDeclined(repo.cards[card.ID].Token, true)
+
+	paid, err := svc.Pay(context.Background(), structs.PaymentOrder{TgID: testTgID, CardID: card.ID, OrderNumber: 1043, Amount: 50000})
+	if err == nil {
+		t.Fatalf("Pay = %+v; want decline", paid)
+	}
+	var se *structs.PaymeSubscribeError
+	if !errors.As(err, &se) || se.Code != -31630 {
+		t.Fatalf("err = %v, want -31630", err)
+	}
+
+	receipts := fake.Receipts()
+	if len(receipts) != 1 || receipts[0].State != subscribetest.ReceiptStateCancelled {
+		t.Fatalf("receipt not cancelled: %+v", receipts)
+	}
+}
+
+func TestCancelReceipt(t *testing.T) {
+	fake, _, svc := newTestService(t)
+	card := addVerifiedCard(t, fake, svc)
+
+	paid, err := svc.Pay(context.Background(), structs.PaymentOrder{TgID: testTgID, CardID: card.ID, OrderNumber: 1044, Amount: 42000})
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if err := svc.CancelReceipt(context.Background(), paid.ID); err != nil {
+		t.Fatalf("CancelReceipt: %v", err)
+	}
+	if r, _ := fake.Receipt(paid.ID); r.State != subscribetest.ReceiptStateCancelled {
+		t.Fatalf("receipt state = %d, want cancelled", r.State)
+	}
+
+	var se *structs.PaymeSubscribeError
+	if err := svc.CancelReceipt(context.Background(), "missing"); !errors.As(err, &se) {
+		t.Fatalf("err = %v, want subscribe error", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	fake, repo, svc := newTestService(t)
+	card := addVerifiedCard(t, fake, svc)
+	token := repo.cards[card.ID].Token
+
+	if err := svc.Remove(context.Background(), testTgID, card.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, ok := fake.Card(token); ok {
+		t.Fatal("token still present at Payme")
+	}
+	if list, _ := svc.List(context.Background(), testTgID); len(list) != 0 {
+		t.Fatalf("card still listed: %+v", list)
+	}
+}
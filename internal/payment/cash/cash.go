@@ -0,0 +1,42 @@
+package cash
+
+import (
+	"context"
+
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/structs"
+)
+
+// naqd to'lovda tashqi provider yo'q: zakaz darhol operatorga, pul kuryer/kassada
+var Module = provider.Register(NewProvider)
+
+type cashProvider struct{}
+
+func NewProvider() provider.Provider {
+	return cashProvider{}
+}
+
+func (cashProvider) Method() string   { return structs.PaymentMethodCash }
+func (cashProvider) Online() bool     { return false }
+func (cashProvider) Configured() bool { return true }
+
+func (cashProvider) CreatePayment(context.Context, structs.PaymentOrder) (structs.PaymentIntent, error) {
+	return structs.PaymentIntent{}, nil
+}
+
+func (cashProvider) Status(_ context.Context, order structs.PaymentOrder) (structs.PaymentState, error) {
+	return structs.PaymentState{
+		Method: structs.PaymentMethodCash,
+		Status: order.PaymentStatus,
+		Amount: order.Amount,
+	}, nil
+}
+
+// Refund - naqd pul qo'lda qaytariladi
+func (cashProvider) Refund(context.Context, structs.PaymentOrder, int64) error {
+	return structs.ErrRefundNotSupported
+}
+
+func (cashProvider) ParseCallback(context.Context, []byte) (structs.PaymentCallback, error) {
+	return structs.PaymentCallback{}, structs.ErrCallbackNotSupported
+}
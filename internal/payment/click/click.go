@@ -16,7 +16,10 @@ import (
 	"time"
 
 	"sushitana/internal/orderflow"
+	"sushitana/internal/payment/fiscal"
+	shopapi "sushitana/internal/payment/shop-api"
 	"sushitana/internal/structs"
+	rtws "sushitana/internal/ws"
 	"sushitana/pkg/logger"
 	orderrepo "sushitana/pkg/repository/postgres/order_repo"
 	clickrepo "sushitana/pkg/repository/postgres/payment_repo/click_repo"
@@ -33,10 +36,15 @@ var (
 // Params — FX injection uchun parametrlar
 type Params struct {
 	fx.In
+	fx.Lifecycle
+
 	Logger    logger.Logger
 	ClickRepo clickrepo.Repo
 	OrderRepo orderrepo.Repo
 	OrderFlow orderflow.Service
+	ShopSvc   shopapi.Service
+	Fiscal    fiscal.Service
+	Hub       *rtws.Hub `optional:"true"`
 }
 
 type Service interface {
@@ -48,6 +56,14 @@ type Service interface {
 
 	ShopPrepare(ctx context.Context, req structs.ClickPrepareRequest) (structs.ClickPrepareResponse, error)
 	ShopComplete(ctx context.Context, req structs.ClickCompleteRequest) (structs.ClickCompleteResponse, error)
+
+	// SendPhoneInvoice - operator: zakaz uchun mijoz telefoniga Click invoice
+	SendPhoneInvoice(ctx context.Context, req structs.SendClickInvoiceRequest) (structs.ClickPhoneInvoice, error)
+	GetPhoneInvoice(ctx context.Context, orderID string) (structs.ClickPhoneInvoice, error)
+
+	// admin panel: invoices jadvali (faqat o'qish)
+	GetInvoices(ctx context.Context, req structs.GetListClickInvoiceRequest) (structs.GetListClickInvoiceResponse, error)
+	GetInvoice(ctx context.Context, id int64) (structs.ClickInvoiceRecord, error)
 }
 
 type service struct {
@@ -55,11 +71,14 @@ type service struct {
 	clickrepo clickrepo.Repo
 	orderRepo orderrepo.Repo
 	orderFlow orderflow.Service
+	shopSvc   shopapi.Service
+	fiscal    fiscal.Service
+	hub       *rtws.Hub
 	client    *http.Client
 }
 
 func New(p Params) Service {
-	return &service{
+	s := &service{
 		logger:    p.Logger,
 		clickrepo: p.ClickRepo,
 		client: &http.Client{
@@ -67,7 +86,23 @@ func New(p Params) Service {
 		},
 		orderRepo: p.OrderRepo,
 		orderFlow: p.OrderFlow,
+		shopSvc:   p.ShopSvc,
+		fiscal:    p.Fiscal,
+		hub:       p.Hub,
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go s.watchInvoices(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return s
 }
 func md5hex(s string) string {
 	sum := md5.Sum([]byte(s))
@@ -158,6 +193,17 @@ func (s *service) ShopPrepare(ctx context.Context, req structs.ClickPrepareReque
 		}, nil
 	}
 
+	// mijoz zakazni bekor qilgan bo'lsa invoice ham bekor
+	if strings.EqualFold(inv.Status, "CANCELLED") {
+		return structs.ClickPrepareResponse{
+			ClickTransId:      req.ClickTransId,
+			MerchantTransId:   req.MerchantTransId,
+			MerchantPrepareId: inv.MerchantPrepareID,
+			Error:             -9,
+			ErrorNote:         "Transaction cancelled",
+		}, nil
+	}
+
 	reqAmt := math.Round(cast.ToFloat64(req.Amount)*100) / 100
 	invAmt := math.Round(cast.ToFloat64(inv.Amount)*100) / 100
 
@@ -430,8 +476,15 @@ func (s *service) ShopComplete(ctx context.Context, req structs.ClickCompleteReq
 		return resp, nil
 	}
 
+	if strings.EqualFold(invoice.Status, "CANCELLED") {
+		resp.Error = -9
+		resp.ErrorNote = "Transaction cancelled"
+		return resp, nil
+	}
+
 	// 2) Click tomonidan kelgan xato status
 	if req.Error != nil && *req.Error != 0 {
+		_ = s.clickrepo.SetCompleteError(ctx, req.MerchantTransId, *req.Error, req.ErrorNote)
 		// xato bo‘lsa UNPAID qilib qo‘yamiz
 		_ = s.orderRepo.UpdatePaymentStatus(ctx, structs.UpdateStatus{
 			OrderId: invoice.OrderID,
@@ -485,5 +538,51 @@ func (s *service) ShopComplete(ctx context.Context, req structs.ClickCompleteReq
 	// 7) notify (COOKING)
 	s.orderFlow.NotifyOrderStatusIfNeeded(ctx, oid, "COOKING")
 
+	// 8) fiskal chek (Click javobini kutmaymiz)
+	go s.submitFiscal(context.Background(), oid, req.ServiceId, req.ClickPaydocId, req.MerchantTransId)
+
 	return resp, nil
 }
+
+// submitFiscal - ofd_data/submit_items; natija invoices.fiscal_* ga yoziladi
+func (s *service) submitFiscal(ctx context.Context, orderID string, serviceID, paymentID int64, merchantTransID string) {
+	ord, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "click fiscal: order not found", zap.String("order_id", orderID), zap.Error(err))
+		return
+	}
+
+	items, err := s.fiscal.Items(ctx, ord.Order)
+	if err != nil || len(items) == 0 {
+		s.logger.Error(ctx, "click fiscal: items not built", zap.String("order_id", orderID), zap.Error(err))
+		return
+	}
+
+	req := structs.ClickSubmitItemsRequest{
+		ServiceID:     serviceID,
+		PaymentID:     paymentID,
+		Items:         s.fiscal.ClickItems(items),
+		ReceivedEcash: ord.Order.PayAmount * 100,
+	}
+	b, _ := json.Marshal(req.Items)
+
+	status, note := structs.FiscalStatusSent, ""
+	resp, err := s.shopSvc.SubmitFiscalItems(ctx, req)
+	switch {
+	case err != nil:
+		status, note = structs.FiscalStatusFailed, err.Error()
+	case resp.ErrorCode != 0:
+		status, note = structs.FiscalStatusFailed, fmt.Sprintf("%d %s", resp.ErrorCode, resp.ErrorNote)
+	}
+	if status == structs.FiscalStatusFailed {
+		s.logger.Error(ctx, "click fiscal: submit_items failed",
+			zap.String("order_id", orderID),
+			zap.Int64("payment_id", paymentID),
+			zap.String("error", note),
+		)
+	}
+
+	if err := s.clickrepo.SetFiscalResult(ctx, merchantTransID, b, status, note); err != nil {
+		s.logger.Error(ctx, "->clickrepo.SetFiscalResult", zap.Error(err))
+	}
+}
@@ -0,0 +1,202 @@
+package click
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/utils"
+
+	"github.com/spf13/cast"
+	"go.uber.org/zap"
+)
+
+const (
+	invoicePollInterval = 30 * time.Second
+	// shundan eski invoicelarni so'ramaymiz (Click invoice ham shu vaqtda eskiradi)
+	invoiceWatchWindow = 24 * time.Hour
+)
+
+// SendPhoneInvoice - telefon orqali olingan zakaz: Click mijoz raqamiga invoice yuboradi,
+// mijoz Click ilovasida to'laydi. merchant_trans_id = zakaz raqami, shuning uchun
+// Prepare/Complete odatdagidek ShopComplete orqali o'tadi; watchInvoices - zaxira.
+func (s *service) SendPhoneInvoice(ctx context.Context, req structs.SendClickInvoiceRequest) (structs.ClickPhoneInvoice, error) {
+	res, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		return structs.ClickPhoneInvoice{}, err
+	}
+	ord := res.Order
+
+	if ord.PaymentMethod != structs.PaymentMethodClick {
+		return structs.ClickPhoneInvoice{}, structs.ErrBadRequest
+	}
+	if ord.PaymentStatus == structs.PaymentStatusPaid || ord.Status != structs.OrderStatusWaitingPayment {
+		return structs.ClickPhoneInvoice{}, structs.ErrOrderNotPayable
+	}
+
+	phone := strings.TrimSpace(req.Phone)
+	if phone == "" {
+		phone = res.Phone
+	}
+	phone = utils.NormalizePhone(phone)
+	if len(phone) != 9 {
+		return structs.ClickPhoneInvoice{}, structs.ErrBadRequest
+	}
+	phone = "998" + phone
+
+	serviceID := cast.ToInt64(strings.TrimSpace(os.Getenv("CLICK_SERVICE_ID")))
+	if serviceID == 0 {
+		return structs.ClickPhoneInvoice{}, errors.New("CLICK_SERVICE_ID env not found")
+	}
+
+	merchantTransID := cast.ToString(ord.OrderNumber)
+	created, err := s.CreateClickInvoice(ctx, structs.CreateInvoiceRequest{
+		ServiceID:       serviceID,
+		MerchantTransId: merchantTransID,
+		Amount:          float64(ord.PayAmount),
+		PhoneNumber:     phone,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "->CreateClickInvoice", zap.String("orderId", ord.ID), zap.Error(err))
+		return structs.ClickPhoneInvoice{}, err
+	}
+
+	if _, err := s.clickrepo.Create(ctx, structs.Invoice{
+		ClickInvoiceID:  created.InvoiceId,
+		MerchantTransID: merchantTransID,
+		OrderID:         sql.NullString{String: ord.ID, Valid: true},
+		TgID:            sql.NullInt64{Int64: ord.TgID, Valid: ord.TgID != 0},
+		CustomerPhone:   sql.NullString{String: phone, Valid: true},
+		Amount:          cast.ToString(ord.PayAmount),
+		Currency:        "UZS",
+		Status:          "WAITING_PAYMENT",
+	}); err != nil {
+		return structs.ClickPhoneInvoice{}, err
+	}
+	if err := s.clickrepo.MarkInvoiceSent(ctx, merchantTransID); err != nil {
+		return structs.ClickPhoneInvoice{}, err
+	}
+
+	inv, err := s.clickrepo.GetPhoneInvoice(ctx, merchantTransID)
+	if err != nil {
+		return structs.ClickPhoneInvoice{}, err
+	}
+	s.publishInvoice(inv, ord.PaymentStatus)
+	return inv, nil
+}
+
+func (s *service) GetPhoneInvoice(ctx context.Context, orderID string) (structs.ClickPhoneInvoice, error) {
+	res, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return structs.ClickPhoneInvoice{}, err
+	}
+	return s.clickrepo.GetPhoneInvoice(ctx, cast.ToString(res.Order.OrderNumber))
+}
+
+// watchInvoices - yuborilgan invoicelar holatini invoice/status bilan kuzatadi
+func (s *service) watchInvoices(ctx context.Context) {
+	ticker := time.NewTicker(invoicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.pollInvoices(ctx)
+		}
+	}
+}
+
+func (s *service) pollInvoices(ctx context.Context) {
+	invoices, err := s.clickrepo.GetWatchedInvoices(ctx, time.Now().Add(-invoiceWatchWindow))
+	if err != nil || len(invoices) == 0 {
+		return
+	}
+	serviceID := cast.ToInt64(strings.TrimSpace(os.Getenv("CLICK_SERVICE_ID")))
+	if serviceID == 0 {
+		return
+	}
+
+	for _, inv := range invoices {
+		if ctx.Err() != nil {
+			return
+		}
+		remote, err := s.InvoiceStatus(ctx, serviceID, inv.InvoiceID)
+		if err != nil {
+			s.logger.Warn(ctx, "click invoice/status failed", zap.Int64("invoiceId", inv.InvoiceID), zap.Error(err))
+			continue
+		}
+		if remote.InvoiceStatus == inv.InvoiceStatus && remote.InvoiceStatusNote == inv.InvoiceStatusNote {
+			continue
+		}
+
+		merchantTransID := cast.ToString(inv.OrderNumber)
+		if err := s.clickrepo.SetInvoiceStatus(ctx, merchantTransID, remote.InvoiceStatus, remote.InvoiceStatusNote); err != nil {
+			continue
+		}
+		inv.InvoiceStatus = remote.InvoiceStatus
+		inv.InvoiceStatusNote = remote.InvoiceStatusNote
+
+		paymentStatus := ""
+		if remote.InvoiceStatus == structs.ClickInvoicePaid && inv.OrderID != "" {
+			inv.Status = structs.PaymentStatusPaid
+			paymentStatus = s.markInvoicePaid(ctx, inv.OrderID, merchantTransID)
+		}
+		s.publishInvoice(inv, paymentStatus)
+	}
+}
+
+// markInvoicePaid - Complete callback kelmay qolgan bo'lsa ShopComplete'dagi PAID yo'li
+func (s *service) markInvoicePaid(ctx context.Context, orderID, merchantTransID string) string {
+	res, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "order.GetByID failed", zap.Error(err))
+		return ""
+	}
+	if res.Order.PaymentStatus == structs.PaymentStatusPaid {
+		return structs.PaymentStatusPaid // Complete allaqachon ishlagan
+	}
+
+	if err := s.clickrepo.SetStatus(ctx, merchantTransID, structs.PaymentStatusPaid); err != nil {
+		s.logger.Error(ctx, "click.SetStatus failed", zap.Error(err))
+	}
+	if err := s.orderRepo.UpdatePaymentStatus(ctx, structs.UpdateStatus{
+		OrderId: orderID,
+		Status:  structs.PaymentStatusPaid,
+	}); err != nil {
+		s.logger.Error(ctx, "order.UpdatePaymentStatus failed", zap.Error(err))
+		return res.Order.PaymentStatus
+	}
+	if res.Order.Status == structs.OrderStatusWaitingPayment {
+		if err := s.orderRepo.UpdateStatus(ctx, structs.UpdateStatus{
+			OrderId: orderID,
+			Status:  structs.OrderStatusCooking,
+		}); err != nil {
+			s.logger.Error(ctx, "order.UpdateStatus failed", zap.Error(err))
+		}
+		if err := s.orderFlow.SendToIikoIfAllowed(ctx, orderID); err != nil {
+			s.logger.Error(ctx, "SendToIikoIfAllowed failed", zap.Error(err))
+		}
+		s.orderFlow.NotifyOrderStatusIfNeeded(ctx, orderID, structs.OrderStatusCooking)
+	}
+	return structs.PaymentStatusPaid
+}
+
+func (s *service) publishInvoice(inv structs.ClickPhoneInvoice, paymentStatus string) {
+	if s.hub == nil {
+		return
+	}
+	s.hub.BroadcastToAdmins(structs.Event{
+		Type: structs.EventClickInvoice,
+		TS:   time.Now(),
+		Payload: structs.ClickInvoicePayload{
+			ClickPhoneInvoice: inv,
+			PaymentStatus:     paymentStatus,
+		},
+	})
+}
@@ -0,0 +1,210 @@
+package click
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"sushitana/internal/payment/provider"
+	shopapi "sushitana/internal/payment/shop-api"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+	clickrepo "sushitana/pkg/repository/postgres/payment_repo/click_repo"
+
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+)
+
+// ProviderModule - provider.Registry uchun
+var ProviderModule = provider.Register(NewProvider)
+
+type ProviderParams struct {
+	fx.In
+	Logger    logger.Logger
+	ClickSvc  Service
+	ShopSvc   shopapi.Service
+	ClickRepo clickrepo.Repo
+	OrderRepo orderrepo.Repo
+}
+
+type clickProvider struct {
+	logger    logger.Logger
+	clickSvc  Service
+	shopSvc   shopapi.Service
+	clickRepo clickrepo.Repo
+	orderRepo orderrepo.Repo
+}
+
+func NewProvider(p ProviderParams) provider.Provider {
+	return &clickProvider{
+		logger:    p.Logger,
+		clickSvc:  p.ClickSvc,
+		shopSvc:   p.ShopSvc,
+		clickRepo: p.ClickRepo,
+		orderRepo: p.OrderRepo,
+	}
+}
+
+func clickIDs() (serviceID, merchantID string) {
+	return strings.TrimSpace(os.Getenv("CLICK_SERVICE_ID")), strings.TrimSpace(os.Getenv("CLICK_MERCHANT_ID"))
+}
+
+func (p *clickProvider) Method() string { return structs.PaymentMethodClick }
+func (p *clickProvider) Online() bool   { return true }
+
+func (p *clickProvider) Configured() bool {
+	serviceID, merchantID := clickIDs()
+	return serviceID != "" && merchantID != ""
+}
+
+// CreatePayment - checkout/prepare + invoices yozuvi + my.click.uz havola.
+// merchant_trans_id = zakaz raqami (Prepare/Complete shu bo'yicha topadi)
+func (p *clickProvider) CreatePayment(ctx context.Context, order structs.PaymentOrder) (structs.PaymentIntent, error) {
+	serviceID, merchantID := clickIDs()
+	if serviceID == "" || merchantID == "" {
+		return structs.PaymentIntent{}, fmt.Errorf("CLICK_SERVICE_ID yoki CLICK_MERCHANT_ID env not found")
+	}
+
+	merchantTransID := cast.ToString(order.OrderNumber)
+
+	prep, err := p.clickSvc.CheckoutPrepare(ctx, structs.CheckoutPrepareRequest{
+		ServiceID:        serviceID,
+		MerchantID:       merchantID,
+		TransactionParam: merchantTransID,
+		Amount:           float64(order.Amount),
+		Description:      fmt.Sprintf("Order #%d", order.OrderNumber),
+	})
+	if err != nil {
+		return structs.PaymentIntent{}, fmt.Errorf("click checkout/prepare failed: %w", err)
+	}
+
+	// orderga click info yozib qo'yish (request_id / transaction_param)
+	_ = p.orderRepo.UpdateClickInfo(ctx, order.OrderID, prep.RequestId, merchantTransID)
+
+	payURL := BuildPayURL(cast.ToInt64(serviceID), merchantID, order.Amount, merchantTransID, "")
+	if _, err := p.clickRepo.Create(ctx, structs.Invoice{
+		MerchantTransID: merchantTransID,
+		OrderID:         sql.NullString{String: order.OrderID, Valid: strings.TrimSpace(order.OrderID) != ""},
+		TgID:            sql.NullInt64{Int64: order.TgID, Valid: order.TgID != 0},
+		CustomerPhone:   sql.NullString{String: order.Phone, Valid: order.Phone != ""},
+		Amount:          cast.ToString(order.Amount),
+		Currency:        "UZS",
+		Status:          "WAITING_PAYMENT",
+	}); err != nil {
+		return structs.PaymentIntent{}, fmt.Errorf("click invoice create failed: %w", err)
+	}
+
+	return structs.PaymentIntent{URL: payURL, ExternalID: prep.RequestId}, nil
+}
+
+// Status - lokal invoices; to'langan bo'lsa Click'dan ham tekshiriladi
+func (p *clickProvider) Status(ctx context.Context, order structs.PaymentOrder) (structs.PaymentState, error) {
+	st := structs.PaymentState{Method: structs.PaymentMethodClick, Amount: order.Amount}
+
+	inv, err := p.clickRepo.GetByMerchantTransID(ctx, cast.ToString(order.OrderNumber))
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
+			st.Status = structs.PaymentStatusPending
+			return st, nil
+		}
+		return st, err
+	}
+	st.Amount = cast.ToInt64(strings.Split(inv.Amount, ".")[0])
+	st.ExternalID = cast.ToString(inv.ClickPaydocID)
+
+	switch strings.ToUpper(inv.Status) {
+	case "PAID":
+		st.Status = structs.PaymentStatusPaid
+	case "CANCELLED":
+		st.Status = "CANCELLED"
+	default:
+		st.Status = structs.PaymentStatusPending
+	}
+
+	if inv.ClickPaydocID != 0 {
+		serviceID, _ := clickIDs()
+		remote, err := p.shopSvc.PaymentStatus(ctx, cast.ToInt64(serviceID), inv.ClickPaydocID)
+		if err == nil && remote.ErrorCode == 0 && remote.PaymentStatus == 2 {
+			st.Status = structs.PaymentStatusPaid
+		}
+	}
+	return st, nil
+}
+
+// Refund - to'liq: payment/reversal, qisman: payment/partial_reversal (payment_id = click_paydoc_id).
+// invoice summasi boshlang'ich to'lov: oldingi qisman qaytarishlar (order.Refunded) ayiriladi
+func (p *clickProvider) Refund(ctx context.Context, order structs.PaymentOrder, amount int64) error {
+	inv, err := p.clickRepo.GetByMerchantTransID(ctx, cast.ToString(order.OrderNumber))
+	if err != nil {
+		return err
+	}
+	if inv.ClickPaydocID == 0 {
+		return structs.ErrOrderNotPayable
+	}
+
+	serviceID, _ := clickIDs()
+	remaining := cast.ToInt64(strings.Split(inv.Amount, ".")[0]) - order.Refunded
+	if amount == 0 {
+		amount = remaining
+	}
+	if remaining <= 0 || amount > remaining {
+		return structs.ErrBadRequest
+	}
+
+	// to'liq reversal faqat hali hech narsa qaytarilmagan bo'lsa; qolgani partial_reversal bilan
+	var resp structs.ReversalResponse
+	if amount == remaining && order.Refunded == 0 {
+		resp, err = p.shopSvc.PaymentReversal(ctx, cast.ToInt64(serviceID), inv.ClickPaydocID)
+	} else {
+		resp, err = p.shopSvc.PaymentPartialReversal(ctx, cast.ToInt64(serviceID), inv.ClickPaydocID, amount)
+	}
+	if err != nil {
+		return fmt.Errorf("click reversal failed: %w", err)
+	}
+	if resp.ErrorCode != 0 {
+		return fmt.Errorf("click reversal failed: %d %s", resp.ErrorCode, resp.ErrorNote)
+	}
+	return nil
+}
+
+// ParseCallback - Shop API prepare/complete (form-urlencoded)
+func (p *clickProvider) ParseCallback(_ context.Context, body []byte) (structs.PaymentCallback, error) {
+	v, err := url.ParseQuery(string(body))
+	if err != nil {
+		return structs.PaymentCallback{}, err
+	}
+	if v.Get("click_trans_id") == "" {
+		return structs.PaymentCallback{}, structs.ErrBadRequest
+	}
+
+	action := "prepare"
+	if v.Get("action") == "1" {
+		action = "complete"
+	}
+	return structs.PaymentCallback{
+		Method:     structs.PaymentMethodClick,
+		Action:     action,
+		OrderRef:   v.Get("merchant_trans_id"),
+		ExternalID: v.Get("click_trans_id"),
+		Amount:     cast.ToInt64(strings.Split(v.Get("amount"), ".")[0]),
+		Success:    cast.ToInt(v.Get("error")) == 0,
+	}, nil
+}
+
+// BuildPayURL - my.click.uz/services/pay havolasi
+func BuildPayURL(serviceID int64, merchantID string, amountInt int64, orderID, returnURL string) string {
+	v := url.Values{}
+	v.Set("service_id", cast.ToString(serviceID))
+	v.Set("merchant_id", merchantID)
+	v.Set("amount", fmt.Sprintf("%d.00", amountInt))
+	v.Set("transaction_param", orderID)
+	if returnURL != "" {
+		v.Set("return_url", returnURL)
+	}
+	return "https://my.click.uz/services/pay?" + v.Encode()
+}
@@ -0,0 +1,30 @@
+package click
+
+import (
+	"context"
+	"errors"
+
+	"sushitana/internal/structs"
+
+	"go.uber.org/zap"
+)
+
+func (s *service) GetInvoices(ctx context.Context, req structs.GetListClickInvoiceRequest) (structs.GetListClickInvoiceResponse, error) {
+	resp, err := s.clickrepo.GetList(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->clickrepo.GetList", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) GetInvoice(ctx context.Context, id int64) (structs.ClickInvoiceRecord, error) {
+	inv, err := s.clickrepo.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->clickrepo.GetByID", zap.Error(err))
+		}
+		return inv, err
+	}
+	return inv, nil
+}
@@ -0,0 +1,196 @@
+package fiscal
+
+import (
+	"context"
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+	productrepo "sushitana/pkg/repository/postgres/product_repo"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+const (
+	defaultVatPercent = 12
+	deliveryTitle     = "Доставка"
+	tipTitle          = "Чаевые курьеру"
+)
+
+type (
+	Params struct {
+		fx.In
+		Logger      logger.Logger
+		Config      config.IConfig
+		ProductRepo productrepo.Repo
+	}
+
+	// Service - zakazdan fiskal chek qatorlari (Payme detail, Click ofd_data)
+	Service interface {
+		Items(ctx context.Context, order structs.Order) ([]structs.FiscalItem, error)
+		PaymeDetail(items []structs.FiscalItem) structs.PaymeReceiptDetail
+		ClickItems(items []structs.FiscalItem) []structs.ClickFiscalItem
+	}
+
+	service struct {
+		logger      logger.Logger
+		cfg         config.IConfig
+		productRepo productrepo.Repo
+	}
+)
+
+func New(p Params) Service {
+	return &service{
+		logger:      p.Logger,
+		cfg:         p.Config,
+		productRepo: p.ProductRepo,
+	}
+}
+
+// Items - mahsulotlar, box'lar (bir xil box bitta qatorda), yetkazish va choy puli.
+// IKPU kiritilmagan mahsulotga fiscal_default_ikpu qo'yiladi.
+func (s *service) Items(ctx context.Context, order structs.Order) ([]structs.FiscalItem, error) {
+	ids := make([]string, 0, len(order.Products)*2)
+	for _, p := range order.Products {
+		ids = append(ids, p.ID)
+		if p.BoxID != "" {
+			ids = append(ids, p.BoxID)
+		}
+	}
+
+	codes, err := s.productRepo.GetFiscal(ctx, ids)
+	if err != nil {
+		s.logger.Error(ctx, "->productRepo.GetFiscal", zap.Error(err))
+		return nil, err
+	}
+
+	var (
+		items    = make([]structs.FiscalItem, 0, len(order.Products)+2)
+		boxIdx   = map[string]int{}
+		defIkpu  = strings.TrimSpace(s.cfg.GetString("fiscal_default_ikpu"))
+		defPack  = strings.TrimSpace(s.cfg.GetString("fiscal_default_package_code"))
+		withCode = func(id, title string, count, price int64) structs.FiscalItem {
+			f := codes[id]
+			it := structs.FiscalItem{
+				Title:       title,
+				Ikpu:        f.Ikpu,
+				PackageCode: f.PackageCode,
+				VatPercent:  f.VatPercent,
+				Count:       count,
+				Price:       price,
+			}
+			if it.Ikpu == "" {
+				s.logger.Warn(ctx, "fiscal: product ikpu empty, default used", zap.String("product_id", id))
+				it.Ikpu = defIkpu
+			}
+			if it.PackageCode == "" {
+				it.PackageCode = defPack
+			}
+			if _, ok := codes[id]; !ok {
+				it.VatPercent = defaultVatPercent
+			}
+			return it
+		}
+	)
+
+	for _, p := range order.Products {
+		if p.Quantity <= 0 {
+			continue
+		}
+		items = append(items, withCode(p.ID, title(p.ProductName), p.Quantity, p.ProductPrice))
+
+		if p.BoxID == "" || p.BoxPrice <= 0 {
+			continue
+		}
+		if i, ok := boxIdx[p.BoxID]; ok {
+			items[i].Count += p.Quantity
+			continue
+		}
+		boxIdx[p.BoxID] = len(items)
+		items = append(items, withCode(p.BoxID, title(p.BoxName), p.Quantity, p.BoxPrice))
+	}
+
+	// promo chegirmasi mahsulot qatorlariga tartib bilan taqsimlanadi (yetkazish/choy puliga tegmaydi)
+	for i, left := 0, order.DiscountAmount; i < len(items) && left > 0; i++ {
+		items[i].Discount = min(left, items[i].Price*items[i].Count)
+		left -= items[i].Discount
+	}
+
+	if order.DeliveryType != structs.DeliveryTypePickup && order.DeliveryPrice > 0 {
+		items = append(items, structs.FiscalItem{
+			Title:       deliveryTitle,
+			Ikpu:        strings.TrimSpace(s.cfg.GetString("fiscal_delivery_ikpu")),
+			PackageCode: strings.TrimSpace(s.cfg.GetString("fiscal_delivery_package_code")),
+			VatPercent:  defaultVatPercent,
+			Count:       1,
+			Price:       order.DeliveryPrice,
+		})
+	}
+	// choy puli ham to'lov summasiga kiradi, chek yig'indisi mos kelishi uchun alohida qator (QQSsiz)
+	if order.TipAmount > 0 {
+		items = append(items, structs.FiscalItem{
+			Title:       tipTitle,
+			Ikpu:        strings.TrimSpace(s.cfg.GetString("fiscal_tip_ikpu")),
+			PackageCode: strings.TrimSpace(s.cfg.GetString("fiscal_tip_package_code")),
+			Count:       1,
+			Price:       order.TipAmount,
+		})
+	}
+	return items, nil
+}
+
+// PaymeDetail - price bitta dona uchun, tiyinda
+func (s *service) PaymeDetail(items []structs.FiscalItem) structs.PaymeReceiptDetail {
+	out := structs.PaymeReceiptDetail{
+		ReceiptType: 0, // sotuv
+		Items:       make([]structs.PaymeReceiptItem, 0, len(items)),
+	}
+	for _, it := range items {
+		out.Items = append(out.Items, structs.PaymeReceiptItem{
+			Title:       it.Title,
+			Price:       it.Price * 100,
+			Count:       it.Count,
+			Code:        it.Ikpu,
+			PackageCode: it.PackageCode,
+			VatPercent:  it.VatPercent,
+			Discount:    it.Discount * 100,
+		})
+	}
+	return out
+}
+
+// ClickItems - Price qator jami, VAT jami ichidagi QQS (tiyinda)
+func (s *service) ClickItems(items []structs.FiscalItem) []structs.ClickFiscalItem {
+	tin := strings.TrimSpace(s.cfg.GetString("fiscal_tin"))
+
+	out := make([]structs.ClickFiscalItem, 0, len(items))
+	for _, it := range items {
+		total := (it.Price*it.Count - it.Discount) * 100
+		out = append(out, structs.ClickFiscalItem{
+			Name:           it.Title,
+			SPIC:           it.Ikpu,
+			PackageCode:    it.PackageCode,
+			GoodPrice:      it.Price * 100,
+			Price:          total,
+			Amount:         it.Count * 1000,
+			VAT:            total * it.VatPercent / (100 + it.VatPercent),
+			VATPercent:     it.VatPercent,
+			CommissionInfo: structs.ClickCommissionInfo{TIN: tin},
+		})
+	}
+	return out
+}
+
+func title(n structs.Name) string {
+	if n.Ru != "" {
+		return n.Ru
+	}
+	if n.Uz != "" {
+		return n.Uz
+	}
+	return n.En
+}
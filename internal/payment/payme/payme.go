@@ -3,12 +3,14 @@ package payme
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"math"
 	"strconv"
 	"strings"
 	"sushitana/internal/iiko"
 	"sushitana/internal/orderflow"
+	"sushitana/internal/payment/fiscal"
 	"sushitana/internal/structs"
 	"sushitana/pkg/logger"
 	orderrepo "sushitana/pkg/repository/postgres/order_repo"
@@ -32,6 +34,7 @@ type (
 		PaymeRepo paymerepo.Repo
 		OrderFlow orderflow.Service
 		IikoSvc   iiko.Service
+		Fiscal    fiscal.Service
 	}
 	Service interface {
 		CheckPerformTransaction(ctx context.Context, p structs.PaymeCheckPerformParams) (structs.PaymeCheckPerformResult, structs.RPCError)
@@ -40,7 +43,12 @@ type (
 		CancelTransaction(ctx context.Context, p structs.PaymeCancelParams) (structs.PaymeCancelResult, structs.RPCError)
 		CheckTransaction(ctx context.Context, p structs.PaymeCheckParams) (structs.PaymeCheckResult, structs.RPCError)
 		GetStatement(ctx context.Context, p structs.PaymeStatementParams) (structs.PaymeStatementResult, structs.RPCError)
+		SetFiscalData(ctx context.Context, p structs.PaymeSetFiscalDataParams) (structs.PaymeSetFiscalDataResult, structs.RPCError)
 		BuildPaymeCheckoutURL(merchantID string, orderID string, amountTiyin int64) (string, error)
+
+		// admin panel: payme_transactions jadvali (faqat o'qish)
+		GetTransactions(ctx context.Context, req structs.GetListPaymeTransactionRequest) (structs.GetListPaymeTransactionResponse, error)
+		GetTransaction(ctx context.Context, id string) (structs.PaymeTransactionRecord, error)
 	}
 	service struct {
 		logger    logger.Logger
@@ -48,6 +56,7 @@ type (
 		paymeRepo paymerepo.Repo
 		orderFlow orderflow.Service
 		iikoSvc   iiko.Service
+		fiscal    fiscal.Service
 	}
 )
 
@@ -58,6 +67,7 @@ func New(p Params) Service {
 		paymeRepo: p.PaymeRepo,
 		iikoSvc:   p.IikoSvc,
 		orderFlow: p.OrderFlow,
+		fiscal:    p.Fiscal,
 	}
 }
 
@@ -190,7 +200,13 @@ func (s *service) CheckPerformTransaction(ctx context.Context, p structs.PaymeCh
 		)
 	}
 
-	if ord.Status != "WAITING_PAYMENT" {
+	// qisman qaytarish: eski chek o'rniga saqlangan kartadan yechilayotgan chek
+	if s.isRebill(ctx, ord, p.Amount) {
+		return structs.PaymeCheckPerformResult{Allow: true, Detail: s.receiptDetail(ctx, ord)}, structs.RPCError{}
+	}
+
+	// to'lov usuli boshqasiga almashtirilgan bo'lsa eski Payme havolasi ishlamasin
+	if ord.Status != "WAITING_PAYMENT" || !strings.EqualFold(ord.PaymentMethod, structs.PaymentMethodPayme) {
 		return structs.PaymeCheckPerformResult{Allow: false}, rpcErr(
 			-31052,
 			"Операция недоступна",
@@ -200,9 +216,9 @@ func (s *service) CheckPerformTransaction(ctx context.Context, p structs.PaymeCh
 		)
 	}
 
-	expected := expectedAmountTiyinFromOrderTotal(any(ord.TotalPrice))
+	expected := expectedAmountTiyinFromOrderTotal(any(ord.PayAmount))
 	if expected == 0 {
-		expected = int64(math.Round(float64(ord.TotalPrice) * 100)) // ✅ *100
+		expected = int64(math.Round(float64(ord.PayAmount) * 100)) // ✅ *100
 	}
 
 	if p.Amount != expected {
@@ -215,7 +231,44 @@ func (s *service) CheckPerformTransaction(ctx context.Context, p structs.PaymeCh
 		)
 	}
 
-	return structs.PaymeCheckPerformResult{Allow: true}, structs.RPCError{}
+	return structs.PaymeCheckPerformResult{Allow: true, Detail: s.receiptDetail(ctx, ord)}, structs.RPCError{}
+}
+
+// isRebill - to'langan zakaz uchun qisman qaytarishda kutilayotgan chek (Refund SetRebill qiladi)
+func (s *service) isRebill(ctx context.Context, ord structs.Order, amountTiyin int64) bool {
+	if ord.ID == "" || !strings.EqualFold(ord.PaymentMethod, structs.PaymentMethodPayme) {
+		return false
+	}
+	rebill, err := s.paymeRepo.GetRebillAmount(ctx, ord.ID)
+	return err == nil && rebill > 0 && rebill*100 == amountTiyin
+}
+
+// receiptDetail - fiskal chek; qurilmasa detail'siz javob beramiz (to'lov to'xtamasin)
+func (s *service) receiptDetail(ctx context.Context, ord structs.Order) *structs.PaymeReceiptDetail {
+	items, err := s.fiscal.Items(ctx, ord)
+	if err != nil || len(items) == 0 {
+		s.logger.Warn(ctx, "payme: fiscal detail not built", zap.String("order_id", ord.ID), zap.Error(err))
+		return nil
+	}
+	detail := s.fiscal.PaymeDetail(items)
+	return &detail
+}
+
+// SetFiscalData - Payme fiskal chek natijasi (receipt_id, qr_code_url ...) tranzaksiyaga yoziladi
+func (s *service) SetFiscalData(ctx context.Context, p structs.PaymeSetFiscalDataParams) (structs.PaymeSetFiscalDataResult, structs.RPCError) {
+	fiscalType := strings.ToUpper(strings.TrimSpace(p.Type))
+	if p.ID == "" || len(p.FiscalData) == 0 || (fiscalType != "PERFORM" && fiscalType != "CANCEL") {
+		return structs.PaymeSetFiscalDataResult{}, rpcErr(-32600, "Неверные параметры", "Noto‘g‘ri parametrlar", "Invalid params", "fiscal_data")
+	}
+
+	n, err := s.paymeRepo.SetFiscalData(ctx, p.ID, fiscalType, p.FiscalData)
+	if err != nil {
+		return structs.PaymeSetFiscalDataResult{}, rpcErr(-32400, "Внутренняя ошибка", "Ichki xato", "Internal error", nil)
+	}
+	if n == 0 {
+		return structs.PaymeSetFiscalDataResult{}, rpcErr(-31003, "Транзакция не найдена", "Tranzaksiya topilmadi", "Transaction not found", "transaction")
+	}
+	return structs.PaymeSetFiscalDataResult{Success: true}, structs.RPCError{}
 }
 func (s *service) CreateTransaction(ctx context.Context, p structs.PaymeCreateParams) (structs.PaymeCreateResult, structs.RPCError) {
 	orderID, ok := parseOrderID(p.Account)
@@ -240,7 +293,11 @@ func (s *service) CreateTransaction(ctx context.Context, p structs.PaymeCreatePa
 		)
 	}
 
-	if ord.Status != "WAITING_PAYMENT" {
+	// qisman qaytarish: eski chek o'rniga saqlangan kartadan yechilayotgan chek
+	rebill := s.isRebill(ctx, ord, p.Amount)
+
+	// to'lov usuli boshqasiga almashtirilgan bo'lsa eski Payme havolasi ishlamasin
+	if !rebill && (ord.Status != "WAITING_PAYMENT" || !strings.EqualFold(ord.PaymentMethod, structs.PaymentMethodPayme)) {
 		return structs.PaymeCreateResult{}, rpcErr(
 			-31052,
 			"Операция недоступна",
@@ -250,11 +307,11 @@ func (s *service) CreateTransaction(ctx context.Context, p structs.PaymeCreatePa
 		)
 	}
 
-	expected := expectedAmountTiyinFromOrderTotal(any(ord.TotalPrice))
+	expected := expectedAmountTiyinFromOrderTotal(any(ord.PayAmount))
 	if expected == 0 {
-		expected = int64(math.Round(float64(ord.TotalPrice)))
+		expected = int64(math.Round(float64(ord.PayAmount)))
 	}
-	if p.Amount != expected {
+	if !rebill && p.Amount != expected {
 		return structs.PaymeCreateResult{}, rpcErr(
 			-31001,
 			"Неверная сумма",
@@ -308,6 +365,12 @@ func (s *service) CreateTransaction(ctx context.Context, p structs.PaymeCreatePa
 		)
 	}
 
+	if detail := s.receiptDetail(ctx, ord); detail != nil {
+		if b, err := json.Marshal(detail); err == nil {
+			_ = s.paymeRepo.SetReceiptDetail(ctx, tx.PaycomTransactionID, b)
+		}
+	}
+
 	return structs.PaymeCreateResult{
 		Transaction: tx.PaycomTransactionID,
 		State:       tx.State,
@@ -348,6 +411,17 @@ func (s *service) PerformTransaction(ctx context.Context, p structs.PaymePerform
 		s.logger.Error(ctx, "payme MarkPerformed failed", zap.Error(err))
 		return structs.PaymePerformResult{}, rpcErr(-32400, "Внутренняя ошибка", "Ichki xato", "Internal error", nil)
 	}
+
+	// almashtiruvchi chek: zakaz allaqachon to'langan va iiko'da, faqat kutishni tozalaymiz
+	if n, err := s.paymeRepo.CountOtherPerformed(ctx, tx.OrderID, p.Id); err == nil && n > 0 {
+		_ = s.paymeRepo.ClearRebill(ctx, tx.OrderID)
+		return structs.PaymePerformResult{
+			Transaction: updated.PaycomTransactionID,
+			State:       updated.State,
+			PerformTime: updated.PerformTime.Int64,
+		}, structs.RPCError{}
+	}
+
 	_ = s.orderRepo.UpdatePaymentStatus(ctx, structs.UpdateStatus{
 		OrderId: tx.OrderID, // UUID
 		Status:  "PAID",
@@ -405,7 +479,9 @@ func (s *service) CancelTransaction(ctx context.Context, p structs.PaymeCancelPa
 
 	if tx.State == paymerepo.StatePerformed {
 		newState = paymerepo.StateCanceledPerformed
-		shouldCancelOrder = true
+		// qisman qaytarishda eski (yoki rollback'da yangi) chek bekor qilinadi - zakaz boshqa chek bilan to'langan
+		n, err := s.paymeRepo.CountOtherPerformed(ctx, tx.OrderID, p.Id)
+		shouldCancelOrder = err != nil || n == 0
 	}
 
 	cancelAt := nowMs()
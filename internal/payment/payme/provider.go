@@ -0,0 +1,186 @@
+package payme
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sushitana/internal/payment/cards"
+	"sushitana/internal/payment/payme/subscribe"
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	paymerepo "sushitana/pkg/repository/postgres/payment_repo/payme_repo"
+
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ProviderModule - provider.Registry uchun
+var ProviderModule = provider.Register(NewProvider)
+
+type ProviderParams struct {
+	fx.In
+	Logger    logger.Logger
+	PaymeSvc  Service
+	PaymeRepo paymerepo.Repo
+	Cards     cards.Service
+}
+
+type paymeProvider struct {
+	logger    logger.Logger
+	paymeSvc  Service
+	paymeRepo paymerepo.Repo
+	cards     cards.Service
+}
+
+func NewProvider(p ProviderParams) provider.Provider {
+	return &paymeProvider{
+		logger:    p.Logger,
+		paymeSvc:  p.PaymeSvc,
+		paymeRepo: p.PaymeRepo,
+		cards:     p.Cards,
+	}
+}
+
+func kassaID() string {
+	return strings.TrimSpace(os.Getenv("PAYME_KASSA_ID"))
+}
+
+func (p *paymeProvider) Method() string   { return structs.PaymentMethodPayme }
+func (p *paymeProvider) Online() bool     { return true }
+func (p *paymeProvider) Configured() bool { return kassaID() != "" }
+
+// CreatePayment - checkout.paycom.uz havola; ac.order_id = zakaz raqami, summa tiyinda.
+// CardID berilsa avval saqlangan kartadan yechib ko'ramiz, o'tmasa odatdagi havola qaytadi
+func (p *paymeProvider) CreatePayment(ctx context.Context, order structs.PaymentOrder) (structs.PaymentIntent, error) {
+	merchantID := kassaID()
+	if merchantID == "" {
+		return structs.PaymentIntent{}, fmt.Errorf("PAYME_KASSA_ID env not found")
+	}
+
+	if order.CardID != 0 {
+		receipt, err := p.cards.Pay(ctx, order)
+		if err == nil && receipt.State == subscribe.ReceiptStatePaid {
+			// qisman qaytarishda shu kartadan qolgan summani qayta yechish uchun
+			if err := p.paymeRepo.SetCardID(ctx, receipt.ID, order.CardID); err != nil {
+				p.logger.Warn(ctx, "payme SetCardID failed", zap.String("receipt", receipt.ID), zap.Error(err))
+			}
+			return structs.PaymentIntent{Paid: true}, nil
+		}
+		p.logger.Warn(ctx, "saved card payment failed, fallback to checkout",
+			zap.String("orderId", order.OrderID), zap.Int("state", receipt.State), zap.Error(err))
+	}
+
+	payURL, err := p.paymeSvc.BuildPaymeCheckoutURL(merchantID, cast.ToString(order.OrderNumber), order.Amount*100)
+	if err != nil {
+		return structs.PaymentIntent{}, fmt.Errorf("build payme checkout url failed: %w", err)
+	}
+	return structs.PaymentIntent{URL: payURL}, nil
+}
+
+// Status - oxirgi payme_transactions yozuvi bo'yicha
+func (p *paymeProvider) Status(ctx context.Context, order structs.PaymentOrder) (structs.PaymentState, error) {
+	st := structs.PaymentState{Method: structs.PaymentMethodPayme, Amount: order.Amount}
+
+	tx, err := p.paymeRepo.GetLastByOrderID(ctx, order.OrderID)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			st.Status = structs.PaymentStatusPending
+			return st, nil
+		}
+		return st, err
+	}
+	st.ExternalID = tx.PaycomTransactionID
+	st.Amount = cast.ToInt64(strings.Split(tx.Amount, ".")[0]) // payme_transactions.amount so'mda
+
+	switch {
+	case tx.State == paymerepo.StatePerformed:
+		st.Status = structs.PaymentStatusPaid
+	case tx.State < 0:
+		st.Status = "CANCELLED"
+	default:
+		st.Status = structs.PaymentStatusPending
+	}
+	return st, nil
+}
+
+// Refund - Merchant API tranzaksiya ID'si = Payme chek ID'si, Subscribe receipts.cancel bilan qaytariladi
+// (CancelTransaction'ni Payme o'zi chaqiradi). Payme'da qisman qaytarish yo'q: saqlangan karta bilan
+// to'langan chek bo'lsa avval qolgan summaga o'sha kartadan yangi chek yechiladi, keyin eskisi bekor
+// qilinadi. Havola orqali to'langan chekni qisman qaytarib bo'lmaydi - ErrRefundNotSupported (MANUAL)
+func (p *paymeProvider) Refund(ctx context.Context, order structs.PaymentOrder, amount int64) error {
+	if !p.cards.Enabled() {
+		return structs.ErrRefundNotSupported
+	}
+	tx, err := p.paymeRepo.GetPerformedByOrderID(ctx, order.OrderID)
+	if err != nil {
+		return err
+	}
+
+	paid := cast.ToInt64(strings.Split(tx.Amount, ".")[0])
+	if amount == 0 || amount >= paid {
+		return p.cards.CancelReceipt(ctx, tx.PaycomTransactionID)
+	}
+	if !tx.CardID.Valid {
+		return structs.ErrRefundNotSupported
+	}
+
+	remaining := paid - amount
+	// Merchant API to'langan zakaz uchun faqat shu summadagi yangi chekni qabul qiladi
+	if err := p.paymeRepo.SetRebill(ctx, tx.PaycomTransactionID, remaining); err != nil {
+		return err
+	}
+	receipt, err := p.cards.Charge(ctx, order.TgID, tx.CardID.Int64, remaining, cast.ToString(order.OrderNumber))
+	if err == nil && receipt.State != subscribe.ReceiptStatePaid {
+		err = fmt.Errorf("payme receipt %s state %d", receipt.ID, receipt.State)
+	}
+	if err != nil {
+		_ = p.paymeRepo.SetRebill(ctx, tx.PaycomTransactionID, 0)
+		return err
+	}
+	if err := p.paymeRepo.SetCardID(ctx, receipt.ID, tx.CardID.Int64); err != nil {
+		p.logger.Warn(ctx, "payme SetCardID failed", zap.String("receipt", receipt.ID), zap.Error(err))
+	}
+
+	if err := p.cards.CancelReceipt(ctx, tx.PaycomTransactionID); err != nil {
+		// eski chek qoldi - yangisini qaytaramiz, mijoz ikki marta to'lamasin
+		if cerr := p.cards.CancelReceipt(ctx, receipt.ID); cerr != nil {
+			p.logger.Error(ctx, "payme rebill rollback failed", zap.String("receipt", receipt.ID), zap.Error(cerr))
+		}
+		return err
+	}
+	return nil
+}
+
+// ParseCallback - Merchant API JSON-RPC so'rovi
+func (p *paymeProvider) ParseCallback(_ context.Context, body []byte) (structs.PaymentCallback, error) {
+	var req struct {
+		Method string `json:"method"`
+		Params struct {
+			ID      string          `json:"id"`
+			Amount  int64           `json:"amount"`
+			Account structs.Account `json:"account"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return structs.PaymentCallback{}, err
+	}
+	if req.Method == "" {
+		return structs.PaymentCallback{}, structs.ErrBadRequest
+	}
+
+	orderRef, _ := parseOrderID(req.Params.Account)
+	return structs.PaymentCallback{
+		Method:     structs.PaymentMethodPayme,
+		Action:     req.Method,
+		OrderRef:   orderRef,
+		ExternalID: req.Params.ID,
+		Amount:     req.Params.Amount / 100,
+		Success:    true,
+	}, nil
+}
@@ -0,0 +1,156 @@
+package payme
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"sushitana/internal/payment/cards"
+	"sushitana/internal/payment/payme/subscribe"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	paymerepo "sushitana/pkg/repository/postgres/payment_repo/payme_repo"
+)
+
+// fakeTxRepo - Refund ishlatadigan payme_transactions metodlari
+type fakeTxRepo struct {
+	paymerepo.Repo
+	performed structs.PaymeTransaction
+	rebill    map[string]int64
+	cardIDs   map[string]int64
+}
+
+func (r *fakeTxRepo) GetPerformedByOrderID(context.Context, string) (structs.PaymeTransaction, error) {
+	if r.performed.PaycomTransactionID == "" {
+		return structs.PaymeTransaction{}, structs.ErrNotFound
+	}
+	return r.performed, nil
+}
+
+func (r *fakeTxRepo) SetRebill(_ context.Context, id string, amount int64) error {
+	r.rebill[id] = amount
+	return nil
+}
+
+func (r *fakeTxRepo) SetCardID(_ context.Context, id string, cardID int64) error {
+	r.cardIDs[id] = cardID
+	return nil
+}
+
+// fakeCards - Subscribe chaqiruvlarini yozib boradi
+type fakeCards struct {
+	cards.Service
+	charged    []int64
+	cancelled  []string
+	chargeErr  error
+	cancelErrs map[string]error
+}
+
+func (c *fakeCards) Enabled() bool { return true }
+
+func (c *fakeCards) Charge(_ context.Context, _, _, amount int64, _ string) (structs.PaymeReceipt, error) {
+	if c.chargeErr != nil {
+		return structs.PaymeReceipt{}, c.chargeErr
+	}
+	c.charged = append(c.charged, amount)
+	return structs.PaymeReceipt{ID: "new-receipt", State: subscribe.ReceiptStatePaid}, nil
+}
+
+func (c *fakeCards) CancelReceipt(_ context.Context, id string) error {
+	if err := c.cancelErrs[id]; err != nil {
+		return err
+	}
+	c.cancelled = append(c.cancelled, id)
+	return nil
+}
+
+func newRefundProvider(tx structs.PaymeTransaction) (*paymeProvider, *fakeTxRepo, *fakeCards) {
+	repo := &fakeTxRepo{performed: tx, rebill: map[string]int64{}, cardIDs: map[string]int64{}}
+	fc := &fakeCards{cancelErrs: map[string]error{}}
+	return &paymeProvider{logger: logger.New("error"), paymeRepo: repo, cards: fc}, repo, fc
+}
+
+var refundOrder = structs.PaymentOrder{OrderID: "order-1", OrderNumber: 1042, TgID: 777000}
+
+func TestRefundFullCancelsReceipt(t *testing.T) {
+	p, _, fc := newRefundProvider(structs.PaymeTransaction{PaycomTransactionID: "old-receipt", Amount: "85000.00"})
+
+	if err := p.Refund(context.Background(), refundOrder, 85000); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if len(fc.cancelled) != 1 || fc.cancelled[0] != "old-receipt" || len(fc.charged) != 0 {
+		t.Fatalf("cancelled = %v, charged = %v", fc.cancelled, fc.charged)
+	}
+}
+
+func TestRefundPartialRebillsSavedCard(t *testing.T) {
+	p, repo, fc := newRefundProvider(structs.PaymeTransaction{
+		PaycomTransactionID: "old-receipt",
+		Amount:              "85000.00",
+		CardID:              sql.NullInt64{Int64: 7, Valid: true},
+	})
+
+	if err := p.Refund(context.Background(), refundOrder, 25000); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if len(fc.charged) != 1 || fc.charged[0] != 60000 {
+		t.Fatalf("charged = %v, want [60000]", fc.charged)
+	}
+	if len(fc.cancelled) != 1 || fc.cancelled[0] != "old-receipt" {
+		t.Fatalf("cancelled = %v, want [old-receipt]", fc.cancelled)
+	}
+	if repo.rebill["old-receipt"] != 60000 {
+		t.Fatalf("rebill = %v", repo.rebill)
+	}
+	if repo.cardIDs["new-receipt"] != 7 {
+		t.Fatalf("card not recorded on new receipt: %v", repo.cardIDs)
+	}
+}
+
+func TestRefundPartialWithoutCardIsManual(t *testing.T) {
+	p, _, fc := newRefundProvider(structs.PaymeTransaction{PaycomTransactionID: "old-receipt", Amount: "85000.00"})
+
+	err := p.Refund(context.Background(), refundOrder, 25000)
+	if !errors.Is(err, structs.ErrRefundNotSupported) {
+		t.Fatalf("err = %v, want ErrRefundNotSupported", err)
+	}
+	if len(fc.charged) != 0 || len(fc.cancelled) != 0 {
+		t.Fatalf("provider touched: charged = %v, cancelled = %v", fc.charged, fc.cancelled)
+	}
+}
+
+func TestRefundPartialChargeFailedKeepsOldReceipt(t *testing.T) {
+	p, repo, fc := newRefundProvider(structs.PaymeTransaction{
+		PaycomTransactionID: "old-receipt",
+		Amount:              "85000.00",
+		CardID:              sql.NullInt64{Int64: 7, Valid: true},
+	})
+	fc.chargeErr = errors.New("declined")
+
+	if err := p.Refund(context.Background(), refundOrder, 25000); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fc.cancelled) != 0 {
+		t.Fatalf("old receipt cancelled without replacement: %v", fc.cancelled)
+	}
+	if repo.rebill["old-receipt"] != 0 {
+		t.Fatalf("rebill not cleared: %v", repo.rebill)
+	}
+}
+
+func TestRefundPartialCancelFailedRollsBack(t *testing.T) {
+	p, _, fc := newRefundProvider(structs.PaymeTransaction{
+		PaycomTransactionID: "old-receipt",
+		Amount:              "85000.00",
+		CardID:              sql.NullInt64{Int64: 7, Valid: true},
+	})
+	fc.cancelErrs["old-receipt"] = errors.New("payme receipts.cancel failed")
+
+	if err := p.Refund(context.Background(), refundOrder, 25000); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fc.cancelled) != 1 || fc.cancelled[0] != "new-receipt" {
+		t.Fatalf("cancelled = %v, want new receipt rolled back", fc.cancelled)
+	}
+}
@@ -0,0 +1,35 @@
+package payme
+
+import (
+	"context"
+	"errors"
+
+	"sushitana/internal/structs"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func (s *service) GetTransactions(ctx context.Context, req structs.GetListPaymeTransactionRequest) (structs.GetListPaymeTransactionResponse, error) {
+	resp, err := s.paymeRepo.GetList(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->paymeRepo.GetList", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) GetTransaction(ctx context.Context, id string) (structs.PaymeTransactionRecord, error) {
+	// id - bizdagi UUID, Payme'ning paycom_transaction_id emas
+	if uuid.Validate(id) != nil {
+		return structs.PaymeTransactionRecord{}, structs.ErrNotFound
+	}
+	tx, err := s.paymeRepo.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->paymeRepo.GetByID", zap.Error(err))
+		}
+		return tx, err
+	}
+	return tx, nil
+}
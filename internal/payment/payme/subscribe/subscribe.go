@@ -0,0 +1,190 @@
+package subscribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+const (
+	defaultBaseURL = "https://checkout.paycom.uz/api"
+	// receipts.pay: 4 - to'langan
+	ReceiptStatePaid = 4
+)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+	}
+
+	// Client - Payme Subscribe API (karta tokenlash va chek orqali to'lash).
+	// PAYME_SUBSCRIBE_URL bilan test (checkout.test.paycom.uz) yoki lokal fake serverga yo'naltiriladi.
+	Client interface {
+		Configured() bool
+
+		// CardsCheck - front-end cards.create bergan token bo'yicha niqoblangan raqam va holat.
+		// Karta raqami (PAN) backend'dan o'tmaydi: cards.create faqat Mini App'da chaqiriladi
+		CardsCheck(ctx context.Context, token string) (structs.PaymeCard, error)
+		CardsGetVerifyCode(ctx context.Context, token string) (structs.PaymeVerifyCode, error)
+		CardsVerify(ctx context.Context, token, code string) (structs.PaymeCard, error)
+		CardsRemove(ctx context.Context, token string) error
+
+		// ReceiptsCreate - amount tiyinda, account.order_id = zakaz raqami (Merchant API bilan bir xil)
+		ReceiptsCreate(ctx context.Context, amount int64, orderRef string) (structs.PaymeReceipt, error)
+		ReceiptsPay(ctx context.Context, receiptID, token string) (structs.PaymeReceipt, error)
+		ReceiptsCancel(ctx context.Context, receiptID string) error
+	}
+
+	client struct {
+		logger logger.Logger
+		http   *http.Client
+		seq    atomic.Int64
+	}
+)
+
+func New(p Params) Client {
+	return &client{
+		logger: p.Logger,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func baseURL() string {
+	if u := strings.TrimSpace(os.Getenv("PAYME_SUBSCRIBE_URL")); u != "" {
+		return u
+	}
+	return defaultBaseURL
+}
+
+func merchantID() string { return strings.TrimSpace(os.Getenv("PAYME_KASSA_ID")) }
+func secretKey() string  { return strings.TrimSpace(os.Getenv("PAYME_SECRET_KEY")) }
+
+func (c *client) Configured() bool {
+	return merchantID() != "" && secretKey() != ""
+}
+
+// call - JSON-RPC. cards.get_verify_code/verify front-end metodlari: X-Auth = merchant_id,
+// qolganlari (cards.check, cards.remove, receipts.*): X-Auth = merchant_id:key
+func (c *client) call(ctx context.Context, method string, params any, withKey bool, out any) error {
+	auth := merchantID()
+	if auth == "" {
+		return errors.New("PAYME_KASSA_ID env not found")
+	}
+	if withKey {
+		if secretKey() == "" {
+			return errors.New("PAYME_SECRET_KEY env not found")
+		}
+		auth += ":" + secretKey()
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"id":     c.seq.Add(1),
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Auth", auth)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.logger.Error(ctx, "payme subscribe request failed", zap.String("method", method), zap.Error(err))
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("payme subscribe %s http=%d", method, resp.StatusCode)
+	}
+
+	var rpc struct {
+		Result json.RawMessage              `json:"result"`
+		Error  *structs.PaymeSubscribeError `json:"error"`
+	}
+	if err := json.Unmarshal(b, &rpc); err != nil {
+		return fmt.Errorf("payme subscribe %s decode failed: %w", method, err)
+	}
+	if rpc.Error != nil {
+		c.logger.Warn(ctx, "payme subscribe error", zap.String("method", method), zap.Int("code", rpc.Error.Code))
+		return rpc.Error
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(rpc.Result, out)
+}
+
+func (c *client) CardsCheck(ctx context.Context, token string) (structs.PaymeCard, error) {
+	var out struct {
+		Card structs.PaymeCard `json:"card"`
+	}
+	err := c.call(ctx, "cards.check", map[string]string{"token": token}, true, &out)
+	return out.Card, err
+}
+
+func (c *client) CardsGetVerifyCode(ctx context.Context, token string) (structs.PaymeVerifyCode, error) {
+	var out structs.PaymeVerifyCode
+	err := c.call(ctx, "cards.get_verify_code", map[string]string{"token": token}, false, &out)
+	return out, err
+}
+
+func (c *client) CardsVerify(ctx context.Context, token, code string) (structs.PaymeCard, error) {
+	var out struct {
+		Card structs.PaymeCard `json:"card"`
+	}
+	err := c.call(ctx, "cards.verify", map[string]string{"token": token, "code": code}, false, &out)
+	return out.Card, err
+}
+
+func (c *client) CardsRemove(ctx context.Context, token string) error {
+	return c.call(ctx, "cards.remove", map[string]string{"token": token}, true, nil)
+}
+
+func (c *client) ReceiptsCreate(ctx context.Context, amount int64, orderRef string) (structs.PaymeReceipt, error) {
+	var out struct {
+		Receipt structs.PaymeReceipt `json:"receipt"`
+	}
+	params := map[string]any{
+		"amount":  amount,
+		"account": map[string]string{"order_id": orderRef},
+	}
+	err := c.call(ctx, "receipts.create", params, true, &out)
+	return out.Receipt, err
+}
+
+func (c *client) ReceiptsPay(ctx context.Context, receiptID, token string) (structs.PaymeReceipt, error) {
+	var out struct {
+		Receipt structs.PaymeReceipt `json:"receipt"`
+	}
+	err := c.call(ctx, "receipts.pay", map[string]string{"id": receiptID, "token": token}, true, &out)
+	return out.Receipt, err
+}
+
+func (c *client) ReceiptsCancel(ctx context.Context, receiptID string) error {
+	return c.call(ctx, "receipts.cancel", map[string]string{"id": receiptID}, true, nil)
+}
@@ -0,0 +1,326 @@
+// Package subscribetest - testlar uchun lokal fake Payme Subscribe API (JSON-RPC).
+// PAYME_SUBSCRIBE_URL'ni Server.URL'ga yo'naltirib subscribe.Client'ni tarmoqsiz tekshirish mumkin.
+package subscribetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+const (
+	MerchantID = "test-kassa"
+	SecretKey  = "test-key"
+	// VerifyCode - cards.verify faqat shu kodni qabul qiladi
+	VerifyCode = "666666"
+
+	ReceiptStateCreated   = 0
+	ReceiptStatePaid      = 4
+	ReceiptStateCancelled = 50
+)
+
+type Card struct {
+	Number    string
+	Expire    string
+	Verified  bool
+	Recurrent bool
+	// Declined - receipts.pay shu karta bilan -31630 qaytaradi (mablag' yetarli emas)
+	Declined bool
+}
+
+type Receipt struct {
+	ID      string
+	Amount  int64
+	OrderID string
+	State   int
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	cards    map[string]*Card
+	receipts map[string]*Receipt
+	calls    []string
+	seq      int
+}
+
+func NewServer() *Server {
+	s := &Server{
+		cards:    map[string]*Card{},
+		receipts: map[string]*Receipt{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Calls - kelgan metodlar tartibi bilan
+func (s *Server) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *Server) Card(token string) (Card, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.cards[token]
+	if !ok {
+		return Card{}, false
+	}
+	return *c, true
+}
+
+func (s *Server) Receipt(id string) (Receipt, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.receipts[id]
+	if !ok {
+		return Receipt{}, false
+	}
+	return *r, true
+}
+
+// Receipts - barcha cheklar
+func (s *Server) Receipts() []Receipt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Receipt, 0, len(s.receipts))
+	for i := 1; i <= s.seq; i++ {
+		if r, ok := s.receipts[fmt.Sprintf("receipt-%d", i)]; ok {
+			out = append(out, *r)
+		}
+	}
+	return out
+}
+
+// AddReceipt - oldindan to'langan chek (masalan, refund testlari uchun)
+func (s *Server) AddReceipt(amount int64, orderID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	id := fmt.Sprintf("receipt-%d", s.seq)
+	s.receipts[id] = &Receipt{ID: id, Amount: amount, OrderID: orderID, State: ReceiptStatePaid}
+	return id
+}
+
+// SetDeclined - karta bilan keyingi to'lovlar rad etiladi
+func (s *Server) SetDeclined(token string, declined bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.cards[token]; ok {
+		c.Declined = declined
+	}
+}
+
+type rpcRequest struct {
+	ID     int64           `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// front-end metodlar faqat merchant_id bilan, qolganlari merchant_id:key bilan
+var frontendMethods = map[string]bool{
+	"cards.create":          true,
+	"cards.get_verify_code": true,
+	"cards.verify":          true,
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, req.Method)
+	s.mu.Unlock()
+
+	want := MerchantID
+	if !frontendMethods[req.Method] {
+		want += ":" + SecretKey
+	}
+	if r.Header.Get("X-Auth") != want {
+		writeRPC(w, req.ID, nil, &rpcError{Code: -32504, Message: "Недостаточно привилегий"})
+		return
+	}
+
+	result, rpcErr := s.dispatch(req)
+	writeRPC(w, req.ID, result, rpcErr)
+}
+
+func writeRPC(w http.ResponseWriter, id int64, result any, rpcErr *rpcError) {
+	resp := map[string]any{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		resp["error"] = rpcErr
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func maskNumber(number string) string {
+	if len(number) < 10 {
+		return number
+	}
+	return number[:6] + strings.Repeat("*", len(number)-10) + number[len(number)-4:]
+}
+
+func cardJSON(token string, c *Card) map[string]any {
+	return map[string]any{
+		"number":    maskNumber(c.Number),
+		"expire":    c.Expire[:2] + "/" + c.Expire[2:],
+		"token":     token,
+		"recurrent": c.Recurrent,
+		"verify":    c.Verified,
+	}
+}
+
+func receiptJSON(r *Receipt) map[string]any {
+	return map[string]any{"_id": r.ID, "state": r.State, "amount": r.Amount}
+}
+
+func (s *Server) dispatch(req rpcRequest) (any, *rpcError) {
+	var p struct {
+		Card *struct {
+			Number string `json:"number"`
+			Expire string `json:"expire"`
+		} `json:"card"`
+		Token   string            `json:"token"`
+		Code    string            `json:"code"`
+		ID      string            `json:"id"`
+		Amount  int64             `json:"amount"`
+		Account map[string]string `json:"account"`
+	}
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		return nil, &rpcError{Code: -32602, Message: "Неверные параметры"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Method {
+	case "cards.create":
+		if p.Card == nil || len(p.Card.Number) != 16 || len(p.Card.Expire) != 4 {
+			return nil, &rpcError{Code: -31300, Message: "Неверный номер карты"}
+		}
+		s.seq++
+		token := fmt.Sprintf("token-%d", s.seq)
+		s.cards[token] = &Card{Number: p.Card.Number, Expire: p.Card.Expire, Recurrent: true}
+		return map[string]any{"card": cardJSON(token, s.cards[token])}, nil
+
+	case "cards.check":
+		c, ok := s.cards[p.Token]
+		if !ok {
+			return nil, &rpcError{Code: -31400, Message: "Карта не найдена"}
+		}
+		return map[string]any{"card": cardJSON(p.Token, c)}, nil
+
+	case "cards.get_verify_code":
+		if _, ok := s.cards[p.Token]; !ok {
+			return nil, &rpcError{Code: -31400, Message: "Карта не найдена"}
+		}
+		return map[string]any{"sent": true, "phone": "99890*****31", "wait": 60000}, nil
+
+	case "cards.verify":
+		c, ok := s.cards[p.Token]
+		if !ok {
+			return nil, &rpcError{Code: -31400, Message: "Карта не найдена"}
+		}
+		if p.Code != VerifyCode {
+			return nil, &rpcError{Code: -31103, Message: "Неверный код"}
+		}
+		c.Verified = true
+		return map[string]any{"card": cardJSON(p.Token, c)}, nil
+
+	case "cards.remove":
+		delete(s.cards, p.Token)
+		return map[string]any{"success": true}, nil
+
+	case "receipts.create":
+		if p.Amount <= 0 {
+			return nil, &rpcError{Code: -31611, Message: "Неверная сумма"}
+		}
+		s.seq++
+		id := fmt.Sprintf("receipt-%d", s.seq)
+		s.receipts[id] = &Receipt{ID: id, Amount: p.Amount, OrderID: p.Account["order_id"], State: ReceiptStateCreated}
+		return map[string]any{"receipt": receiptJSON(s.receipts[id])}, nil
+
+	case "receipts.pay":
+		r, ok := s.receipts[p.ID]
+		if !ok {
+			return nil, &rpcError{Code: -31602, Message: "Чек не найден"}
+		}
+		c, ok := s.cards[p.Token]
+		if !ok || !c.Verified {
+			return nil, &rpcError{Code: -31400, Message: "Карта не найдена"}
+		}
+		if c.Declined {
+			return nil, &rpcError{Code: -31630, Message: "Недостаточно средств"}
+		}
+		if r.State != ReceiptStateCreated {
+			return nil, &rpcError{Code: -31601, Message: "Чек уже обработан"}
+		}
+		r.State = ReceiptStatePaid
+		return map[string]any{"receipt": receiptJSON(r)}, nil
+
+	case "receipts.cancel":
+		r, ok := s.receipts[p.ID]
+		if !ok {
+			return nil, &rpcError{Code: -31602, Message: "Чек не найден"}
+		}
+		r.State = ReceiptStateCancelled
+		return map[string]any{"receipt": receiptJSON(r)}, nil
+	}
+	return nil, &rpcError{Code: -32601, Message: "Метод не найден"}
+}
+
+// CreateCard - Mini App'dagi kabi cards.create'ni front-end kalit bilan chaqiradi va token qaytaradi
+func (s *Server) CreateCard(number, expire string) (string, error) {
+	body, _ := json.Marshal(map[string]any{
+		"id":     1,
+		"method": "cards.create",
+		"params": map[string]any{
+			"card": map[string]string{"number": number, "expire": expire},
+			"save": true,
+		},
+	})
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Auth", MerchantID)
+
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Result struct {
+			Card struct {
+				Token string `json:"token"`
+			} `json:"card"`
+		} `json:"result"`
+		Error *rpcError `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if out.Error != nil {
+		return "", fmt.Errorf("cards.create: %d %s", out.Error.Code, out.Error.Message)
+	}
+	return out.Result.Card.Token, nil
+}
@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+// Provider - bitta to'lov usuli (orders.payment_method). Yangi usul = yangi Provider + Register,
+// zakaz yaratish kodiga tegilmaydi.
+type Provider interface {
+	// Method - CASH, CLICK, PAYME, TELEGRAM ...
+	Method() string
+	// Online - true bo'lsa zakaz PAID bo'lmaguncha WAITING_PAYMENT'da turadi (operator/iiko'ga chiqmaydi)
+	Online() bool
+	// Configured - kalitlar sozlanmagan bo'lsa mijozga ko'rsatilmaydi
+	Configured() bool
+
+	CreatePayment(ctx context.Context, order structs.PaymentOrder) (structs.PaymentIntent, error)
+	Status(ctx context.Context, order structs.PaymentOrder) (structs.PaymentState, error)
+	// Refund - amount so'mda, 0 = to'liq
+	Refund(ctx context.Context, order structs.PaymentOrder, amount int64) error
+	ParseCallback(ctx context.Context, body []byte) (structs.PaymentCallback, error)
+}
+
+// Register - provider konstruktorini "payment_providers" guruhiga qo'shadi:
+//
+//	var Module = fx.Options(fx.Provide(New), provider.Register(NewProvider))
+func Register(ctor any) fx.Option {
+	return fx.Provide(fx.Annotate(ctor, fx.ResultTags(`group:"payment_providers"`)))
+}
+
+// filial kalitlari: payment_methods_<branch> config
+const (
+	BranchOlmaliq   = "olmaliq"
+	BranchOhangaron = "ohangaron"
+	BranchPickup    = "pickup"
+)
+
+// BranchByZone - ZoneChecker indeksi (0 olmaliq.json, 1 ohongoron.json)
+func BranchByZone(idx int) string {
+	if idx == 1 {
+		return BranchOhangaron
+	}
+	return BranchOlmaliq
+}
+
+// bot klaviaturasidagi tartib; ro'yxatda yo'qlar oxirida alifbo bo'yicha
+var defaultOrder = []string{
+	structs.PaymentMethodPayme,
+	structs.PaymentMethodClick,
+	structs.PaymentMethodUzum,
+	structs.PaymentMethodTelegram,
+	structs.PaymentMethodCash,
+}
+
+type (
+	Params struct {
+		fx.In
+
+		Config    config.IConfig
+		Logger    logger.Logger
+		Providers []Provider `group:"payment_providers"`
+	}
+
+	Registry interface {
+		// Get - method normalize qilinadi ("click" -> CLICK); noma'lum -> ErrBadRequest
+		Get(method string) (Provider, error)
+		// Online - noma'lum method uchun false
+		Online(method string) bool
+		// Enabled - filialda yoqilgan usullar (payment_methods_<branch>, bo'lmasa payment_methods, bo'lmasa hammasi)
+		Enabled(branch string) []string
+		IsEnabled(branch, method string) bool
+	}
+
+	registry struct {
+		logger    logger.Logger
+		cfg       config.IConfig
+		providers map[string]Provider
+		ordered   []string
+	}
+)
+
+func New(p Params) Registry {
+	r := &registry{
+		logger:    p.Logger,
+		cfg:       p.Config,
+		providers: make(map[string]Provider, len(p.Providers)),
+	}
+	for _, pr := range p.Providers {
+		r.providers[strings.ToUpper(pr.Method())] = pr
+	}
+
+	for _, m := range defaultOrder {
+		if _, ok := r.providers[m]; ok {
+			r.ordered = append(r.ordered, m)
+		}
+	}
+	var rest []string
+	for m := range r.providers {
+		if !contains(defaultOrder, m) {
+			rest = append(rest, m)
+		}
+	}
+	sort.Strings(rest)
+	r.ordered = append(r.ordered, rest...)
+	return r
+}
+
+func (r *registry) Get(method string) (Provider, error) {
+	p, ok := r.providers[strings.ToUpper(strings.TrimSpace(method))]
+	if !ok {
+		return nil, structs.ErrBadRequest
+	}
+	return p, nil
+}
+
+func (r *registry) Online(method string) bool {
+	p, err := r.Get(method)
+	return err == nil && p.Online()
+}
+
+func (r *registry) Enabled(branch string) []string {
+	allowed := r.configured(branch)
+
+	list := make([]string, 0, len(r.ordered))
+	for _, m := range r.ordered {
+		if allowed != nil && !contains(allowed, m) {
+			continue
+		}
+		if !r.providers[m].Configured() {
+			continue
+		}
+		list = append(list, m)
+	}
+	return list
+}
+
+func (r *registry) IsEnabled(branch, method string) bool {
+	return contains(r.Enabled(branch), strings.ToUpper(strings.TrimSpace(method)))
+}
+
+// configured - nil: cheklov yo'q
+func (r *registry) configured(branch string) []string {
+	raw := ""
+	if b := strings.ToLower(strings.TrimSpace(branch)); b != "" {
+		raw = r.cfg.GetString("payment_methods_" + b)
+	}
+	if strings.TrimSpace(raw) == "" {
+		raw = r.cfg.GetString("payment_methods")
+	}
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	var list []string
+	for _, m := range strings.Split(raw, ",") {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		if m == "" {
+			continue
+		}
+		if _, ok := r.providers[m]; !ok {
+			r.logger.Warn(context.Background(), "unknown payment method in config", zap.String("method", m), zap.String("branch", branch))
+			continue
+		}
+		list = append(list, m)
+	}
+	return list
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package shopapi
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
@@ -38,6 +39,9 @@ type Service interface {
 	PaymentStatus(ctx context.Context, serviceID int64, paymentID int64) (structs.PaymentStatusResponse, error)
 	PaymentStatusByMTI(ctx context.Context, serviceID int64, merchantTransID string, paymentDate time.Time) (structs.StatusByMTIResponse, error)
 	PaymentReversal(ctx context.Context, serviceID int64, paymentID int64) (structs.ReversalResponse, error)
+	// PaymentPartialReversal - amount so'mda, to'lovning bir qismini qaytaradi
+	PaymentPartialReversal(ctx context.Context, serviceID int64, paymentID int64, amount int64) (structs.ReversalResponse, error)
+	SubmitFiscalItems(ctx context.Context, req structs.ClickSubmitItemsRequest) (structs.ClickSubmitItemsResponse, error)
 }
 
 type service struct {
@@ -141,7 +145,20 @@ func (s *service) authHeader(now time.Time) (string, error) {
 }
 
 func (s *service) doJSON(ctx context.Context, method, fullURL string, out any) error {
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
+	return s.doJSONBody(ctx, method, fullURL, nil, out)
+}
+
+func (s *service) doJSONBody(ctx context.Context, method, fullURL string, in any, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
 	if err != nil {
 		return err
 	}
@@ -194,3 +211,18 @@ func (s *service) PaymentReversal(ctx context.Context, serviceID int64, paymentI
 	err := s.doJSON(ctx, http.MethodDelete, u, &out)
 	return out, err
 }
+
+func (s *service) PaymentPartialReversal(ctx context.Context, serviceID int64, paymentID int64, amount int64) (structs.ReversalResponse, error) {
+	var out structs.ReversalResponse
+	u := fmt.Sprintf("%s/payment/partial_reversal/%d/%d/%d", merchantBaseURL, serviceID, paymentID, amount)
+	err := s.doJSON(ctx, http.MethodDelete, u, &out)
+	return out, err
+}
+
+// SubmitFiscalItems - to'lovdan keyin fiskal chek qatorlari (OFD)
+func (s *service) SubmitFiscalItems(ctx context.Context, req structs.ClickSubmitItemsRequest) (structs.ClickSubmitItemsResponse, error) {
+	var out structs.ClickSubmitItemsResponse
+	u := merchantBaseURL + "/payment/ofd_data/submit_items"
+	err := s.doJSONBody(ctx, http.MethodPost, u, req, &out)
+	return out, err
+}
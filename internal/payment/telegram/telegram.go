@@ -0,0 +1,89 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/structs"
+	telegramrepo "sushitana/pkg/repository/postgres/payment_repo/telegram_repo"
+
+	"go.uber.org/fx"
+)
+
+// Telegram Payments: invoice'ni bot yuboradi, successful_payment'ni bot ushlaydi
+var Module = provider.Register(NewProvider)
+
+type ProviderParams struct {
+	fx.In
+	TgPayRepo telegramrepo.Repo
+}
+
+type telegramProvider struct {
+	tgPayRepo telegramrepo.Repo
+}
+
+func NewProvider(p ProviderParams) provider.Provider {
+	return &telegramProvider{tgPayRepo: p.TgPayRepo}
+}
+
+func (p *telegramProvider) Method() string { return structs.PaymentMethodTelegram }
+func (p *telegramProvider) Online() bool   { return true }
+
+// Configured - BotFather provider token bo'lmasa Telegram to'lov o'chiq
+func (p *telegramProvider) Configured() bool {
+	return strings.TrimSpace(os.Getenv("TELEGRAM_PAYMENT_PROVIDER_TOKEN")) != ""
+}
+
+// CreatePayment - havola yo'q, bot sendInvoice qiladi
+func (p *telegramProvider) CreatePayment(context.Context, structs.PaymentOrder) (structs.PaymentIntent, error) {
+	return structs.PaymentIntent{}, nil
+}
+
+func (p *telegramProvider) Status(ctx context.Context, order structs.PaymentOrder) (structs.PaymentState, error) {
+	st := structs.PaymentState{Method: structs.PaymentMethodTelegram, Amount: order.Amount}
+
+	pay, err := p.tgPayRepo.GetByOrderID(ctx, order.OrderID)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			st.Status = structs.PaymentStatusPending
+			return st, nil
+		}
+		return st, err
+	}
+	st.Status = structs.PaymentStatusPaid
+	st.ExternalID = pay.TelegramPaymentChargeID
+	st.Amount = pay.TotalAmount / 100
+	return st, nil
+}
+
+// Refund - Bot API'da faqat Stars uchun refund bor, karta to'lovi provider kabinetidan qaytariladi
+func (p *telegramProvider) Refund(context.Context, structs.PaymentOrder, int64) error {
+	return structs.ErrRefundNotSupported
+}
+
+// ParseCallback - successful_payment obyekti (invoice_payload = orders.id)
+func (p *telegramProvider) ParseCallback(_ context.Context, body []byte) (structs.PaymentCallback, error) {
+	var sp struct {
+		InvoicePayload          string `json:"invoice_payload"`
+		TotalAmount             int64  `json:"total_amount"`
+		TelegramPaymentChargeID string `json:"telegram_payment_charge_id"`
+	}
+	if err := json.Unmarshal(body, &sp); err != nil {
+		return structs.PaymentCallback{}, err
+	}
+	if sp.InvoicePayload == "" || sp.TelegramPaymentChargeID == "" {
+		return structs.PaymentCallback{}, structs.ErrBadRequest
+	}
+	return structs.PaymentCallback{
+		Method:     structs.PaymentMethodTelegram,
+		Action:     "successful_payment",
+		OrderRef:   sp.InvoicePayload,
+		ExternalID: sp.TelegramPaymentChargeID,
+		Amount:     sp.TotalAmount / 100,
+		Success:    true,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package uzum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"sushitana/internal/payment/provider"
+	"sushitana/internal/structs"
+	uzumrepo "sushitana/pkg/repository/postgres/payment_repo/uzum_repo"
+
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+)
+
+// ProviderModule - provider.Registry uchun
+var ProviderModule = provider.Register(NewProvider)
+
+type ProviderParams struct {
+	fx.In
+	UzumSvc  Service
+	UzumRepo uzumrepo.Repo
+}
+
+type uzumProvider struct {
+	uzumSvc  Service
+	uzumRepo uzumrepo.Repo
+}
+
+func NewProvider(p ProviderParams) provider.Provider {
+	return &uzumProvider{
+		uzumSvc:  p.UzumSvc,
+		uzumRepo: p.UzumRepo,
+	}
+}
+
+func (p *uzumProvider) Method() string   { return structs.PaymentMethodUzum }
+func (p *uzumProvider) Online() bool     { return true }
+func (p *uzumProvider) Configured() bool { return ServiceID() != 0 }
+
+func (p *uzumProvider) CreatePayment(_ context.Context, order structs.PaymentOrder) (structs.PaymentIntent, error) {
+	serviceID := ServiceID()
+	if serviceID == 0 {
+		return structs.PaymentIntent{}, fmt.Errorf("UZUM_SERVICE_ID env not found")
+	}
+	payURL := p.uzumSvc.BuildCheckoutURL(cast.ToString(serviceID), cast.ToString(order.OrderNumber), order.Amount*100)
+	return structs.PaymentIntent{URL: payURL}, nil
+}
+
+func (p *uzumProvider) Status(ctx context.Context, order structs.PaymentOrder) (structs.PaymentState, error) {
+	st := structs.PaymentState{Method: structs.PaymentMethodUzum, Amount: order.Amount}
+
+	tx, err := p.uzumRepo.GetLastByOrderID(ctx, order.OrderID)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			st.Status = structs.PaymentStatusPending
+			return st, nil
+		}
+		return st, err
+	}
+	st.ExternalID = tx.TransID
+	st.Amount = tx.Amount / 100
+
+	switch tx.Status {
+	case structs.UzumStatusConfirmed:
+		st.Status = structs.PaymentStatusPaid
+	case structs.UzumStatusReversed:
+		st.Status = "CANCELLED"
+	default:
+		st.Status = structs.PaymentStatusPending
+	}
+	return st, nil
+}
+
+// Refund - reverse'ni Uzum o'zi chaqiradi (kabinet orqali), merchant tomondan API yo'q
+func (p *uzumProvider) Refund(context.Context, structs.PaymentOrder, int64) error {
+	return structs.ErrRefundNotSupported
+}
+
+// ParseCallback - check/create/confirm/reverse/status body'si (action'ni handler URL'dan biladi)
+func (p *uzumProvider) ParseCallback(_ context.Context, body []byte) (structs.PaymentCallback, error) {
+	var req structs.UzumRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return structs.PaymentCallback{}, err
+	}
+	if req.ServiceID == 0 {
+		return structs.PaymentCallback{}, structs.ErrBadRequest
+	}
+
+	action := "check"
+	switch {
+	case req.PaymentSource != "":
+		action = "confirm"
+	case req.Amount != 0 && req.TransID != "":
+		action = "create"
+	case req.TransID != "":
+		action = "status"
+	}
+	return structs.PaymentCallback{
+		Method:     structs.PaymentMethodUzum,
+		Action:     action,
+		OrderRef:   req.Params[accountParam],
+		ExternalID: req.TransID,
+		Amount:     req.Amount / 100,
+		Success:    true,
+	}, nil
+}
@@ -0,0 +1,334 @@
+package uzum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"sushitana/internal/orderflow"
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+	uzumrepo "sushitana/pkg/repository/postgres/payment_repo/uzum_repo"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var (
+	Module = fx.Provide(New)
+)
+
+// Uzum kabinetida sozlangan "params" kaliti (zakaz raqami)
+const accountParam = "order_id"
+
+// uzum_transactions unique constraint'lari (30_uzum_transactions_ddl.up.sql)
+const (
+	transIDConstraint        = "uzum_transactions_trans_id_key"
+	activePerOrderConstraint = "uzum_one_active_per_order_uq"
+)
+
+type (
+	Params struct {
+		fx.In
+		Logger    logger.Logger
+		OrderRepo orderrepo.Repo
+		UzumRepo  uzumrepo.Repo
+		OrderFlow orderflow.Service
+	}
+
+	// Service - Uzum Merchant API webhook'lari (check/create/confirm/reverse/status)
+	Service interface {
+		Check(ctx context.Context, req structs.UzumRequest) structs.UzumResponse
+		Create(ctx context.Context, req structs.UzumRequest) structs.UzumResponse
+		Confirm(ctx context.Context, req structs.UzumRequest) structs.UzumResponse
+		Reverse(ctx context.Context, req structs.UzumRequest) structs.UzumResponse
+		Status(ctx context.Context, req structs.UzumRequest) structs.UzumResponse
+		BuildCheckoutURL(serviceID string, orderNumber string, amountTiyin int64) string
+	}
+
+	service struct {
+		logger    logger.Logger
+		orderRepo orderrepo.Repo
+		uzumRepo  uzumrepo.Repo
+		orderFlow orderflow.Service
+	}
+)
+
+func New(p Params) Service {
+	return &service{
+		logger:    p.Logger,
+		orderRepo: p.OrderRepo,
+		uzumRepo:  p.UzumRepo,
+		orderFlow: p.OrderFlow,
+	}
+}
+
+func nowMs() int64 { return time.Now().UnixMilli() }
+
+// ServiceID - UZUM_SERVICE_ID env (bo'sh bo'lsa 0)
+func ServiceID() int64 {
+	return cast.ToInt64(strings.TrimSpace(os.Getenv("UZUM_SERVICE_ID")))
+}
+
+func fail(req structs.UzumRequest, code string) structs.UzumResponse {
+	return structs.UzumResponse{
+		ServiceID: req.ServiceID,
+		Timestamp: nowMs(),
+		TransID:   req.TransID,
+		Status:    structs.UzumStatusFailed,
+		ErrorCode: code,
+	}
+}
+
+func txResponse(tx structs.UzumTransaction) structs.UzumResponse {
+	return structs.UzumResponse{
+		ServiceID:   tx.ServiceID,
+		Timestamp:   nowMs(),
+		TransID:     tx.TransID,
+		Status:      tx.Status,
+		TransTime:   tx.TransTime,
+		ConfirmTime: tx.ConfirmTime.Int64,
+		ReverseTime: tx.ReverseTime.Int64,
+		Amount:      tx.Amount,
+	}
+}
+
+// payableOrder - params.order_id bo'yicha WAITING_PAYMENT zakaz
+func (s *service) payableOrder(ctx context.Context, req structs.UzumRequest) (structs.Order, string) {
+	if req.ServiceID != ServiceID() {
+		return structs.Order{}, structs.UzumErrInvalidServiceID
+	}
+	number := strings.TrimSpace(req.Params[accountParam])
+	if number == "" {
+		return structs.Order{}, structs.UzumErrMissingParams
+	}
+
+	ord, err := s.orderRepo.GetByOrderNumber(ctx, cast.ToInt64(number))
+	if err != nil {
+		return structs.Order{}, structs.UzumErrAccountNotFound
+	}
+	if code := payable(ord); code != "" {
+		return structs.Order{}, code
+	}
+	return ord, ""
+}
+
+// payable - zakaz hali Uzum orqali to'lovni kutyaptimi ("" bo'lsa ha)
+func payable(ord structs.Order) string {
+	if strings.ToUpper(ord.PaymentStatus) == structs.PaymentStatusPaid {
+		return structs.UzumErrAlreadyPaid
+	}
+	if ord.Status != structs.OrderStatusWaitingPayment || !strings.EqualFold(ord.PaymentMethod, structs.PaymentMethodUzum) {
+		return structs.UzumErrInvalidOperation
+	}
+	return ""
+}
+
+func (s *service) Check(ctx context.Context, req structs.UzumRequest) structs.UzumResponse {
+	ord, code := s.payableOrder(ctx, req)
+	if code != "" {
+		return fail(req, code)
+	}
+
+	return structs.UzumResponse{
+		ServiceID: req.ServiceID,
+		Timestamp: nowMs(),
+		Status:    structs.UzumStatusOK,
+		Data: map[string]any{
+			accountParam: map[string]any{"value": cast.ToString(ord.OrderNumber)},
+			"amount":     map[string]any{"value": ord.PayAmount * 100},
+		},
+	}
+}
+
+func (s *service) Create(ctx context.Context, req structs.UzumRequest) structs.UzumResponse {
+	if strings.TrimSpace(req.TransID) == "" {
+		return fail(req, structs.UzumErrMissingParams)
+	}
+
+	// qayta kelgan create - mavjud tranzaksiya
+	if existing, err := s.uzumRepo.GetByTransID(ctx, req.TransID); err == nil {
+		return txResponse(existing)
+	}
+
+	ord, code := s.payableOrder(ctx, req)
+	if code != "" {
+		return fail(req, code)
+	}
+	if req.Amount != ord.PayAmount*100 {
+		return fail(req, structs.UzumErrInvalidAmount)
+	}
+
+	tx, err := s.uzumRepo.Create(ctx, structs.UzumTransaction{
+		TransID:   req.TransID,
+		OrderID:   ord.ID,
+		ServiceID: req.ServiceID,
+		Amount:    req.Amount,
+		TransTime: nowMs(),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			switch pgErr.ConstraintName {
+			case activePerOrderConstraint:
+				// boshqa transId bilan aktiv tranzaksiya bor
+				return fail(req, structs.UzumErrAlreadyPaid)
+			case transIDConstraint:
+				// shu transId bilan parallel create ulgurib qoldi
+				if existing, err := s.uzumRepo.GetByTransID(ctx, req.TransID); err == nil {
+					return txResponse(existing)
+				}
+			}
+		}
+		s.logger.Error(ctx, "->uzumRepo.Create", zap.Error(err))
+		return fail(req, structs.UzumErrDataVerification)
+	}
+
+	resp := txResponse(tx)
+	resp.Data = map[string]any{accountParam: cast.ToString(ord.OrderNumber)}
+	return resp
+}
+
+func (s *service) Confirm(ctx context.Context, req structs.UzumRequest) structs.UzumResponse {
+	tx, err := s.uzumRepo.GetByTransID(ctx, req.TransID)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return fail(req, structs.UzumErrTransNotFound)
+		}
+		return fail(req, structs.UzumErrDataVerification)
+	}
+
+	switch tx.Status {
+	case structs.UzumStatusConfirmed:
+		// oldingi confirm zakazni PAID qilolmagan bo'lsa Uzum qayta yuborganda yakunlaymiz
+		ord, err := s.orderRepo.GetByID(ctx, tx.OrderID)
+		if err != nil {
+			s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", tx.OrderID), zap.Error(err))
+			return fail(req, structs.UzumErrDataVerification)
+		}
+		if ord.Order.PaymentStatus != structs.PaymentStatusPaid {
+			if err := s.markPaid(ctx, tx.OrderID); err != nil {
+				return fail(req, structs.UzumErrDataVerification)
+			}
+		}
+		return txResponse(tx)
+	case structs.UzumStatusReversed:
+		return fail(req, structs.UzumErrPaymentCancelled)
+	}
+
+	// create'dan keyin to'lov usuli almashgan yoki zakaz bekor bo'lgan bo'lsa pul yechilmaydi
+	ord, err := s.orderRepo.GetByID(ctx, tx.OrderID)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", tx.OrderID), zap.Error(err))
+		return fail(req, structs.UzumErrDataVerification)
+	}
+	if code := payable(ord.Order); code != "" {
+		s.logger.Warn(ctx, "uzum confirm for not payable order, reversing",
+			zap.String("orderId", tx.OrderID),
+			zap.String("transId", tx.TransID),
+			zap.String("status", ord.Order.Status),
+			zap.String("paymentMethod", ord.Order.PaymentMethod),
+		)
+		if _, err := s.uzumRepo.ReverseCreatedByOrderID(ctx, tx.OrderID, nowMs()); err != nil {
+			s.logger.Error(ctx, "->uzumRepo.ReverseCreatedByOrderID", zap.String("orderId", tx.OrderID), zap.Error(err))
+		}
+		return fail(req, structs.UzumErrPaymentCancelled)
+	}
+
+	updated, err := s.uzumRepo.MarkConfirmed(ctx, req.TransID, nowMs(), req.PaymentSource, req.Phone)
+	if err != nil {
+		s.logger.Error(ctx, "->uzumRepo.MarkConfirmed", zap.Error(err))
+		return fail(req, structs.UzumErrDataVerification)
+	}
+
+	if err := s.markPaid(ctx, updated.OrderID); err != nil {
+		return fail(req, structs.UzumErrDataVerification)
+	}
+	return txResponse(updated)
+}
+
+// markPaid - zakazni PAID + COOKING qilib iiko'ga yuboradi; PAID yozilmasa xato qaytaradi
+func (s *service) markPaid(ctx context.Context, orderID string) error {
+	err := s.orderRepo.UpdatePaymentStatus(ctx, structs.UpdateStatus{
+		OrderId: orderID,
+		Status:  structs.PaymentStatusPaid,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.UpdatePaymentStatus", zap.String("orderId", orderID), zap.Error(err))
+		return err
+	}
+
+	err = s.orderRepo.UpdateStatus(ctx, structs.UpdateStatus{
+		OrderId: orderID,
+		Status:  "COOKING",
+	})
+	if err != nil {
+		// pul olingan, operator zakazni qo'lda oshxonaga o'tkazadi
+		s.logger.Error(ctx, "->orderRepo.UpdateStatus COOKING", zap.String("orderId", orderID), zap.Error(err))
+		return nil
+	}
+	if err := s.orderFlow.SendToIikoIfAllowed(ctx, orderID); err != nil {
+		s.logger.Error(ctx, "sendToIikoIfAllowed failed", zap.Error(err))
+	}
+	s.orderFlow.NotifyOrderStatusIfNeeded(ctx, orderID, "COOKING")
+	return nil
+}
+
+// Reverse - Uzum tomonidan bekor qilish; to'langan bo'lsa zakaz ham bekor
+func (s *service) Reverse(ctx context.Context, req structs.UzumRequest) structs.UzumResponse {
+	tx, err := s.uzumRepo.GetByTransID(ctx, req.TransID)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return fail(req, structs.UzumErrTransNotFound)
+		}
+		return fail(req, structs.UzumErrDataVerification)
+	}
+	if tx.Status == structs.UzumStatusReversed {
+		return txResponse(tx)
+	}
+
+	updated, err := s.uzumRepo.MarkReversed(ctx, req.TransID, nowMs())
+	if err != nil {
+		s.logger.Error(ctx, "->uzumRepo.MarkReversed", zap.Error(err))
+		return fail(req, structs.UzumErrDataVerification)
+	}
+
+	if tx.Status == structs.UzumStatusConfirmed {
+		err = s.orderRepo.UpdateStatus(ctx, structs.UpdateStatus{
+			OrderId: updated.OrderID,
+			Status:  "CANCELLED",
+		})
+		if err != nil {
+			s.logger.Error(ctx, "->orderRepo.UpdateStatus CANCELLED", zap.String("orderId", updated.OrderID), zap.Error(err))
+		}
+	}
+	return txResponse(updated)
+}
+
+func (s *service) Status(ctx context.Context, req structs.UzumRequest) structs.UzumResponse {
+	tx, err := s.uzumRepo.GetByTransID(ctx, req.TransID)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return fail(req, structs.UzumErrTransNotFound)
+		}
+		return fail(req, structs.UzumErrDataVerification)
+	}
+	return txResponse(tx)
+}
+
+// BuildCheckoutURL - Uzum Bank ilovasida xizmat sahifasi (params.order_id oldindan to'ldirilgan)
+func (s *service) BuildCheckoutURL(serviceID string, orderNumber string, amountTiyin int64) string {
+	v := url.Values{}
+	v.Set("serviceId", serviceID)
+	v.Set(accountParam, orderNumber)
+	v.Set("amount", fmt.Sprintf("%d", amountTiyin))
+	return "https://www.uzumbank.uz/open-service?" + v.Encode()
+}
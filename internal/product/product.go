@@ -31,6 +31,7 @@ type (
 		Patch(ctx context.Context, req structs.PatchProduct) (int64, error)
 		GetListCategoryName(ctx context.Context, req string) (resp []structs.Product, err error)
 		GetBox(ctx context.Context) (resp structs.GetListProductResponse, err error)
+		Search(ctx context.Context, query string, limit int) ([]structs.Product, error)
 	}
 	service struct {
 		productRepo productRepo.Repo
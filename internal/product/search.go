@@ -0,0 +1,200 @@
+package product
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// boxlar alohida guruhda turadi (GetBox bilan bir xil), qidiruvda ko'rsatmaymiz
+	boxParentGroup = "8a82292e-a027-4e69-8554-7fde17c058c8"
+
+	searchDefaultLimit = 10
+	searchMaxProducts  = 1000
+)
+
+// qidiruvda bir xil o'qiladigan harf birikmalari (lotin/kirill/ruscha yozuv farqlari)
+var searchFolds = strings.NewReplacer(
+	"ʻ", "", "ʼ", "", "’", "", "‘", "", "`", "", "'", "",
+	"ph", "f",
+	"kh", "h",
+	"ts", "s", // пицца -> pitstsa -> pissa
+	"z", "s", // pizza -> pissa
+	"x", "h",
+	"q", "k",
+	"c", "k",
+	"w", "v",
+)
+
+// normalizeSearch - kichik harf, kirill -> lotin, apostroflarsiz, takror harflarsiz tokenlar.
+// "Филадельфия", "filadelfiya", "Philadelphia" -> bir xil ko'rinishga yaqinlashadi.
+func normalizeSearch(s string) []string {
+	s = searchFolds.Replace(utils.CyrillicToLatin(s))
+
+	var b strings.Builder
+	var prev rune
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			if r == prev {
+				continue
+			}
+			b.WriteRune(r)
+		default:
+			r = ' '
+			if prev != ' ' {
+				b.WriteRune(r)
+			}
+		}
+		prev = r
+	}
+	return strings.Fields(b.String())
+}
+
+// allowedTypos - qisqa so'zlarda xato kechirilmaydi, uzunlarida 1-2 ta
+func allowedTypos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// tokenScore - bitta so'rov so'zi nom so'zlaridan biriga qanchalik mos (0 = mos emas)
+func tokenScore(q string, words []string, joined string) int {
+	best := 0
+	maxTypos := allowedTypos(len(q))
+
+	for _, w := range words {
+		score := 0
+		switch {
+		case w == q:
+			score = 100
+		case strings.HasPrefix(w, q):
+			score = 80
+		default:
+			// "philadelfia" / "filadelfya" - xatoli to'liq so'z yoki xatoli boshlanish
+			if maxTypos > 0 {
+				d := levenshtein(q, w)
+				if len(w) > len(q) {
+					d = min(d, levenshtein(q, w[:len(q)]))
+				}
+				if d <= maxTypos {
+					score = 40 - 10*d
+				}
+			}
+		}
+		best = max(best, score)
+	}
+
+	if best == 0 && len(q) >= 3 && strings.Contains(joined, q) {
+		best = 60
+	}
+	return best
+}
+
+// searchScore - barcha so'rov so'zlari mos kelishi shart, aks holda 0
+func searchScore(query []string, p structs.Product) int {
+	var words []string
+	for _, n := range []string{p.Name.Uz, p.Name.Ru, p.Name.En} {
+		words = append(words, normalizeSearch(n)...)
+	}
+	if len(words) == 0 {
+		return 0
+	}
+	joined := strings.Join(words, " ")
+
+	total := 0
+	for _, q := range query {
+		s := tokenScore(q, words, joined)
+		if s == 0 {
+			return 0
+		}
+		total += s
+	}
+	return total
+}
+
+// Search - bot/inline qidiruv: uz/ru/en nomlar bo'yicha, kirill/lotin va kichik xatolarni kechiradi.
+// Faqat aktiv va narxi bor mahsulotlar (boxlarsiz) qaytadi.
+func (s service) Search(ctx context.Context, query string, limit int) ([]structs.Product, error) {
+	tokens := normalizeSearch(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+
+	active := true
+	list, err := s.productRepo.GetList(ctx, structs.GetListProductRequest{
+		Limit:    searchMaxProducts,
+		IsActive: &active,
+	})
+	if err != nil {
+		s.logger.Error(ctx, "->productRepo.GetList", zap.Error(err))
+		return nil, err
+	}
+
+	type hit struct {
+		p     structs.Product
+		score int
+	}
+	var hits []hit
+	for _, p := range list.Products {
+		if p.ParentGroup == boxParentGroup || p.IsDeleted {
+			continue
+		}
+		if score := searchScore(tokens, p); score > 0 {
+			hits = append(hits, hit{p: p, score: score})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].p.Index < hits[j].p.Index
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	resp := make([]structs.Product, 0, len(hits))
+	for _, h := range hits {
+		resp = append(resp, h.p)
+	}
+	return resp, nil
+}
@@ -0,0 +1,97 @@
+package product
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	productRepo "sushitana/pkg/repository/postgres/product_repo"
+)
+
+// memProducts - Search ishlatadigan GetList; iiko'dan kelganidek nomlar asosan ruscha
+type memProducts struct {
+	productRepo.Repo
+	products []structs.Product
+}
+
+func (r memProducts) GetList(context.Context, structs.GetListProductRequest) (structs.GetListProductResponse, error) {
+	return structs.GetListProductResponse{Products: r.products}, nil
+}
+
+var searchCatalog = []structs.Product{
+	{ID: "fila", Index: 1, Name: structs.Name{Ru: "Филадельфия лайт"}},
+	{ID: "pizza", Index: 2, Name: structs.Name{Ru: "Пицца Пепперони"}},
+	{ID: "cali", Index: 3, Name: structs.Name{Ru: "Калифорния с лососем"}},
+	{ID: "cola", Index: 4, Name: structs.Name{Ru: "Кола 0.5"}},
+	{ID: "shurpa", Index: 5, Name: structs.Name{Uz: "Sho'rva", Ru: "Шурпа"}},
+	{ID: "box", Index: 6, Name: structs.Name{Ru: "Коробка для пиццы"}, ParentGroup: boxParentGroup},
+	{ID: "old", Index: 7, Name: structs.Name{Ru: "Филадельфия классик"}, IsDeleted: true},
+}
+
+func TestSearch(t *testing.T) {
+	svc := New(Params{ProductRepo: memProducts{products: searchCatalog}, Logger: logger.New("error")})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"latin to cyrillic", "filadelfiya", []string{"fila"}},
+		{"english spelling", "Philadelphia", []string{"fila"}},
+		{"cyrillic typo", "филадельфя", []string{"fila"}},
+		{"latin typo", "filadelfya", []string{"fila"}},
+		{"prefix", "filad", []string{"fila"}},
+		{"pizza folds", "pizza", []string{"pizza"}},
+		{"two words", "pitsa pepperoni", []string{"pizza"}},
+		{"doubled letters", "пеперони", []string{"pizza"}},
+		{"all words required", "pizza losos", nil},
+		{"translation is not transliteration", "california salmon", nil},
+		{"transliteration", "kaliforniya losos", []string{"cali"}},
+		{"cyrillic to uzbek latin", "шорва", []string{"shurpa"}},
+		{"apostrophe", "sho`rva", []string{"shurpa"}},
+		{"short words no typos", "кот", nil},
+		{"one typo from four letters", "кока", []string{"cola"}},
+		{"short prefix", "ко", []string{"cola"}},
+		{"no match", "burger", nil},
+		{"empty", "  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := svc.Search(context.Background(), tt.query, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range list {
+				got = append(got, p.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	products := []structs.Product{
+		{ID: "typo", Index: 1, Name: structs.Name{Ru: "Ролл Филодельфия"}},
+		{ID: "roll", Index: 2, Name: structs.Name{Ru: "Филадельфия ролл"}},
+		{ID: "plain", Index: 3, Name: structs.Name{Ru: "Филадельфия"}},
+	}
+	svc := New(Params{ProductRepo: memProducts{products: products}, Logger: logger.New("error")})
+
+	list, err := svc.Search(context.Background(), "filadelfiya", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range list {
+		got = append(got, p.ID)
+	}
+	// aniq moslar xatolidan oldin, teng ball -> katalog tartibi (Index), keyin limit
+	if want := []string{"roll", "plain"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Search = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,287 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+	operatorrepo "sushitana/pkg/repository/postgres/operator_repo"
+	ratingrepo "sushitana/pkg/repository/postgres/rating_repo"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+// baho tugmalari (callback prefix'lari)
+const (
+	CbFood     = "rate_f:"    // rate_f:<orderID>:<1..5>
+	CbDelivery = "rate_d:"    // rate_d:<orderID>:<1..5>
+	CbSkip     = "rate_skip:" // rate_skip:<orderID> - izohsiz tugatish
+)
+
+const (
+	defaultDelay         = 30 * time.Minute
+	defaultAlertMaxScore = 3
+	pollInterval         = time.Minute
+	retryAfter           = 10 * time.Minute
+	claimBatch           = 20
+)
+
+type (
+	Params struct {
+		fx.In
+		fx.Lifecycle
+
+		Config       config.IConfig
+		Logger       logger.Logger
+		RatingRepo   ratingrepo.Repo
+		OperatorRepo operatorrepo.Repo
+		Bot          *tgbotapi.BotAPI `optional:"true"`
+	}
+
+	Service interface {
+		// Schedule - zakaz COMPLETED/DELIVERED bo'lganda; so'rov rating_delay_minutes'dan keyin boradi
+		Schedule(ctx context.Context, req structs.ScheduleRating)
+		RateFood(ctx context.Context, tgID int64, orderID string, score int) (structs.OrderRating, error)
+		RateDelivery(ctx context.Context, tgID int64, orderID string, score int) (structs.OrderRating, error)
+		Comment(ctx context.Context, tgID int64, orderID, comment string) error
+
+		GetList(ctx context.Context, req structs.GetListRatingRequest) (structs.GetListRatingResponse, error)
+		Summary(ctx context.Context, from, to *time.Time) (structs.RatingSummary, error)
+	}
+
+	service struct {
+		chatID        int64
+		delay         time.Duration
+		alertMaxScore int
+		logger        logger.Logger
+		ratingRepo    ratingrepo.Repo
+		operatorRepo  operatorrepo.Repo
+		bot           *tgbotapi.BotAPI
+	}
+)
+
+func New(p Params) Service {
+	s := &service{
+		chatID:        p.Config.GetInt64("admin_chat_id"),
+		delay:         time.Duration(p.Config.GetInt("rating_delay_minutes")) * time.Minute,
+		alertMaxScore: p.Config.GetInt("rating_alert_max_score"),
+		logger:        p.Logger,
+		ratingRepo:    p.RatingRepo,
+		operatorRepo:  p.OperatorRepo,
+		bot:           p.Bot,
+	}
+	if s.delay <= 0 {
+		s.delay = defaultDelay
+	}
+	if s.alertMaxScore <= 0 {
+		s.alertMaxScore = defaultAlertMaxScore
+	}
+
+	if s.bot != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		p.Lifecycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go s.run(ctx)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				return nil
+			},
+		})
+	}
+	return s
+}
+
+// StarsKeyboard - 1..5 yulduz, bitta qatorda
+func StarsKeyboard(prefix, orderID string) tgbotapi.InlineKeyboardMarkup {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, 5)
+	for i := 1; i <= 5; i++ {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			strconv.Itoa(i)+"⭐", fmt.Sprintf("%s%s:%d", prefix, orderID, i),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+func (s *service) Schedule(ctx context.Context, req structs.ScheduleRating) {
+	if strings.TrimSpace(req.OrderID) == "" {
+		return
+	}
+	if err := s.ratingRepo.Schedule(ctx, req, time.Now().Add(s.delay)); err != nil {
+		s.logger.Error(ctx, "->ratingRepo.Schedule", zap.String("orderId", req.OrderID), zap.Error(err))
+	}
+}
+
+func (s *service) RateFood(ctx context.Context, tgID int64, orderID string, score int) (structs.OrderRating, error) {
+	if score < 1 || score > 5 {
+		return structs.OrderRating{}, structs.ErrBadRequest
+	}
+	r, err := s.ratingRepo.SetFood(ctx, orderID, tgID, score)
+	if err != nil {
+		return r, err
+	}
+	s.alertIfLow(ctx, r)
+	return r, nil
+}
+
+func (s *service) RateDelivery(ctx context.Context, tgID int64, orderID string, score int) (structs.OrderRating, error) {
+	if score < 1 || score > 5 {
+		return structs.OrderRating{}, structs.ErrBadRequest
+	}
+	r, err := s.ratingRepo.SetDelivery(ctx, orderID, tgID, score)
+	if err != nil {
+		return r, err
+	}
+	s.alertIfLow(ctx, r)
+	return r, nil
+}
+
+func (s *service) Comment(ctx context.Context, tgID int64, orderID, comment string) error {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil
+	}
+	if err := s.ratingRepo.SetComment(ctx, orderID, tgID, comment); err != nil {
+		return err
+	}
+
+	// past baho bo'lsa izoh ham operatorlarga
+	r, err := s.ratingRepo.Get(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "->ratingRepo.Get", zap.String("orderId", orderID), zap.Error(err))
+		return nil
+	}
+	if s.isLow(r) {
+		s.sendToGroup(ctx, orderID, fmt.Sprintf("💬 Комментарий к заказу №%d:\n%s", r.OrderNumber, html.EscapeString(comment)))
+	}
+	return nil
+}
+
+func (s *service) GetList(ctx context.Context, req structs.GetListRatingRequest) (structs.GetListRatingResponse, error) {
+	resp, err := s.ratingRepo.GetList(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->ratingRepo.GetList", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) Summary(ctx context.Context, from, to *time.Time) (structs.RatingSummary, error) {
+	resp, err := s.ratingRepo.Summary(ctx, from, to)
+	if err != nil {
+		s.logger.Error(ctx, "->ratingRepo.Summary", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+// run - har daqiqada vaqti kelgan so'rovlarni yuboradi
+func (s *service) run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.askDue(ctx)
+		}
+	}
+}
+
+func (s *service) askDue(ctx context.Context) {
+	due, err := s.ratingRepo.ClaimDue(ctx, claimBatch)
+	if err != nil {
+		s.logger.Error(ctx, "->ratingRepo.ClaimDue", zap.Error(err))
+		return
+	}
+	for _, r := range due {
+		s.ask(ctx, r)
+	}
+}
+
+func (s *service) ask(ctx context.Context, r structs.OrderRating) {
+	lang := utils.Lang(r.Language)
+
+	msg := tgbotapi.NewMessage(r.TgID, fmt.Sprintf(texts.Get(lang, texts.RatingAskFood), r.OrderNumber))
+	msg.ReplyMarkup = StarsKeyboard(CbFood, r.OrderID)
+	if _, err := s.bot.Send(msg); err != nil {
+		// botni bloklagan mijozga qayta urinmaymiz
+		var tgErr *tgbotapi.Error
+		if errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden {
+			return
+		}
+		s.logger.Warn(ctx, "rating prompt send failed", zap.String("orderId", r.OrderID), zap.Error(err))
+		_ = s.ratingRepo.Unclaim(ctx, r.OrderID, time.Now().Add(retryAfter))
+	}
+}
+
+// isLow - ikkala bahodan biri ham alertMaxScore'dan oshmasa
+func (s *service) isLow(r structs.OrderRating) bool {
+	return (r.Food != nil && *r.Food <= s.alertMaxScore) ||
+		(r.Delivery != nil && *r.Delivery <= s.alertMaxScore)
+}
+
+// alertIfLow - baho to'liq qo'yilgach (RATED) past bo'lsa operator guruhiga bir marta
+func (s *service) alertIfLow(ctx context.Context, r structs.OrderRating) {
+	if r.Status != structs.RatingStatusRated || !s.isLow(r) {
+		return
+	}
+	ok, err := s.ratingRepo.MarkAlerted(ctx, r.OrderID)
+	if err != nil || !ok {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠️ <b>Низкая оценка · заказ №%d</b>\n", r.OrderNumber)
+	if r.Food != nil {
+		fmt.Fprintf(&b, "🍣 Еда: %s\n", stars(*r.Food))
+	}
+	if r.Delivery != nil {
+		fmt.Fprintf(&b, "🚗 Доставка: %s\n", stars(*r.Delivery))
+	}
+	if r.CourierName != "" {
+		fmt.Fprintf(&b, "🛵 Курьер: %s\n", html.EscapeString(r.CourierName))
+	}
+	fmt.Fprintf(&b, "👤 <a href=\"tg://user?id=%d\">%d</a>", r.TgID, r.TgID)
+
+	s.sendToGroup(ctx, r.OrderID, b.String())
+}
+
+// sendToGroup - iloji bo'lsa zakaz postiga reply qilib
+func (s *service) sendToGroup(ctx context.Context, orderID, text string) {
+	if s.bot == nil || s.chatID == 0 {
+		return
+	}
+	msg := tgbotapi.NewMessage(s.chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if post, err := s.operatorRepo.Get(ctx, orderID); err == nil && post.ChatID == s.chatID && post.MessageID != 0 {
+		msg.ReplyParameters = tgbotapi.ReplyParameters{MessageID: post.MessageID, AllowSendingWithoutReply: true}
+	}
+	if _, err := s.bot.Send(msg); err != nil {
+		s.logger.Error(ctx, "rating alert send failed", zap.String("orderId", orderID), zap.Error(err))
+	}
+}
+
+func stars(n int) string {
+	if n < 0 || n > 5 {
+		return strconv.Itoa(n)
+	}
+	return strings.Repeat("★", n) + strings.Repeat("☆", 5-n) + fmt.Sprintf(" (%d)", n)
+}
@@ -0,0 +1,461 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"sushitana/internal/orderflow"
+	shopapi "sushitana/internal/payment/shop-api"
+	"sushitana/internal/structs"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+	clickrepo "sushitana/pkg/repository/postgres/payment_repo/click_repo"
+	paymerepo "sushitana/pkg/repository/postgres/payment_repo/payme_repo"
+	reconciliationrepo "sushitana/pkg/repository/postgres/reconciliation_repo"
+	refundrepo "sushitana/pkg/repository/postgres/refund_repo"
+
+	"github.com/spf13/cast"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+const (
+	defaultInterval = time.Hour
+	defaultLookback = 24 * time.Hour
+	// endi yaratilgan zakazlarga tegmaymiz - mijoz hali to'lov sahifasida bo'lishi mumkin
+	settleDelay = 15 * time.Minute
+	maxPeriod   = 31 * 24 * time.Hour
+	pageSize    = 500
+
+	clickPaymentSuccess = 2 // status_by_mti: payment_status
+)
+
+type (
+	Params struct {
+		fx.In
+		fx.Lifecycle
+
+		Config             config.IConfig
+		Logger             logger.Logger
+		ReconciliationRepo reconciliationrepo.Repo
+		OrderRepo          orderrepo.Repo
+		ClickRepo          clickrepo.Repo
+		PaymeRepo          paymerepo.Repo
+		RefundRepo         refundrepo.Repo
+		ShopSvc            shopapi.Service
+		OrderFlow          orderflow.Service
+	}
+
+	Service interface {
+		// Run - [from, to) oralig'idagi CLICK/PAYME zakazlarni solishtiradi.
+		// Click - status_by_mti orqali Click'ning o'zidan; Payme - faqat lokal payme_transactions
+		// (Merchant API'da Payme'dan holat so'rab bo'lmaydi, jadvalni Payme callback'lari to'ldiradi)
+		Run(ctx context.Context, from, to time.Time, trigger string) (structs.Reconciliation, error)
+
+		GetList(ctx context.Context, req structs.GetListReconciliationRequest) (structs.GetListReconciliationResponse, error)
+		GetByID(ctx context.Context, id string) (structs.GetReconciliationResponse, error)
+	}
+
+	service struct {
+		logger             logger.Logger
+		reconciliationRepo reconciliationrepo.Repo
+		orderRepo          orderrepo.Repo
+		clickRepo          clickrepo.Repo
+		paymeRepo          paymerepo.Repo
+		refundRepo         refundrepo.Repo
+		shopSvc            shopapi.Service
+		orderFlow          orderflow.Service
+		interval           time.Duration
+		lookback           time.Duration
+	}
+)
+
+func New(p Params) Service {
+	s := &service{
+		logger:             p.Logger,
+		reconciliationRepo: p.ReconciliationRepo,
+		orderRepo:          p.OrderRepo,
+		clickRepo:          p.ClickRepo,
+		paymeRepo:          p.PaymeRepo,
+		refundRepo:         p.RefundRepo,
+		shopSvc:            p.ShopSvc,
+		orderFlow:          p.OrderFlow,
+		interval:           time.Duration(p.Config.GetInt("reconciliation_interval_minutes")) * time.Minute,
+		lookback:           time.Duration(p.Config.GetInt("reconciliation_lookback_hours")) * time.Hour,
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	if s.lookback <= 0 {
+		s.lookback = defaultLookback
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go s.runScheduled(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+	return s
+}
+
+// runScheduled - har interval'da oxirgi lookback soatni tekshiradi
+func (s *service) runScheduled(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			to := time.Now().Add(-settleDelay)
+			if _, err := s.Run(ctx, to.Add(-s.lookback), to, structs.ReconcileTriggerScheduled); err != nil &&
+				!errors.Is(err, structs.ErrReconcileRunning) {
+				s.logger.Error(ctx, "scheduled reconciliation failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *service) GetList(ctx context.Context, req structs.GetListReconciliationRequest) (structs.GetListReconciliationResponse, error) {
+	resp, err := s.reconciliationRepo.GetList(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->reconciliationRepo.GetList", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) GetByID(ctx context.Context, id string) (structs.GetReconciliationResponse, error) {
+	var resp structs.GetReconciliationResponse
+
+	rec, err := s.reconciliationRepo.GetByID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->reconciliationRepo.GetByID", zap.Error(err))
+		}
+		return resp, err
+	}
+	items, err := s.reconciliationRepo.GetItems(ctx, id)
+	if err != nil {
+		s.logger.Error(ctx, "->reconciliationRepo.GetItems", zap.Error(err))
+		return resp, err
+	}
+	resp.Reconciliation = rec
+	resp.Items = items
+	return resp, nil
+}
+
+func (s *service) Run(ctx context.Context, from, to time.Time, trigger string) (structs.Reconciliation, error) {
+	if !to.After(from) || to.Sub(from) > maxPeriod {
+		return structs.Reconciliation{}, structs.ErrBadRequest
+	}
+	// bir nechta gateway nusxasi bo'lsa ham bir vaqtda bitta sverka
+	unlock, ok, err := s.reconciliationRepo.TryLock(ctx)
+	if err != nil {
+		return structs.Reconciliation{}, err
+	}
+	if !ok {
+		return structs.Reconciliation{}, structs.ErrReconcileRunning
+	}
+	defer unlock()
+
+	rec, err := s.reconciliationRepo.Create(ctx, from, to, trigger)
+	if err != nil {
+		s.logger.Error(ctx, "->reconciliationRepo.Create", zap.Error(err))
+		return rec, err
+	}
+
+	rec.Status = structs.ReconcileStatusDone
+	if err := s.reconcile(ctx, &rec); err != nil {
+		rec.Status = structs.ReconcileStatusFailed
+		rec.Error = err.Error()
+	}
+	if err := s.reconciliationRepo.Finish(ctx, rec); err != nil {
+		s.logger.Error(ctx, "->reconciliationRepo.Finish", zap.Error(err))
+		return rec, err
+	}
+
+	s.logger.Info(ctx, "payment reconciliation finished",
+		zap.String("id", rec.ID),
+		zap.String("status", rec.Status),
+		zap.Int64("checked", rec.Checked),
+		zap.Int64("fixed", rec.Fixed),
+		zap.Int64("mismatches", rec.Mismatches),
+	)
+	return rec, nil
+}
+
+func (s *service) reconcile(ctx context.Context, rec *structs.Reconciliation) error {
+	// Payme: o'zimizdagi payme_transactions (GetStatement bilan bir xil manba)
+	statement, err := s.paymeRepo.GetStatement(ctx, rec.PeriodFrom.UnixMilli(), rec.PeriodTo.UnixMilli())
+	if err != nil {
+		s.logger.Error(ctx, "->paymeRepo.GetStatement", zap.Error(err))
+		return fmt.Errorf("payme statement failed: %w", err)
+	}
+	paymeTxs := make(map[string]structs.PaymeTransaction, len(statement))
+	for _, tx := range statement {
+		if prev, ok := paymeTxs[tx.OrderID]; ok && prev.State == paymerepo.StatePerformed {
+			continue // bajarilgan tranzaksiya ustun
+		}
+		paymeTxs[tx.OrderID] = tx
+	}
+
+	seen := make(map[string]bool)
+	for _, method := range []string{structs.PaymentMethodClick, structs.PaymentMethodPayme} {
+		for offset := int64(0); ; offset += pageSize {
+			list, err := s.orderRepo.GetList(ctx, structs.GetListOrderRequest{
+				Limit:         pageSize,
+				Offset:        offset,
+				PaymentMethod: method,
+				CreatedAtFrom: &rec.PeriodFrom,
+				CreatedAtTo:   &rec.PeriodTo,
+			})
+			if err != nil {
+				s.logger.Error(ctx, "->orderRepo.GetList", zap.Error(err))
+				return fmt.Errorf("get orders failed: %w", err)
+			}
+
+			for _, ord := range list.Orders {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if ord.PaymentMethod != method {
+					continue // ILIKE filtri
+				}
+				seen[ord.ID] = true
+				rec.Checked++
+
+				var item *structs.ReconciliationItem
+				if method == structs.PaymentMethodClick {
+					item = s.checkClick(ctx, ord)
+				} else {
+					tx, ok := paymeTxs[ord.ID]
+					if !ok {
+						last, err := s.paymeRepo.GetLastByOrderID(ctx, ord.ID)
+						if err == nil {
+							tx, ok = last, true
+						} else if !errors.Is(err, structs.ErrNotFound) {
+							s.logger.Error(ctx, "->paymeRepo.GetLastByOrderID", zap.Error(err))
+						}
+					}
+					item = s.checkPayme(ctx, ord, tx, ok)
+				}
+				s.addItem(ctx, rec, item)
+			}
+
+			if int64(len(list.Orders)) < pageSize {
+				break
+			}
+		}
+	}
+
+	// davrda bajarilgan, lekin zakazi davrdan oldin yaratilgan tranzaksiyalar
+	for orderID, tx := range paymeTxs {
+		if seen[orderID] || tx.State != paymerepo.StatePerformed {
+			continue
+		}
+		res, err := s.orderRepo.GetByID(ctx, orderID)
+		if err != nil {
+			s.logger.Warn(ctx, "->orderRepo.GetByID", zap.String("orderID", orderID), zap.Error(err))
+			continue
+		}
+		rec.Checked++
+		s.addItem(ctx, rec, s.checkPayme(ctx, res.Order, tx, true))
+	}
+	return nil
+}
+
+func (s *service) addItem(ctx context.Context, rec *structs.Reconciliation, item *structs.ReconciliationItem) {
+	if item == nil {
+		return
+	}
+	if item.Fixed {
+		rec.Fixed++
+	} else {
+		rec.Mismatches++
+	}
+	if err := s.reconciliationRepo.AddItem(ctx, rec.ID, *item); err != nil {
+		s.logger.Error(ctx, "->reconciliationRepo.AddItem", zap.Error(err))
+	}
+}
+
+// checkClick - merchant_trans_id = zakaz raqami; holatni Click'dan status_by_mti bilan olamiz
+func (s *service) checkClick(ctx context.Context, ord structs.Order) *structs.ReconciliationItem {
+	number := cast.ToString(ord.OrderNumber)
+	localPaid := ord.PaymentStatus == structs.PaymentStatusPaid
+
+	var invoiceAmount int64
+	invoicePaid := false
+	if inv, err := s.clickRepo.GetByMerchantTransID(ctx, number); err == nil {
+		invoiceAmount = amountSom(inv.Amount)
+		invoicePaid = strings.EqualFold(inv.Status, structs.PaymentStatusPaid)
+	}
+
+	item := newItem(ord, structs.PaymentMethodClick)
+	item.ProviderAmount = invoiceAmount
+
+	serviceID := cast.ToInt64(strings.TrimSpace(os.Getenv("CLICK_SERVICE_ID")))
+	remote, err := s.clickStatus(ctx, serviceID, number, ord.CreatedAt)
+	if err != nil {
+		if !localPaid && !invoicePaid {
+			return nil // to'lanmagan zakaz - tekshira olmadik, xolos
+		}
+		item.Kind = structs.MismatchProviderError
+		item.Note = err.Error()
+		return item
+	}
+
+	remotePaid := remote.ErrorCode == 0 && remote.PaymentStatus == clickPaymentSuccess
+	item.ProviderStatus = fmt.Sprintf("%d", remote.PaymentStatus)
+	if remote.ErrorCode != 0 {
+		item.ProviderStatus = fmt.Sprintf("error %d", remote.ErrorCode)
+	}
+
+	switch {
+	case remotePaid && invoiceAmount > 0 && invoiceAmount != s.paidAmount(ctx, ord):
+		item.Kind = structs.MismatchAmount
+	case remotePaid && !localPaid:
+		if isClosed(ord.Status) {
+			item.Kind = structs.MismatchPaidButCancelled
+			return item
+		}
+		item.Kind = structs.MismatchPaidAtProvider
+		if err := s.clickRepo.SetStatus(ctx, number, structs.PaymentStatusPaid); err != nil {
+			s.logger.Error(ctx, "->clickRepo.SetStatus", zap.Error(err))
+		}
+		s.markPaid(ctx, ord, item)
+	case !remotePaid && localPaid:
+		item.Kind = structs.MismatchNotPaidAtProvider
+	default:
+		return nil
+	}
+	return item
+}
+
+// clickStatus - status_by_mti to'lov sanasini so'raydi; zakaz kuni topilmasa ertasini ham ko'ramiz
+func (s *service) clickStatus(ctx context.Context, serviceID int64, number string, createdAt time.Time) (structs.StatusByMTIResponse, error) {
+	if serviceID == 0 {
+		return structs.StatusByMTIResponse{}, errors.New("CLICK_SERVICE_ID env not found")
+	}
+	remote, err := s.shopSvc.PaymentStatusByMTI(ctx, serviceID, number, createdAt)
+	if err != nil {
+		s.logger.Error(ctx, "->shopSvc.PaymentStatusByMTI", zap.String("mti", number), zap.Error(err))
+		return remote, err
+	}
+	if remote.ErrorCode != 0 {
+		next := createdAt.AddDate(0, 0, 1)
+		if next.Before(time.Now()) {
+			if r2, err := s.shopSvc.PaymentStatusByMTI(ctx, serviceID, number, next); err == nil && r2.ErrorCode == 0 {
+				return r2, nil
+			}
+		}
+	}
+	return remote, nil
+}
+
+// checkPayme - Payme tomonga so'rov yo'q: tx lokal payme_transactions'dan (Payme callback'lari yozgan).
+// Shuning uchun callback umuman kelmagan to'lov bu yerda "NONE" bo'lib ko'rinadi, Payme kabinetidan tekshiriladi
+func (s *service) checkPayme(ctx context.Context, ord structs.Order, tx structs.PaymeTransaction, found bool) *structs.ReconciliationItem {
+	localPaid := ord.PaymentStatus == structs.PaymentStatusPaid
+
+	item := newItem(ord, structs.PaymentMethodPayme)
+	item.ProviderStatus = "NONE"
+	if found {
+		item.ProviderStatus = fmt.Sprintf("state %d", tx.State)
+		item.ProviderAmount = amountSom(tx.Amount)
+	}
+
+	switch {
+	case found && tx.State == paymerepo.StatePerformed && item.ProviderAmount != s.paidAmount(ctx, ord):
+		item.Kind = structs.MismatchAmount
+	case found && tx.State == paymerepo.StatePerformed && !localPaid:
+		if isClosed(ord.Status) {
+			item.Kind = structs.MismatchPaidButCancelled
+			return item
+		}
+		item.Kind = structs.MismatchPaidAtProvider
+		s.markPaid(ctx, ord, item)
+	case found && tx.State == paymerepo.StateCanceledPerformed && localPaid:
+		item.Kind = structs.MismatchProviderCancelled
+	case localPaid && (!found || tx.State != paymerepo.StatePerformed):
+		item.Kind = structs.MismatchNotPaidAtProvider
+	default:
+		return nil
+	}
+	return item
+}
+
+// markPaid - Click/Payme callback'idagi oddiy PAID yo'li: PAID -> COOKING -> iiko -> xabar
+func (s *service) markPaid(ctx context.Context, ord structs.Order, item *structs.ReconciliationItem) {
+	if err := s.orderRepo.UpdatePaymentStatus(ctx, structs.UpdateStatus{
+		OrderId: ord.ID,
+		Status:  structs.PaymentStatusPaid,
+	}); err != nil {
+		s.logger.Error(ctx, "->orderRepo.UpdatePaymentStatus", zap.Error(err))
+		item.Note = "auto-fix failed: " + err.Error()
+		return
+	}
+	item.Fixed = true
+
+	// operator zakazni allaqachon siljitgan bo'lsa statusga tegmaymiz
+	if ord.Status != structs.OrderStatusWaitingPayment {
+		item.Note = "payment_status -> PAID"
+		return
+	}
+	if err := s.orderRepo.UpdateStatus(ctx, structs.UpdateStatus{
+		OrderId: ord.ID,
+		Status:  structs.OrderStatusCooking,
+	}); err != nil {
+		s.logger.Error(ctx, "->orderRepo.UpdateStatus", zap.Error(err))
+	}
+	if err := s.orderFlow.SendToIikoIfAllowed(ctx, ord.ID); err != nil {
+		s.logger.Error(ctx, "->orderFlow.SendToIikoIfAllowed", zap.Error(err))
+	}
+	s.orderFlow.NotifyOrderStatusIfNeeded(ctx, ord.ID, structs.OrderStatusCooking)
+	item.Note = "payment_status -> PAID, status -> COOKING"
+}
+
+// paidAmount - mijoz to'lagan summa: zakazdan qator olib tashlangan bo'lsa
+// TotalPrice kamaygan, qaytarilgan qism qo'shib solishtiriladi (choy puli PayAmount ichida)
+func (s *service) paidAmount(ctx context.Context, ord structs.Order) int64 {
+	refunded, err := s.refundRepo.SumByOrderID(ctx, ord.ID)
+	if err != nil {
+		return ord.PayAmount
+	}
+	return ord.PayAmount + refunded
+}
+
+func newItem(ord structs.Order, method string) *structs.ReconciliationItem {
+	return &structs.ReconciliationItem{
+		OrderID:       ord.ID,
+		OrderNumber:   ord.OrderNumber,
+		PaymentMethod: method,
+		OrderStatus:   ord.Status,
+		PaymentStatus: ord.PaymentStatus,
+		OrderAmount:   ord.PayAmount,
+	}
+}
+
+func isClosed(status string) bool {
+	return status == structs.OrderStatusCancelled || status == structs.OrderStatusRejected
+}
+
+// amountSom - invoices.amount / payme_transactions.amount (NUMERIC, so'm) -> int64
+func amountSom(v string) int64 {
+	return int64(math.Round(cast.ToFloat64(strings.TrimSpace(v))))
+}
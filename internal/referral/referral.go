@@ -0,0 +1,267 @@
+package referral
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+	clientrepo "sushitana/pkg/repository/postgres/client_repo"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+	referralrepo "sushitana/pkg/repository/postgres/referral_repo"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+const (
+	defaultRewardAmount = 20000 // so'm, har ikkala tomonga
+	codePrefix          = "REF"
+	codeAlphabet        = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // 0/O, 1/I chalkashmasin
+	codeLength          = 6
+)
+
+type (
+	Params struct {
+		fx.In
+
+		Config       config.IConfig
+		Logger       logger.Logger
+		ReferralRepo referralrepo.Repo
+		ClientRepo   clientrepo.Repo
+		OrderRepo    orderrepo.Repo
+		Bot          *tgbotapi.BotAPI `optional:"true"`
+	}
+
+	Service interface {
+		// Record - AccountMw yangi mijoz yaratganda, /start ref_<tgId> bo'lsa
+		Record(ctx context.Context, refereeTgID int64, source string)
+		// OnOrderCompleted - do'stning birinchi yakunlangan zakazi -> ikkalasiga promo-kod
+		OnOrderCompleted(ctx context.Context, orderID string)
+
+		// Discount - checkout'da promo-kod uchun chegirma; kod referal kodi bo'lmasa ErrNotFound
+		Discount(ctx context.Context, tgID int64, code string) (int64, error)
+
+		Stats(ctx context.Context, tgID int64) (structs.ReferralStats, error)
+		RewardAmount() int64
+
+		GetList(ctx context.Context, req structs.GetListReferralRequest) (structs.GetListReferralResponse, error)
+		Summary(ctx context.Context, from, to *time.Time) (structs.ReferralSummary, error)
+	}
+
+	service struct {
+		amount       int64
+		logger       logger.Logger
+		referralRepo referralrepo.Repo
+		clientRepo   clientrepo.Repo
+		orderRepo    orderrepo.Repo
+		bot          *tgbotapi.BotAPI
+	}
+)
+
+func New(p Params) Service {
+	s := &service{
+		amount:       p.Config.GetInt64("referral_reward_amount"),
+		logger:       p.Logger,
+		referralRepo: p.ReferralRepo,
+		clientRepo:   p.ClientRepo,
+		orderRepo:    p.OrderRepo,
+		bot:          p.Bot,
+	}
+	if s.amount <= 0 {
+		s.amount = defaultRewardAmount
+	}
+	return s
+}
+
+func (s *service) RewardAmount() int64 {
+	return s.amount
+}
+
+func (s *service) Record(ctx context.Context, refereeTgID int64, source string) {
+	link, ok := utils.ParseDeepLink(source)
+	if !ok || link.Kind != utils.DeepLinkRef {
+		return
+	}
+	referrerTgID, err := strconv.ParseInt(link.Value, 10, 64)
+	if err != nil || referrerTgID == 0 || referrerTgID == refereeTgID {
+		return
+	}
+
+	created, err := s.referralRepo.Create(ctx, refereeTgID, referrerTgID)
+	if err != nil {
+		s.logger.Error(ctx, "->referralRepo.Create", zap.Int64("tgId", refereeTgID), zap.Error(err))
+		return
+	}
+	if created {
+		s.logger.Info(ctx, "referral recorded", zap.Int64("referee", refereeTgID), zap.Int64("referrer", referrerTgID))
+	}
+}
+
+func (s *service) OnOrderCompleted(ctx context.Context, orderID string) {
+	ord, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error(ctx, "->orderRepo.GetByID", zap.String("orderId", orderID), zap.Error(err))
+		return
+	}
+	tgID := ord.Order.TgID
+	if tgID == 0 {
+		return
+	}
+
+	ref, err := s.referralRepo.GetPending(ctx, tgID)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) {
+			s.logger.Error(ctx, "->referralRepo.GetPending", zap.Int64("tgId", tgID), zap.Error(err))
+		}
+		return
+	}
+
+	// bir odam ikkinchi akkaunt ochib o'zini taklif qilmasin
+	reason, err := s.fraudReason(ctx, ref)
+	if err != nil {
+		// tekshira olmadik -> PENDING qoladi, keyingi yakunlangan zakazda qayta tekshiriladi
+		if errors.Is(err, errNoRefereePhone) {
+			s.logger.Info(ctx, "referral postponed: referee has no phone", zap.Int64("referee", tgID))
+		} else {
+			s.logger.Error(ctx, "referral fraud check failed", zap.Int64("referee", tgID), zap.Error(err))
+		}
+		return
+	}
+	if reason != "" {
+		s.logger.Info(ctx, "referral rejected", zap.Int64("referee", tgID), zap.String("reason", reason))
+		if err := s.referralRepo.Reject(ctx, tgID, reason); err != nil {
+			s.logger.Error(ctx, "->referralRepo.Reject", zap.Error(err))
+		}
+		return
+	}
+
+	reward := structs.ReferralReward{
+		OrderID:      orderID,
+		ReferrerCode: newCode(),
+		RefereeCode:  newCode(),
+		Amount:       s.amount,
+	}
+	ok, err := s.referralRepo.Reward(ctx, tgID, reward)
+	if err != nil {
+		s.logger.Error(ctx, "->referralRepo.Reward", zap.Int64("tgId", tgID), zap.Error(err))
+		return
+	}
+	if !ok {
+		return
+	}
+
+	s.grant(ctx, ref.ReferrerTgID, utils.Lang(ref.ReferrerLanguage), reward.ReferrerCode, texts.ReferralRewardReferrer)
+	s.grant(ctx, ref.RefereeTgID, utils.Lang(ref.RefereeLanguage), reward.RefereeCode, texts.ReferralRewardReferee)
+}
+
+// errNoRefereePhone - telefonsiz mijozni dublikatga tekshirib bo'lmaydi, hali mukofotga loyiq emas
+var errNoRefereePhone = errors.New("referee phone is missing")
+
+// fraudReason - ("", nil) bo'lsa hammasi joyida; xato bo'lsa tekshiruv natijasiz (referal PENDING qoladi)
+func (s *service) fraudReason(ctx context.Context, ref structs.Referral) (string, error) {
+	phone := utils.NormalizePhone(ref.RefereePhone)
+	if phone == "" {
+		return "", errNoRefereePhone
+	}
+	if phone == utils.NormalizePhone(ref.ReferrerPhone) {
+		return structs.ReferralRejectSelfPhone, nil
+	}
+	taken, err := s.referralRepo.PhoneTaken(ctx, phone, ref.RefereeTgID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return structs.ReferralRejectDuplicatePhone, nil
+	}
+	return "", nil
+}
+
+// grant - kod mijozning promo_code'iga (bo'sh bo'lsa) yoziladi -> keyingi zakazda chegirma sifatida qo'llanadi
+func (s *service) grant(ctx context.Context, tgID int64, lang utils.Lang, code string, key texts.TextKey) {
+	if c, err := s.clientRepo.GetByTgID(ctx, tgID); err == nil && c.PromoCode == "" {
+		if err := s.clientRepo.UpdatePromoCode(ctx, tgID, code); err != nil {
+			s.logger.Error(ctx, "->clientRepo.UpdatePromoCode", zap.Int64("tgId", tgID), zap.Error(err))
+		}
+	}
+
+	if s.bot == nil {
+		return
+	}
+	text := fmt.Sprintf(texts.Get(lang, key), formatMoney(s.amount), texts.Get(lang, texts.CurrencyUzs), code)
+	if _, err := s.bot.Send(tgbotapi.NewMessage(tgID, text)); err != nil {
+		s.logger.Warn(ctx, "referral reward notify failed", zap.Int64("tgId", tgID), zap.Error(err))
+	}
+}
+
+func (s *service) Discount(ctx context.Context, tgID int64, code string) (int64, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !strings.HasPrefix(code, codePrefix) {
+		return 0, structs.ErrNotFound
+	}
+	amount, err := s.referralRepo.PromoAmount(ctx, tgID, code)
+	if err != nil {
+		if !errors.Is(err, structs.ErrNotFound) && !errors.Is(err, structs.ErrPromoCodeUsed) {
+			s.logger.Error(ctx, "->referralRepo.PromoAmount", zap.Int64("tgId", tgID), zap.Error(err))
+		}
+		return 0, err
+	}
+	return amount, nil
+}
+
+func (s *service) Stats(ctx context.Context, tgID int64) (structs.ReferralStats, error) {
+	resp, err := s.referralRepo.Stats(ctx, tgID)
+	if err != nil {
+		s.logger.Error(ctx, "->referralRepo.Stats", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) GetList(ctx context.Context, req structs.GetListReferralRequest) (structs.GetListReferralResponse, error) {
+	resp, err := s.referralRepo.GetList(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->referralRepo.GetList", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func (s *service) Summary(ctx context.Context, from, to *time.Time) (structs.ReferralSummary, error) {
+	resp, err := s.referralRepo.Summary(ctx, from, to)
+	if err != nil {
+		s.logger.Error(ctx, "->referralRepo.Summary", zap.Error(err))
+		return resp, err
+	}
+	return resp, nil
+}
+
+func newCode() string {
+	b := make([]byte, codeLength)
+	_, _ = rand.Read(b)
+	var sb strings.Builder
+	sb.WriteString(codePrefix)
+	for _, v := range b {
+		sb.WriteByte(codeAlphabet[int(v)%len(codeAlphabet)])
+	}
+	return sb.String()
+}
+
+func formatMoney(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
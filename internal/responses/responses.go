@@ -18,6 +18,8 @@ const (
 	ForbiddenCode    = 403
 
 	BlockedCode = iota + 1500
+
+	TooManyRequestsCode = 429
 )
 
 var (
@@ -32,5 +34,8 @@ var (
 	UserBlocked  = newResponse(UnauthorizedCode, "UserBlocked")
 	Forbidden    = newResponse(ForbiddenCode, "Запрещено")
 
+	TooManyRequests = newResponse(TooManyRequestsCode, "Слишком много запросов")
+	NotCancellable  = newResponse(BadRequestCode, "Заказ нельзя отменить")
+
 	Blocked = newResponse(BlockedCode, "Blocked")
 )
@@ -0,0 +1,89 @@
+package structs
+
+import "time"
+
+const (
+	BroadcastStatusDraft     = "DRAFT"
+	BroadcastStatusRunning   = "RUNNING"
+	BroadcastStatusPaused    = "PAUSED"
+	BroadcastStatusDone      = "DONE"
+	BroadcastStatusCancelled = "CANCELLED"
+)
+
+// broadcast_recipients.status
+const (
+	RecipientPending = "PENDING"
+	RecipientSent    = "SENT"
+	RecipientBlocked = "BLOCKED" // 403: bot bloklangan -> clients.is_active = false
+	RecipientFailed  = "FAILED"
+)
+
+type BroadcastButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// BroadcastSegment - bo'sh maydon = filtr yo'q. Faqat is_active mijozlar.
+type BroadcastSegment struct {
+	Languages     []string   `json:"languages"`       // uz, ru, en
+	LastOrderFrom *time.Time `json:"last_order_from"` // oxirgi zakaz shu sanadan keyin
+	LastOrderTo   *time.Time `json:"last_order_to"`   // oxirgi zakaz shu sanadan oldin (ko'pdan beri zakaz bermaganlar)
+	NoOrders      bool       `json:"no_orders"`       // hali zakaz bermaganlar
+	MinOrders     *int64     `json:"min_orders"`      // yakunlangan zakazlar soni
+	MaxOrders     *int64     `json:"max_orders"`
+	Zones         []string   `json:"zones"` // olmaliq, ohangaron - oxirgi yetkazish manzili bo'yicha
+}
+
+type Broadcast struct {
+	ID         int64             `json:"id"`
+	Title      string            `json:"title"`
+	Text       string            `json:"text"`
+	Photo      string            `json:"photo"`
+	Buttons    []BroadcastButton `json:"buttons"`
+	Segment    BroadcastSegment  `json:"segment"`
+	Status     string            `json:"status"`
+	Total      int64             `json:"total"`
+	Sent       int64             `json:"sent"`
+	Blocked    int64             `json:"blocked"`
+	Failed     int64             `json:"failed"`
+	CreatedBy  string            `json:"created_by"`
+	StartedAt  *time.Time        `json:"started_at"`
+	FinishedAt *time.Time        `json:"finished_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+type CreateBroadcast struct {
+	Title     string            `json:"title"`
+	Text      string            `json:"text"`
+	Photo     string            `json:"photo"`
+	Buttons   []BroadcastButton `json:"buttons"`
+	Segment   BroadcastSegment  `json:"segment"`
+	CreatedBy string            `json:"-"`
+}
+
+type GetListBroadcastRequest struct {
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+	Status string `json:"status"`
+}
+
+type GetListBroadcastResponse struct {
+	Count      int64       `json:"count"`
+	Broadcasts []Broadcast `json:"broadcasts"`
+}
+
+// BroadcastCandidate - segment SQL filtridan o'tgan mijoz; zona Go'da tekshiriladi
+type BroadcastCandidate struct {
+	TgID    int64
+	LastLat float64
+	LastLng float64
+}
+
+type BroadcastPreview struct {
+	Count int64 `json:"count"`
+}
+
+type BroadcastTest struct {
+	TgID int64 `json:"tg_id"`
+}
@@ -0,0 +1,95 @@
+package structs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ClientCard - Payme'da tokenlangan karta. Token hech qachon JSON'ga chiqmaydi
+type ClientCard struct {
+	ID         int64      `json:"id"`
+	TgID       int64      `json:"tgId"`
+	Number     string     `json:"number"` // 860006******6311
+	Expire     string     `json:"expire"` // 03/99
+	Verified   bool       `json:"verified"`
+	Token      string     `json:"-"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Last4 - "•••• 1234" uchun
+func (c ClientCard) Last4() string {
+	if len(c.Number) < 4 {
+		return c.Number
+	}
+	return c.Number[len(c.Number)-4:]
+}
+
+// AddCardRequest - Mini App cards.create'ni o'zi chaqiradi (front-end kalit), bizga faqat token keladi
+type AddCardRequest struct {
+	TgID  int64  `json:"-"`
+	Token string `json:"token"`
+}
+
+// AddCardResponse - karta yaratildi, SMS-kod yuborildi
+type AddCardResponse struct {
+	Card  ClientCard `json:"card"`
+	Phone string     `json:"phone"` // SMS ketgan raqam (niqoblangan)
+	Wait  int64      `json:"wait"`  // ms, qayta yuborishgacha
+}
+
+type VerifyCardRequest struct {
+	TgID int64  `json:"-"`
+	ID   int64  `json:"-"`
+	Code string `json:"code"`
+}
+
+/* ---------- Payme Subscribe API ---------- */
+
+type PaymeCard struct {
+	Number    string `json:"number"`
+	Expire    string `json:"expire"`
+	Token     string `json:"token"`
+	Recurrent bool   `json:"recurrent"`
+	Verify    bool   `json:"verify"`
+}
+
+type PaymeVerifyCode struct {
+	Sent  bool   `json:"sent"`
+	Phone string `json:"phone"`
+	Wait  int64  `json:"wait"`
+}
+
+type PaymeReceipt struct {
+	ID    string `json:"_id"`
+	State int    `json:"state"`
+}
+
+// PaymeSubscribeError - Subscribe API JSON-RPC xatosi (-31xxx karta/chek xatolari)
+type PaymeSubscribeError struct {
+	Code    int             `json:"code"`
+	Message json.RawMessage `json:"message"`
+}
+
+func (e *PaymeSubscribeError) Error() string {
+	return fmt.Sprintf("payme subscribe error %d: %s", e.Code, e.Text())
+}
+
+// Text - message string yoki {"ru","uz","en"} bo'lib kelishi mumkin
+func (e *PaymeSubscribeError) Text() string {
+	var s string
+	if err := json.Unmarshal(e.Message, &s); err == nil {
+		return s
+	}
+	var m map[string]string
+	if err := json.Unmarshal(e.Message, &m); err == nil {
+		if v := m["ru"]; v != "" {
+			return v
+		}
+		for _, v := range m {
+			return v
+		}
+	}
+	return string(e.Message)
+}
@@ -1,5 +1,7 @@
 package structs
 
+import "time"
+
 type Cart struct {
 	ID        int64  `json:"id"`
 	TGID      int64  `json:"tg_id"`
@@ -45,3 +47,17 @@ type ProductCart struct {
 	Name   Name   `json:"name"`
 	ImgUrl string `json:"img_url"`
 }
+
+// AbandonedCartsRequest - tashlab ketilgan savatlarni qidirish oynasi
+type AbandonedCartsRequest struct {
+	IdleBefore   time.Time // oxirgi o'zgarish shundan oldin
+	NotOlderThan time.Time // juda eski savatlarni bezovta qilmaymiz
+	OrderAfter   time.Time // shundan keyin zakaz bergan bo'lsa - eslatmaymiz
+	Limit        int64
+}
+
+type AbandonedCart struct {
+	TgID      int64
+	Language  string
+	StartedAt time.Time // savatdagi birinchi mahsulot qo'shilgan vaqt - eslatma kaliti
+}
@@ -15,13 +15,16 @@ type Client struct {
 	CompletedOrderCount int64      `json:"completed_order_count"`
 	CanceledOrderCount  int64      `json:"canceled_order_count"`
 	IsActive            bool       `json:"is_active"`
+	Source              string     `json:"source"`     // /start payload, ro'yxatdan o'tgandagi
+	PromoCode           string     `json:"promo_code"` // promo_<code> link, keyingi buyurtmaga
 	CreatedAt           time.Time  `json:"createdAt"`
 	UpdatedAt           time.Time  `json:"updatedAt"`
 }
 
 type CreateClient struct {
-	TgID  int64  `json:"tgid"`
-	Phone string `json:"phone"`
+	TgID   int64  `json:"tgid"`
+	Phone  string `json:"phone"`
+	Source string `json:"source"`
 }
 
 type GetListClientRequest struct {
@@ -29,6 +32,7 @@ type GetListClientRequest struct {
 	Limit         int64      `json:"limit"`
 	PhoneNumber   string     `json:"phone_number"`
 	Name          string     `json:"name"`
+	Source        string     `json:"source"` // prefix: "promo_", "ref_", "p_abc"
 	CreatedAtFrom *time.Time `json:"created_at_from"`
 	CreatedAtTo   *time.Time `json:"created_at_to"`
 }
@@ -0,0 +1,21 @@
+package structs
+
+import "time"
+
+// ClientAddress - mijozning saqlangan manzili ("🏠 Uy", "🏢 Ish" ...)
+type ClientAddress struct {
+	ID         int64     `json:"id"`
+	TgID       int64     `json:"tgid"`
+	Name       string    `json:"name"`
+	Address    Address   `json:"address"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SaveClientAddress struct {
+	ID      int64   `json:"-"`
+	TgID    int64   `json:"-"`
+	Name    string  `json:"name"`
+	Address Address `json:"address"`
+}
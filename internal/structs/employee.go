@@ -12,6 +12,7 @@ type Employee struct {
 	PhoneNumber string     `json:"phone_number"`
 	RoleId      string     `json:"role_id"`
 	RoleName    string     `json:"role_name,omitempty"`
+	TgID        *int64     `json:"tg_id"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
 }
@@ -24,6 +25,7 @@ type CreateEmployee struct {
 	IsActive    bool   `json:"is_active"`
 	PhoneNumber string `json:"phone_number"`
 	RoleId      string `json:"role_id"`
+	TgID        *int64 `json:"tg_id"`
 }
 
 type PatchEmployee struct {
@@ -35,6 +37,7 @@ type PatchEmployee struct {
 	IsActive    *bool   `json:"is_active"`
 	PhoneNumber *string `json:"phone_number"`
 	RoleId      *string `json:"role_id"`
+	TgID        *int64  `json:"tg_id"`
 }
 
 type UpdateEmployee struct {
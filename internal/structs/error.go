@@ -6,14 +6,27 @@ import (
 )
 
 var (
-	ErrBadRequest        = errors.New("bad request")
-	ErrNoRowsAffected    = errors.New("no rows affected")
-	ErrNotFound          = errors.New("no rows in result set")
-	ErrUserBlocked       = errors.New("user blocked")
-	ErrUniqueViolation   = errors.New("unique Violation error")
-	ErrWhiteList         = errors.New("account in whitelist")
-	ErrAlreadyBooked     = errors.New("allready booked")
-	ErrOutOfDeliveryZone = errors.New("the specified location is out of delivery.")
+	ErrBadRequest         = errors.New("bad request")
+	ErrNoRowsAffected     = errors.New("no rows affected")
+	ErrNotFound           = errors.New("no rows in result set")
+	ErrUserBlocked        = errors.New("user blocked")
+	ErrUniqueViolation    = errors.New("unique Violation error")
+	ErrWhiteList          = errors.New("account in whitelist")
+	ErrAlreadyBooked      = errors.New("allready booked")
+	ErrOutOfDeliveryZone  = errors.New("the specified location is out of delivery.")
+	ErrNotCancellable     = errors.New("order can not be cancelled")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrForbidden          = errors.New("forbidden")
+	ErrOrderNotPayable    = errors.New("order is not waiting for payment")
+	ErrAmountMismatch     = errors.New("payment amount mismatch")
+	ErrNotWaitingOperator = errors.New("order is not waiting for operator")
+	ErrOrderPaid          = errors.New("order is already paid")
+	ErrReconcileRunning   = errors.New("reconciliation is already running")
+	ErrNotRefundable      = errors.New("order is not paid or already closed")
+	ErrInvalidChange      = errors.New("change amount is less than order total")
+	ErrInvalidTip         = errors.New("invalid tip amount")
+	ErrOrderChanged       = errors.New("order items were changed concurrently")
+	ErrPromoCodeUsed      = errors.New("promo code is already used")
 )
 
 type ErrMinOrder struct {
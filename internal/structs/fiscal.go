@@ -0,0 +1,85 @@
+package structs
+
+import "encoding/json"
+
+// invoices.fiscal_status (Click)
+const (
+	FiscalStatusSent   = "SENT"
+	FiscalStatusFailed = "FAILED"
+)
+
+type ProductFiscal struct {
+	ID          string `json:"id"`
+	Ikpu        string `json:"ikpu"`
+	PackageCode string `json:"packageCode"`
+	VatPercent  int64  `json:"vatPercent"`
+}
+
+// FiscalItem - chekdagi bitta qator (mahsulot, box yoki yetkazish). Narx so'mda, bittasi uchun
+type FiscalItem struct {
+	Title       string `json:"title"`
+	Ikpu        string `json:"ikpu"`
+	PackageCode string `json:"packageCode"`
+	VatPercent  int64  `json:"vatPercent"`
+	Count       int64  `json:"count"`
+	Price       int64  `json:"price"`
+	Discount    int64  `json:"discount"` // qator bo'yicha jami chegirma (promo-kod)
+}
+
+// PaymeReceiptDetail - CheckPerformTransaction "detail" (narxlar tiyinda)
+type PaymeReceiptDetail struct {
+	ReceiptType int                `json:"receipt_type"`
+	Items       []PaymeReceiptItem `json:"items"`
+}
+
+type PaymeReceiptItem struct {
+	Title       string `json:"title"`
+	Price       int64  `json:"price"`
+	Count       int64  `json:"count"`
+	Code        string `json:"code"`
+	PackageCode string `json:"package_code"`
+	VatPercent  int64  `json:"vat_percent"`
+	Discount    int64  `json:"discount"`
+}
+
+// PaymeSetFiscalDataParams - Payme fiskal chek natijasini yuboradi
+type PaymeSetFiscalDataParams struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"` // PERFORM / CANCEL
+	FiscalData json.RawMessage `json:"fiscal_data"`
+}
+
+type PaymeSetFiscalDataResult struct {
+	Success bool `json:"success"`
+}
+
+// ClickSubmitItemsRequest - POST /payment/ofd_data/submit_items (summalar tiyinda)
+type ClickSubmitItemsRequest struct {
+	ServiceID     int64             `json:"service_id"`
+	PaymentID     int64             `json:"payment_id"`
+	Items         []ClickFiscalItem `json:"items"`
+	ReceivedEcash int64             `json:"received_ecash"`
+	ReceivedCash  int64             `json:"received_cash"`
+	ReceivedCard  int64             `json:"received_card"`
+}
+
+type ClickFiscalItem struct {
+	Name           string              `json:"Name"`
+	SPIC           string              `json:"SPIC"`
+	PackageCode    string              `json:"PackageCode"`
+	GoodPrice      int64               `json:"GoodPrice"`
+	Price          int64               `json:"Price"`
+	Amount         int64               `json:"Amount"` // 1000 = 1 dona
+	VAT            int64               `json:"VAT"`
+	VATPercent     int64               `json:"VATPercent"`
+	CommissionInfo ClickCommissionInfo `json:"CommissionInfo"`
+}
+
+type ClickCommissionInfo struct {
+	TIN string `json:"TIN,omitempty"`
+}
+
+type ClickSubmitItemsResponse struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorNote string `json:"error_note"`
+}
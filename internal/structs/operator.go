@@ -0,0 +1,28 @@
+package structs
+
+import "time"
+
+// operator guruhidagi amallar (operator_posts.action)
+const (
+	OperatorActionConfirmed = "CONFIRMED"
+	OperatorActionRejected  = "REJECTED"
+)
+
+// OperatorPost - admin_chat_id guruhiga yuborilgan zakaz xabari
+type OperatorPost struct {
+	OrderID    string    `json:"order_id"`
+	ChatID     int64     `json:"chat_id"`
+	MessageID  int       `json:"message_id"`
+	EmployeeID *int64    `json:"employee_id"`
+	Action     string    `json:"action"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RejectOrderByOperator - operator guruhidan "❌ Rad etish"
+type RejectOrderByOperator struct {
+	OrderId    string `json:"orderId"`
+	EmployeeID int64  `json:"employeeId"`
+	Reason     string `json:"reason"`
+}
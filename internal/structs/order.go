@@ -10,9 +10,11 @@ const (
 	DeliveryTypeDelivery = "DELIVERY"
 	DeliveryTypePickup   = "PICKUP"
 
-	PaymentMethodCash  = "CASH"
-	PaymentMethodClick = "CLICK"
-	PaymentMethodPayme = "PAYME"
+	PaymentMethodCash     = "CASH"
+	PaymentMethodClick    = "CLICK"
+	PaymentMethodPayme    = "PAYME"
+	PaymentMethodTelegram = "TELEGRAM"
+	PaymentMethodUzum     = "UZUM"
 )
 
 func NormalizeDeliveryType(v string) (string, error) {
@@ -27,36 +29,6 @@ func NormalizeDeliveryType(v string) (string, error) {
 	}
 }
 
-func NormalizePaymentMethod(v string) (string, error) {
-	s := strings.TrimSpace(strings.ToUpper(v))
-	switch s {
-	case "CASH":
-		return "CASH", nil
-	case "PAYME":
-		return "PAYME", nil
-	case "CLICK":
-		return "CLICK", nil
-	default:
-		return "", fmt.Errorf("invalid paymentMethod: %q", v)
-	}
-}
-
-func ToIikoPaymentKind(method string) (string, error) {
-	m, err := NormalizePaymentMethod(method)
-	if err != nil {
-		return "", err
-	}
-	if m == PaymentMethodCash {
-		return IikoPaymentKindCash, nil
-	}
-	return IikoPaymentKindOnline, nil
-}
-
-const (
-	IikoPaymentKindCash   = "CASH"
-	IikoPaymentKindOnline = "ONLINE"
-)
-
 type Order struct {
 	ID                string         `json:"id"`
 	TgID              int64          `json:"tgId"`
@@ -77,6 +49,13 @@ type Order struct {
 	Name              string         `json:"name,omitempty"`
 	PaymentUrl        string         `json:"payment_url"`
 	OrderPriceForIIKO int64          `json:"order_price_for_iiko"`
+	ChangeFrom        int64          `json:"changeFrom"` // CASH: mijoz shu kupyuradan qaytim so'raydi (0 - kerak emas)
+	TipAmount         int64          `json:"tipAmount"`  // online: kuryerga choy puli, TotalPrice'ga kirmaydi
+	PayAmount         int64          `json:"payAmount"`  // provider'dan yechiladigan summa: TotalPrice + TipAmount
+	PromoCode         string         `json:"promoCode"`
+	DiscountAmount    int64          `json:"discountAmount"` // referal promo-kod chegirmasi, TotalPrice'dan ayrilgan
+	CourierID         string         `json:"courierId"`
+	CourierName       string         `json:"courierName"`
 	CreatedAt         time.Time      `json:"createdAt"`
 	UpdateAt          time.Time      `json:"updateAt"`
 }
@@ -103,6 +82,7 @@ type Address struct {
 	Entrance   string  `json:"entrance,omitempty"`
 	Floor      string  `json:"floor,omitempty"`
 	Doorphone  string  `json:"doorphone,omitempty"`
+	Comment    string  `json:"comment,omitempty"` // kuryer uchun izoh
 }
 
 type OrderProduct struct {
@@ -129,6 +109,12 @@ type CreateOrder struct {
 	IIKODeliveryID string         `json:"iikDeliveryId"`
 	OrderNumber    int64          `json:"order_number"`
 	TotalPrice     int64          `json:"totalPrice"`
+	OnlinePayment  bool           `json:"-"`                    // provider.Online(): status WAITING_PAYMENT/PENDING bilan yaratiladi
+	CardID         int64          `json:"cardId,omitempty"`     // PAYME: saqlangan karta bilan to'lash
+	ChangeFrom     int64          `json:"changeFrom,omitempty"` // CASH + DELIVERY: qaytim qaysi summadan
+	TipAmount      int64          `json:"tipAmount,omitempty"`  // online + DELIVERY: kuryerga choy puli
+	PromoCode      string         `json:"promoCode,omitempty"`  // referal promo-kodi: mijozniki bo'lsa chegirma beriladi
+	DiscountAmount int64          `json:"-"`                    // Create() promo-koddan hisoblaydi
 }
 
 type GetListOrderRequest struct {
@@ -155,6 +141,28 @@ type UpdateStatus struct {
 	Status  string `json:"status"`
 }
 
+// mijozning o'zi bekor qilishi (bot yoki Mini App)
+type CancelOrderByClient struct {
+	OrderId string `json:"orderId"`
+	TgID    int64  `json:"-"` // bot: account, Mini App: initData
+	Reason  string `json:"reason"`
+}
+
+// to'lanmagan zakazda to'lov usulini almashtirish / havolani qayta yaratish
+type ChangePaymentMethod struct {
+	OrderId       string `json:"orderId"`
+	TgID          int64  `json:"-"` // bot: account, Mini App: initData
+	PaymentMethod string `json:"paymentMethod"`
+}
+
+type ChangePaymentMethodResponse struct {
+	OrderId       string `json:"orderId"`
+	PaymentMethod string `json:"paymentMethod"`
+	PaymentStatus string `json:"paymentStatus"`
+	Status        string `json:"status"`
+	PaymentUrl    string `json:"payment_url"`
+}
+
 type IikoCreateSettings struct {
 	TransportToFrontTimeout int  `json:"transportToFrontTimeout,omitempty"`
 	CheckStopList           bool `json:"checkStopList,omitempty"`
@@ -181,6 +189,20 @@ type IikoOrder struct {
 
 	Items    []IikoOrderItem `json:"items"`
 	Payments []IikoPayment   `json:"payments,omitempty"`
+
+	// referal promo-kod chegirmasi (IIKO_PROMO_DISCOUNT_TYPE_ID)
+	DiscountsInfo *IikoDiscountsInfo `json:"discountsInfo,omitempty"`
+}
+
+type IikoDiscountsInfo struct {
+	Discounts []IikoDiscount `json:"discounts"`
+}
+
+// IikoDiscount - "RMS" turi: summasi belgilangan (erkin summali) chegirma
+type IikoDiscount struct {
+	DiscountTypeId string  `json:"discountTypeId"`
+	Sum            float64 `json:"sum"`
+	Type           string  `json:"type"`
 }
 
 type IikoDeliveryPoint struct {
@@ -239,6 +261,20 @@ type IikoPayment struct {
 	IsProcessedExternally bool    `json:"isProcessedExternally,omitempty"`
 }
 
+// deliveries/change_payments - qaytarishdan keyin to'lov summasi
+type IikoChangePaymentsRequest struct {
+	OrganizationId string        `json:"organizationId"`
+	OrderId        string        `json:"orderId"`
+	Payments       []IikoPayment `json:"payments"`
+}
+
+// deliveries/change_comment - oshxona qaysi qatorlar olib tashlanganini ko'rsin
+type IikoChangeCommentRequest struct {
+	OrganizationId string `json:"organizationId"`
+	OrderId        string `json:"orderId"`
+	Comment        string `json:"comment"`
+}
+
 type IikoCreateDeliveryResponse struct {
 	CorrelationId string        `json:"correlationId"`
 	OrderInfo     IikoOrderInfo `json:"orderInfo"`
@@ -263,3 +299,22 @@ type NotifyTarget struct {
 	TgID        int64
 	OrderNumber int64
 }
+
+type CourierTips struct {
+	CourierID   string `json:"courier_id"` // '' - iiko kuryerni yubormagan
+	CourierName string `json:"courier_name"`
+	Count       int64  `json:"count"`
+	Amount      int64  `json:"amount"`
+}
+
+// TipSummary - admin panel: choy puli, umumiy va kuryerlar kesimida
+type TipSummary struct {
+	Count    int64         `json:"count"`
+	Amount   int64         `json:"amount"`
+	Couriers []CourierTips `json:"couriers"`
+}
+
+// AppliedDiscount - promo chegirmasi mahsulotlar summasidan oshmaydi (qatorlar olib tashlanganda ham)
+func AppliedDiscount(discount, productsTotal int64) int64 {
+	return max(0, min(discount, productsTotal))
+}
@@ -15,6 +15,7 @@ type PaymeTransaction struct {
 	PerformTime         sql.NullInt64
 	CancelTime          sql.NullInt64
 	Reason              sql.NullInt64
+	CardID              sql.NullInt64 // faqat GetPerformedByOrderID: saqlangan karta bilan to'langan chek
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }
@@ -31,7 +32,8 @@ type Account struct {
 }
 
 type PaymeCheckPerformResult struct {
-	Allow bool `json:"allow"`
+	Allow  bool                `json:"allow"`
+	Detail *PaymeReceiptDetail `json:"detail,omitempty"`
 }
 
 type PaymeCreateParams struct {
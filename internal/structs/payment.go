@@ -153,3 +153,27 @@ type ClickPaymentAttempt struct {
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 }
+
+// Click invoice/status: invoice_status qiymatlari
+const (
+	ClickInvoiceWaiting = 0
+	ClickInvoicePaid    = 2 // manfiy - bekor qilingan / muddati o'tgan
+)
+
+// ClickPhoneInvoice - operator mijoz telefoniga yuborgan Click invoice
+type ClickPhoneInvoice struct {
+	OrderID           string    `json:"orderId"`
+	OrderNumber       int64     `json:"order_number"`
+	InvoiceID         int64     `json:"invoiceId"`
+	Phone             string    `json:"phone"`
+	Amount            string    `json:"amount"`
+	Status            string    `json:"status"` // invoices.status: WAITING_PAYMENT / PAID / CANCELLED
+	InvoiceStatus     int       `json:"invoiceStatus"`
+	InvoiceStatusNote string    `json:"invoiceStatusNote"`
+	SentAt            time.Time `json:"sentAt"`
+}
+
+type SendClickInvoiceRequest struct {
+	OrderID string `json:"-"`
+	Phone   string `json:"phone"` // bo'sh bo'lsa zakazdagi telefon
+}
@@ -0,0 +1,82 @@
+package structs
+
+import "time"
+
+// ClickInvoiceRecord - admin panel: invoices qatori + bog'langan zakaz
+type ClickInvoiceRecord struct {
+	ID                 int64      `json:"id"`
+	ClickInvoiceID     int64      `json:"clickInvoiceId"`
+	ClickTransID       int64      `json:"clickTransId"`
+	ClickPaydocID      int64      `json:"clickPaydocId"`
+	MerchantPrepareID  int64      `json:"merchantPrepareId"`
+	MerchantTransID    string     `json:"merchantTransId"`
+	OrderID            string     `json:"orderId"`
+	OrderNumber        int64      `json:"orderNumber"`
+	OrderStatus        string     `json:"orderStatus"`
+	OrderPaymentStatus string     `json:"orderPaymentStatus"`
+	Phone              string     `json:"phone"`
+	Amount             string     `json:"amount"`
+	Status             string     `json:"status"`
+	Comment            string     `json:"comment"`
+	InvoiceStatus      int        `json:"invoiceStatus"`
+	InvoiceStatusNote  string     `json:"invoiceStatusNote"`
+	CompleteError      int        `json:"completeError"`
+	CompleteErrorNote  string     `json:"completeErrorNote"`
+	FiscalStatus       string     `json:"fiscalStatus"`
+	FiscalError        string     `json:"fiscalError"`
+	InvoiceSentAt      *time.Time `json:"invoiceSentAt"`
+	PreparedAt         *time.Time `json:"preparedAt"`
+	CompletedAt        *time.Time `json:"completedAt"`
+	CancelledAt        *time.Time `json:"cancelledAt"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+type GetListClickInvoiceRequest struct {
+	Limit       int64
+	Offset      int64
+	Status      string
+	OrderNumber int64
+	Phone       string
+	From        *time.Time
+	To          *time.Time
+}
+
+type GetListClickInvoiceResponse struct {
+	Count    int64                `json:"count"`
+	Invoices []ClickInvoiceRecord `json:"invoices"`
+}
+
+// PaymeTransactionRecord - admin panel: payme_transactions qatori. *Time - Payme ms epoch, o'zgartirilmagan
+type PaymeTransactionRecord struct {
+	ID                  string    `json:"id"`
+	PaycomTransactionID string    `json:"paycomTransactionId"`
+	OrderID             string    `json:"orderId"`
+	OrderNumber         int64     `json:"orderNumber"`
+	OrderStatus         string    `json:"orderStatus"`
+	OrderPaymentStatus  string    `json:"orderPaymentStatus"`
+	Phone               string    `json:"phone"`
+	Amount              string    `json:"amount"`
+	State               int       `json:"state"`
+	CreateTime          int64     `json:"createTime"`
+	PerformTime         int64     `json:"performTime"`
+	CancelTime          int64     `json:"cancelTime"`
+	Reason              *int64    `json:"reason"`
+	CreatedAt           time.Time `json:"createdAt"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+}
+
+type GetListPaymeTransactionRequest struct {
+	Limit       int64
+	Offset      int64
+	State       *int
+	OrderNumber int64
+	Phone       string
+	From        *time.Time
+	To          *time.Time
+}
+
+type GetListPaymeTransactionResponse struct {
+	Count        int64                    `json:"count"`
+	Transactions []PaymeTransactionRecord `json:"transactions"`
+}
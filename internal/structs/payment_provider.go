@@ -0,0 +1,54 @@
+package structs
+
+import "errors"
+
+var (
+	ErrPaymentMethodDisabled = errors.New("payment method is not enabled")
+	ErrRefundNotSupported    = errors.New("refund is not supported by payment provider")
+	ErrCallbackNotSupported  = errors.New("callback parsing is not supported by payment provider")
+	ErrCardLimit             = errors.New("saved card limit reached")
+)
+
+// orders.payment_status
+const (
+	PaymentStatusUnpaid  = "UNPAID"  // naqd: kuryer/kassada
+	PaymentStatusPending = "PENDING" // online: to'lov kutilmoqda
+	PaymentStatusPaid    = "PAID"
+)
+
+// PaymentOrder - provider'ga kerakli zakaz ma'lumoti (summa so'mda)
+type PaymentOrder struct {
+	OrderID       string
+	OrderNumber   int64
+	TgID          int64
+	Phone         string
+	Amount        int64
+	PaymentStatus string
+	CardID        int64 // saqlangan karta bilan bir bosishda to'lash (faqat PAYME)
+	Refunded      int64 // Refund: shu zakaz bo'yicha oldingi qaytarishlar (so'm), joriysidan tashqari
+}
+
+// PaymentIntent - CreatePayment natijasi. URL bo'sh bo'lishi mumkin (CASH, TELEGRAM invoice'ni bot o'zi yuboradi)
+type PaymentIntent struct {
+	URL        string `json:"url"`
+	ExternalID string `json:"external_id"` // click request_id va h.k.
+	Paid       bool   `json:"paid"`        // saqlangan karta: to'lov shu zahoti o'tdi
+}
+
+// PaymentState - provider tomonidagi holat
+type PaymentState struct {
+	Method     string `json:"method"`
+	Status     string `json:"status"` // UNPAID, PENDING, PAID, CANCELLED
+	ExternalID string `json:"external_id"`
+	Amount     int64  `json:"amount"` // so'm
+}
+
+// PaymentCallback - provider webhook'ining umumiy ko'rinishi (log, solishtirish uchun)
+type PaymentCallback struct {
+	Method     string `json:"method"`
+	Action     string `json:"action"`    // prepare/complete, CreateTransaction/PerformTransaction ...
+	OrderRef   string `json:"order_ref"` // merchant_trans_id / account.order_id (zakaz raqami)
+	ExternalID string `json:"external_id"`
+	Amount     int64  `json:"amount"` // so'm
+	Success    bool   `json:"success"`
+}
@@ -31,6 +31,10 @@ type Product struct {
 	CreatedAt          time.Time   `json:"createdAt"`
 	UpdatedAt          time.Time   `json:"updatedAt"`
 	Weight             float64     `json:"weight"`
+	// fiskal chek uchun (admin kiritadi, iiko sync tegmaydi)
+	Ikpu        string `json:"ikpu"`
+	PackageCode string `json:"packageCode"`
+	VatPercent  int64  `json:"vatPercent"`
 }
 
 type BoxInfo struct {
@@ -133,4 +137,7 @@ type PatchProduct struct {
 	IsActive    *bool        `json:"isActive"`
 	BoxId       *string      `json:"box_id"`
 	Description *Description `json:"description"`
+	Ikpu        *string      `json:"ikpu"`
+	PackageCode *string      `json:"packageCode"`
+	VatPercent  *int64       `json:"vatPercent"`
 }
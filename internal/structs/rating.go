@@ -0,0 +1,67 @@
+package structs
+
+import "time"
+
+const (
+	RatingStatusPending = "PENDING" // ask_at kelishini kutyapti
+	RatingStatusAsked   = "ASKED"   // bot so'radi
+	RatingStatusRated   = "RATED"   // mijoz baho qo'ydi
+)
+
+// ScheduleRating - zakaz COMPLETED/DELIVERED bo'lganda baho so'rovini rejalashtirish
+type ScheduleRating struct {
+	OrderID     string `json:"order_id"`
+	CourierID   string `json:"courier_id"`
+	CourierName string `json:"courier_name"`
+}
+
+type OrderRating struct {
+	OrderID      string     `json:"order_id"`
+	OrderNumber  int64      `json:"order_number"`
+	TgID         int64      `json:"tg_id"`
+	DeliveryType string     `json:"delivery_type"`
+	CourierID    string     `json:"courier_id"`
+	CourierName  string     `json:"courier_name"`
+	Status       string     `json:"status"`
+	Food         *int       `json:"food"`
+	Delivery     *int       `json:"delivery"`
+	Comment      string     `json:"comment"`
+	AskAt        time.Time  `json:"ask_at"`
+	AskedAt      *time.Time `json:"asked_at"`
+	RatedAt      *time.Time `json:"rated_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Language string `json:"-"` // bot so'rovi uchun (clients.language)
+}
+
+type GetListRatingRequest struct {
+	Limit     int64      `json:"limit"`
+	Offset    int64      `json:"offset"`
+	CourierID string     `json:"courier_id"`
+	MaxScore  int        `json:"max_score"` // faqat past baholar: food yoki delivery <= max_score
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+}
+
+type GetListRatingResponse struct {
+	Count   int64         `json:"count"`
+	Ratings []OrderRating `json:"ratings"`
+}
+
+type RatingAverage struct {
+	Count    int64   `json:"count"`
+	Food     float64 `json:"food"`
+	Delivery float64 `json:"delivery"`
+}
+
+type CourierRating struct {
+	CourierID   string `json:"courier_id"`
+	CourierName string `json:"courier_name"`
+	RatingAverage
+}
+
+// RatingSummary - admin panel: umumiy o'rtacha va kuryerlar kesimida
+type RatingSummary struct {
+	Overall  RatingAverage   `json:"overall"`
+	Couriers []CourierRating `json:"couriers"`
+}
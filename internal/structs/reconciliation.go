@@ -0,0 +1,77 @@
+package structs
+
+import "time"
+
+const (
+	ReconcileTriggerScheduled = "SCHEDULED"
+	ReconcileTriggerManual    = "MANUAL"
+
+	ReconcileStatusRunning = "RUNNING"
+	ReconcileStatusDone    = "DONE"
+	ReconcileStatusFailed  = "FAILED"
+)
+
+// payment_reconciliation_items.kind
+const (
+	// provider'da to'langan, bizda PENDING (fixed=true bo'lsa avtomatik PAID qilindi)
+	MismatchPaidAtProvider = "PAID_AT_PROVIDER"
+	// provider'da to'langan, lekin zakaz bekor qilingan - pulni qaytarish kerak bo'lishi mumkin
+	MismatchPaidButCancelled = "PAID_BUT_CANCELLED"
+	// bizda PAID, provider'da to'lov yo'q
+	MismatchNotPaidAtProvider = "NOT_PAID_AT_PROVIDER"
+	// provider to'lovni bekor qilgan (Payme -2), bizda PAID
+	MismatchProviderCancelled = "PROVIDER_CANCELLED"
+	MismatchAmount            = "AMOUNT_MISMATCH"
+	// Click invoices PAID, provider tekshiruvi esa xato qaytardi
+	MismatchProviderError = "PROVIDER_ERROR"
+)
+
+type Reconciliation struct {
+	ID         string     `json:"id"`
+	PeriodFrom time.Time  `json:"periodFrom"`
+	PeriodTo   time.Time  `json:"periodTo"`
+	Trigger    string     `json:"trigger"`
+	Status     string     `json:"status"`
+	Checked    int64      `json:"checked"`
+	Fixed      int64      `json:"fixed"`
+	Mismatches int64      `json:"mismatches"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+type ReconciliationItem struct {
+	ID             int64     `json:"id"`
+	OrderID        string    `json:"orderId"`
+	OrderNumber    int64     `json:"orderNumber"`
+	PaymentMethod  string    `json:"paymentMethod"`
+	Kind           string    `json:"kind"`
+	OrderStatus    string    `json:"orderStatus"`
+	PaymentStatus  string    `json:"paymentStatus"`
+	ProviderStatus string    `json:"providerStatus"`
+	OrderAmount    int64     `json:"orderAmount"`
+	ProviderAmount int64     `json:"providerAmount"`
+	Fixed          bool      `json:"fixed"`
+	Note           string    `json:"note"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type RunReconciliationRequest struct {
+	From time.Time `json:"from" binding:"required"`
+	To   time.Time `json:"to" binding:"required"`
+}
+
+type GetListReconciliationRequest struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type GetListReconciliationResponse struct {
+	Count           int64            `json:"count"`
+	Reconciliations []Reconciliation `json:"reconciliations"`
+}
+
+type GetReconciliationResponse struct {
+	Reconciliation Reconciliation       `json:"reconciliation"`
+	Items          []ReconciliationItem `json:"items"`
+}
@@ -0,0 +1,82 @@
+package structs
+
+import "time"
+
+const (
+	ReferralPending  = "PENDING"
+	ReferralRewarded = "REWARDED"
+	ReferralRejected = "REJECTED"
+)
+
+// referrals.reject_reason
+const (
+	ReferralRejectSelfPhone      = "self_phone"      // do'stning telefoni taklif qilganniki bilan bir xil
+	ReferralRejectDuplicatePhone = "duplicate_phone" // bu telefon boshqa (eski) akkauntda bor
+)
+
+type Referral struct {
+	RefereeTgID   int64      `json:"referee_tg_id"`
+	RefereeName   string     `json:"referee_name"`
+	RefereePhone  string     `json:"referee_phone"`
+	ReferrerTgID  int64      `json:"referrer_tg_id"`
+	ReferrerName  string     `json:"referrer_name"`
+	ReferrerPhone string     `json:"referrer_phone"`
+	Status        string     `json:"status"`
+	RejectReason  string     `json:"reject_reason"`
+	OrderID       string     `json:"order_id"`
+	ReferrerCode  string     `json:"referrer_code"`
+	RefereeCode   string     `json:"referee_code"`
+	RewardAmount  int64      `json:"reward_amount"`
+	CreatedAt     time.Time  `json:"created_at"`
+	RewardedAt    *time.Time `json:"rewarded_at"`
+
+	ReferrerLanguage string `json:"-"`
+	RefereeLanguage  string `json:"-"`
+}
+
+// ReferralReward - RewardPending'ga beriladigan kodlar
+type ReferralReward struct {
+	OrderID      string
+	ReferrerCode string
+	RefereeCode  string
+	Amount       int64
+}
+
+type GetListReferralRequest struct {
+	Offset       int64      `json:"offset"`
+	Limit        int64      `json:"limit"`
+	Status       string     `json:"status"`
+	ReferrerTgID int64      `json:"referrer_tg_id"`
+	From         *time.Time `json:"from"`
+	To           *time.Time `json:"to"`
+}
+
+type GetListReferralResponse struct {
+	Count     int64      `json:"count"`
+	Referrals []Referral `json:"referrals"`
+}
+
+// ReferralStats - bot'dagi "Do'stlarni taklif qilish" ekrani
+type ReferralStats struct {
+	Invited  int64    `json:"invited"`
+	Rewarded int64    `json:"rewarded"`
+	Pending  int64    `json:"pending"`
+	Codes    []string `json:"codes"` // olingan promo-kodlar (taklif qilgan sifatida ham, do'st sifatida ham)
+}
+
+type TopReferrer struct {
+	TgID     int64  `json:"tg_id"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Invited  int64  `json:"invited"`
+	Rewarded int64  `json:"rewarded"`
+}
+
+type ReferralSummary struct {
+	Total          int64         `json:"total"`
+	Pending        int64         `json:"pending"`
+	Rewarded       int64         `json:"rewarded"`
+	Rejected       int64         `json:"rejected"`
+	RewardedAmount int64         `json:"rewarded_amount"`
+	TopReferrers   []TopReferrer `json:"top_referrers"`
+}
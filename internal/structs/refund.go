@@ -0,0 +1,59 @@
+package structs
+
+import "time"
+
+// order_refunds.status
+const (
+	// yozuv provider chaqirilishidan oldin yaratiladi; natija kelgach DONE/MANUAL/FAILED
+	RefundStatusPending = "PENDING"
+	// provider orqali qaytarildi
+	RefundStatusDone = "DONE"
+	// provider qisman qaytarishni qo'llamaydi - kabinetdan qo'lda qaytariladi
+	RefundStatusManual = "MANUAL"
+	// provider xato qaytardi, qo'lda tekshirish kerak
+	RefundStatusFailed = "FAILED"
+)
+
+// RefundItem - olib tashlanadigan qator; Quantity 0 = qator butunlay
+type RefundItem struct {
+	ProductID string `json:"productId"`
+	Quantity  int64  `json:"quantity"`
+	Name      Name   `json:"name"`
+	// bitta dona narxi (mahsulot + box)
+	Price int64 `json:"price"`
+}
+
+type OrderRefund struct {
+	ID            int64        `json:"id"`
+	OrderID       string       `json:"orderId"`
+	PaymentMethod string       `json:"paymentMethod"`
+	Amount        int64        `json:"amount"`
+	TotalBefore   int64        `json:"totalBefore"`
+	TotalAfter    int64        `json:"totalAfter"`
+	Items         []RefundItem `json:"items"`
+	Reason        string       `json:"reason"`
+	Status        string       `json:"status"`
+	Note          string       `json:"note"`
+	CreatedBy     int          `json:"createdBy"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+type RemoveOrderItemsRequest struct {
+	OrderID    string       `json:"-"`
+	EmployeeID int          `json:"-"`
+	Items      []RefundItem `json:"items"`
+	Reason     string       `json:"reason"`
+}
+
+type RemoveOrderItemsResponse struct {
+	Refund OrderRefund `json:"refund"`
+	Order  Order       `json:"order"`
+}
+
+// MarkRefundDoneRequest - MANUAL/FAILED qaytarish kabinetdan qilingandan keyin
+type MarkRefundDoneRequest struct {
+	OrderID  string `json:"-"`
+	RefundID int64  `json:"-"`
+	Note     string `json:"note"`
+}
@@ -0,0 +1,47 @@
+package structs
+
+import "time"
+
+const (
+	SupportDirectionIn  = "IN"  // mijozdan operatorga
+	SupportDirectionOut = "OUT" // operatordan mijozga
+)
+
+// SupportThread - mijozning support yozishmasi (operator guruhidagi topic/header)
+type SupportThread struct {
+	TgID            int64     `json:"tg_id"`
+	ChatID          int64     `json:"chat_id"`
+	TopicID         int       `json:"topic_id"`
+	HeaderMessageID int       `json:"header_message_id"`
+	OrderID         *string   `json:"order_id"`
+	LastMessageAt   time.Time `json:"last_message_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type SupportMessage struct {
+	ID              int64     `json:"id"`
+	TgID            int64     `json:"tg_id"`
+	Direction       string    `json:"direction"`
+	Kind            string    `json:"kind"`
+	Text            string    `json:"text"`
+	FileID          string    `json:"file_id"`
+	ClientMessageID int       `json:"-"`
+	GroupMessageID  int       `json:"-"`
+	EmployeeID      *int64    `json:"employee_id"`
+	OrderID         *string   `json:"order_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type GetSupportMessagesRequest struct {
+	TgID   int64 `json:"tg_id"`
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+// SupportConversation - admin panelda mijoz kartasidagi yozishma
+type SupportConversation struct {
+	Thread   *SupportThread   `json:"thread"`
+	Count    int64            `json:"count"`
+	Messages []SupportMessage `json:"messages"`
+}
@@ -0,0 +1,15 @@
+package structs
+
+import "time"
+
+// Telegram native payments (sendInvoice -> pre_checkout_query -> successful_payment)
+type TelegramPayment struct {
+	ID                      string    `json:"id"`
+	OrderID                 string    `json:"orderId"`
+	TgID                    int64     `json:"tgId"`
+	Currency                string    `json:"currency"`
+	TotalAmount             int64     `json:"totalAmount"` // eng kichik birlikda (UZS uchun tiyin)
+	TelegramPaymentChargeID string    `json:"telegramPaymentChargeId"`
+	ProviderPaymentChargeID string    `json:"providerPaymentChargeId"`
+	CreatedAt               time.Time `json:"createdAt"`
+}
@@ -0,0 +1,70 @@
+package structs
+
+import (
+	"database/sql"
+	"time"
+)
+
+// uzum_transactions.status va Merchant API javobidagi status
+const (
+	UzumStatusOK        = "OK"
+	UzumStatusFailed    = "FAILED"
+	UzumStatusCreated   = "CREATED"
+	UzumStatusConfirmed = "CONFIRMED"
+	UzumStatusReversed  = "REVERSED"
+)
+
+// Uzum Merchant API xato kodlari
+const (
+	UzumErrAccessDenied     = "10001"
+	UzumErrJSONParse        = "10002"
+	UzumErrInvalidOperation = "10003"
+	UzumErrMissingParams    = "10005"
+	UzumErrInvalidServiceID = "10006"
+	UzumErrAccountNotFound  = "10007"
+	UzumErrAlreadyPaid      = "10008"
+	UzumErrPaymentCancelled = "10009"
+	UzumErrInvalidAmount    = "10011"
+	UzumErrTransNotFound    = "10014"
+	UzumErrDataVerification = "99999"
+)
+
+type UzumTransaction struct {
+	ID            string
+	TransID       string
+	OrderID       string
+	ServiceID     int64
+	Amount        int64 // tiyin
+	Status        string
+	TransTime     int64
+	ConfirmTime   sql.NullInt64
+	ReverseTime   sql.NullInt64
+	PaymentSource sql.NullString
+	Phone         sql.NullString
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// UzumRequest - check/create/confirm/reverse/status so'rovlari uchun umumiy body
+type UzumRequest struct {
+	ServiceID     int64             `json:"serviceId"`
+	Timestamp     int64             `json:"timestamp"`
+	TransID       string            `json:"transId,omitempty"`
+	Params        map[string]string `json:"params,omitempty"`
+	Amount        int64             `json:"amount,omitempty"`
+	PaymentSource string            `json:"paymentSource,omitempty"`
+	Phone         string            `json:"phone,omitempty"`
+}
+
+type UzumResponse struct {
+	ServiceID   int64          `json:"serviceId"`
+	Timestamp   int64          `json:"timestamp,omitempty"`
+	TransID     string         `json:"transId,omitempty"`
+	Status      string         `json:"status"`
+	TransTime   int64          `json:"transTime,omitempty"`
+	ConfirmTime int64          `json:"confirmTime,omitempty"`
+	ReverseTime int64          `json:"reverseTime,omitempty"`
+	Data        map[string]any `json:"data,omitempty"`
+	Amount      int64          `json:"amount,omitempty"`
+	ErrorCode   string         `json:"errorCode,omitempty"`
+}
@@ -9,6 +9,9 @@ const (
 	EventOrderUpsert    EventType = "order.upsert"    // ixtiyoriy: full order
 	EventOrdersSnapshot EventType = "orders.snapshot" // ixtiyoriy: connect bo‘lganda
 	EventOrderRemove    EventType = "order.remove"
+	EventOrderCancelled EventType = "order.cancelled" // mijoz o'zi bekor qildi
+	EventClickInvoice   EventType = "order.click_invoice"
+	EventOrderRefund    EventType = "order.refund" // to'langan zakazdan qator olib tashlandi
 )
 
 type Event struct {
@@ -62,3 +65,22 @@ type OrderUpsertPayload struct {
 type OrderRemovePayload struct {
 	ID string `json:"id"`
 }
+
+type OrderCancelledPayload struct {
+	ID          string `json:"id"`
+	TgID        int64  `json:"tgId"`
+	OrderNumber int64  `json:"order_number"`
+	Reason      string `json:"reason"`
+	CancelledBy string `json:"cancelledBy"`
+}
+
+// operator yuborgan Click invoice holati (yuborildi / to'landi / bekor)
+type ClickInvoicePayload struct {
+	ClickPhoneInvoice
+	PaymentStatus string `json:"paymentStatus,omitempty"`
+}
+
+type OrderRefundPayload struct {
+	OrderNumber int64       `json:"orderNumber"`
+	Refund      OrderRefund `json:"refund"`
+}
@@ -0,0 +1,325 @@
+package support
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/internal/texts"
+	"sushitana/pkg/config"
+	"sushitana/pkg/logger"
+	employeerepo "sushitana/pkg/repository/postgres/employee_repo"
+	orderrepo "sushitana/pkg/repository/postgres/order_repo"
+	supportrepo "sushitana/pkg/repository/postgres/support_repo"
+	"sushitana/pkg/utils"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+
+		Config       config.IConfig
+		Logger       logger.Logger
+		SupportRepo  supportrepo.Repo
+		OrderRepo    orderrepo.Repo
+		EmployeeRepo employeerepo.Repo
+		Bot          *tgbotapi.BotAPI `optional:"true"`
+	}
+
+	Service interface {
+		// ChatID - operatorlar yozishadigan guruh (support_chat_id, bo'lmasa admin_chat_id); 0 - support o'chiq
+		ChatID() int64
+		// FromClient - support rejimidagi mijoz xabari guruhdagi uning topic/thread'iga ko'chiriladi
+		FromClient(ctx context.Context, client structs.Client, msg *tgbotapi.Message) error
+		// FromOperator - guruhdagi javob mijozga; mijoz thread'iga tegishli bo'lmasa false
+		FromOperator(ctx context.Context, msg *tgbotapi.Message) (bool, error)
+		GetConversation(ctx context.Context, req structs.GetSupportMessagesRequest) (structs.SupportConversation, error)
+	}
+
+	service struct {
+		chatID       int64
+		logger       logger.Logger
+		supportRepo  supportrepo.Repo
+		orderRepo    orderrepo.Repo
+		employeeRepo employeerepo.Repo
+		bot          *tgbotapi.BotAPI
+	}
+)
+
+func New(p Params) Service {
+	chatID := p.Config.GetInt64("support_chat_id")
+	if chatID == 0 {
+		chatID = p.Config.GetInt64("admin_chat_id")
+	}
+	return &service{
+		chatID:       chatID,
+		logger:       p.Logger,
+		supportRepo:  p.SupportRepo,
+		orderRepo:    p.OrderRepo,
+		employeeRepo: p.EmployeeRepo,
+		bot:          p.Bot,
+	}
+}
+
+func (s *service) ChatID() int64 {
+	if s.bot == nil {
+		return 0
+	}
+	return s.chatID
+}
+
+func (s *service) FromClient(ctx context.Context, client structs.Client, msg *tgbotapi.Message) error {
+	if s.ChatID() == 0 {
+		return structs.ErrBadRequest
+	}
+
+	order := s.lastOrder(ctx, client.TgID)
+	var orderID *string
+	if order != nil {
+		orderID = &order.ID
+	}
+
+	thread, err := s.ensureThread(ctx, client, order)
+	if err != nil {
+		return err
+	}
+
+	cp := tgbotapi.NewCopyMessage(thread.ChatID, msg.Chat.ID, msg.MessageID)
+	cp.MessageThreadID = thread.TopicID
+	if thread.TopicID == 0 && thread.HeaderMessageID != 0 {
+		// oddiy guruh: mijoz xabarlari header'ga reply bo'lib turadi, operator shunga reply qiladi
+		cp.ReplyParameters = tgbotapi.ReplyParameters{MessageID: thread.HeaderMessageID, AllowSendingWithoutReply: true}
+	}
+	copied, err := s.bot.CopyMessage(cp)
+	if err != nil {
+		s.logger.Error(ctx, "support: copy to group failed", zap.Int64("tgId", client.TgID), zap.Error(err))
+		return fmt.Errorf("support copy to group failed: %w", err)
+	}
+
+	kind, text, fileID := messageContent(msg)
+	if err := s.supportRepo.CreateMessage(ctx, structs.SupportMessage{
+		TgID:            client.TgID,
+		Direction:       structs.SupportDirectionIn,
+		Kind:            kind,
+		Text:            text,
+		FileID:          fileID,
+		ClientMessageID: msg.MessageID,
+		GroupMessageID:  copied.MessageID,
+		OrderID:         orderID,
+	}); err != nil {
+		s.logger.Error(ctx, "->supportRepo.CreateMessage", zap.Error(err))
+	}
+	if err := s.supportRepo.TouchThread(ctx, client.TgID, orderID); err != nil {
+		s.logger.Error(ctx, "->supportRepo.TouchThread", zap.Error(err))
+	}
+	return nil
+}
+
+// ensureThread - mijozga topic (forum bo'lsa) yoki header xabar; buyurtma o'zgargan bo'lsa yangi header
+func (s *service) ensureThread(ctx context.Context, client structs.Client, order *structs.Order) (structs.SupportThread, error) {
+	thread, err := s.supportRepo.GetThread(ctx, client.TgID)
+	if err != nil && !errors.Is(err, structs.ErrNotFound) {
+		return structs.SupportThread{}, err
+	}
+
+	fresh := errors.Is(err, structs.ErrNotFound) || thread.ChatID != s.chatID
+	orderChanged := order != nil && (thread.OrderID == nil || *thread.OrderID != order.ID)
+	if !fresh && !orderChanged {
+		return thread, nil
+	}
+
+	if fresh {
+		thread = structs.SupportThread{TgID: client.TgID, ChatID: s.chatID}
+
+		// forum guruh bo'lsa har mijozga alohida topic; oddiy guruhda xato qaytadi -> header reply'lar
+		topic := tgbotapi.CreateForumTopicConfig{
+			ChatConfig: tgbotapi.ChatConfig{ChatID: s.chatID},
+			Name:       topicName(client),
+		}
+		if resp, err := s.bot.Request(topic); err == nil {
+			var ft tgbotapi.ForumTopic
+			if err := json.Unmarshal(resp.Result, &ft); err == nil {
+				thread.TopicID = ft.MessageThreadID
+			}
+		}
+	}
+
+	header := tgbotapi.NewMessage(s.chatID, headerText(client, order))
+	header.ParseMode = tgbotapi.ModeHTML
+	header.MessageThreadID = thread.TopicID
+	sent, err := s.bot.Send(header)
+	if err != nil {
+		s.logger.Error(ctx, "support: header send failed", zap.Int64("tgId", client.TgID), zap.Error(err))
+		return structs.SupportThread{}, fmt.Errorf("support header send failed: %w", err)
+	}
+	thread.HeaderMessageID = sent.MessageID
+	if order != nil {
+		thread.OrderID = &order.ID
+	}
+
+	if err := s.supportRepo.SaveThread(ctx, thread); err != nil {
+		return structs.SupportThread{}, err
+	}
+	return thread, nil
+}
+
+func (s *service) FromOperator(ctx context.Context, msg *tgbotapi.Message) (bool, error) {
+	if msg == nil || msg.From == nil || msg.From.IsBot || msg.Chat.ID != s.chatID {
+		return false, nil
+	}
+	// topic ochildi/yopildi kabi service xabarlar
+	if msg.ForumTopicCreated != nil || msg.ForumTopicClosed != nil || msg.ForumTopicReopened != nil || msg.ForumTopicEdited != nil {
+		return false, nil
+	}
+
+	var (
+		tgID int64
+		err  = structs.ErrNotFound
+	)
+	if msg.IsTopicMessage && msg.MessageThreadID != 0 {
+		tgID, err = s.supportRepo.FindTgIDByTopic(ctx, msg.Chat.ID, msg.MessageThreadID)
+	}
+	if errors.Is(err, structs.ErrNotFound) && msg.ReplyToMessage != nil {
+		tgID, err = s.supportRepo.FindTgIDByGroupMessage(ctx, msg.Chat.ID, msg.ReplyToMessage.MessageID)
+	}
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	copied, err := s.bot.CopyMessage(tgbotapi.NewCopyMessage(tgID, msg.Chat.ID, msg.MessageID))
+	if err != nil {
+		s.logger.Error(ctx, "support: copy to client failed", zap.Int64("tgId", tgID), zap.Error(err))
+		return true, fmt.Errorf("support copy to client failed: %w", err)
+	}
+
+	var employeeID *int64
+	if emp, err := s.employeeRepo.GetByTgID(ctx, msg.From.ID); err == nil {
+		employeeID = &emp.Id
+	}
+
+	var orderID *string
+	if thread, err := s.supportRepo.GetThread(ctx, tgID); err == nil {
+		orderID = thread.OrderID
+	}
+
+	kind, text, fileID := messageContent(msg)
+	if err := s.supportRepo.CreateMessage(ctx, structs.SupportMessage{
+		TgID:            tgID,
+		Direction:       structs.SupportDirectionOut,
+		Kind:            kind,
+		Text:            text,
+		FileID:          fileID,
+		ClientMessageID: copied.MessageID,
+		GroupMessageID:  msg.MessageID,
+		EmployeeID:      employeeID,
+		OrderID:         orderID,
+	}); err != nil {
+		s.logger.Error(ctx, "->supportRepo.CreateMessage", zap.Error(err))
+	}
+	if err := s.supportRepo.TouchThread(ctx, tgID, nil); err != nil {
+		s.logger.Error(ctx, "->supportRepo.TouchThread", zap.Error(err))
+	}
+	return true, nil
+}
+
+func (s *service) GetConversation(ctx context.Context, req structs.GetSupportMessagesRequest) (structs.SupportConversation, error) {
+	var resp structs.SupportConversation
+
+	thread, err := s.supportRepo.GetThread(ctx, req.TgID)
+	switch {
+	case err == nil:
+		resp.Thread = &thread
+	case !errors.Is(err, structs.ErrNotFound):
+		s.logger.Error(ctx, "->supportRepo.GetThread", zap.Error(err))
+		return structs.SupportConversation{}, err
+	}
+
+	list, count, err := s.supportRepo.GetMessages(ctx, req)
+	if err != nil {
+		s.logger.Error(ctx, "->supportRepo.GetMessages", zap.Error(err))
+		return structs.SupportConversation{}, err
+	}
+	resp.Messages = list
+	resp.Count = count
+	return resp, nil
+}
+
+func (s *service) lastOrder(ctx context.Context, tgID int64) *structs.Order {
+	list, err := s.orderRepo.GetByTgId(ctx, tgID)
+	if err != nil || len(list.Orders) == 0 {
+		return nil
+	}
+	return &list.Orders[0]
+}
+
+func topicName(c structs.Client) string {
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = "Клиент"
+	}
+	if r := []rune(name); len(r) > 100 {
+		name = string(r[:100])
+	}
+	return fmt.Sprintf("%s · %d", name, c.TgID)
+}
+
+// headerText - operatorga: kim yozyapti va oxirgi buyurtmasi
+func headerText(c structs.Client, o *structs.Order) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "💬 <b>%s</b>\n", html.EscapeString(strings.TrimSpace(c.Name)))
+	if phone := strings.TrimSpace(c.Phone); phone != "" {
+		fmt.Fprintf(&b, "📞 %s\n", html.EscapeString(phone))
+	}
+	fmt.Fprintf(&b, "🆔 <code>%d</code>\n", c.TgID)
+	if o != nil {
+		fmt.Fprintf(&b, "🧾 Последний заказ #%d · %s · %s %s\n",
+			o.OrderNumber,
+			html.EscapeString(texts.OrderStatus(utils.RU, strings.ToUpper(o.Status))),
+			utils.FCurrency(float64(o.TotalPrice)),
+			texts.Get(utils.RU, texts.CurrencySymbol),
+		)
+	}
+	b.WriteString("\n↩️ Ответьте в этой ветке (или reply на сообщение клиента)")
+	return b.String()
+}
+
+// messageContent - admin panel uchun xabar turi, matni/caption va file_id
+func messageContent(m *tgbotapi.Message) (kind, text, fileID string) {
+	text = m.Text
+	if text == "" {
+		text = m.Caption
+	}
+	switch {
+	case len(m.Photo) > 0:
+		return "photo", text, m.Photo[len(m.Photo)-1].FileID
+	case m.Voice != nil:
+		return "voice", text, m.Voice.FileID
+	case m.VideoNote != nil:
+		return "video_note", text, m.VideoNote.FileID
+	case m.Video != nil:
+		return "video", text, m.Video.FileID
+	case m.Audio != nil:
+		return "audio", text, m.Audio.FileID
+	case m.Document != nil:
+		return "document", text, m.Document.FileID
+	case m.Sticker != nil:
+		return "sticker", m.Sticker.Emoji, m.Sticker.FileID
+	case m.Location != nil:
+		return "location", fmt.Sprintf("%.6f,%.6f", m.Location.Latitude, m.Location.Longitude), ""
+	default:
+		return "text", text, ""
+	}
+}
@@ -73,6 +73,126 @@ const (
 
 	DeliveryZonesNotConfigured TextKey = "delivery_zones_not_configured"
 
+	// My orders
+	MyOrdersButton       TextKey = "my_orders_button"
+	MyOrdersTitle        TextKey = "my_orders_title"
+	MyOrdersEmpty        TextKey = "my_orders_empty"
+	OrderDetailTitle     TextKey = "order_detail_title"    // format: "#%d"
+	OrderDetailDate      TextKey = "order_detail_date"     // format: "%s"
+	OrderDetailAddress   TextKey = "order_detail_address"  // format: "%s"
+	OrderDetailPayment   TextKey = "order_detail_payment"  // format: "%s, %s"
+	OrderDetailEta       TextKey = "order_detail_eta"      // format: "%s"
+	OrderDetailDelivery  TextKey = "order_detail_delivery" // format: "%s"
+	OrderRefreshBtn      TextKey = "order_refresh_btn"
+	OrderPayAgainBtn     TextKey = "order_pay_again_btn"
+	OrderReorderBtn      TextKey = "order_reorder_btn"
+	OrderReorderDone     TextKey = "order_reorder_done"
+	OrderRefreshed       TextKey = "order_refreshed"
+	PaymentStatusPaid    TextKey = "payment_status_paid"
+	PaymentStatusPending TextKey = "payment_status_pending"
+	PaymentStatusUnpaid  TextKey = "payment_status_unpaid"
+
+	// Order cancel (mijoz o'zi)
+	OrderCancelBtn         TextKey = "order_cancel_btn"
+	OrderCancelAskReason   TextKey = "order_cancel_ask_reason" // format: "#%d"
+	OrderCancelReasonMind  TextKey = "order_cancel_reason_mind"
+	OrderCancelReasonLong  TextKey = "order_cancel_reason_long"
+	OrderCancelReasonWrong TextKey = "order_cancel_reason_wrong"
+	OrderCancelDone        TextKey = "order_cancel_done" // format: "#%d"
+	OrderCancelNotAllowed  TextKey = "order_cancel_not_allowed"
+	OrderCancelTooMany     TextKey = "order_cancel_too_many"
+
+	// to'lov usulini almashtirish (WAITING_PAYMENT)
+	OrderChangePaymentBtn  TextKey = "order_change_payment_btn"
+	OrderChangePaymentAsk  TextKey = "order_change_payment_ask" // format: "#%d"
+	OrderPaymentChanged    TextKey = "order_payment_changed"
+	OrderPaymentNotAllowed TextKey = "order_payment_not_allowed"
+
+	// to'langan zakazdan qator olib tashlandi
+	OrderItemsRemoved TextKey = "order_items_removed" // format: "#%d"
+	OrderRefundAmount TextKey = "order_refund_amount" // format: summa
+	OrderRefundManual TextKey = "order_refund_manual"
+
+	// yetkazishda qaytim summasi (naqd) va kuryerga choy puli (online)
+	OrderAskChange      TextKey = "order_ask_change"
+	OrderNoChangeBtn    TextKey = "order_no_change_btn"
+	OrderChangeTooSmall TextKey = "order_change_too_small"
+	OrderAskTip         TextKey = "order_ask_tip"
+	OrderNoTipBtn       TextKey = "order_no_tip_btn"
+	OrderTipInvalid     TextKey = "order_tip_invalid"
+
+	// Telegram native payments
+	TgInvoiceTitle       TextKey = "tg_invoice_title" // format: "#%d"
+	TgInvoiceDescription TextKey = "tg_invoice_description"
+	TgInvoiceDelivery    TextKey = "tg_invoice_delivery"
+	TgInvoiceTip         TextKey = "tg_invoice_tip"
+	TgInvoiceDiscount    TextKey = "tg_invoice_discount" // format: "%s" (promo-kod)
+	TgPayOrderClosed     TextKey = "tg_pay_order_closed"
+	TgPayAmountChanged   TextKey = "tg_pay_amount_changed"
+	TgPaySuccess         TextKey = "tg_pay_success"
+	TgPayResendInvoice   TextKey = "tg_pay_resend_invoice"
+
+	// Product search (bot + inline)
+	SearchButton     TextKey = "search_button"
+	SearchPrompt     TextKey = "search_prompt"
+	SearchInlineBtn  TextKey = "search_inline_btn"
+	SearchResults    TextKey = "search_results" // format: "%s"
+	SearchEmpty      TextKey = "search_empty"   // format: "%s"
+	SearchAdded      TextKey = "search_added"
+	SearchInlineOpen TextKey = "search_inline_open"
+
+	// /start deep links
+	DeepLinkNotFound  TextKey = "deep_link_not_found"
+	DeepLinkPromo     TextKey = "deep_link_promo"     // format: "%s"
+	PromoOrderComment TextKey = "promo_order_comment" // format: "%s"
+	PromoCodeUsed     TextKey = "promo_code_used"     // format: "%s"
+
+	// saqlangan manzillar
+	AskSavedAddress TextKey = "ask_saved_address"
+
+	// lokatsiyadan keyingi manzil tafsilotlari (ixtiyoriy)
+	AddressHouseAsk     TextKey = "address_house_ask"
+	AddressFlatAsk      TextKey = "address_flat_ask"
+	AddressEntranceAsk  TextKey = "address_entrance_ask"
+	AddressFloorAsk     TextKey = "address_floor_ask"
+	AddressDoorphoneAsk TextKey = "address_doorphone_ask"
+	AddressCommentAsk   TextKey = "address_comment_ask"
+	AddressSkipBtn      TextKey = "address_skip_btn"
+	AddressDoneBtn      TextKey = "address_done_btn"
+
+	// support chat (mijoz <-> operator)
+	SupportButton      TextKey = "support_button"
+	SupportWelcome     TextKey = "support_welcome"
+	SupportExitButton  TextKey = "support_exit_button"
+	SupportUnavailable TextKey = "support_unavailable"
+	SupportSendFailed  TextKey = "support_send_failed"
+
+	// yetkazilgandan keyin baho
+	RatingAskFood     TextKey = "rating_ask_food"
+	RatingAskDelivery TextKey = "rating_ask_delivery"
+	RatingAskComment  TextKey = "rating_ask_comment"
+	RatingSkipBtn     TextKey = "rating_skip_btn"
+	RatingThanks      TextKey = "rating_thanks"
+
+	// tashlab ketilgan savat eslatmasi
+	CartReminderTitle       TextKey = "cart_reminder_title"
+	CartReminderCompleteBtn TextKey = "cart_reminder_complete_btn"
+	CartReminderOffBtn      TextKey = "cart_reminder_off_btn"
+	CartReminderOffDone     TextKey = "cart_reminder_off_done"
+
+	// referal dasturi
+	ReferralButton         TextKey = "referral_button"
+	ReferralInfo           TextKey = "referral_info"
+	ReferralCodes          TextKey = "referral_codes"
+	ReferralShareBtn       TextKey = "referral_share_btn"
+	ReferralShareText      TextKey = "referral_share_text"
+	ReferralRewardReferrer TextKey = "referral_reward_referrer"
+	ReferralRewardReferee  TextKey = "referral_reward_referee"
+
+	// saqlangan karta (Payme Subscribe)
+	SavedCardPaid   TextKey = "saved_card_paid"
+	SavedCardFailed TextKey = "saved_card_failed"
+
 	MinOrderNotReached TextKey = "MinOrderNotReached"
 	ZoneOhangaron      TextKey = "ZoneOhangaron"
 	CurrencyUzs        TextKey = "CurrencyUzs"
@@ -439,13 +559,498 @@ var MapText = map[TextKey]utils.Language{
 		RU: "сум",
 		EN: "UZS",
 	},
+	MyOrdersButton: {
+		UZ: "📋 Buyurtmalarim",
+		RU: "📋 Мои заказы",
+		EN: "📋 My orders",
+	},
+	MyOrdersTitle: {
+		UZ: "📋 Oxirgi buyurtmalaringiz:",
+		RU: "📋 Ваши последние заказы:",
+		EN: "📋 Your recent orders:",
+	},
+	MyOrdersEmpty: {
+		UZ: "Sizda hali buyurtmalar yo‘q",
+		RU: "У вас пока нет заказов",
+		EN: "You have no orders yet",
+	},
+	OrderDetailTitle: {
+		UZ: "🧾 Buyurtma #%d",
+		RU: "🧾 Заказ #%d",
+		EN: "🧾 Order #%d",
+	},
+	OrderDetailDate: {
+		UZ: "🕒 Sana: %s",
+		RU: "🕒 Дата: %s",
+		EN: "🕒 Date: %s",
+	},
+	OrderDetailAddress: {
+		UZ: "📍 Manzil: %s",
+		RU: "📍 Адрес: %s",
+		EN: "📍 Address: %s",
+	},
+	OrderDetailPayment: {
+		UZ: "💸 To‘lov: %s, %s",
+		RU: "💸 Оплата: %s, %s",
+		EN: "💸 Payment: %s, %s",
+	},
+	OrderDetailEta: {
+		UZ: "⏱ Taxminiy vaqt: %s gacha",
+		RU: "⏱ Ориентировочно до: %s",
+		EN: "⏱ Estimated by: %s",
+	},
+	OrderDetailDelivery: {
+		UZ: "🚚 Yetkazib berish: %s",
+		RU: "🚚 Доставка: %s",
+		EN: "🚚 Delivery: %s",
+	},
+	OrderRefreshBtn: {
+		UZ: "🔄 Yangilash",
+		RU: "🔄 Обновить",
+		EN: "🔄 Refresh",
+	},
+	OrderPayAgainBtn: {
+		UZ: "💳 To‘lash",
+		RU: "💳 Оплатить",
+		EN: "💳 Pay",
+	},
+	OrderChangePaymentBtn: {
+		UZ: "🔀 To‘lov usulini o‘zgartirish",
+		RU: "🔀 Изменить способ оплаты",
+		EN: "🔀 Change payment method",
+	},
+	OrderChangePaymentAsk: {
+		UZ: "#%d buyurtma uchun yangi to‘lov usulini tanlang:",
+		RU: "Выберите новый способ оплаты для заказа #%d:",
+		EN: "Choose a new payment method for order #%d:",
+	},
+	OrderPaymentChanged: {
+		UZ: "✅ To‘lov usuli o‘zgartirildi",
+		RU: "✅ Способ оплаты изменён",
+		EN: "✅ Payment method changed",
+	},
+	OrderPaymentNotAllowed: {
+		UZ: "Bu buyurtma to‘lovni kutmayapti",
+		RU: "Этот заказ не ожидает оплаты",
+		EN: "This order is not awaiting payment",
+	},
+	OrderItemsRemoved: {
+		UZ: "⚠️ #%d buyurtmadan quyidagi mahsulotlar olib tashlandi:",
+		RU: "⚠️ Из заказа #%d убраны следующие позиции:",
+		EN: "⚠️ The following items were removed from order #%d:",
+	},
+	OrderRefundAmount: {
+		UZ: "💸 Qaytariladigan summa: %s so‘m",
+		RU: "💸 Сумма к возврату: %s сум",
+		EN: "💸 Refund amount: %s UZS",
+	},
+	OrderRefundManual: {
+		UZ: "Pul 1-3 ish kuni ichida kartangizga qaytariladi.",
+		RU: "Деньги вернутся на карту в течение 1-3 рабочих дней.",
+		EN: "The money will be returned to your card within 1-3 business days.",
+	},
+	OrderReorderBtn: {
+		UZ: "🔁 Qayta buyurtma",
+		RU: "🔁 Повторить заказ",
+		EN: "🔁 Reorder",
+	},
+	OrderReorderDone: {
+		UZ: "✅ Mahsulotlar savatga qo‘shildi",
+		RU: "✅ Товары добавлены в корзину",
+		EN: "✅ Items added to cart",
+	},
+	OrderRefreshed: {
+		UZ: "Yangilandi",
+		RU: "Обновлено",
+		EN: "Updated",
+	},
+	PaymentStatusPaid: {
+		UZ: "✅ to‘langan",
+		RU: "✅ оплачено",
+		EN: "✅ paid",
+	},
+	PaymentStatusPending: {
+		UZ: "⏳ to‘lov kutilmoqda",
+		RU: "⏳ ожидает оплаты",
+		EN: "⏳ pending",
+	},
+	PaymentStatusUnpaid: {
+		UZ: "to‘lanmagan",
+		RU: "не оплачено",
+		EN: "unpaid",
+	},
+	OrderCancelBtn: {
+		UZ: "❌ Bekor qilish",
+		RU: "❌ Отменить заказ",
+		EN: "❌ Cancel order",
+	},
+	OrderCancelAskReason: {
+		UZ: "Buyurtma #%d ni bekor qilish sababini tanlang yoki yozib yuboring:",
+		RU: "Выберите или напишите причину отмены заказа #%d:",
+		EN: "Choose or type the reason for cancelling order #%d:",
+	},
+	OrderCancelReasonMind: {
+		UZ: "Fikrimni o‘zgartirdim",
+		RU: "Передумал(а)",
+		EN: "Changed my mind",
+	},
+	OrderCancelReasonLong: {
+		UZ: "Juda uzoq kutish",
+		RU: "Слишком долго ждать",
+		EN: "Takes too long",
+	},
+	OrderCancelReasonWrong: {
+		UZ: "Buyurtmada xato bor",
+		RU: "Ошибка в заказе",
+		EN: "Mistake in the order",
+	},
+	OrderCancelDone: {
+		UZ: "❌ Buyurtma bekor qilindi",
+		RU: "❌ Заказ отменён",
+		EN: "❌ Order has been cancelled",
+	},
+	OrderCancelNotAllowed: {
+		UZ: "Bu buyurtmani endi bekor qilib bo‘lmaydi. Operator bilan bog‘laning.",
+		RU: "Этот заказ уже нельзя отменить. Свяжитесь с оператором.",
+		EN: "This order can no longer be cancelled. Please contact the operator.",
+	},
+	OrderCancelTooMany: {
+		UZ: "Juda ko‘p bekor qilindi. Birozdan keyin urinib ko‘ring.",
+		RU: "Слишком много отмен. Попробуйте позже.",
+		EN: "Too many cancellations. Please try again later.",
+	},
+	TgInvoiceTitle: {
+		UZ: "Buyurtma #%d",
+		RU: "Заказ #%d",
+		EN: "Order #%d",
+	},
+	TgInvoiceDescription: {
+		UZ: "SUSHITANA buyurtmasi uchun to‘lov. To‘lovdan so‘ng buyurtmangizni tayyorlashni boshlaymiz.",
+		RU: "Оплата заказа SUSHITANA. После оплаты мы начнём готовить ваш заказ.",
+		EN: "Payment for your SUSHITANA order. We start cooking right after the payment.",
+	},
+	TgInvoiceDelivery: {
+		UZ: "Yetkazib berish",
+		RU: "Доставка",
+		EN: "Delivery",
+	},
+	TgInvoiceTip: {
+		UZ: "Kuryerga choy puli",
+		RU: "Чаевые курьеру",
+		EN: "Courier tip",
+	},
+	TgInvoiceDiscount: {
+		UZ: "Chegirma (%s)",
+		RU: "Скидка (%s)",
+		EN: "Discount (%s)",
+	},
+	OrderAskChange: {
+		UZ: "💵 Qaysi summadan qaytim tayyorlab qo‘yaylik? Summani yozing yoki tanlang.",
+		RU: "💵 С какой суммы подготовить сдачу? Напишите сумму или выберите.",
+		EN: "💵 What amount should the courier bring change for? Type it or pick one.",
+	},
+	OrderNoChangeBtn: {
+		UZ: "Qaytim kerak emas",
+		RU: "Без сдачи",
+		EN: "No change needed",
+	},
+	OrderChangeTooSmall: {
+		UZ: "Summa buyurtma summasidan kam bo‘lmasligi kerak.",
+		RU: "Сумма не может быть меньше суммы заказа.",
+		EN: "The amount can't be less than the order total.",
+	},
+	OrderAskTip: {
+		UZ: "🛵 Kuryerga choy puli qoldirasizmi? U buyurtma bilan birga to‘lanadi.",
+		RU: "🛵 Оставите чаевые курьеру? Они оплачиваются вместе с заказом.",
+		EN: "🛵 Would you like to tip the courier? It's paid together with the order.",
+	},
+	OrderNoTipBtn: {
+		UZ: "Choy pulisiz",
+		RU: "Без чаевых",
+		EN: "No tip",
+	},
+	OrderTipInvalid: {
+		UZ: "Choy puli summasi noto‘g‘ri.",
+		RU: "Неверная сумма чаевых.",
+		EN: "Invalid tip amount.",
+	},
+	TgPayOrderClosed: {
+		UZ: "Bu buyurtma uchun to‘lov endi qabul qilinmaydi.",
+		RU: "Оплата по этому заказу больше не принимается.",
+		EN: "This order can no longer be paid.",
+	},
+	TgPayAmountChanged: {
+		UZ: "Buyurtma summasi o‘zgardi. Iltimos, yangi hisobni to‘lang.",
+		RU: "Сумма заказа изменилась. Пожалуйста, оплатите новый счёт.",
+		EN: "The order total has changed. Please pay the new invoice.",
+	},
+	TgPaySuccess: {
+		UZ: "✅ To‘lov qabul qilindi. Buyurtmangiz tayyorlanmoqda.",
+		RU: "✅ Оплата получена. Ваш заказ готовится.",
+		EN: "✅ Payment received. Your order is being prepared.",
+	},
+	TgPayResendInvoice: {
+		UZ: "⏳ To‘lov hali yakunlanmadi. Quyidagi hisob orqali to‘lang:",
+		RU: "⏳ Оплата ещё не завершена. Оплатите счёт ниже:",
+		EN: "⏳ Payment is not finished yet. Please pay the invoice below:",
+	},
+	SearchButton: {
+		UZ: "🔍 Qidirish",
+		RU: "🔍 Поиск",
+		EN: "🔍 Search",
+	},
+	SearchPrompt: {
+		UZ: "🔍 Mahsulot nomini yozing (masalan: filadelfiya, ролл, pizza):",
+		RU: "🔍 Напишите название блюда (например: филадельфия, roll, пицца):",
+		EN: "🔍 Type a product name (e.g. philadelphia, roll, pizza):",
+	},
+	SearchInlineBtn: {
+		UZ: "🔎 Inline qidiruv",
+		RU: "🔎 Инлайн-поиск",
+		EN: "🔎 Inline search",
+	},
+	SearchResults: {
+		UZ: "🔍 «%s» bo‘yicha topildi:",
+		RU: "🔍 Найдено по запросу «%s»:",
+		EN: "🔍 Results for “%s”:",
+	},
+	SearchEmpty: {
+		UZ: "😔 «%s» bo‘yicha hech narsa topilmadi. Boshqacha yozib ko‘ring.",
+		RU: "😔 По запросу «%s» ничего не найдено. Попробуйте написать иначе.",
+		EN: "😔 Nothing found for “%s”. Try another spelling.",
+	},
+	SearchAdded: {
+		UZ: "✅ Savatga qo‘shildi",
+		RU: "✅ Добавлено в корзину",
+		EN: "✅ Added to cart",
+	},
+	SearchInlineOpen: {
+		UZ: "🛒 Botda ochish",
+		RU: "🛒 Открыть в боте",
+		EN: "🛒 Open in bot",
+	},
+	DeepLinkNotFound: {
+		UZ: "😔 Havoladagi mahsulot hozir mavjud emas. Menyudan tanlab ko‘ring.",
+		RU: "😔 Товар по ссылке сейчас недоступен. Выберите из меню.",
+		EN: "😔 The item from the link is not available now. Please choose from the menu.",
+	},
+	DeepLinkPromo: {
+		UZ: "🎁 «%s» promo-kodi saqlandi. U keyingi buyurtmangizga qo‘shiladi va operator chegirmani hisobga oladi.",
+		RU: "🎁 Промокод «%s» сохранён. Он будет добавлен к вашему следующему заказу, оператор учтёт скидку.",
+		EN: "🎁 Promo code “%s” saved. It will be attached to your next order and the operator will apply the discount.",
+	},
+	PromoOrderComment: {
+		UZ: "🎁 Promo-kod: %s",
+		RU: "🎁 Промокод: %s",
+		EN: "🎁 Promo code: %s",
+	},
+	PromoCodeUsed: {
+		UZ: "🎁 «%s» promo-kodi boshqa buyurtmada ishlatilgan. Buyurtmani chegirmasiz qayta tasdiqlang.",
+		RU: "🎁 Промокод «%s» уже использован в другом заказе. Подтвердите заказ ещё раз без скидки.",
+		EN: "🎁 Promo code “%s” has already been used in another order. Please confirm the order again without the discount.",
+	},
+	AskSavedAddress: {
+		UZ: "Saqlangan manzillardan birini tanlang, yangi lokatsiya yuboring yoki manzilni yozing:",
+		RU: "Выберите один из сохранённых адресов, отправьте новую локацию или напишите адрес:",
+		EN: "Pick one of your saved addresses, send a new location or type the address:",
+	},
+	AddressHouseAsk: {
+		UZ: "🏠 Uy raqamini yozing (yoki o‘tkazib yuboring):",
+		RU: "🏠 Укажите номер дома (или пропустите):",
+		EN: "🏠 Enter the house number (or skip):",
+	},
+	AddressFlatAsk: {
+		UZ: "🚪 Xonadon raqami:",
+		RU: "🚪 Номер квартиры:",
+		EN: "🚪 Flat number:",
+	},
+	AddressEntranceAsk: {
+		UZ: "🏢 Podyezd:",
+		RU: "🏢 Подъезд:",
+		EN: "🏢 Entrance:",
+	},
+	AddressFloorAsk: {
+		UZ: "🔢 Qavat:",
+		RU: "🔢 Этаж:",
+		EN: "🔢 Floor:",
+	},
+	AddressDoorphoneAsk: {
+		UZ: "🔔 Domofon kodi:",
+		RU: "🔔 Код домофона:",
+		EN: "🔔 Doorphone code:",
+	},
+	AddressCommentAsk: {
+		UZ: "💬 Kuryer uchun izoh (mo‘ljal, qo‘ng‘iroq qilish va h.k.):",
+		RU: "💬 Комментарий для курьера (ориентир, позвонить и т.п.):",
+		EN: "💬 Comment for the courier (landmark, call on arrival, etc.):",
+	},
+	AddressSkipBtn: {
+		UZ: "⏭ O‘tkazib yuborish",
+		RU: "⏭ Пропустить",
+		EN: "⏭ Skip",
+	},
+	AddressDoneBtn: {
+		UZ: "✅ Tayyor",
+		RU: "✅ Готово",
+		EN: "✅ Done",
+	},
+	SupportButton: {
+		UZ: "💬 Operatorga yozish",
+		RU: "💬 Написать оператору",
+		EN: "💬 Chat with operator",
+	},
+	SupportWelcome: {
+		UZ: "💬 Savolingizni yozing. Rasm yoki ovozli xabar ham yuborishingiz mumkin — operator shu yerda javob beradi.",
+		RU: "💬 Напишите ваш вопрос. Можно отправить фото или голосовое сообщение — оператор ответит здесь.",
+		EN: "💬 Type your question. You can also send a photo or a voice message — an operator will reply here.",
+	},
+	SupportExitButton: {
+		UZ: "⬅️ Chatdan chiqish",
+		RU: "⬅️ Выйти из чата",
+		EN: "⬅️ Leave chat",
+	},
+	SupportUnavailable: {
+		UZ: "😔 Hozir chat ishlamayapti. Iltimos, qo‘ng‘iroq qiling: +998981406003",
+		RU: "😔 Чат сейчас недоступен. Пожалуйста, позвоните: +998981406003",
+		EN: "😔 Chat is unavailable right now. Please call: +998981406003",
+	},
+	SupportSendFailed: {
+		UZ: "❗️ Xabar yuborilmadi, qaytadan urinib ko‘ring.",
+		RU: "❗️ Сообщение не отправлено, попробуйте ещё раз.",
+		EN: "❗️ Message was not sent, please try again.",
+	},
+	RatingAskFood: {
+		UZ: "🍣 №%d buyurtma yetkazildi. Taomlarni qanday baholaysiz?",
+		RU: "🍣 Заказ №%d доставлен. Как вам еда?",
+		EN: "🍣 Order #%d is complete. How was the food?",
+	},
+	RatingAskDelivery: {
+		UZ: "🚗 Yetkazib berish qanday bo‘ldi?",
+		RU: "🚗 Как прошла доставка?",
+		EN: "🚗 How was the delivery?",
+	},
+	RatingAskComment: {
+		UZ: "✍️ Rahmat! Izoh qoldirmoqchimisiz? Yozib yuboring yoki o‘tkazib yuboring.",
+		RU: "✍️ Спасибо! Хотите оставить комментарий? Напишите его или пропустите.",
+		EN: "✍️ Thanks! Want to leave a comment? Type it or skip.",
+	},
+	RatingSkipBtn: {
+		UZ: "⏭ O‘tkazib yuborish",
+		RU: "⏭ Пропустить",
+		EN: "⏭ Skip",
+	},
+	RatingThanks: {
+		UZ: "🙏 Fikringiz uchun rahmat!",
+		RU: "🙏 Спасибо за отзыв!",
+		EN: "🙏 Thank you for your feedback!",
+	},
+	CartReminderTitle: {
+		UZ: "🛒 Savatingizda mahsulotlar qoldi:",
+		RU: "🛒 В вашей корзине остались товары:",
+		EN: "🛒 You left some items in your cart:",
+	},
+	CartReminderCompleteBtn: {
+		UZ: "✅ Buyurtmani yakunlash",
+		RU: "✅ Оформить заказ",
+		EN: "✅ Complete order",
+	},
+	CartReminderOffBtn: {
+		UZ: "🔕 Boshqa eslatmang",
+		RU: "🔕 Больше не напоминать",
+		EN: "🔕 Don't remind me",
+	},
+	CartReminderOffDone: {
+		UZ: "🔕 Savat eslatmalari o‘chirildi",
+		RU: "🔕 Напоминания о корзине отключены",
+		EN: "🔕 Cart reminders turned off",
+	},
+	ReferralButton: {
+		UZ: "🎁 Do‘stlarni taklif qilish",
+		RU: "🎁 Пригласить друзей",
+		EN: "🎁 Invite friends",
+	},
+	ReferralInfo: {
+		UZ: "🎁 Do‘stingizni taklif qiling!\n\nDo‘stingiz havola orqali kirib, birinchi buyurtmasini olganda ikkalangiz ham %s %s lik promo-kod olasiz.\n\n🔗 Sizning havolangiz:\n%s\n\n👥 Taklif qilinganlar: %d\n✅ Mukofot berilgan: %d\n⏳ Birinchi buyurtmani kutmoqda: %d",
+		RU: "🎁 Приглашайте друзей!\n\nКогда друг перейдёт по ссылке и получит первый заказ, вы оба получите промокод на %s %s.\n\n🔗 Ваша ссылка:\n%s\n\n👥 Приглашено: %d\n✅ Награждено: %d\n⏳ Ждут первого заказа: %d",
+		EN: "🎁 Invite your friends!\n\nWhen a friend joins via your link and receives their first order, you both get a %s %s promo code.\n\n🔗 Your link:\n%s\n\n👥 Invited: %d\n✅ Rewarded: %d\n⏳ Awaiting first order: %d",
+	},
+	ReferralCodes: {
+		UZ: "🎟 Promo-kodlaringiz: %s",
+		RU: "🎟 Ваши промокоды: %s",
+		EN: "🎟 Your promo codes: %s",
+	},
+	ReferralShareBtn: {
+		UZ: "📤 Ulashish",
+		RU: "📤 Поделиться",
+		EN: "📤 Share",
+	},
+	ReferralShareText: {
+		UZ: "Sushitana’dan buyurtma bering va birinchi buyurtmangizga promo-kod oling 🍣",
+		RU: "Заказывайте в Sushitana и получите промокод за первый заказ 🍣",
+		EN: "Order from Sushitana and get a promo code for your first order 🍣",
+	},
+	ReferralRewardReferrer: {
+		UZ: "🎉 Siz taklif qilgan do‘stingiz birinchi buyurtmasini oldi!\nSizga %s %s lik promo-kod: %s\nKeyingi buyurtmada avtomatik qo‘llaniladi.",
+		RU: "🎉 Ваш приглашённый друг получил первый заказ!\nВаш промокод на %s %s: %s\nОн будет применён к следующему заказу.",
+		EN: "🎉 The friend you invited received their first order!\nYour %s %s promo code: %s\nIt will be applied to your next order.",
+	},
+	ReferralRewardReferee: {
+		UZ: "🎉 Birinchi buyurtmangiz uchun rahmat!\nSizga %s %s lik promo-kod: %s\nKeyingi buyurtmada avtomatik qo‘llaniladi.",
+		RU: "🎉 Спасибо за первый заказ!\nВаш промокод на %s %s: %s\nОн будет применён к следующему заказу.",
+		EN: "🎉 Thank you for your first order!\nYour %s %s promo code: %s\nIt will be applied to your next order.",
+	},
 	MinOrderNotReached: {
 		UZ: "%s hududi uchun minimal buyurtma %s %s.\nHozir: %s %s.\nSavatga yana mahsulot qo‘shing.",
 		RU: "Для зоны %s минимальный заказ %s %s.\nСейчас: %s %s.\nДобавьте товары в корзину.",
 		EN: "Minimum order for %s is %s %s.\nCurrent: %s %s.\nPlease add more items to your cart.",
 	},
+	SavedCardPaid: {
+		UZ: "✅ Buyurtma •••• %s kartadan to‘landi. Tayyorlashni boshlaymiz!",
+		RU: "✅ Заказ оплачен картой •••• %s. Начинаем готовить!",
+		EN: "✅ The order was paid with card •••• %s. We're starting to cook!",
+	},
+	SavedCardFailed: {
+		UZ: "•••• %s kartadan to‘lab bo‘lmadi. Quyidagi havola orqali to‘lang.",
+		RU: "Не удалось списать оплату с карты •••• %s. Оплатите по ссылке ниже.",
+		EN: "Payment with card •••• %s failed. Please pay using the link below.",
+	},
 }
 
 func Get(lang utils.Lang, key TextKey) string {
 	return MapText[key].By(lang)
 }
+
+// OrderStatus - order_status enum qiymatini tilga mos label'ga aylantiradi.
+func OrderStatus(lang utils.Lang, st string) string {
+	key, ok := orderStatusKeys[st]
+	if !ok {
+		return st
+	}
+	return Get(lang, key)
+}
+
+// PaymentStatus - payment_status enum qiymatini tilga mos label'ga aylantiradi.
+func PaymentStatus(lang utils.Lang, st string) string {
+	switch st {
+	case "PAID":
+		return Get(lang, PaymentStatusPaid)
+	case "PENDING":
+		return Get(lang, PaymentStatusPending)
+	case "UNPAID":
+		return Get(lang, PaymentStatusUnpaid)
+	default:
+		return st
+	}
+}
+
+var orderStatusKeys = map[string]TextKey{
+	"WAITING_PAYMENT":  OrderStatusWaitingPayment,
+	"WAITING_OPERATOR": OrderStatusWaitingOperator,
+	"COOKING":          OrderStatusCooking,
+	"READY_FOR_PICKUP": OrderStatusReadyForPickup,
+	"ON_THE_WAY":       OrderStatusOnTheWay,
+	"DELIVERED":        OrderStatusDelivered,
+	"COMPLETED":        OrderStatusCompleted,
+	"CANCELLED":        OrderStatusCancelled,
+	"REJECTED":         OrderStatusRejected,
+}
@@ -0,0 +1,6 @@
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS cancel_reason TEXT,
+ADD COLUMN IF NOT EXISTS cancelled_by VARCHAR(20),
+ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_orders_tg_id_cancelled_at ON orders(tg_id, cancelled_at) WHERE cancelled_by = 'CLIENT';
@@ -0,0 +1,14 @@
+ALTER TYPE payment_method ADD VALUE IF NOT EXISTS 'TELEGRAM';
+
+CREATE TABLE IF NOT EXISTS telegram_payments (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    tg_id BIGINT NOT NULL,
+    currency VARCHAR(8) NOT NULL,
+    total_amount BIGINT NOT NULL,
+    telegram_payment_charge_id VARCHAR(255) NOT NULL UNIQUE,
+    provider_payment_charge_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_telegram_payments_order_id ON telegram_payments(order_id);
@@ -0,0 +1,9 @@
+-- /start deep link: mijozni qaysi link olib kelgan (p_<id>, c_<id>, promo_<code>, ref_<tgId>)
+ALTER TABLE clients
+    ADD COLUMN IF NOT EXISTS source VARCHAR(64) DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_clients_source ON clients(source) WHERE source <> '';
+
+-- promo_<code> link: keyingi buyurtma izohiga qo'shiladi, operator hisobga oladi
+ALTER TABLE clients
+    ADD COLUMN IF NOT EXISTS promo_code VARCHAR(64) DEFAULT '';
@@ -0,0 +1,12 @@
+-- mijoz manzillari: muvaffaqiyatli buyurtmalardan keyin saqlanadi, keyingi safar bot'da tanlanadi
+CREATE TABLE IF NOT EXISTS client_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    tg_id BIGINT NOT NULL,
+    name VARCHAR(64) NOT NULL DEFAULT '',
+    address JSONB NOT NULL DEFAULT '{}'::jsonb,
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_addresses_tg_id ON client_addresses(tg_id, last_used_at DESC);
@@ -0,0 +1,17 @@
+-- xodim <-> Telegram akkaunt (operator guruhidagi tugmalar kim bosganini bilish uchun)
+ALTER TABLE employees
+ADD COLUMN IF NOT EXISTS tg_id BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_tg_id ON employees(tg_id) WHERE tg_id IS NOT NULL;
+
+-- admin_chat_id guruhidagi zakaz posti: status o'zgarganda shu xabar edit qilinadi
+CREATE TABLE IF NOT EXISTS operator_posts (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL DEFAULT 0,
+    employee_id BIGINT,
+    action VARCHAR(20) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
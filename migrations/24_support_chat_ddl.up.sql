@@ -0,0 +1,31 @@
+-- mijoz <-> operator yozishmasi: har mijozga bitta thread (forum topic yoki oddiy guruhda header xabar)
+CREATE TABLE IF NOT EXISTS support_threads (
+    tg_id BIGINT PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    topic_id BIGINT NOT NULL DEFAULT 0,
+    header_message_id BIGINT NOT NULL DEFAULT 0,
+    order_id UUID,
+    last_message_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_support_threads_topic ON support_threads(chat_id, topic_id) WHERE topic_id <> 0;
+CREATE INDEX IF NOT EXISTS idx_support_threads_header ON support_threads(header_message_id) WHERE header_message_id <> 0;
+
+CREATE TABLE IF NOT EXISTS support_messages (
+    id BIGSERIAL PRIMARY KEY,
+    tg_id BIGINT NOT NULL,
+    direction VARCHAR(8) NOT NULL, -- IN: mijozdan, OUT: operatordan
+    kind VARCHAR(16) NOT NULL DEFAULT 'text',
+    text TEXT NOT NULL DEFAULT '',
+    file_id TEXT NOT NULL DEFAULT '',
+    client_message_id BIGINT NOT NULL DEFAULT 0,
+    group_message_id BIGINT NOT NULL DEFAULT 0,
+    employee_id BIGINT,
+    order_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_support_messages_tg_id ON support_messages(tg_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_support_messages_group_msg ON support_messages(group_message_id) WHERE group_message_id <> 0;
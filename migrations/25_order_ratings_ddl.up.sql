@@ -0,0 +1,23 @@
+-- yetkazilgandan keyin baho: ovqat va yetkazish 1..5, ixtiyoriy izoh.
+-- ask_at - bot so'rovni qachon yuborishi (COMPLETED/DELIVERED + kechikish)
+CREATE TABLE IF NOT EXISTS order_ratings (
+    order_id UUID PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    tg_id BIGINT NOT NULL,
+    delivery_type VARCHAR(16) NOT NULL DEFAULT '',
+    courier_id VARCHAR(64) NOT NULL DEFAULT '', -- iiko courierInfo.courier.id
+    courier_name TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING', -- PENDING -> ASKED -> RATED
+    food SMALLINT CHECK (food BETWEEN 1 AND 5),
+    delivery SMALLINT CHECK (delivery BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    ask_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    asked_at TIMESTAMPTZ,
+    rated_at TIMESTAMPTZ,
+    alerted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_ratings_due ON order_ratings(ask_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_order_ratings_courier ON order_ratings(courier_id) WHERE courier_id <> '';
+CREATE INDEX IF NOT EXISTS idx_order_ratings_rated_at ON order_ratings(rated_at) WHERE rated_at IS NOT NULL;
@@ -0,0 +1,50 @@
+-- botni bloklagan mijozlar (broadcast 403) -> is_active = false, qaytib /start bosganda yana true
+ALTER TABLE clients
+    ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_clients_language ON clients(language);
+
+-- marketing xabarnomalari: DRAFT -> RUNNING <-> PAUSED -> DONE | CANCELLED
+CREATE TABLE IF NOT EXISTS broadcasts (
+    id BIGSERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    text TEXT NOT NULL DEFAULT '',
+    photo TEXT NOT NULL DEFAULT '', -- telegram file_id yoki URL
+    buttons JSONB NOT NULL DEFAULT '[]'::jsonb,
+    segment JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(16) NOT NULL DEFAULT 'DRAFT',
+    total INT NOT NULL DEFAULT 0,
+    sent INT NOT NULL DEFAULT 0,
+    blocked INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    created_by VARCHAR(64) NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_running ON broadcasts(id) WHERE status = 'RUNNING';
+
+CREATE TABLE IF NOT EXISTS broadcast_recipients (
+    broadcast_id BIGINT NOT NULL REFERENCES broadcasts(id) ON DELETE CASCADE,
+    tg_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING', -- PENDING, SENT, BLOCKED, FAILED
+    error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ,
+    PRIMARY KEY (broadcast_id, tg_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcast_recipients_pending ON broadcast_recipients(broadcast_id) WHERE status = 'PENDING';
+
+INSERT INTO access_scopes (id, name, description)
+VALUES
+    (17, 'broadcast-read', 'Allows the user to view broadcasts and their delivery stats'),
+    (18, 'broadcast-write', 'Allows the user to create, start, pause or cancel broadcasts')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_access_scopes (role_id, access_scope_id)
+VALUES
+  ('cdd37b47-c947-4faf-becc-0ed0c256d642', 17),
+  ('cdd37b47-c947-4faf-becc-0ed0c256d642', 18)
+ON CONFLICT DO NOTHING;
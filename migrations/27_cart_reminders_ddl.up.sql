@@ -0,0 +1,23 @@
+-- tashlab ketilgan savat eslatmasi uchun vaqtlar.
+-- Eski qatorlar NULL qoladi (yoshi noma'lum) -> ularga eslatma yuborilmaydi.
+ALTER TABLE carts
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+ALTER TABLE carts
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN updated_at SET DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_carts_tgid ON carts(tgid);
+
+-- mijoz "🔕 Eslatmasin" bossa false
+ALTER TABLE clients
+    ADD COLUMN IF NOT EXISTS cart_reminders BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- har savatga (birinchi mahsulot qo'shilgan vaqt bo'yicha) ko'pi bilan bitta eslatma
+CREATE TABLE IF NOT EXISTS cart_reminders (
+    tg_id BIGINT NOT NULL,
+    cart_started_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tg_id, cart_started_at)
+);
@@ -0,0 +1,27 @@
+-- referal dasturi: do'st /start ref_<tgId> orqali keldi -> birinchi yakunlangan zakazda ikkalasiga promo-kod
+CREATE TABLE IF NOT EXISTS referrals (
+    referee_tg_id BIGINT PRIMARY KEY,          -- taklif qilingan (yangi mijoz), faqat bir marta
+    referrer_tg_id BIGINT NOT NULL,            -- taklif qilgan
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING', -- PENDING, REWARDED, REJECTED
+    reject_reason VARCHAR(32) NOT NULL DEFAULT '', -- self_phone, duplicate_phone
+    order_id VARCHAR(64) NOT NULL DEFAULT '',  -- mukofotga sabab bo'lgan zakaz
+    referrer_code VARCHAR(32) NOT NULL DEFAULT '',
+    referee_code VARCHAR(32) NOT NULL DEFAULT '',
+    reward_amount BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    rewarded_at TIMESTAMPTZ,
+    CHECK (referee_tg_id <> referrer_tg_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals(referrer_tg_id);
+CREATE INDEX IF NOT EXISTS idx_referrals_status ON referrals(status);
+
+INSERT INTO access_scopes (id, name, description)
+VALUES
+    (19, 'referral-read', 'Allows the user to view referral reports')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_access_scopes (role_id, access_scope_id)
+VALUES
+  ('cdd37b47-c947-4faf-becc-0ed0c256d642', 19)
+ON CONFLICT DO NOTHING;
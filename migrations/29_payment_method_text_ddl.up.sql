@@ -0,0 +1,2 @@
+-- yangi to'lov provayderlari uchun enum'ni kengaytirish shart emas (provider.Registry tekshiradi)
+ALTER TABLE orders ALTER COLUMN payment_method TYPE VARCHAR(16) USING payment_method::text;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS uzum_transactions (
+    id UUID PRIMARY KEY,
+    trans_id TEXT NOT NULL UNIQUE,                -- Uzum "transId"
+    order_id UUID NOT NULL REFERENCES orders(id),
+    service_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,                       -- tiyin
+    status VARCHAR(16) NOT NULL,                  -- CREATED, CONFIRMED, REVERSED, FAILED
+    trans_time BIGINT NOT NULL,                   -- ms epoch
+    confirm_time BIGINT,
+    reverse_time BIGINT,
+    payment_source VARCHAR(32),
+    phone VARCHAR(32),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS uzum_transactions_order_id_idx
+  ON uzum_transactions(order_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uzum_one_active_per_order_uq
+  ON uzum_transactions(order_id)
+  WHERE status IN ('CREATED', 'CONFIRMED');
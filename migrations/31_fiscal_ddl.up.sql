@@ -0,0 +1,16 @@
+-- fiskal chek: MXIK (IKPU), o'lchov birligi (package code), QQS
+ALTER TABLE product
+    ADD COLUMN IF NOT EXISTS ikpu VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS package_code VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS vat_percent INT NOT NULL DEFAULT 12;
+
+-- Payme: CheckPerform/Create'da yuborilgan detail va SetFiscalData natijasi
+ALTER TABLE payme_transactions
+    ADD COLUMN IF NOT EXISTS receipt_detail JSONB,
+    ADD COLUMN IF NOT EXISTS fiscal_data JSONB;
+
+-- Click: ofd_data/submit_items
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS fiscal_items JSONB,
+    ADD COLUMN IF NOT EXISTS fiscal_status VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS fiscal_error TEXT;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS payment_reconciliations (
+    id UUID PRIMARY KEY,
+    period_from TIMESTAMPTZ NOT NULL,
+    period_to TIMESTAMPTZ NOT NULL,
+    trigger VARCHAR(16) NOT NULL,                 -- SCHEDULED, MANUAL
+    status VARCHAR(16) NOT NULL DEFAULT 'RUNNING', -- RUNNING, DONE, FAILED
+    checked INT NOT NULL DEFAULT 0,
+    fixed INT NOT NULL DEFAULT 0,
+    mismatches INT NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_reconciliations_started_at ON payment_reconciliations(started_at DESC);
+
+CREATE TABLE IF NOT EXISTS payment_reconciliation_items (
+    id BIGSERIAL PRIMARY KEY,
+    reconciliation_id UUID NOT NULL REFERENCES payment_reconciliations(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL,
+    order_number BIGINT NOT NULL DEFAULT 0,
+    payment_method VARCHAR(16) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    order_status VARCHAR(32) NOT NULL DEFAULT '',
+    payment_status VARCHAR(16) NOT NULL DEFAULT '',
+    provider_status VARCHAR(32) NOT NULL DEFAULT '',
+    order_amount BIGINT NOT NULL DEFAULT 0,
+    provider_amount BIGINT NOT NULL DEFAULT 0,
+    fixed BOOLEAN NOT NULL DEFAULT FALSE,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_reconciliation_items_run ON payment_reconciliation_items(reconciliation_id);
+
+INSERT INTO access_scopes (id, name, description)
+VALUES
+    (20, 'reconciliation-read', 'Allows the user to view payment reconciliation reports'),
+    (21, 'reconciliation-write', 'Allows the user to start payment reconciliation manually')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_access_scopes (role_id, access_scope_id)
+VALUES
+  ('cdd37b47-c947-4faf-becc-0ed0c256d642', 20),
+  ('cdd37b47-c947-4faf-becc-0ed0c256d642', 21)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- operator paneldan mijoz telefoniga yuborilgan Click invoice holati (invoice/status)
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS invoice_status INT,
+    ADD COLUMN IF NOT EXISTS invoice_status_note TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS invoice_sent_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_invoices_invoice_sent_at
+    ON invoices(invoice_sent_at)
+    WHERE invoice_sent_at IS NOT NULL;
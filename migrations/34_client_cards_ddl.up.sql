@@ -0,0 +1,16 @@
+-- Payme Subscribe API (cards.create/verify) orqali saqlangan kartalar.
+-- token faqat backend'da ishlatiladi, API javoblarida chiqmaydi
+CREATE TABLE IF NOT EXISTS client_cards (
+    id BIGSERIAL PRIMARY KEY,
+    tg_id BIGINT NOT NULL,
+    provider VARCHAR(16) NOT NULL DEFAULT 'PAYME',
+    token TEXT NOT NULL,
+    number VARCHAR(32) NOT NULL,  -- niqoblangan: 860006******6311
+    expire VARCHAR(8) NOT NULL DEFAULT '',
+    verified BOOLEAN NOT NULL DEFAULT FALSE,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_cards_tg_id ON client_cards(tg_id, verified);
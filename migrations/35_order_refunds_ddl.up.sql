@@ -0,0 +1,19 @@
+-- to'langan zakazdan olib tashlangan qatorlar uchun qaytarishlar (refund ledger)
+CREATE TABLE IF NOT EXISTS order_refunds (
+    id BIGSERIAL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    payment_method VARCHAR(16) NOT NULL,
+    amount BIGINT NOT NULL,                      -- so'm
+    total_before BIGINT NOT NULL DEFAULT 0,
+    total_after BIGINT NOT NULL DEFAULT 0,
+    items JSONB NOT NULL DEFAULT '[]'::jsonb,
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL,                 -- DONE, MANUAL, FAILED
+    note TEXT NOT NULL DEFAULT '',
+    created_by INT NOT NULL DEFAULT 0,           -- employee id
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_refunds_order ON order_refunds(order_id);
+CREATE INDEX IF NOT EXISTS idx_order_refunds_status ON order_refunds(status) WHERE status <> 'DONE';
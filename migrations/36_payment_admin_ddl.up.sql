@@ -0,0 +1,21 @@
+-- Click lifecycle vaqtlari (admin panelda ko'rsatish uchun); eski qatorlarda NULL qoladi
+ALTER TABLE invoices
+    ADD COLUMN IF NOT EXISTS prepared_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS complete_error INT,
+    ADD COLUMN IF NOT EXISTS complete_error_note TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_invoices_created_at ON invoices(created_at);
+CREATE INDEX IF NOT EXISTS idx_invoices_order_id ON invoices(order_id);
+CREATE INDEX IF NOT EXISTS payme_transactions_created_at_idx ON payme_transactions(created_at);
+
+INSERT INTO access_scopes (id, name, description)
+VALUES
+    (22, 'payment-read', 'Allows the user to view Click invoices and Payme transactions')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_access_scopes (role_id, access_scope_id)
+VALUES
+  ('cdd37b47-c947-4faf-becc-0ed0c256d642', 22)
+ON CONFLICT DO NOTHING;
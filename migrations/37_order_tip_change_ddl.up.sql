@@ -0,0 +1,11 @@
+-- change_from: naqd to'lovda mijoz qaysi kupyuradan qaytim so'raydi (0 - qaytim kerak emas)
+-- tip_amount: online to'lovda kuryerga choy puli, total_price'ga kirmaydi, to'lov summasiga qo'shiladi
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS change_from BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tip_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS courier_id VARCHAR(64) NOT NULL DEFAULT '', -- iiko courierInfo.courier.id
+    ADD COLUMN IF NOT EXISTS courier_name TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_orders_tip_courier
+    ON orders(courier_id, created_at)
+    WHERE tip_amount > 0;
@@ -0,0 +1,8 @@
+-- Payme qisman qaytarish: saqlangan karta bilan to'langan chek bekor qilinib, qolgan summaga
+-- o'sha kartadan yangi chek yechiladi.
+-- card_id: chek qaysi client_cards kartasi bilan to'langan (NULL - havola orqali)
+-- rebill_amount: shu chek o'rniga kutilayotgan yangi chek summasi (so'm, 0 - yo'q); Merchant API
+-- to'langan zakaz uchun faqat shu summadagi chekni qabul qiladi
+ALTER TABLE payme_transactions
+    ADD COLUMN IF NOT EXISTS card_id BIGINT,
+    ADD COLUMN IF NOT EXISTS rebill_amount BIGINT NOT NULL DEFAULT 0;
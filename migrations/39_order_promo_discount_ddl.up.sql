@@ -0,0 +1,10 @@
+-- promo_code: checkout'da qo'llangan referal promo-kodi
+-- discount_amount: shu kod bo'yicha chegirma (so'm), total_price'dan ayriladi
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS promo_code VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS discount_amount BIGINT NOT NULL DEFAULT 0;
+
+-- bitta kod bitta amaldagi zakazda; zakaz bekor/rad etilsa kod yana ishlatilishi mumkin
+CREATE UNIQUE INDEX IF NOT EXISTS orders_promo_code_active_uq
+    ON orders(promo_code)
+    WHERE promo_code <> '' AND order_status NOT IN ('CANCELLED', 'REJECTED');
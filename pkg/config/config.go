@@ -58,8 +58,30 @@ func NewConfig() IConfig {
 	_ = cfg.BindEnv("redis.password", "REDIS_PASSWORD")
 	_ = cfg.BindEnv("redis.addrs", "REDIS_ADDRS")
 	_ = cfg.BindEnv("admin_chat_id", "ADMIN_CHAT_ID")
+	_ = cfg.BindEnv("support_chat_id", "SUPPORT_CHAT_ID")
+	_ = cfg.BindEnv("rating_delay_minutes", "RATING_DELAY_MINUTES")
+	_ = cfg.BindEnv("rating_alert_max_score", "RATING_ALERT_MAX_SCORE")
+	_ = cfg.BindEnv("cart_reminder_idle_minutes", "CART_REMINDER_IDLE_MINUTES")
+	_ = cfg.BindEnv("cart_reminder_recent_order_hours", "CART_REMINDER_RECENT_ORDER_HOURS")
+	_ = cfg.BindEnv("referral_reward_amount", "REFERRAL_REWARD_AMOUNT")
+	_ = cfg.BindEnv("payment_methods", "PAYMENT_METHODS")
+	_ = cfg.BindEnv("payment_methods_olmaliq", "PAYMENT_METHODS_OLMALIQ")
+	_ = cfg.BindEnv("payment_methods_ohangaron", "PAYMENT_METHODS_OHANGARON")
+	_ = cfg.BindEnv("payment_methods_pickup", "PAYMENT_METHODS_PICKUP")
+	_ = cfg.BindEnv("fiscal_tin", "FISCAL_TIN")
+	_ = cfg.BindEnv("fiscal_default_ikpu", "FISCAL_DEFAULT_IKPU")
+	_ = cfg.BindEnv("fiscal_default_package_code", "FISCAL_DEFAULT_PACKAGE_CODE")
+	_ = cfg.BindEnv("fiscal_delivery_ikpu", "FISCAL_DELIVERY_IKPU")
+	_ = cfg.BindEnv("fiscal_delivery_package_code", "FISCAL_DELIVERY_PACKAGE_CODE")
+	_ = cfg.BindEnv("fiscal_tip_ikpu", "FISCAL_TIP_IKPU")
+	_ = cfg.BindEnv("fiscal_tip_package_code", "FISCAL_TIP_PACKAGE_CODE")
+	_ = cfg.BindEnv("reconciliation_interval_minutes", "RECONCILIATION_INTERVAL_MINUTES")
+	_ = cfg.BindEnv("reconciliation_lookback_hours", "RECONCILIATION_LOOKBACK_HOURS")
 	_ = cfg.BindEnv("gin.trusted_proxies", "GIN_TRUSTED_PROXIES")
 	_ = cfg.BindEnv("bot_token_sushitana", "BOT_TOKEN_SUSHITANA")
+	_ = cfg.BindEnv("bot.state.storage", "BOT_STATE_STORAGE")
+	_ = cfg.BindEnv("bot.state.ttl", "BOT_STATE_TTL")
+	_ = cfg.BindEnv("bot.state.migrate", "BOT_STATE_MIGRATE")
 	if secret := os.Getenv("SECRET_KEY"); secret != "" {
 		cfg.Set("secret_key", secret)
 	}
@@ -23,6 +23,11 @@ type Client interface {
 	Find(ctx context.Context, key string) (value string, err error)
 	Delete(ctx context.Context, key string) (err error)
 	FindObj(ctx context.Context, key string, value any) error
+
+	// hash helperlar (bot state va h.k. uchun)
+	HSave(ctx context.Context, key string, fields map[string]string, dur time.Duration) error
+	HUpdate(ctx context.Context, key string, fields map[string]string, dur time.Duration) (bool, error)
+	HFindAll(ctx context.Context, key string) (map[string]string, error)
 }
 
 type client struct {
@@ -126,3 +131,65 @@ func (c client) FindObj(ctx context.Context, key string, value interface{}) erro
 	}
 	return nil
 }
+
+// HSave hashni to'liq almashtiradi (DEL + HSET + EXPIRE bitta tranzaksiyada).
+func (c client) HSave(ctx context.Context, key string, fields map[string]string, dur time.Duration) error {
+	k := c.getPrefixedKey(key)
+
+	_, err := c.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, k)
+		if len(fields) > 0 {
+			pipe.HSet(ctx, k, fields)
+		}
+		if dur > 0 {
+			pipe.Expire(ctx, k, dur)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hsave key: %w", err)
+	}
+	return nil
+}
+
+// hUpdateScript key mavjud bo'lsagina fieldlarni yangilaydi va TTL ni uzaytiradi.
+var hUpdateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+if #ARGV > 1 then
+	redis.call('HSET', KEYS[1], unpack(ARGV, 2))
+end
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// HUpdate mavjud hashga fieldlarni atomik tarzda qo'shadi/yangilaydi.
+// Key yo'q bo'lsa hech narsa qilmaydi va false qaytaradi.
+func (c client) HUpdate(ctx context.Context, key string, fields map[string]string, dur time.Duration) (bool, error) {
+	args := make([]any, 0, len(fields)*2+1)
+	args = append(args, dur.Milliseconds())
+	for f, v := range fields {
+		args = append(args, f, v)
+	}
+
+	n, err := hUpdateScript.Run(ctx, c.redis, []string{c.getPrefixedKey(key)}, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to hupdate key: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (c client) HFindAll(ctx context.Context, key string) (map[string]string, error) {
+	value, err := c.redis.HGetAll(ctx, c.getPrefixedKey(key)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hgetall key: %w", err)
+	}
+	if len(value) == 0 {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,236 @@
+package addressrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+const (
+	// bitta mijozda ko'pi bilan shuncha manzil ko'rsatamiz
+	listLimit = 10
+	// ~100 m: shu radiusdagi lokatsiya "o'sha manzil" hisoblanadi
+	sameCoordDelta = 0.001
+)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		Create(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error)
+		Update(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error)
+		Delete(ctx context.Context, tgID, id int64) error
+		GetByID(ctx context.Context, tgID, id int64) (structs.ClientAddress, error)
+		GetListByTgID(ctx context.Context, tgID int64) ([]structs.ClientAddress, error)
+		// SaveFromOrder - yetkazilgan zakaz manzili: yaqin manzil bo'lsa last_used_at yangilanadi, aks holda yangi yoziladi
+		SaveFromOrder(ctx context.Context, tgID int64, addr structs.Address, defaultName string) error
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+const selectColumns = `
+	id,
+	tg_id,
+	name,
+	address,
+	last_used_at,
+	created_at,
+	updated_at
+`
+
+func scanAddress(row pgx.Row) (structs.ClientAddress, error) {
+	var (
+		resp    structs.ClientAddress
+		rawAddr []byte
+	)
+	err := row.Scan(
+		&resp.ID,
+		&resp.TgID,
+		&resp.Name,
+		&rawAddr,
+		&resp.LastUsedAt,
+		&resp.CreatedAt,
+		&resp.UpdatedAt,
+	)
+	if err != nil {
+		return structs.ClientAddress{}, err
+	}
+	if len(rawAddr) > 0 {
+		if err := json.Unmarshal(rawAddr, &resp.Address); err != nil {
+			return structs.ClientAddress{}, fmt.Errorf("unmarshal address failed: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+func (r repo) Create(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error) {
+	raw, err := json.Marshal(req.Address)
+	if err != nil {
+		return structs.ClientAddress{}, fmt.Errorf("marshal address failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO client_addresses (tg_id, name, address)
+		VALUES ($1, $2, $3)
+		RETURNING ` + selectColumns
+
+	resp, err := scanAddress(r.db.QueryRow(ctx, query, req.TgID, req.Name, raw))
+	if err != nil {
+		r.logger.Error(ctx, "client address create failed", zap.Error(err))
+		return structs.ClientAddress{}, fmt.Errorf("create client address failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) Update(ctx context.Context, req structs.SaveClientAddress) (structs.ClientAddress, error) {
+	raw, err := json.Marshal(req.Address)
+	if err != nil {
+		return structs.ClientAddress{}, fmt.Errorf("marshal address failed: %w", err)
+	}
+
+	query := `
+		UPDATE client_addresses
+		SET name       = $1,
+		    address    = $2,
+		    updated_at = now()
+		WHERE id = $3
+		  AND tg_id = $4
+		RETURNING ` + selectColumns
+
+	resp, err := scanAddress(r.db.QueryRow(ctx, query, req.Name, raw, req.ID, req.TgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.ClientAddress{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "client address update failed", zap.Error(err))
+		return structs.ClientAddress{}, fmt.Errorf("update client address failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) Delete(ctx context.Context, tgID, id int64) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM client_addresses WHERE id = $1 AND tg_id = $2`, id, tgID)
+	if err != nil {
+		r.logger.Error(ctx, "client address delete failed", zap.Error(err))
+		return fmt.Errorf("delete client address failed: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return structs.ErrNotFound
+	}
+	return nil
+}
+
+func (r repo) GetByID(ctx context.Context, tgID, id int64) (structs.ClientAddress, error) {
+	query := `SELECT ` + selectColumns + ` FROM client_addresses WHERE id = $1 AND tg_id = $2`
+
+	resp, err := scanAddress(r.db.QueryRow(ctx, query, id, tgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.ClientAddress{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "client address get failed", zap.Error(err))
+		return structs.ClientAddress{}, fmt.Errorf("get client address failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetListByTgID(ctx context.Context, tgID int64) ([]structs.ClientAddress, error) {
+	query := `
+		SELECT ` + selectColumns + `
+		FROM client_addresses
+		WHERE tg_id = $1
+		ORDER BY last_used_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, tgID, listLimit)
+	if err != nil {
+		r.logger.Error(ctx, "client address list failed", zap.Error(err))
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var list []structs.ClientAddress
+	for rows.Next() {
+		a, err := scanAddress(rows)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+		list = append(list, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration failed: %w", err)
+	}
+	return list, nil
+}
+
+func (r repo) SaveFromOrder(ctx context.Context, tgID int64, addr structs.Address, defaultName string) error {
+	if tgID == 0 || addr.Lat == 0 || addr.Lng == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(addr)
+	if err != nil {
+		return fmt.Errorf("marshal address failed: %w", err)
+	}
+
+	// mavjud manzil: nomi qoladi, zakazdagi to'ldirilgan maydonlar (kvartira, qavat...) ustidan yoziladi
+	update := `
+		UPDATE client_addresses
+		SET address      = address || $1::jsonb,
+		    last_used_at = now(),
+		    updated_at   = now()
+		WHERE id = (
+			SELECT id FROM client_addresses
+			WHERE tg_id = $2
+			  AND abs((address->>'lat')::float8 - $3) < $5
+			  AND abs((address->>'lng')::float8 - $4) < $5
+			ORDER BY last_used_at DESC
+			LIMIT 1
+		)
+	`
+	res, err := r.db.Exec(ctx, update, raw, tgID, addr.Lat, addr.Lng, sameCoordDelta)
+	if err != nil {
+		r.logger.Error(ctx, "client address touch failed", zap.Error(err))
+		return fmt.Errorf("touch client address failed: %w", err)
+	}
+	if res.RowsAffected() > 0 {
+		return nil
+	}
+
+	if _, err := r.db.Exec(ctx,
+		`INSERT INTO client_addresses (tg_id, name, address) VALUES ($1, $2, $3)`,
+		tgID, defaultName, raw,
+	); err != nil {
+		r.logger.Error(ctx, "client address insert failed", zap.Error(err))
+		return fmt.Errorf("insert client address failed: %w", err)
+	}
+	return nil
+}
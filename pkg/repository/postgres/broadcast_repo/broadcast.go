@@ -0,0 +1,391 @@
+package broadcastrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// TryLock - barcha gateway nusxalaridan bittasi yuboradi (Postgres advisory lock).
+		// Band bo'lsa ok=false; unlock chaqirilguncha lock ushlab turiladi
+		TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+		Create(ctx context.Context, req structs.CreateBroadcast) (int64, error)
+		GetByID(ctx context.Context, id int64) (structs.Broadcast, error)
+		GetList(ctx context.Context, req structs.GetListBroadcastRequest) (structs.GetListBroadcastResponse, error)
+		// GetRunning - worker restartdan keyin ham davom ettiradi
+		GetRunning(ctx context.Context) ([]structs.Broadcast, error)
+		// SetStatus - faqat from holatlaridan biridan o'tadi (pause/resume/cancel poygasiz)
+		SetStatus(ctx context.Context, id int64, to string, from ...string) (bool, error)
+
+		// Candidates - segment'ning SQL qismi (til, oxirgi zakaz, zakazlar soni)
+		Candidates(ctx context.Context, seg structs.BroadcastSegment) ([]structs.BroadcastCandidate, error)
+		AddRecipients(ctx context.Context, id int64, tgIDs []int64) error
+		NextPending(ctx context.Context, id int64, limit int) ([]int64, error)
+		MarkRecipient(ctx context.Context, id, tgID int64, status, errText string) error
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+// sendLockKey - pg_try_advisory_xact_lock kaliti (boshqa advisory lock'lar bilan to'qnashmasin)
+const sendLockKey int64 = 0x6263617374 // "bcast"
+
+// TryLock - lock tranzaksiyaga bog'langan: pool ulanishi qaytsa ham osilib qolmaydi,
+// jarayon o'lsa Postgres o'zi bo'shatadi
+func (r repo) TryLock(ctx context.Context) (func(), bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "broadcast lock begin failed", zap.Error(err))
+		return nil, false, err
+	}
+
+	var ok bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, sendLockKey).Scan(&ok); err != nil {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		r.logger.Error(ctx, "broadcast lock failed", zap.Error(err))
+		return nil, false, err
+	}
+	if !ok {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		return nil, false, nil
+	}
+
+	return func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }, true, nil
+}
+
+const broadcastColumns = `
+	id,
+	title,
+	text,
+	photo,
+	buttons,
+	segment,
+	status,
+	total,
+	sent,
+	blocked,
+	failed,
+	created_by,
+	started_at,
+	finished_at,
+	created_at,
+	updated_at
+`
+
+func scanBroadcast(row pgx.Row, extra ...any) (structs.Broadcast, error) {
+	var b structs.Broadcast
+	dest := append(extra,
+		&b.ID,
+		&b.Title,
+		&b.Text,
+		&b.Photo,
+		&b.Buttons,
+		&b.Segment,
+		&b.Status,
+		&b.Total,
+		&b.Sent,
+		&b.Blocked,
+		&b.Failed,
+		&b.CreatedBy,
+		&b.StartedAt,
+		&b.FinishedAt,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+	err := row.Scan(dest...)
+	if b.Buttons == nil {
+		b.Buttons = []structs.BroadcastButton{}
+	}
+	return b, err
+}
+
+func (r repo) Create(ctx context.Context, req structs.CreateBroadcast) (int64, error) {
+	if req.Buttons == nil {
+		req.Buttons = []structs.BroadcastButton{}
+	}
+	var id int64
+	query := `
+		INSERT INTO broadcasts (title, text, photo, buttons, segment, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := r.db.QueryRow(ctx, query,
+		req.Title,
+		req.Text,
+		req.Photo,
+		req.Buttons,
+		req.Segment,
+		req.CreatedBy,
+	).Scan(&id)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("create broadcast failed: %w", err)
+	}
+	return id, nil
+}
+
+func (r repo) GetByID(ctx context.Context, id int64) (structs.Broadcast, error) {
+	query := `SELECT ` + broadcastColumns + ` FROM broadcasts WHERE id = $1`
+	resp, err := scanBroadcast(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.Broadcast{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return structs.Broadcast{}, fmt.Errorf("get broadcast failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetList(ctx context.Context, req structs.GetListBroadcastRequest) (structs.GetListBroadcastResponse, error) {
+	resp := structs.GetListBroadcastResponse{Broadcasts: []structs.Broadcast{}}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	query := `
+		SELECT COUNT(*) OVER(), ` + broadcastColumns + `
+		FROM broadcasts
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY id DESC
+		OFFSET $2 LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, strings.ToUpper(strings.TrimSpace(req.Status)), req.Offset, req.Limit)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get broadcasts failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		b, err := scanBroadcast(rows, &resp.Count)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan broadcast failed: %w", err)
+		}
+		resp.Broadcasts = append(resp.Broadcasts, b)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("broadcasts rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetRunning(ctx context.Context) ([]structs.Broadcast, error) {
+	query := `SELECT ` + broadcastColumns + ` FROM broadcasts WHERE status = 'RUNNING' ORDER BY id`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, fmt.Errorf("get running broadcasts failed: %w", err)
+	}
+	defer rows.Close()
+
+	list := []structs.Broadcast{}
+	for rows.Next() {
+		b, err := scanBroadcast(rows)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return nil, fmt.Errorf("scan broadcast failed: %w", err)
+		}
+		list = append(list, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcasts rows failed: %w", err)
+	}
+	return list, nil
+}
+
+func (r repo) SetStatus(ctx context.Context, id int64, to string, from ...string) (bool, error) {
+	query := `
+		UPDATE broadcasts
+		SET status      = $2,
+		    started_at  = CASE WHEN $2 = 'RUNNING' THEN COALESCE(started_at, now()) ELSE started_at END,
+		    finished_at = CASE WHEN $2 IN ('DONE', 'CANCELLED') THEN now() ELSE finished_at END,
+		    updated_at  = now()
+		WHERE id = $1 AND status = ANY($3)
+	`
+	res, err := r.db.Exec(ctx, query, id, to, from)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return false, fmt.Errorf("set broadcast status failed: %w", err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (r repo) Candidates(ctx context.Context, seg structs.BroadcastSegment) ([]structs.BroadcastCandidate, error) {
+	where := " WHERE COALESCE(c.is_active, true) AND COALESCE(c.tgid, 0) <> 0"
+	args := []interface{}{}
+	argIndex := 1
+
+	if len(seg.Languages) > 0 {
+		where += fmt.Sprintf(" AND COALESCE(c.language, 'uz') = ANY($%d)", argIndex)
+		args = append(args, seg.Languages)
+		argIndex++
+	}
+	if seg.NoOrders {
+		where += " AND st.last_at IS NULL"
+	}
+	if seg.LastOrderFrom != nil {
+		where += fmt.Sprintf(" AND st.last_at >= $%d", argIndex)
+		args = append(args, *seg.LastOrderFrom)
+		argIndex++
+	}
+	if seg.LastOrderTo != nil {
+		where += fmt.Sprintf(" AND st.last_at < $%d", argIndex)
+		args = append(args, *seg.LastOrderTo)
+		argIndex++
+	}
+	if seg.MinOrders != nil {
+		where += fmt.Sprintf(" AND st.done >= $%d", argIndex)
+		args = append(args, *seg.MinOrders)
+		argIndex++
+	}
+	if seg.MaxOrders != nil {
+		where += fmt.Sprintf(" AND st.done <= $%d", argIndex)
+		args = append(args, *seg.MaxOrders)
+		argIndex++
+	}
+
+	// zona uchun oxirgi yetkazish manzili (segment'da zona bo'lmasa ham arzon: LATERAL + LIMIT 1)
+	query := `
+		SELECT
+			c.tgid,
+			COALESCE((la.address->>'lat')::float8, 0),
+			COALESCE((la.address->>'lng')::float8, 0)
+		FROM clients c
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE o.order_status IN ('COMPLETED', 'DELIVERED')) AS done,
+				MAX(o.created_at) FILTER (WHERE o.order_status NOT IN ('CANCELLED', 'REJECTED')) AS last_at
+			FROM orders o
+			WHERE o.tg_id = c.tgid
+		) st ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT o.address
+			FROM orders o
+			WHERE o.tg_id = c.tgid AND o.delivery_type = 'DELIVERY'
+			ORDER BY o.created_at DESC
+			LIMIT 1
+		) la ON TRUE
+	` + where + " ORDER BY c.id"
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, fmt.Errorf("broadcast candidates failed: %w", err)
+	}
+	defer rows.Close()
+
+	list := []structs.BroadcastCandidate{}
+	for rows.Next() {
+		var c structs.BroadcastCandidate
+		if err := rows.Scan(&c.TgID, &c.LastLat, &c.LastLng); err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return nil, fmt.Errorf("scan broadcast candidate failed: %w", err)
+		}
+		list = append(list, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcast candidates rows failed: %w", err)
+	}
+	return list, nil
+}
+
+func (r repo) AddRecipients(ctx context.Context, id int64, tgIDs []int64) error {
+	query := `
+		WITH ins AS (
+			INSERT INTO broadcast_recipients (broadcast_id, tg_id)
+			SELECT $1, unnest($2::bigint[])
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
+		UPDATE broadcasts
+		SET total = total + (SELECT COUNT(*) FROM ins), updated_at = now()
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, tgIDs); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("add broadcast recipients failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) NextPending(ctx context.Context, id int64, limit int) ([]int64, error) {
+	query := `
+		SELECT tg_id FROM broadcast_recipients
+		WHERE broadcast_id = $1 AND status = 'PENDING'
+		ORDER BY tg_id
+		LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, id, limit)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, fmt.Errorf("next broadcast recipients failed: %w", err)
+	}
+	defer rows.Close()
+
+	var list []int64
+	for rows.Next() {
+		var tgID int64
+		if err := rows.Scan(&tgID); err != nil {
+			return nil, fmt.Errorf("scan broadcast recipient failed: %w", err)
+		}
+		list = append(list, tgID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("broadcast recipients rows failed: %w", err)
+	}
+	return list, nil
+}
+
+// MarkRecipient - natija + broadcasts hisoblagichlari bitta so'rovda
+func (r repo) MarkRecipient(ctx context.Context, id, tgID int64, status, errText string) error {
+	query := `
+		WITH upd AS (
+			UPDATE broadcast_recipients
+			SET status = $3, error = $4, sent_at = now()
+			WHERE broadcast_id = $1 AND tg_id = $2 AND status = 'PENDING'
+			RETURNING status
+		)
+		UPDATE broadcasts
+		SET sent       = sent + (SELECT COUNT(*) FROM upd WHERE status = 'SENT'),
+		    blocked    = blocked + (SELECT COUNT(*) FROM upd WHERE status = 'BLOCKED'),
+		    failed     = failed + (SELECT COUNT(*) FROM upd WHERE status = 'FAILED'),
+		    updated_at = now()
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id, tgID, status, errText); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("mark broadcast recipient failed: %w", err)
+	}
+	return nil
+}
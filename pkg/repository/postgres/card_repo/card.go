@@ -0,0 +1,208 @@
+package cardrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// Create - cards.check natijasi (token front-end cards.create'dan), SMS tasdiqlanmaguncha verified=false
+		Create(ctx context.Context, tgID int64, card structs.PaymeCard) (structs.ClientCard, error)
+		// MarkVerified - cards.verify'dan keyin; shu mijozdagi o'sha kartaning eski nusxasi o'chiriladi
+		MarkVerified(ctx context.Context, tgID, id int64, card structs.PaymeCard) (structs.ClientCard, error)
+		GetByID(ctx context.Context, tgID, id int64) (structs.ClientCard, error)
+		GetListByTgID(ctx context.Context, tgID int64) ([]structs.ClientCard, error)
+		CountVerified(ctx context.Context, tgID int64) (int64, error)
+		Delete(ctx context.Context, tgID, id int64) error
+		// DeleteUnverified - yangi karta qo'shishda tashlab ketilgan urinishlarni tozalaydi
+		DeleteUnverified(ctx context.Context, tgID int64) ([]string, error)
+		Touch(ctx context.Context, id int64) error
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+const selectColumns = `
+	id,
+	tg_id,
+	number,
+	expire,
+	verified,
+	token,
+	last_used_at,
+	created_at
+`
+
+func scanCard(row pgx.Row) (structs.ClientCard, error) {
+	var c structs.ClientCard
+	err := row.Scan(
+		&c.ID,
+		&c.TgID,
+		&c.Number,
+		&c.Expire,
+		&c.Verified,
+		&c.Token,
+		&c.LastUsedAt,
+		&c.CreatedAt,
+	)
+	return c, err
+}
+
+func (r repo) Create(ctx context.Context, tgID int64, card structs.PaymeCard) (structs.ClientCard, error) {
+	query := `
+		INSERT INTO client_cards (tg_id, token, number, expire, verified)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + selectColumns
+
+	c, err := scanCard(r.db.QueryRow(ctx, query, tgID, card.Token, card.Number, card.Expire, card.Verify))
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return c, fmt.Errorf("create card failed: %w", err)
+	}
+	return c, nil
+}
+
+func (r repo) MarkVerified(ctx context.Context, tgID, id int64, card structs.PaymeCard) (structs.ClientCard, error) {
+	query := `
+		UPDATE client_cards
+		SET verified   = TRUE,
+		    token      = $3,
+		    number     = $4,
+		    expire     = $5,
+		    updated_at = now()
+		WHERE tg_id = $1 AND id = $2
+		RETURNING ` + selectColumns
+
+	c, err := scanCard(r.db.QueryRow(ctx, query, tgID, id, card.Token, card.Number, card.Expire))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return c, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return c, fmt.Errorf("verify card failed: %w", err)
+	}
+
+	dup := `DELETE FROM client_cards WHERE tg_id = $1 AND id <> $2 AND number = $3 AND expire = $4`
+	if _, err := r.db.Exec(ctx, dup, tgID, id, c.Number, c.Expire); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+	}
+	return c, nil
+}
+
+func (r repo) GetByID(ctx context.Context, tgID, id int64) (structs.ClientCard, error) {
+	query := `SELECT ` + selectColumns + ` FROM client_cards WHERE tg_id = $1 AND id = $2`
+
+	c, err := scanCard(r.db.QueryRow(ctx, query, tgID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return c, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return c, fmt.Errorf("get card failed: %w", err)
+	}
+	return c, nil
+}
+
+func (r repo) GetListByTgID(ctx context.Context, tgID int64) ([]structs.ClientCard, error) {
+	list := []structs.ClientCard{}
+	query := `SELECT ` + selectColumns + `
+		FROM client_cards
+		WHERE tg_id = $1 AND verified
+		ORDER BY last_used_at DESC NULLS LAST, created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, tgID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return list, fmt.Errorf("get cards failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanCard(rows)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return list, fmt.Errorf("scan card failed: %w", err)
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (r repo) CountVerified(ctx context.Context, tgID int64) (int64, error) {
+	var n int64
+	query := `SELECT COUNT(*) FROM client_cards WHERE tg_id = $1 AND verified`
+	if err := r.db.QueryRow(ctx, query, tgID).Scan(&n); err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("count cards failed: %w", err)
+	}
+	return n, nil
+}
+
+func (r repo) Delete(ctx context.Context, tgID, id int64) error {
+	res, err := r.db.Exec(ctx, `DELETE FROM client_cards WHERE tg_id = $1 AND id = $2`, tgID, id)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("delete card failed: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return structs.ErrNotFound
+	}
+	return nil
+}
+
+func (r repo) DeleteUnverified(ctx context.Context, tgID int64) ([]string, error) {
+	query := `DELETE FROM client_cards WHERE tg_id = $1 AND NOT verified RETURNING token`
+	rows, err := r.db.Query(ctx, query, tgID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, fmt.Errorf("delete unverified cards failed: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r repo) Touch(ctx context.Context, id int64) error {
+	if _, err := r.db.Exec(ctx, `UPDATE client_cards SET last_used_at = now() WHERE id = $1`, id); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("touch card failed: %w", err)
+	}
+	return nil
+}
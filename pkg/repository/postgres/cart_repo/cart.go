@@ -8,6 +8,7 @@ import (
 	"sushitana/pkg/db"
 	"sushitana/pkg/logger"
 	"sushitana/pkg/utils"
+	"time"
 
 	"github.com/spf13/cast"
 	"go.uber.org/fx"
@@ -33,6 +34,12 @@ type (
 		GetByUserTgID(ctx context.Context, tgID int64) (structs.GetCartByTgID, error)
 		GetByTgID(ctx context.Context, tgID int64) (structs.CartInfo, error)
 		ChangeCountDelta(ctx context.Context, tgid int64, productID string, delta int64) (newCount int64, err error)
+
+		// Abandoned - idleBefore'dan beri tegilmagan (lekin notOlderThan'dan yangi) va
+		// orderAfter'dan keyin zakaz bermagan, eslatma olmagan savatlar
+		Abandoned(ctx context.Context, req structs.AbandonedCartsRequest) ([]structs.AbandonedCart, error)
+		// MarkReminded - shu savatga eslatma yozib qo'yiladi; false - allaqachon yuborilgan
+		MarkReminded(ctx context.Context, tgID int64, cartStartedAt time.Time) (bool, error)
 	}
 
 	repo struct {
@@ -54,7 +61,7 @@ func (r repo) Create(ctx context.Context, req structs.CreateCart) error {
 		INSERT INTO carts (tgid, product_id, count)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (tgid, product_id)
-		DO UPDATE SET count = carts.count + EXCLUDED.count
+		DO UPDATE SET count = carts.count + EXCLUDED.count, updated_at = now()
 	`
 
 	if _, err := r.db.Exec(ctx, upsertQuery, req.TGID, req.ProductID, req.Count); err != nil {
@@ -81,7 +88,7 @@ func (r repo) ChangeCountDelta(ctx context.Context, tgid int64, productID string
 			INSERT INTO carts (tgid, product_id, count)
 			VALUES ($1, $2, GREATEST($3, 0))
 			ON CONFLICT (tgid, product_id)
-			DO UPDATE SET count = GREATEST(carts.count + EXCLUDED.count, 0)
+			DO UPDATE SET count = GREATEST(carts.count + EXCLUDED.count, 0), updated_at = now()
 			RETURNING count
 		),
 		del AS (
@@ -141,6 +148,7 @@ func (r repo) Patch(ctx context.Context, req structs.PatchCart) (int64, error) {
 	if len(setValues) == 0 {
 		return 0, fmt.Errorf("no fields to update for cart with ID %d", *req.TGID)
 	}
+	setValues = append(setValues, "updated_at = now()")
 
 	query := fmt.Sprintf(`
         UPDATE carts
@@ -328,3 +336,63 @@ GROUP BY t.tgid;
 	res.Cart = cart
 	return res, nil
 }
+
+func (r repo) Abandoned(ctx context.Context, req structs.AbandonedCartsRequest) ([]structs.AbandonedCart, error) {
+	query := `
+		WITH idle AS (
+			SELECT
+				c.tgid,
+				MIN(c.created_at) AS started_at,
+				MAX(c.updated_at) AS touched_at
+			FROM carts c
+			-- migratsiyagacha qo'shilgan qatorlarda created_at NULL - yoshi noma'lum, eslatilmaydi
+			WHERE c.count > 0 AND c.created_at IS NOT NULL AND c.updated_at IS NOT NULL
+			GROUP BY c.tgid
+		)
+		SELECT
+			i.tgid,
+			COALESCE(cl.language, 'uz'),
+			i.started_at
+		FROM idle i
+		JOIN clients cl ON cl.tgid = i.tgid
+		WHERE i.touched_at < $1
+		  AND i.touched_at >= $2
+		  AND cl.cart_reminders
+		  AND COALESCE(cl.is_active, true)
+		  AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.tg_id = i.tgid AND o.created_at >= $3)
+		  AND NOT EXISTS (SELECT 1 FROM cart_reminders r WHERE r.tg_id = i.tgid AND r.cart_started_at = i.started_at)
+		ORDER BY i.touched_at
+		LIMIT $4
+	`
+	rows, err := r.db.Query(ctx, query, req.IdleBefore, req.NotOlderThan, req.OrderAfter, req.Limit)
+	if err != nil {
+		r.logger.Error(ctx, "failed to get abandoned carts", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []structs.AbandonedCart
+	for rows.Next() {
+		var a structs.AbandonedCart
+		if err := rows.Scan(&a.TgID, &a.Language, &a.StartedAt); err != nil {
+			r.logger.Error(ctx, "failed to scan abandoned cart", zap.Error(err))
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (r repo) MarkReminded(ctx context.Context, tgID int64, cartStartedAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO cart_reminders (tg_id, cart_started_at)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+	res, err := r.db.Exec(ctx, query, tgID, cartStartedAt)
+	if err != nil {
+		r.logger.Error(ctx, "failed to mark cart reminded", zap.Error(err))
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
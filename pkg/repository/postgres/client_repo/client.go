@@ -37,6 +37,13 @@ type (
 		UpdateLanguage(ctx context.Context, tgID int64, lang utils.Lang) error
 		UpdatePhone(ctx context.Context, tgID int64, phone string) error
 		UpdateName(ctx context.Context, tgID int64, name string) error
+		UpdatePromoCode(ctx context.Context, tgID int64, code string) error
+		// RestorePromoCode - bekor qilingan zakazdagi kod qaytariladi (mijozda boshqa kod bo'lmasa)
+		RestorePromoCode(ctx context.Context, tgID int64, code string) error
+		// SetActive - false: botni bloklagan (broadcast 403), true: qaytib keldi
+		SetActive(ctx context.Context, tgID int64, active bool) error
+		// SetCartReminders - "tashlab ketilgan savat" eslatmalarini yoqish/o'chirish
+		SetCartReminders(ctx context.Context, tgID int64, enabled bool) error
 		GetLanguageByTgID(ctx context.Context, tgID int64) (string, error)
 	}
 
@@ -55,9 +62,9 @@ func New(p Params) Repo {
 func (r *repo) Create(ctx context.Context, req structs.CreateClient) (resp structs.Client, err error) {
 	r.logger.Info(ctx, "Create client", zap.Any("req", req))
 	query := `
-        INSERT INTO clients (tgid) VALUES ($1) ON CONFLICT (tgid) DO NOTHING
+        INSERT INTO clients (tgid, source) VALUES ($1, $2) ON CONFLICT (tgid) DO NOTHING
     `
-	err = r.db.QueryRow(ctx, query, req.TgID).Scan(&resp.ID, &resp.Language)
+	err = r.db.QueryRow(ctx, query, req.TgID, req.Source).Scan(&resp.ID, &resp.Language)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			existing, getErr := r.GetByTgID(ctx, req.TgID)
@@ -86,7 +93,9 @@ func (r repo) GetByTgID(ctx context.Context, tgid int64) (structs.Client, error)
 				name,
                 created_at,
                 updated_at,
-				is_active
+				is_active,
+				COALESCE(source, ''),
+				COALESCE(promo_code, '')
             FROM clients
             WHERE tgid = $1
         `
@@ -101,6 +110,8 @@ func (r repo) GetByTgID(ctx context.Context, tgid int64) (structs.Client, error)
 		&resp.CreatedAt,
 		&resp.UpdatedAt,
 		&resp.IsActive,
+		&resp.Source,
+		&resp.PromoCode,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -176,6 +187,7 @@ func (r repo) GetList(ctx context.Context, req structs.GetListClientRequest) (re
 		c.updated_at,
 		COALESCE(c.is_active, true)  AS is_active,
 		COALESCE(c.name, '')         AS name,
+		COALESCE(c.source, '')       AS source,
 		COALESCE(o.completed_orders, 0) AS completed_orders,
 		COALESCE(o.total_orders, 0)     AS total_orders,
 		COALESCE(o.cancelled_orders, 0) AS cancelled_orders
@@ -212,6 +224,7 @@ func (r repo) GetList(ctx context.Context, req structs.GetListClientRequest) (re
 			&client.UpdatedAt,
 			&client.IsActive,
 			&client.Name,
+			&client.Source,
 			&client.CompletedOrderCount,
 			&client.OrderCount,
 			&client.CanceledOrderCount,
@@ -251,6 +264,12 @@ func filterClientQuery(req structs.GetListClientRequest) (string, pgx.NamedArgs)
 		b.WriteString(` AND c.name ILIKE @name `)
 	}
 
+	if !utils.StrEmpty(strings.TrimSpace(req.Source)) {
+		// "_" LIKE'da wildcard, p_/c_ prefikslar uchun escape qilamiz
+		queryParams["source"] = strings.ReplaceAll(strings.TrimSpace(req.Source), "_", `\_`) + "%"
+		b.WriteString(` AND c.source LIKE @source `)
+	}
+
 	// ✅ date range filter (created_at_from/to)
 	if req.CreatedAtFrom != nil {
 		queryParams["created_at_from"] = *req.CreatedAtFrom
@@ -360,6 +379,72 @@ func (r *repo) UpdateName(ctx context.Context, tgID int64, name string) error {
 	return nil
 }
 
+// UpdatePromoCode - promo_<code> deep link'dan; buyurtma yaratilgach "" bilan tozalanadi
+func (r *repo) UpdatePromoCode(ctx context.Context, tgID int64, code string) error {
+	query := `
+        UPDATE clients
+        SET promo_code = $1,
+            updated_at = now()
+        WHERE tgid = $2
+    `
+	result, err := r.db.Exec(ctx, query, code, tgID)
+	if err != nil {
+		r.logger.Error(ctx, "error updating promo code", zap.Error(err))
+		return fmt.Errorf("failed to update promo code: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		r.logger.Warn(ctx, "no client found with given tgid", zap.Int64("tgid", tgID))
+		return structs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *repo) RestorePromoCode(ctx context.Context, tgID int64, code string) error {
+	query := `
+        UPDATE clients
+        SET promo_code = $1,
+            updated_at = now()
+        WHERE tgid = $2
+          AND COALESCE(promo_code, '') = ''
+    `
+	if _, err := r.db.Exec(ctx, query, code, tgID); err != nil {
+		r.logger.Error(ctx, "error restoring promo code", zap.Error(err))
+		return fmt.Errorf("failed to restore promo code: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) SetActive(ctx context.Context, tgID int64, active bool) error {
+	query := `
+        UPDATE clients
+        SET is_active  = $1,
+            blocked_at = CASE WHEN $1 THEN NULL ELSE now() END,
+            updated_at = now()
+        WHERE tgid = $2
+    `
+	if _, err := r.db.Exec(ctx, query, active, tgID); err != nil {
+		r.logger.Error(ctx, "error updating is_active", zap.Error(err))
+		return fmt.Errorf("failed to update is_active: %w", err)
+	}
+	return nil
+}
+
+func (r *repo) SetCartReminders(ctx context.Context, tgID int64, enabled bool) error {
+	query := `
+        UPDATE clients
+        SET cart_reminders = $1,
+            updated_at     = now()
+        WHERE tgid = $2
+    `
+	if _, err := r.db.Exec(ctx, query, enabled, tgID); err != nil {
+		r.logger.Error(ctx, "error updating cart_reminders", zap.Error(err))
+		return fmt.Errorf("failed to update cart_reminders: %w", err)
+	}
+	return nil
+}
+
 func (r *repo) GetLanguageByTgID(ctx context.Context, tgID int64) (string, error) {
 	r.logger.Info(ctx, "Update client lang", zap.Int64("tgid", tgID))
 	var language string
@@ -12,8 +12,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -34,6 +36,7 @@ type (
 		GetAll(ctx context.Context, req structs.GetListEmployeeRequest) (structs.GetListEmployeeResponse, error)
 		Delete(ctx context.Context, id int64) error
 		Patch(ctx context.Context, req structs.PatchEmployee) (int64, error)
+		GetByTgID(ctx context.Context, tgID int64) (structs.Employee, error)
 	}
 
 	repo struct {
@@ -65,8 +68,9 @@ func (r repo) Create(ctx context.Context, req structs.CreateEmployee) (structs.E
 				password,
 				is_active,
 				phone_number,
-				role_id
-			) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id
+				role_id,
+				tg_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id
 		`
 		id int64
 	)
@@ -95,6 +99,7 @@ func (r repo) Create(ctx context.Context, req structs.CreateEmployee) (structs.E
 		req.IsActive,
 		req.PhoneNumber,
 		req.RoleId,
+		req.TgID,
 	).Scan(&id)
 	if err != nil {
 		errors.As(err, &pgErr)
@@ -129,6 +134,7 @@ func (r repo) GetById(ctx context.Context, id int64) (structs.Employee, error) {
 				is_active,
 				phone_number,
 				role_id,
+				tg_id,
 				created_at,
 				updated_at
 			FROM employees
@@ -144,6 +150,7 @@ func (r repo) GetById(ctx context.Context, id int64) (structs.Employee, error) {
 		&resp.IsActive,
 		&resp.PhoneNumber,
 		&resp.RoleId,
+		&resp.TgID,
 		&resp.CreatedAt,
 		&resp.UpdatedAt,
 	)
@@ -171,6 +178,7 @@ func (r repo) GetAll(ctx context.Context, req structs.GetListEmployeeRequest) (s
 				e.phone_number,
 				e.role_id,
 				r.role_name,
+				e.tg_id,
 				e.created_at,
 				e.updated_at
 			FROM employees as e
@@ -215,6 +223,7 @@ func (r repo) GetAll(ctx context.Context, req structs.GetListEmployeeRequest) (s
 			&employee.PhoneNumber,
 			&employee.RoleId,
 			&employee.RoleName,
+			&employee.TgID,
 			&employee.CreatedAt,
 			&employee.UpdatedAt,
 		)
@@ -270,6 +279,14 @@ func (r repo) Patch(ctx context.Context, req structs.PatchEmployee) (int64, erro
 	if req.RoleId != nil {
 		addFiled("role_id", *req.RoleId, "role_id")
 	}
+	if req.TgID != nil {
+		// 0 -> bog'lanishni olib tashlash
+		if *req.TgID == 0 {
+			updateFields = append(updateFields, "tg_id = NULL")
+		} else {
+			addFiled("tg_id", *req.TgID, "tg_id")
+		}
+	}
 	updateFields = append(updateFields, "updated_at = NOW()")
 	query := fmt.Sprintf(`
 		UPDATE "employees"
@@ -332,3 +349,46 @@ func (r repo) Update(ctx context.Context, req structs.UpdateEmployee) (int64, er
 	}
 	return ra, nil
 }
+
+// GetByTgID - operator guruhida tugma bosgan Telegram foydalanuvchisi qaysi xodim
+func (r repo) GetByTgID(ctx context.Context, tgID int64) (structs.Employee, error) {
+	var (
+		resp  structs.Employee
+		query = `
+			SELECT
+				id,
+				name,
+				surname,
+				username,
+				is_active,
+				phone_number,
+				role_id,
+				tg_id,
+				created_at,
+				updated_at
+			FROM employees
+			WHERE tg_id = $1
+		`
+	)
+
+	err := r.db.QueryRow(ctx, query, tgID).Scan(
+		&resp.Id,
+		&resp.Name,
+		&resp.Surname,
+		&resp.Username,
+		&resp.IsActive,
+		&resp.PhoneNumber,
+		&resp.RoleId,
+		&resp.TgID,
+		&resp.CreatedAt,
+		&resp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.Employee{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return structs.Employee{}, fmt.Errorf("get employee by tg_id failed: %w", err)
+	}
+	return resp, nil
+}
@@ -1,6 +1,9 @@
 package postgres
 
 import (
+	addressrepo "sushitana/pkg/repository/postgres/address_repo"
+	broadcastrepo "sushitana/pkg/repository/postgres/broadcast_repo"
+	cardrepo "sushitana/pkg/repository/postgres/card_repo"
 	cartrepo "sushitana/pkg/repository/postgres/cart_repo"
 	categoryrepo "sushitana/pkg/repository/postgres/category_repo"
 	clientRepo "sushitana/pkg/repository/postgres/client_repo"
@@ -8,11 +11,19 @@ import (
 	filerepo "sushitana/pkg/repository/postgres/file_repo"
 	iikorepo "sushitana/pkg/repository/postgres/iiko_repo"
 	menurepo "sushitana/pkg/repository/postgres/menu_repo"
+	operatorrepo "sushitana/pkg/repository/postgres/operator_repo"
 	orderrepo "sushitana/pkg/repository/postgres/order_repo"
 	clickrepo "sushitana/pkg/repository/postgres/payment_repo/click_repo"
 	paymerepo "sushitana/pkg/repository/postgres/payment_repo/payme_repo"
+	telegramrepo "sushitana/pkg/repository/postgres/payment_repo/telegram_repo"
+	uzumrepo "sushitana/pkg/repository/postgres/payment_repo/uzum_repo"
 	productRepo "sushitana/pkg/repository/postgres/product_repo"
+	ratingrepo "sushitana/pkg/repository/postgres/rating_repo"
+	reconciliationrepo "sushitana/pkg/repository/postgres/reconciliation_repo"
+	referralrepo "sushitana/pkg/repository/postgres/referral_repo"
+	refundrepo "sushitana/pkg/repository/postgres/refund_repo"
 	rolerepo "sushitana/pkg/repository/postgres/role_repo"
+	supportrepo "sushitana/pkg/repository/postgres/support_repo"
 	userRepo "sushitana/pkg/repository/postgres/users_repo"
 
 	"go.uber.org/fx"
@@ -32,4 +43,15 @@ var Module = fx.Options(
 	orderrepo.Module,
 	clickrepo.Module,
 	paymerepo.Module,
+	telegramrepo.Module,
+	uzumrepo.Module,
+	addressrepo.Module,
+	operatorrepo.Module,
+	supportrepo.Module,
+	ratingrepo.Module,
+	broadcastrepo.Module,
+	referralrepo.Module,
+	reconciliationrepo.Module,
+	cardrepo.Module,
+	refundrepo.Module,
 )
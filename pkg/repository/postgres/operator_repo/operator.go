@@ -0,0 +1,138 @@
+package operatorrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// Claim - zakaz uchun post yozuvini band qiladi; allaqachon bor bo'lsa false (ikki marta post qilinmasin)
+		Claim(ctx context.Context, orderID string, chatID int64) (bool, error)
+		SetMessageID(ctx context.Context, orderID string, messageID int) error
+		Release(ctx context.Context, orderID string) error
+		Get(ctx context.Context, orderID string) (structs.OperatorPost, error)
+		SetAction(ctx context.Context, orderID string, employeeID int64, action, reason string) error
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+func (r repo) Claim(ctx context.Context, orderID string, chatID int64) (bool, error) {
+	query := `
+		INSERT INTO operator_posts (order_id, chat_id)
+		VALUES ($1, $2)
+		ON CONFLICT (order_id) DO NOTHING
+	`
+	res, err := r.db.Exec(ctx, query, orderID, chatID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return false, fmt.Errorf("claim operator post failed: %w", err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (r repo) SetMessageID(ctx context.Context, orderID string, messageID int) error {
+	query := `
+		UPDATE operator_posts
+		SET message_id = $2,
+		    updated_at = now()
+		WHERE order_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, orderID, messageID); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("set operator post message_id failed: %w", err)
+	}
+	return nil
+}
+
+// Release - xabar yuborilmay qolsa band qilingan yozuv o'chiriladi (keyingi status'da qayta urinadi)
+func (r repo) Release(ctx context.Context, orderID string) error {
+	query := `DELETE FROM operator_posts WHERE order_id = $1 AND message_id = 0`
+	if _, err := r.db.Exec(ctx, query, orderID); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("release operator post failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) Get(ctx context.Context, orderID string) (structs.OperatorPost, error) {
+	var (
+		resp  structs.OperatorPost
+		query = `
+			SELECT
+				order_id,
+				chat_id,
+				message_id,
+				employee_id,
+				action,
+				reason,
+				created_at,
+				updated_at
+			FROM operator_posts
+			WHERE order_id = $1
+		`
+	)
+	err := r.db.QueryRow(ctx, query, orderID).Scan(
+		&resp.OrderID,
+		&resp.ChatID,
+		&resp.MessageID,
+		&resp.EmployeeID,
+		&resp.Action,
+		&resp.Reason,
+		&resp.CreatedAt,
+		&resp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.OperatorPost{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return structs.OperatorPost{}, fmt.Errorf("get operator post failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) SetAction(ctx context.Context, orderID string, employeeID int64, action, reason string) error {
+	query := `
+		UPDATE operator_posts
+		SET employee_id = $2,
+		    action      = $3,
+		    reason      = $4,
+		    updated_at  = now()
+		WHERE order_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, orderID, employeeID, action, reason); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("set operator post action failed: %w", err)
+	}
+	return nil
+}
@@ -4,13 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"sushitana/internal/structs"
 	"sushitana/pkg/db"
 	"sushitana/pkg/logger"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -42,6 +46,14 @@ type (
 		TryMarkNotified(ctx context.Context, orderID string, st string) (structs.NotifyTarget, bool, error)
 		GetProductPriceWithBox(ctx context.Context, productID string) (price int64, name structs.Name, url string, boxID string, err error)
 		GetByIikoOrderID(ctx context.Context, iikoOrderID string) (resp structs.Order, err error)
+		CancelByClient(ctx context.Context, req structs.CancelOrderByClient) error
+		ConfirmByOperator(ctx context.Context, orderID string) error
+		RejectByOperator(ctx context.Context, req structs.RejectOrderByOperator) error
+		CountClientCancels(ctx context.Context, tgID int64, since time.Time) (int64, error)
+		ChangePaymentMethod(ctx context.Context, orderID, method string, online bool) error
+		// SetCourier - iiko webhook'dagi kuryer (choy puli shunga yoziladi)
+		SetCourier(ctx context.Context, orderID, courierID, courierName string) error
+		TipSummary(ctx context.Context, from, to *time.Time) (structs.TipSummary, error)
 	}
 
 	repo struct {
@@ -65,15 +77,16 @@ func (r repo) Create(ctx context.Context, req structs.CreateOrder) (id string, e
 		paymentStatus string
 	)
 	id = uuid.NewString()
-	switch req.PaymentMethod {
-	case "CASH":
-		status = "WAITING_OPERATOR"
-		paymentStatus = "UNPAID"
-	case "CLICK", "PAYME":
+	if strings.TrimSpace(req.PaymentMethod) == "" {
+		return "", fmt.Errorf("unsupported payment method: %s", req.PaymentMethod)
+	}
+	// online usullar PAID bo'lguncha WAITING_PAYMENT'da turadi
+	if req.OnlinePayment {
 		status = "WAITING_PAYMENT"
 		paymentStatus = "PENDING"
-	default:
-		return "", fmt.Errorf("unsupported payment method: %s", req.PaymentMethod)
+	} else {
+		status = "WAITING_OPERATOR"
+		paymentStatus = "UNPAID"
 	}
 	deliveryType := strings.ToUpper(strings.TrimSpace(req.DeliveryType))
 
@@ -95,8 +108,12 @@ func (r repo) Create(ctx context.Context, req structs.CreateOrder) (id string, e
 			iiko_order_id,
 			iiko_delivery_id,
 			delivery_price,
-			items
-		) VALUES ($1, $2::bigint, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			items,
+			change_from,
+			tip_amount,
+			promo_code,
+			discount_amount
+		) VALUES ($1, $2::bigint, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	`
 
 	if _, err := r.db.Exec(ctx, query,
@@ -112,7 +129,16 @@ func (r repo) Create(ctx context.Context, req structs.CreateOrder) (id string, e
 		req.IIKODeliveryID,
 		deliveryPrice,
 		req.Products,
+		req.ChangeFrom,
+		req.TipAmount,
+		req.PromoCode,
+		req.DiscountAmount,
 	); err != nil {
+		// promo-kod parallel boshqa zakazda ishlatildi (orders_promo_code_active_uq)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == "orders_promo_code_active_uq" {
+			return "", structs.ErrPromoCodeUsed
+		}
 		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
 		return "", fmt.Errorf("create order failed: %w", err)
 	}
@@ -139,6 +165,12 @@ func (r repo) GetByTgId(ctx context.Context, tgId int64) (resp structs.GetListOr
             o.items,
             o.order_number,
             o.delivery_price,
+            o.change_from,
+            o.tip_amount,
+            o.promo_code,
+            o.discount_amount,
+            o.courier_id,
+            o.courier_name,
             o.created_at,
             o.updated_at,
 			o.payment_url,
@@ -180,6 +212,12 @@ func (r repo) GetByTgId(ctx context.Context, tgId int64) (resp structs.GetListOr
 			&itemsBytes,
 			&order.OrderNumber,
 			&order.DeliveryPrice,
+			&order.ChangeFrom,
+			&order.TipAmount,
+			&order.PromoCode,
+			&order.DiscountAmount,
+			&order.CourierID,
+			&order.CourierName,
 			&order.CreatedAt,
 			&order.UpdateAt,
 			&order.PaymentUrl,
@@ -222,6 +260,12 @@ func (r repo) GetByID(ctx context.Context, id string) (resp structs.GetListPrima
 			o.iiko_delivery_id,
 			o.items,
 			o.delivery_price,
+			o.change_from,
+			o.tip_amount,
+			o.promo_code,
+			o.discount_amount,
+			o.courier_id,
+			o.courier_name,
 			o.order_number,
 			COALESCE(o.payment_url, '') AS payment_url,
 			o.created_at,
@@ -246,6 +290,12 @@ func (r repo) GetByID(ctx context.Context, id string) (resp structs.GetListPrima
 		&order.IIKODeliveryID,
 		&order.Products,
 		&order.DeliveryPrice,
+		&order.ChangeFrom,
+		&order.TipAmount,
+		&order.PromoCode,
+		&order.DiscountAmount,
+		&order.CourierID,
+		&order.CourierName,
 		&order.OrderNumber,
 		&order.PaymentUrl,
 		&order.CreatedAt,
@@ -317,8 +367,11 @@ func (r repo) GetByID(ctx context.Context, id string) (resp structs.GetListPrima
 	}
 
 	// final totals
-	order.OrderPriceForIIKO = orderTotal + boxTotal
-	order.TotalPrice = orderTotal + boxTotal + order.DeliveryPrice
+	// promo chegirmasi iiko to'lov summasidan ham ayriladi (iiko'da discountsInfo bilan)
+	order.DiscountAmount = structs.AppliedDiscount(order.DiscountAmount, orderTotal+boxTotal)
+	order.OrderPriceForIIKO = orderTotal + boxTotal - order.DiscountAmount
+	order.TotalPrice = orderTotal + boxTotal + order.DeliveryPrice - order.DiscountAmount
+	order.PayAmount = order.TotalPrice + order.TipAmount
 
 	r.logger.Info(ctx, "order retrieved", zap.String("id", id))
 	resp.Order = order
@@ -342,6 +395,12 @@ func (r repo) GetByMerchantTransId(ctx context.Context, merchantTransID string)
 			o.iiko_delivery_id,
 			o.items,
 			o.delivery_price,
+			o.change_from,
+			o.tip_amount,
+			o.promo_code,
+			o.discount_amount,
+			o.courier_id,
+			o.courier_name,
 			o.order_number,
 			o.payment_url,
 			o.created_at,
@@ -372,6 +431,12 @@ func (r repo) GetByMerchantTransId(ctx context.Context, merchantTransID string)
 		&order.IIKODeliveryID,
 		&itemsBytes,
 		&order.DeliveryPrice,
+		&order.ChangeFrom,
+		&order.TipAmount,
+		&order.PromoCode,
+		&order.DiscountAmount,
+		&order.CourierID,
+		&order.CourierName,
 		&order.OrderNumber,
 		&order.PaymentUrl,
 		&order.CreatedAt,
@@ -407,6 +472,12 @@ func (r repo) GetByOrderNumber(ctx context.Context, number int64) (resp structs.
 			o.iiko_delivery_id,
 			o.items,
 			o.delivery_price,
+			o.change_from,
+			o.tip_amount,
+			o.promo_code,
+			o.discount_amount,
+			o.courier_id,
+			o.courier_name,
 			o.order_number,
 			o.payment_url,
 			o.created_at,
@@ -437,6 +508,12 @@ func (r repo) GetByOrderNumber(ctx context.Context, number int64) (resp structs.
 		&order.IIKODeliveryID,
 		&itemsBytes,
 		&order.DeliveryPrice,
+		&order.ChangeFrom,
+		&order.TipAmount,
+		&order.PromoCode,
+		&order.DiscountAmount,
+		&order.CourierID,
+		&order.CourierName,
 		&order.OrderNumber,
 		&order.PaymentUrl,
 		&order.CreatedAt,
@@ -475,6 +552,12 @@ func (r repo) GetList(ctx context.Context, req structs.GetListOrderRequest) (res
 			o.iiko_delivery_id,
 			o.items,
 			o.delivery_price,
+			o.change_from,
+			o.tip_amount,
+			o.promo_code,
+			o.discount_amount,
+			o.courier_id,
+			o.courier_name,
 			o.order_number,
 			o.payment_url,
 			o.created_at,
@@ -581,6 +664,12 @@ func (r repo) GetList(ctx context.Context, req structs.GetListOrderRequest) (res
 			&order.IIKODeliveryID,
 			&itemsBytes,
 			&order.DeliveryPrice,
+			&order.ChangeFrom,
+			&order.TipAmount,
+			&order.PromoCode,
+			&order.DiscountAmount,
+			&order.CourierID,
+			&order.CourierName,
 			&order.OrderNumber,
 			&order.PaymentUrl,
 			&order.CreatedAt,
@@ -651,6 +740,201 @@ func (r repo) UpdateStatus(ctx context.Context, req structs.UpdateStatus) error
 	return nil
 }
 
+// CancelByClient faqat COOKING'gacha bo'lgan va to'lanmagan zakazni bekor qiladi.
+// Shart bajarilmasa structs.ErrNotCancellable qaytadi.
+func (r repo) CancelByClient(ctx context.Context, req structs.CancelOrderByClient) error {
+	query := `
+		UPDATE orders
+		SET order_status  = 'CANCELLED',
+		    cancel_reason = $3,
+		    cancelled_by  = 'CLIENT',
+		    cancelled_at  = now(),
+		    updated_at    = now()
+		WHERE id = $1
+		  AND tg_id = $2
+		  AND order_status IN ('WAITING_PAYMENT', 'WAITING_OPERATOR')
+		  AND payment_status <> 'PAID'
+	`
+	rowsAffected, err := r.db.Exec(ctx, query, req.OrderId, req.TgID, req.Reason)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("cancel order by client failed: %w", err)
+	}
+	if rowsAffected.RowsAffected() == 0 {
+		return structs.ErrNotCancellable
+	}
+	return nil
+}
+
+// ConfirmByOperator faqat operator tasdiqini kutayotgan zakazni COOKING'ga o'tkazadi
+// (parallel bekor qilingan/rad etilgan zakaz oshxonaga ketmasin).
+// Shart bajarilmasa structs.ErrNotWaitingOperator qaytadi.
+func (r repo) ConfirmByOperator(ctx context.Context, orderID string) error {
+	query := `
+		UPDATE orders
+		SET order_status = 'COOKING',
+		    updated_at   = now()
+		WHERE id = $1
+		  AND order_status = 'WAITING_OPERATOR'
+	`
+	rowsAffected, err := r.db.Exec(ctx, query, orderID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("confirm order by operator failed: %w", err)
+	}
+	if rowsAffected.RowsAffected() == 0 {
+		return structs.ErrNotWaitingOperator
+	}
+	return nil
+}
+
+// RejectByOperator faqat operator tasdiqini kutayotgan va to'lanmagan zakazni rad etadi
+// (to'langan zakaz pulini qaytarmasdan yopib bo'lmaydi).
+// Shart bajarilmasa structs.ErrNotWaitingOperator qaytadi.
+func (r repo) RejectByOperator(ctx context.Context, req structs.RejectOrderByOperator) error {
+	query := `
+		UPDATE orders
+		SET order_status  = 'REJECTED',
+		    cancel_reason = $2,
+		    cancelled_by  = 'OPERATOR',
+		    cancelled_at  = now(),
+		    updated_at    = now()
+		WHERE id = $1
+		  AND order_status = 'WAITING_OPERATOR'
+		  AND payment_status <> 'PAID'
+	`
+	rowsAffected, err := r.db.Exec(ctx, query, req.OrderId, req.Reason)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("reject order by operator failed: %w", err)
+	}
+	if rowsAffected.RowsAffected() == 0 {
+		return structs.ErrNotWaitingOperator
+	}
+	return nil
+}
+
+// ChangePaymentMethod faqat to'lanmagan WAITING_PAYMENT zakazda usulni almashtiradi, eski havola o'chadi.
+// Status/payment_status Create'dagidek: online -> WAITING_PAYMENT/PENDING, naqd -> WAITING_OPERATOR/UNPAID.
+// Naqdga o'tsa choy puli, online'ga o'tsa qaytim summasi nolga tushadi.
+// Shart bajarilmasa structs.ErrOrderNotPayable qaytadi.
+func (r repo) ChangePaymentMethod(ctx context.Context, orderID, method string, online bool) error {
+	status, paymentStatus := "WAITING_OPERATOR", "UNPAID"
+	if online {
+		status, paymentStatus = "WAITING_PAYMENT", "PENDING"
+	}
+
+	query := `
+		UPDATE orders
+		SET payment_method = $2,
+		    order_status   = $3,
+		    payment_status = $4,
+		    payment_url    = '',
+		    tip_amount     = CASE WHEN $5 THEN tip_amount ELSE 0 END,
+		    change_from    = CASE WHEN $5 THEN 0 ELSE change_from END,
+		    updated_at     = now()
+		WHERE id = $1
+		  AND order_status = 'WAITING_PAYMENT'
+		  AND payment_status <> 'PAID'
+	`
+	rowsAffected, err := r.db.Exec(ctx, query, orderID, method, status, paymentStatus, online)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("change payment method failed: %w", err)
+	}
+	if rowsAffected.RowsAffected() == 0 {
+		return structs.ErrOrderNotPayable
+	}
+	return nil
+}
+
+func (r repo) SetCourier(ctx context.Context, orderID, courierID, courierName string) error {
+	query := `
+		UPDATE orders
+		SET courier_id   = $2,
+		    courier_name = $3,
+		    updated_at   = now()
+		WHERE id = $1
+		  AND (courier_id <> $2 OR courier_name <> $3)
+	`
+	if _, err := r.db.Exec(ctx, query, orderID, courierID, courierName); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("set order courier failed: %w", err)
+	}
+	return nil
+}
+
+// TipSummary - to'langan va yetkazilgan zakazlardagi choy puli, umumiy va har kuryer
+func (r repo) TipSummary(ctx context.Context, from, to *time.Time) (structs.TipSummary, error) {
+	resp := structs.TipSummary{Couriers: []structs.CourierTips{}}
+
+	// GROUPING SETS: () - umumiy, (courier_id) - har kuryer (kuryer noma'lum bo'lsa '' ostida)
+	query := `
+		SELECT
+			GROUPING(courier_id) = 1,
+			COALESCE(courier_id, ''),
+			COALESCE(MAX(courier_name), ''),
+			COUNT(*),
+			COALESCE(SUM(tip_amount), 0)::bigint
+		FROM orders
+		WHERE tip_amount > 0
+		  AND payment_status = 'PAID'
+		  AND order_status IN ('DELIVERED', 'COMPLETED')
+		  AND ($1::timestamptz IS NULL OR created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR created_at < $2)
+		GROUP BY GROUPING SETS ((), (courier_id))
+		ORDER BY 1 DESC, 5 DESC
+	`
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get tip summary failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			overall bool
+			item    structs.CourierTips
+		)
+		if err := rows.Scan(
+			&overall,
+			&item.CourierID,
+			&item.CourierName,
+			&item.Count,
+			&item.Amount,
+		); err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan tip summary failed: %w", err)
+		}
+		if overall {
+			resp.Count, resp.Amount = item.Count, item.Amount
+			continue
+		}
+		resp.Couriers = append(resp.Couriers, item)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("tip summary rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) CountClientCancels(ctx context.Context, tgID int64, since time.Time) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE tg_id = $1
+		  AND cancelled_by = 'CLIENT'
+		  AND cancelled_at >= $2
+	`
+	var cnt int64
+	if err := r.db.QueryRow(ctx, query, tgID, since).Scan(&cnt); err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("count client cancels failed: %w", err)
+	}
+	return cnt, nil
+}
+
 func (r repo) UpdatePaymentStatus(ctx context.Context, req structs.UpdateStatus) error {
 	r.logger.Info(ctx, "Update order status", zap.String("orderId", req.OrderId), zap.String("status", req.Status))
 
@@ -811,8 +1095,11 @@ func (r repo) enrichOrder(ctx context.Context, order *structs.Order, prodCache m
 	}
 
 	order.TotalCount = totalItems
-	order.OrderPriceForIIKO = orderTotal + boxTotal
-	order.TotalPrice = orderTotal + boxTotal + order.DeliveryPrice
+	// promo chegirmasi iiko to'lov summasidan ham ayriladi (iiko'da discountsInfo bilan)
+	order.DiscountAmount = structs.AppliedDiscount(order.DiscountAmount, orderTotal+boxTotal)
+	order.OrderPriceForIIKO = orderTotal + boxTotal - order.DiscountAmount
+	order.TotalPrice = orderTotal + boxTotal + order.DeliveryPrice - order.DiscountAmount
+	order.PayAmount = order.TotalPrice + order.TipAmount
 }
 
 func (r *repo) TryMarkNotified(ctx context.Context, orderID string, st string) (structs.NotifyTarget, bool, error) {
@@ -853,6 +1140,12 @@ func (r repo) GetByIikoOrderID(ctx context.Context, iikoOrderID string) (resp st
       o.iiko_delivery_id,
       o.items,
       o.delivery_price,
+      o.change_from,
+      o.tip_amount,
+      o.promo_code,
+      o.discount_amount,
+      o.courier_id,
+      o.courier_name,
       o.order_number,
       o.payment_url,
       o.created_at,
@@ -883,6 +1176,12 @@ func (r repo) GetByIikoOrderID(ctx context.Context, iikoOrderID string) (resp st
 		&order.IIKODeliveryID,
 		&itemsBytes,
 		&order.DeliveryPrice,
+		&order.ChangeFrom,
+		&order.TipAmount,
+		&order.PromoCode,
+		&order.DiscountAmount,
+		&order.CourierID,
+		&order.CourierName,
 		&order.OrderNumber,
 		&order.PaymentUrl,
 		&order.CreatedAt,
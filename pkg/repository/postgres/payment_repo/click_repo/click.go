@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"sushitana/internal/structs"
 	"sushitana/pkg/db"
 	"sushitana/pkg/logger"
@@ -34,6 +36,24 @@ type (
 		GetInvoiceByTransID(ctx context.Context, transID string) (structs.ClickInvoice, error)
 		UpsertPrepare(ctx context.Context, merchantTransID string, clickTransID, clickPaydocID int64, amount string) (merchantPrepareID int64, err error)
 		UpdateOnComplete(ctx context.Context, merchantTransID string, merchantPrepareID int64, clickTransID int64, status string) (invoiceID string, orderID sql.NullString, err error)
+		CancelByOrderID(ctx context.Context, orderID string) (int64, error)
+		// SetFiscalResult - ofd_data/submit_items natijasi
+		SetFiscalResult(ctx context.Context, merchantTransID string, items []byte, status, errNote string) error
+		// SetStatus - sverka (reconciliation) Click'da to'langanini topsa invoice'ni PAID qiladi
+		SetStatus(ctx context.Context, merchantTransID string, status string) error
+		// SetCompleteError - Click Complete'ni error != 0 bilan yubordi (to'lov o'tmadi)
+		SetCompleteError(ctx context.Context, merchantTransID string, code int, note string) error
+
+		// MarkInvoiceSent - invoice/create muvaffaqiyatli bo'ldi, holatni kuzatishni boshlaymiz
+		MarkInvoiceSent(ctx context.Context, merchantTransID string) error
+		GetPhoneInvoice(ctx context.Context, merchantTransID string) (structs.ClickPhoneInvoice, error)
+		// GetWatchedInvoices - since dan keyin yuborilgan, holati hali yakunlanmagan invoicelar
+		GetWatchedInvoices(ctx context.Context, since time.Time) ([]structs.ClickPhoneInvoice, error)
+		SetInvoiceStatus(ctx context.Context, merchantTransID string, invoiceStatus int, note string) error
+
+		// admin panel (payment-read)
+		GetList(ctx context.Context, req structs.GetListClickInvoiceRequest) (structs.GetListClickInvoiceResponse, error)
+		GetByID(ctx context.Context, id int64) (structs.ClickInvoiceRecord, error)
 	}
 
 	repo struct {
@@ -175,6 +195,7 @@ func (r repo) UpsertPrepare(ctx context.Context, merchantTransID string, clickTr
       click_trans_id   = $1,
       click_paydoc_id  = $2,
       amount           = COALESCE(NULLIF($3, '')::numeric, amount),
+      prepared_at      = now(),
       updated_at       = now()
     WHERE merchant_trans_id = $4
     RETURNING merchant_prepare_id
@@ -206,6 +227,9 @@ func (r repo) UpdateOnComplete(ctx context.Context, merchantTransID string, merc
     SET
       click_trans_id = $1,
       status        = $2,
+      completed_at  = now(),
+      complete_error = NULL,
+      complete_error_note = '',
       updated_at    = now()
     WHERE merchant_trans_id = $3
       AND merchant_prepare_id = $4
@@ -415,3 +439,323 @@ func (r *repo) GetInvoiceByTransID(ctx context.Context, transID string) (structs
 	}
 	return inv, nil
 }
+
+// CancelByOrderID zakazga tegishli, hali to'lanmagan invoicelarni CANCELLED qiladi.
+// Shundan keyin Click prepare/complete -9 (cancelled) qaytaradi.
+func (r repo) CancelByOrderID(ctx context.Context, orderID string) (int64, error) {
+	query := `
+		UPDATE invoices
+		SET status       = 'CANCELLED',
+		    cancelled_at = now(),
+		    updated_at   = now()
+		WHERE order_id = $1
+		  AND status NOT IN ('PAID', 'CANCELLED')
+	`
+	res, err := r.db.Exec(ctx, query, orderID)
+	if err != nil {
+		r.logger.Error(ctx, "click CancelByOrderID failed", zap.Error(err))
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func (r repo) SetFiscalResult(ctx context.Context, merchantTransID string, items []byte, status, errNote string) error {
+	query := `
+		UPDATE invoices
+		SET fiscal_items = $2::jsonb,
+		    fiscal_status = $3,
+		    fiscal_error = NULLIF($4, ''),
+		    updated_at = now()
+		WHERE merchant_trans_id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, merchantTransID, string(items), status, errNote); err != nil {
+		r.logger.Error(ctx, "click SetFiscalResult failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) SetStatus(ctx context.Context, merchantTransID string, status string) error {
+	query := `
+		UPDATE invoices
+		SET status       = $2::varchar,
+		    completed_at = CASE WHEN $2::varchar = 'PAID' THEN COALESCE(completed_at, now()) ELSE completed_at END,
+		    updated_at   = now()
+		WHERE merchant_trans_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, merchantTransID, status); err != nil {
+		r.logger.Error(ctx, "click SetStatus failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) MarkInvoiceSent(ctx context.Context, merchantTransID string) error {
+	query := `
+		UPDATE invoices
+		SET invoice_status      = $2,
+		    invoice_status_note = '',
+		    invoice_sent_at     = now(),
+		    updated_at          = now()
+		WHERE merchant_trans_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, merchantTransID, structs.ClickInvoiceWaiting); err != nil {
+		r.logger.Error(ctx, "click MarkInvoiceSent failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const phoneInvoiceColumns = `
+	COALESCE(i.order_id::text, ''),
+	COALESCE(o.order_number, 0),
+	i.click_invoice_id,
+	COALESCE(i.customer_phone, ''),
+	i.amount::text,
+	i.status,
+	COALESCE(i.invoice_status, 0),
+	i.invoice_status_note,
+	i.invoice_sent_at
+`
+
+func scanPhoneInvoice(row pgx.Row) (structs.ClickPhoneInvoice, error) {
+	var inv structs.ClickPhoneInvoice
+	err := row.Scan(
+		&inv.OrderID,
+		&inv.OrderNumber,
+		&inv.InvoiceID,
+		&inv.Phone,
+		&inv.Amount,
+		&inv.Status,
+		&inv.InvoiceStatus,
+		&inv.InvoiceStatusNote,
+		&inv.SentAt,
+	)
+	return inv, err
+}
+
+func (r repo) GetPhoneInvoice(ctx context.Context, merchantTransID string) (structs.ClickPhoneInvoice, error) {
+	query := `SELECT ` + phoneInvoiceColumns + `
+		FROM invoices i
+		LEFT JOIN orders o ON o.id = i.order_id
+		WHERE i.merchant_trans_id = $1
+		  AND i.invoice_sent_at IS NOT NULL
+	`
+	inv, err := scanPhoneInvoice(r.db.QueryRow(ctx, query, merchantTransID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return inv, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "click GetPhoneInvoice failed", zap.Error(err))
+		return inv, err
+	}
+	return inv, nil
+}
+
+func (r repo) GetWatchedInvoices(ctx context.Context, since time.Time) ([]structs.ClickPhoneInvoice, error) {
+	query := `SELECT ` + phoneInvoiceColumns + `
+		FROM invoices i
+		LEFT JOIN orders o ON o.id = i.order_id
+		WHERE i.invoice_sent_at >= $1
+		  AND i.status <> 'CANCELLED'
+		  AND COALESCE(i.invoice_status, 0) >= 0
+		  AND COALESCE(i.invoice_status, 0) <> $2
+		ORDER BY i.invoice_sent_at
+	`
+	rows, err := r.db.Query(ctx, query, since, structs.ClickInvoicePaid)
+	if err != nil {
+		r.logger.Error(ctx, "click GetWatchedInvoices failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []structs.ClickPhoneInvoice
+	for rows.Next() {
+		inv, err := scanPhoneInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+func (r repo) SetInvoiceStatus(ctx context.Context, merchantTransID string, invoiceStatus int, note string) error {
+	query := `
+		UPDATE invoices
+		SET invoice_status      = $2,
+		    invoice_status_note = $3,
+		    updated_at          = now()
+		WHERE merchant_trans_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, merchantTransID, invoiceStatus, note); err != nil {
+		r.logger.Error(ctx, "click SetInvoiceStatus failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) SetCompleteError(ctx context.Context, merchantTransID string, code int, note string) error {
+	query := `
+		UPDATE invoices
+		SET complete_error      = $2,
+		    complete_error_note = $3,
+		    updated_at          = now()
+		WHERE merchant_trans_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, merchantTransID, code, note); err != nil {
+		r.logger.Error(ctx, "click SetCompleteError failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+const invoiceRecordColumns = `
+	i.id,
+	i.click_invoice_id,
+	COALESCE(i.click_trans_id, 0),
+	COALESCE(i.click_paydoc_id, 0),
+	COALESCE(i.merchant_prepare_id, 0),
+	i.merchant_trans_id,
+	COALESCE(i.order_id::text, ''),
+	COALESCE(o.order_number, 0),
+	COALESCE(o.order_status::text, ''),
+	COALESCE(o.payment_status::text, ''),
+	COALESCE(NULLIF(i.customer_phone, ''), c.phone, ''),
+	i.amount::text,
+	i.status,
+	COALESCE(i.comment, ''),
+	COALESCE(i.invoice_status, 0),
+	i.invoice_status_note,
+	COALESCE(i.complete_error, 0),
+	i.complete_error_note,
+	COALESCE(i.fiscal_status, ''),
+	COALESCE(i.fiscal_error, ''),
+	i.invoice_sent_at,
+	i.prepared_at,
+	i.completed_at,
+	i.cancelled_at,
+	i.created_at,
+	i.updated_at
+`
+
+const invoiceRecordFrom = `
+	FROM invoices i
+	LEFT JOIN orders o ON o.id = i.order_id
+	LEFT JOIN clients c ON c.tgid = o.tg_id
+`
+
+func scanInvoiceRecord(row pgx.Row, extra ...any) (structs.ClickInvoiceRecord, error) {
+	var inv structs.ClickInvoiceRecord
+	dest := append(extra,
+		&inv.ID,
+		&inv.ClickInvoiceID,
+		&inv.ClickTransID,
+		&inv.ClickPaydocID,
+		&inv.MerchantPrepareID,
+		&inv.MerchantTransID,
+		&inv.OrderID,
+		&inv.OrderNumber,
+		&inv.OrderStatus,
+		&inv.OrderPaymentStatus,
+		&inv.Phone,
+		&inv.Amount,
+		&inv.Status,
+		&inv.Comment,
+		&inv.InvoiceStatus,
+		&inv.InvoiceStatusNote,
+		&inv.CompleteError,
+		&inv.CompleteErrorNote,
+		&inv.FiscalStatus,
+		&inv.FiscalError,
+		&inv.InvoiceSentAt,
+		&inv.PreparedAt,
+		&inv.CompletedAt,
+		&inv.CancelledAt,
+		&inv.CreatedAt,
+		&inv.UpdatedAt,
+	)
+	err := row.Scan(dest...)
+	return inv, err
+}
+
+func (r repo) GetList(ctx context.Context, req structs.GetListClickInvoiceRequest) (structs.GetListClickInvoiceResponse, error) {
+	resp := structs.GetListClickInvoiceResponse{Invoices: []structs.ClickInvoiceRecord{}}
+
+	where := " WHERE TRUE"
+	args := []interface{}{}
+	argIndex := 1
+
+	if strings.TrimSpace(req.Status) != "" {
+		where += fmt.Sprintf(" AND i.status ILIKE $%d", argIndex)
+		args = append(args, strings.TrimSpace(req.Status))
+		argIndex++
+	}
+	if req.OrderNumber > 0 {
+		where += fmt.Sprintf(" AND i.merchant_trans_id = $%d", argIndex)
+		args = append(args, fmt.Sprintf("%d", req.OrderNumber))
+		argIndex++
+	}
+	if strings.TrimSpace(req.Phone) != "" {
+		where += fmt.Sprintf(" AND (i.customer_phone ILIKE $%d OR c.phone ILIKE $%d)", argIndex, argIndex)
+		args = append(args, "%"+strings.TrimSpace(req.Phone)+"%")
+		argIndex++
+	}
+	if req.From != nil {
+		where += fmt.Sprintf(" AND i.created_at >= $%d", argIndex)
+		args = append(args, *req.From)
+		argIndex++
+	}
+	if req.To != nil {
+		where += fmt.Sprintf(" AND i.created_at <= $%d", argIndex)
+		args = append(args, *req.To)
+		argIndex++
+	}
+
+	limit := " LIMIT 20"
+	offset := " OFFSET 0"
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	query := `SELECT COUNT(*) OVER(), ` + invoiceRecordColumns + invoiceRecordFrom + where +
+		" ORDER BY i.created_at DESC, i.id DESC" + limit + offset
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get click invoices failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		inv, err := scanInvoiceRecord(rows, &resp.Count)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan click invoice failed: %w", err)
+		}
+		resp.Invoices = append(resp.Invoices, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("click invoices rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetByID(ctx context.Context, id int64) (structs.ClickInvoiceRecord, error) {
+	query := `SELECT ` + invoiceRecordColumns + invoiceRecordFrom + ` WHERE i.id = $1`
+
+	inv, err := scanInvoiceRecord(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return inv, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return inv, fmt.Errorf("get click invoice failed: %w", err)
+	}
+	return inv, nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"sushitana/internal/structs"
 	"sushitana/pkg/db"
 	"sushitana/pkg/logger"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
@@ -35,6 +38,30 @@ type (
 		MarkPerformed(ctx context.Context, paycomTransID string, performTime int64) (structs.PaymeTransaction, error)
 		MarkCanceled(ctx context.Context, paycomTransID string, cancelTime int64, reason int, newState int) (structs.PaymeTransaction, error)
 		GetStatement(ctx context.Context, from, to int64) ([]structs.PaymeTransaction, error)
+		CancelActiveByOrderID(ctx context.Context, orderID string, cancelTime int64, reason int) (int64, error)
+		// GetLastByOrderID - zakazning oxirgi tranzaksiyasi (holatni tekshirish uchun); to'langani
+		// bo'lsa o'sha (qisman qaytarishda bekor qilingan almashtiruvchi chek holatni buzmasin)
+		GetLastByOrderID(ctx context.Context, orderID string) (structs.PaymeTransaction, error)
+		// SetReceiptDetail - Payme'ga yuborilgan fiskal detail (JSON)
+		SetReceiptDetail(ctx context.Context, paycomTransID string, detail []byte) error
+		// SetFiscalData - SetFiscalData natijasi, type (PERFORM/CANCEL) kaliti ostida
+		SetFiscalData(ctx context.Context, paycomTransID string, fiscalType string, data []byte) (int64, error)
+
+		// SetCardID - chek saqlangan karta bilan to'landi (qisman qaytarishda qayta yechish uchun)
+		SetCardID(ctx context.Context, paycomTransID string, cardID int64) error
+		// GetPerformedByOrderID - zakazning oxirgi to'langan (state=2) tranzaksiyasi, card_id bilan
+		GetPerformedByOrderID(ctx context.Context, orderID string) (structs.PaymeTransaction, error)
+		// SetRebill - eski chek o'rniga kutilayotgan yangi chek summasi (so'm), 0 - tozalash
+		SetRebill(ctx context.Context, paycomTransID string, amount int64) error
+		// GetRebillAmount - zakaz bo'yicha kutilayotgan yangi chek summasi (so'm), yo'q bo'lsa 0
+		GetRebillAmount(ctx context.Context, orderID string) (int64, error)
+		ClearRebill(ctx context.Context, orderID string) error
+		// CountOtherPerformed - zakazning boshqa to'langan tranzaksiyalari (chek almashtirilayotganini bilish uchun)
+		CountOtherPerformed(ctx context.Context, orderID, paycomTransID string) (int64, error)
+
+		// admin panel (payment-read)
+		GetList(ctx context.Context, req structs.GetListPaymeTransactionRequest) (structs.GetListPaymeTransactionResponse, error)
+		GetByID(ctx context.Context, id string) (structs.PaymeTransactionRecord, error)
 	}
 	repo struct {
 		logger logger.Logger
@@ -267,3 +294,346 @@ func (r repo) GetStatement(ctx context.Context, from, to int64) ([]structs.Payme
 	}
 	return out, nil
 }
+
+// CancelActiveByOrderID zakaz bo'yicha hali perform qilinmagan (state=1) tranzaksiyani
+// -1 ga o'tkazadi, shunda PerformTransaction -31008 qaytaradi.
+func (r repo) CancelActiveByOrderID(ctx context.Context, orderID string, cancelTime int64, reason int) (int64, error) {
+	query := `
+		UPDATE payme_transactions
+		SET state = $2,
+		    cancel_time = $3,
+		    reason = $4,
+		    updated_at = now()
+		WHERE order_id = $1
+		  AND state = $5
+	`
+	res, err := r.db.Exec(ctx, query, orderID, StateCanceledCreated, cancelTime, reason, StateCreated)
+	if err != nil {
+		r.logger.Error(ctx, "payme CancelActiveByOrderID failed", zap.Error(err))
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func (r repo) GetLastByOrderID(ctx context.Context, orderID string) (structs.PaymeTransaction, error) {
+	query := `
+		SELECT
+			id,
+			paycom_transaction_id,
+			order_id,
+			amount::text,
+			state,
+			created_time,
+			perform_time,
+			cancel_time,
+			reason,
+			created_at,
+			updated_at
+		FROM payme_transactions
+		WHERE order_id = $1
+		ORDER BY (state = 2) DESC, created_at DESC
+		LIMIT 1
+	`
+
+	var tx structs.PaymeTransaction
+	err := r.db.QueryRow(ctx, query, orderID).Scan(
+		&tx.ID,
+		&tx.PaycomTransactionID,
+		&tx.OrderID,
+		&tx.Amount,
+		&tx.State,
+		&tx.CreatedTime,
+		&tx.PerformTime,
+		&tx.CancelTime,
+		&tx.Reason,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return structs.PaymeTransaction{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "payme GetLastByOrderID failed", zap.Error(err))
+		return structs.PaymeTransaction{}, err
+	}
+	return tx, nil
+}
+
+func (r repo) SetReceiptDetail(ctx context.Context, paycomTransID string, detail []byte) error {
+	query := `
+		UPDATE payme_transactions
+		SET receipt_detail = $2::jsonb,
+		    updated_at = now()
+		WHERE paycom_transaction_id = $1
+	`
+
+	if _, err := r.db.Exec(ctx, query, paycomTransID, string(detail)); err != nil {
+		r.logger.Error(ctx, "payme SetReceiptDetail failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) SetFiscalData(ctx context.Context, paycomTransID string, fiscalType string, data []byte) (int64, error) {
+	query := `
+		UPDATE payme_transactions
+		SET fiscal_data = COALESCE(fiscal_data, '{}'::jsonb) || jsonb_build_object($2::text, $3::jsonb),
+		    updated_at = now()
+		WHERE paycom_transaction_id = $1
+	`
+
+	res, err := r.db.Exec(ctx, query, paycomTransID, fiscalType, string(data))
+	if err != nil {
+		r.logger.Error(ctx, "payme SetFiscalData failed", zap.Error(err))
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func (r repo) SetCardID(ctx context.Context, paycomTransID string, cardID int64) error {
+	query := `
+		UPDATE payme_transactions
+		SET card_id = $2,
+		    updated_at = now()
+		WHERE paycom_transaction_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, paycomTransID, cardID); err != nil {
+		r.logger.Error(ctx, "payme SetCardID failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) GetPerformedByOrderID(ctx context.Context, orderID string) (structs.PaymeTransaction, error) {
+	query := `
+		SELECT
+			id,
+			paycom_transaction_id,
+			order_id,
+			amount::text,
+			state,
+			created_time,
+			perform_time,
+			cancel_time,
+			reason,
+			card_id,
+			created_at,
+			updated_at
+		FROM payme_transactions
+		WHERE order_id = $1
+		  AND state = $2
+		ORDER BY perform_time DESC NULLS LAST, created_at DESC
+		LIMIT 1
+	`
+
+	var tx structs.PaymeTransaction
+	err := r.db.QueryRow(ctx, query, orderID, StatePerformed).Scan(
+		&tx.ID,
+		&tx.PaycomTransactionID,
+		&tx.OrderID,
+		&tx.Amount,
+		&tx.State,
+		&tx.CreatedTime,
+		&tx.PerformTime,
+		&tx.CancelTime,
+		&tx.Reason,
+		&tx.CardID,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return structs.PaymeTransaction{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "payme GetPerformedByOrderID failed", zap.Error(err))
+		return structs.PaymeTransaction{}, err
+	}
+	return tx, nil
+}
+
+func (r repo) SetRebill(ctx context.Context, paycomTransID string, amount int64) error {
+	query := `
+		UPDATE payme_transactions
+		SET rebill_amount = $2,
+		    updated_at = now()
+		WHERE paycom_transaction_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, paycomTransID, amount); err != nil {
+		r.logger.Error(ctx, "payme SetRebill failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) GetRebillAmount(ctx context.Context, orderID string) (int64, error) {
+	var amount int64
+	query := `
+		SELECT COALESCE(MAX(rebill_amount), 0)
+		FROM payme_transactions
+		WHERE order_id = $1
+		  AND state = $2
+	`
+	if err := r.db.QueryRow(ctx, query, orderID, StatePerformed).Scan(&amount); err != nil {
+		r.logger.Error(ctx, "payme GetRebillAmount failed", zap.Error(err))
+		return 0, err
+	}
+	return amount, nil
+}
+
+func (r repo) ClearRebill(ctx context.Context, orderID string) error {
+	query := `
+		UPDATE payme_transactions
+		SET rebill_amount = 0,
+		    updated_at = now()
+		WHERE order_id = $1
+		  AND rebill_amount <> 0
+	`
+	if _, err := r.db.Exec(ctx, query, orderID); err != nil {
+		r.logger.Error(ctx, "payme ClearRebill failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r repo) CountOtherPerformed(ctx context.Context, orderID, paycomTransID string) (int64, error) {
+	var n int64
+	query := `
+		SELECT COUNT(*)
+		FROM payme_transactions
+		WHERE order_id = $1
+		  AND paycom_transaction_id <> $2
+		  AND state = $3
+	`
+	if err := r.db.QueryRow(ctx, query, orderID, paycomTransID, StatePerformed).Scan(&n); err != nil {
+		r.logger.Error(ctx, "payme CountOtherPerformed failed", zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
+
+const transactionRecordColumns = `
+	t.id,
+	t.paycom_transaction_id,
+	t.order_id,
+	COALESCE(o.order_number, 0),
+	COALESCE(o.order_status::text, ''),
+	COALESCE(o.payment_status::text, ''),
+	COALESCE(c.phone, ''),
+	t.amount::text,
+	t.state,
+	t.created_time,
+	COALESCE(t.perform_time, 0),
+	COALESCE(t.cancel_time, 0),
+	t.reason,
+	t.created_at,
+	t.updated_at
+`
+
+const transactionRecordFrom = `
+	FROM payme_transactions t
+	LEFT JOIN orders o ON o.id = t.order_id
+	LEFT JOIN clients c ON c.tgid = o.tg_id
+`
+
+func scanTransactionRecord(row pgx.Row, extra ...any) (structs.PaymeTransactionRecord, error) {
+	var tx structs.PaymeTransactionRecord
+	dest := append(extra,
+		&tx.ID,
+		&tx.PaycomTransactionID,
+		&tx.OrderID,
+		&tx.OrderNumber,
+		&tx.OrderStatus,
+		&tx.OrderPaymentStatus,
+		&tx.Phone,
+		&tx.Amount,
+		&tx.State,
+		&tx.CreateTime,
+		&tx.PerformTime,
+		&tx.CancelTime,
+		&tx.Reason,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+	)
+	err := row.Scan(dest...)
+	return tx, err
+}
+
+func (r repo) GetList(ctx context.Context, req structs.GetListPaymeTransactionRequest) (structs.GetListPaymeTransactionResponse, error) {
+	resp := structs.GetListPaymeTransactionResponse{Transactions: []structs.PaymeTransactionRecord{}}
+
+	where := " WHERE TRUE"
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.State != nil {
+		where += fmt.Sprintf(" AND t.state = $%d", argIndex)
+		args = append(args, *req.State)
+		argIndex++
+	}
+	if req.OrderNumber > 0 {
+		where += fmt.Sprintf(" AND o.order_number = $%d", argIndex)
+		args = append(args, req.OrderNumber)
+		argIndex++
+	}
+	if strings.TrimSpace(req.Phone) != "" {
+		where += fmt.Sprintf(" AND c.phone ILIKE $%d", argIndex)
+		args = append(args, "%"+strings.TrimSpace(req.Phone)+"%")
+		argIndex++
+	}
+	if req.From != nil {
+		where += fmt.Sprintf(" AND t.created_at >= $%d", argIndex)
+		args = append(args, *req.From)
+		argIndex++
+	}
+	if req.To != nil {
+		where += fmt.Sprintf(" AND t.created_at <= $%d", argIndex)
+		args = append(args, *req.To)
+		argIndex++
+	}
+
+	limit := " LIMIT 20"
+	offset := " OFFSET 0"
+	if req.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", req.Limit)
+	}
+	if req.Offset > 0 {
+		offset = fmt.Sprintf(" OFFSET %d", req.Offset)
+	}
+
+	query := `SELECT COUNT(*) OVER(), ` + transactionRecordColumns + transactionRecordFrom + where +
+		" ORDER BY t.created_at DESC" + limit + offset
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get payme transactions failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tx, err := scanTransactionRecord(rows, &resp.Count)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan payme transaction failed: %w", err)
+		}
+		resp.Transactions = append(resp.Transactions, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("payme transactions rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetByID(ctx context.Context, id string) (structs.PaymeTransactionRecord, error) {
+	query := `SELECT ` + transactionRecordColumns + transactionRecordFrom + ` WHERE t.id = $1`
+
+	tx, err := scanTransactionRecord(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return tx, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return tx, fmt.Errorf("get payme transaction failed: %w", err)
+	}
+	return tx, nil
+}
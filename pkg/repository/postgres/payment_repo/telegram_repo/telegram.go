@@ -0,0 +1,140 @@
+package telegramrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// CreatePaid - successful_payment'ni yozadi va zakazni PAID qiladi (bitta tranzaksiyada).
+		// Telegram update qayta kelsa (charge id bir xil) created=false.
+		CreatePaid(ctx context.Context, req structs.TelegramPayment) (created bool, err error)
+		GetByOrderID(ctx context.Context, orderID string) (structs.TelegramPayment, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+// CreatePaid - zakaz yangilanmasa charge yozuvi ham qolmaydi, qayta kelgan update yana ishlanadi
+func (r repo) CreatePaid(ctx context.Context, req structs.TelegramPayment) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		INSERT INTO telegram_payments (
+			id,
+			order_id,
+			tg_id,
+			currency,
+			total_amount,
+			telegram_payment_charge_id,
+			provider_payment_charge_id,
+			created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+		ON CONFLICT (telegram_payment_charge_id) DO NOTHING
+	`
+
+	res, err := tx.Exec(ctx, query,
+		uuid.NewString(),
+		req.OrderID,
+		req.TgID,
+		req.Currency,
+		req.TotalAmount,
+		req.TelegramPaymentChargeID,
+		req.ProviderPaymentChargeID,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "telegram payment Create failed", zap.Error(err))
+		return false, err
+	}
+	if res.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	res, err = tx.Exec(ctx, `
+		UPDATE orders
+		SET payment_status = 'PAID',
+		    updated_at     = now()
+		WHERE id = $1
+	`, req.OrderID)
+	if err != nil {
+		r.logger.Error(ctx, "telegram payment order PAID update failed", zap.Error(err))
+		return false, err
+	}
+	if res.RowsAffected() == 0 {
+		return false, structs.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r repo) GetByOrderID(ctx context.Context, orderID string) (structs.TelegramPayment, error) {
+	query := `
+		SELECT
+			id,
+			order_id,
+			tg_id,
+			currency,
+			total_amount,
+			telegram_payment_charge_id,
+			provider_payment_charge_id,
+			created_at
+		FROM telegram_payments
+		WHERE order_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var p structs.TelegramPayment
+	err := r.db.QueryRow(ctx, query, orderID).Scan(
+		&p.ID,
+		&p.OrderID,
+		&p.TgID,
+		&p.Currency,
+		&p.TotalAmount,
+		&p.TelegramPaymentChargeID,
+		&p.ProviderPaymentChargeID,
+		&p.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return structs.TelegramPayment{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "telegram payment GetByOrderID failed", zap.Error(err))
+		return structs.TelegramPayment{}, err
+	}
+	return p, nil
+}
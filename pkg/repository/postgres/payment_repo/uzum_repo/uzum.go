@@ -0,0 +1,215 @@
+package uzumrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		Create(ctx context.Context, tx structs.UzumTransaction) (structs.UzumTransaction, error)
+		GetByTransID(ctx context.Context, transID string) (structs.UzumTransaction, error)
+		GetLastByOrderID(ctx context.Context, orderID string) (structs.UzumTransaction, error)
+		MarkConfirmed(ctx context.Context, transID string, confirmTime int64, paymentSource, phone string) (structs.UzumTransaction, error)
+		MarkReversed(ctx context.Context, transID string, reverseTime int64) (structs.UzumTransaction, error)
+		// ReverseCreatedByOrderID - zakazning hali tasdiqlanmagan (CREATED) tranzaksiyalari REVERSED'ga,
+		// shunda keyingi confirm UzumErrPaymentCancelled oladi
+		ReverseCreatedByOrderID(ctx context.Context, orderID string, reverseTime int64) (int64, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+const returning = `
+	id, trans_id, order_id, service_id,
+	amount, status, trans_time,
+	confirm_time, reverse_time,
+	payment_source, phone,
+	created_at, updated_at
+`
+
+func scan(row pgx.Row) (structs.UzumTransaction, error) {
+	var tx structs.UzumTransaction
+	err := row.Scan(
+		&tx.ID,
+		&tx.TransID,
+		&tx.OrderID,
+		&tx.ServiceID,
+		&tx.Amount,
+		&tx.Status,
+		&tx.TransTime,
+		&tx.ConfirmTime,
+		&tx.ReverseTime,
+		&tx.PaymentSource,
+		&tx.Phone,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+			return structs.UzumTransaction{}, structs.ErrNotFound
+		}
+		return structs.UzumTransaction{}, err
+	}
+	return tx, nil
+}
+
+// Create - trans_id bo'yicha idempotent (qayta kelsa mavjudini qaytaradi)
+func (r repo) Create(ctx context.Context, req structs.UzumTransaction) (structs.UzumTransaction, error) {
+	query := `
+		INSERT INTO uzum_transactions (
+			id,
+			trans_id,
+			order_id,
+			service_id,
+			amount,
+			status,
+			trans_time,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, now(), now()
+		)
+		ON CONFLICT (trans_id)
+		DO UPDATE SET updated_at = now()
+		RETURNING` + returning
+
+	tx, err := scan(r.db.QueryRow(ctx, query,
+		uuid.NewString(),
+		req.TransID,
+		req.OrderID,
+		req.ServiceID,
+		req.Amount,
+		structs.UzumStatusCreated,
+		req.TransTime,
+	))
+	if err != nil {
+		r.logger.Error(ctx, "uzum Create failed", zap.Error(err))
+		return structs.UzumTransaction{}, err
+	}
+	return tx, nil
+}
+
+func (r repo) GetByTransID(ctx context.Context, transID string) (structs.UzumTransaction, error) {
+	query := `SELECT` + returning + `
+		FROM uzum_transactions
+		WHERE trans_id = $1
+		LIMIT 1
+	`
+
+	tx, err := scan(r.db.QueryRow(ctx, query, transID))
+	if err != nil && !errors.Is(err, structs.ErrNotFound) {
+		r.logger.Error(ctx, "uzum GetByTransID failed", zap.Error(err))
+	}
+	return tx, err
+}
+
+func (r repo) GetLastByOrderID(ctx context.Context, orderID string) (structs.UzumTransaction, error) {
+	query := `SELECT` + returning + `
+		FROM uzum_transactions
+		WHERE order_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	tx, err := scan(r.db.QueryRow(ctx, query, orderID))
+	if err != nil && !errors.Is(err, structs.ErrNotFound) {
+		r.logger.Error(ctx, "uzum GetLastByOrderID failed", zap.Error(err))
+	}
+	return tx, err
+}
+
+// MarkConfirmed - faqat CREATED holatdan
+func (r repo) MarkConfirmed(ctx context.Context, transID string, confirmTime int64, paymentSource, phone string) (structs.UzumTransaction, error) {
+	query := `
+		UPDATE uzum_transactions
+		SET status = $2,
+		    confirm_time = $3,
+		    payment_source = NULLIF($4, ''),
+		    phone = NULLIF($5, ''),
+		    updated_at = now()
+		WHERE trans_id = $1
+		  AND status = $6
+		RETURNING` + returning
+
+	tx, err := scan(r.db.QueryRow(ctx, query,
+		transID,
+		structs.UzumStatusConfirmed,
+		confirmTime,
+		paymentSource,
+		phone,
+		structs.UzumStatusCreated,
+	))
+	if err != nil && !errors.Is(err, structs.ErrNotFound) {
+		r.logger.Error(ctx, "uzum MarkConfirmed failed", zap.Error(err))
+	}
+	return tx, err
+}
+
+// MarkReversed - CREATED yoki CONFIRMED holatdan
+func (r repo) MarkReversed(ctx context.Context, transID string, reverseTime int64) (structs.UzumTransaction, error) {
+	query := `
+		UPDATE uzum_transactions
+		SET status = $2,
+		    reverse_time = $3,
+		    updated_at = now()
+		WHERE trans_id = $1
+		  AND status IN ($4, $5)
+		RETURNING` + returning
+
+	tx, err := scan(r.db.QueryRow(ctx, query,
+		transID,
+		structs.UzumStatusReversed,
+		reverseTime,
+		structs.UzumStatusCreated,
+		structs.UzumStatusConfirmed,
+	))
+	if err != nil && !errors.Is(err, structs.ErrNotFound) {
+		r.logger.Error(ctx, "uzum MarkReversed failed", zap.Error(err))
+	}
+	return tx, err
+}
+
+func (r repo) ReverseCreatedByOrderID(ctx context.Context, orderID string, reverseTime int64) (int64, error) {
+	query := `
+		UPDATE uzum_transactions
+		SET status = $2,
+		    reverse_time = $3,
+		    updated_at = now()
+		WHERE order_id = $1
+		  AND status = $4
+	`
+	res, err := r.db.Exec(ctx, query, orderID, structs.UzumStatusReversed, reverseTime, structs.UzumStatusCreated)
+	if err != nil {
+		r.logger.Error(ctx, "uzum ReverseCreatedByOrderID failed", zap.Error(err))
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
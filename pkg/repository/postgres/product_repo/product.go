@@ -35,6 +35,8 @@ type (
 		Patch(ctx context.Context, req structs.PatchProduct) (int64, error)
 		GetListCategoryName(ctx context.Context, req string) ([]structs.Product, error)
 		GetBox(ctx context.Context) (resp structs.GetListProductResponse, err error)
+		// GetFiscal - fiskal chek uchun IKPU/package/QQS (product va box id'lar bo'yicha)
+		GetFiscal(ctx context.Context, ids []string) (map[string]structs.ProductFiscal, error)
 	}
 
 	repo struct {
@@ -141,6 +143,7 @@ func (r *repo) Create(ctx context.Context, req []structs.CreateProduct) error {
 		FROM data
 		ON CONFLICT (id) DO UPDATE
 		SET
+			-- ikpu/package_code/vat_percent admin'niki, iiko sync ularga tegmaydi
 			group_id = EXCLUDED.group_id,
 			name = product.name || (
 				CASE
@@ -267,7 +270,10 @@ func (r *repo) GetByID(ctx context.Context, id string) (structs.Product, error)
 				description,
 				created_at,
 				updated_at,
-				weight
+				weight,
+				ikpu,
+				package_code,
+				vat_percent
 			FROM product
 			WHERE id = $1
 			AND EXISTS (
@@ -303,6 +309,9 @@ func (r *repo) GetByID(ctx context.Context, id string) (structs.Product, error)
 		&resp.CreatedAt,
 		&resp.UpdatedAt,
 		&resp.Weight,
+		&resp.Ikpu,
+		&resp.PackageCode,
+		&resp.VatPercent,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -466,7 +475,10 @@ func (r *repo) GetList(ctx context.Context, req structs.GetListProductRequest) (
 			description,
 			created_at,
 			updated_at,
-			weight
+			weight,
+			ikpu,
+			package_code,
+			vat_percent
 		FROM product
 		%s
 		AND EXISTS (
@@ -515,6 +527,9 @@ func (r *repo) GetList(ctx context.Context, req structs.GetListProductRequest) (
 			&p.CreatedAt,
 			&p.UpdatedAt,
 			&p.Weight,
+			&p.Ikpu,
+			&p.PackageCode,
+			&p.VatPercent,
 		)
 		if err != nil {
 			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
@@ -566,6 +581,18 @@ func (r *repo) Patch(ctx context.Context, req structs.PatchProduct) (int64, erro
 		setValues = append(setValues, "description = :description")
 		params["description"] = *req.Description
 	}
+	if req.Ikpu != nil {
+		setValues = append(setValues, "ikpu = :ikpu")
+		params["ikpu"] = strings.TrimSpace(*req.Ikpu)
+	}
+	if req.PackageCode != nil {
+		setValues = append(setValues, "package_code = :package_code")
+		params["package_code"] = strings.TrimSpace(*req.PackageCode)
+	}
+	if req.VatPercent != nil {
+		setValues = append(setValues, "vat_percent = :vat_percent")
+		params["vat_percent"] = *req.VatPercent
+	}
 	setValues = append(setValues, "updated_At = NOW()")
 	if len(setValues) == 0 {
 		return 0, fmt.Errorf("no fields to update for product with ID %s", req.ID)
@@ -830,3 +857,41 @@ func (r *repo) GetBox(ctx context.Context) (resp structs.GetListProductResponse,
 
 	return resp, nil
 }
+
+func (r *repo) GetFiscal(ctx context.Context, ids []string) (map[string]structs.ProductFiscal, error) {
+	resp := make(map[string]structs.ProductFiscal, len(ids))
+	if len(ids) == 0 {
+		return resp, nil
+	}
+
+	query := `
+		SELECT
+			id,
+			ikpu,
+			package_code,
+			vat_percent
+		FROM product
+		WHERE id = ANY($1)
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f structs.ProductFiscal
+		if err := rows.Scan(&f.ID, &f.Ikpu, &f.PackageCode, &f.VatPercent); err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return nil, fmt.Errorf("row scan failed: %w", err)
+		}
+		resp[f.ID] = f
+	}
+	if rows.Err() != nil {
+		r.logger.Error(ctx, "err on rows iteration", zap.Error(rows.Err()))
+		return nil, fmt.Errorf("rows iteration failed: %w", rows.Err())
+	}
+	return resp, nil
+}
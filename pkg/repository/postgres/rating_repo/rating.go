@@ -0,0 +1,386 @@
+package ratingrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// Schedule - zakaz yakunlanganda so'rovni rejalashtiradi (qayta chaqirilsa faqat kuryer yangilanadi)
+		Schedule(ctx context.Context, req structs.ScheduleRating, askAt time.Time) error
+		// ClaimDue - vaqti kelgan so'rovlarni ASKED qilib oladi (bir nechta instance bo'lsa ham bir marta)
+		ClaimDue(ctx context.Context, limit int) ([]structs.OrderRating, error)
+		// Unclaim - bot yubora olmasa keyinroq qayta urinish
+		Unclaim(ctx context.Context, orderID string, askAt time.Time) error
+		Get(ctx context.Context, orderID string) (structs.OrderRating, error)
+
+		SetFood(ctx context.Context, orderID string, tgID int64, score int) (structs.OrderRating, error)
+		SetDelivery(ctx context.Context, orderID string, tgID int64, score int) (structs.OrderRating, error)
+		SetComment(ctx context.Context, orderID string, tgID int64, comment string) error
+		// MarkAlerted - past baho haqida operator guruhiga faqat bir marta
+		MarkAlerted(ctx context.Context, orderID string) (bool, error)
+
+		GetList(ctx context.Context, req structs.GetListRatingRequest) (structs.GetListRatingResponse, error)
+		Summary(ctx context.Context, from, to *time.Time) (structs.RatingSummary, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+const ratingColumns = `
+	r.order_id::text,
+	COALESCE(o.order_number, 0),
+	r.tg_id,
+	r.delivery_type,
+	r.courier_id,
+	r.courier_name,
+	r.status,
+	r.food,
+	r.delivery,
+	r.comment,
+	r.ask_at,
+	r.asked_at,
+	r.rated_at,
+	r.created_at,
+	COALESCE(c.language, 'uz')
+`
+
+func scanRating(row pgx.Row, extra ...any) (structs.OrderRating, error) {
+	var resp structs.OrderRating
+	dest := append(extra,
+		&resp.OrderID,
+		&resp.OrderNumber,
+		&resp.TgID,
+		&resp.DeliveryType,
+		&resp.CourierID,
+		&resp.CourierName,
+		&resp.Status,
+		&resp.Food,
+		&resp.Delivery,
+		&resp.Comment,
+		&resp.AskAt,
+		&resp.AskedAt,
+		&resp.RatedAt,
+		&resp.CreatedAt,
+		&resp.Language,
+	)
+	err := row.Scan(dest...)
+	return resp, err
+}
+
+func (r repo) Schedule(ctx context.Context, req structs.ScheduleRating, askAt time.Time) error {
+	// tg_id = 0 (saytdan) zakazlarga bot yozolmaydi
+	query := `
+		INSERT INTO order_ratings (order_id, tg_id, delivery_type, courier_id, courier_name, ask_at)
+		SELECT o.id, o.tg_id, o.delivery_type::text, $2, $3, $4
+		FROM orders o
+		WHERE o.id = $1::uuid AND COALESCE(o.tg_id, 0) <> 0
+		ON CONFLICT (order_id) DO UPDATE
+		SET courier_id   = COALESCE(NULLIF(EXCLUDED.courier_id, ''), order_ratings.courier_id),
+		    courier_name = COALESCE(NULLIF(EXCLUDED.courier_name, ''), order_ratings.courier_name),
+		    updated_at   = now()
+	`
+	_, err := r.db.Exec(ctx, query,
+		req.OrderID,
+		strings.TrimSpace(req.CourierID),
+		strings.TrimSpace(req.CourierName),
+		askAt,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("schedule order rating failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) ClaimDue(ctx context.Context, limit int) ([]structs.OrderRating, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	query := `
+		WITH due AS (
+			SELECT order_id
+			FROM order_ratings
+			WHERE status = 'PENDING' AND ask_at <= now()
+			ORDER BY ask_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), upd AS (
+			UPDATE order_ratings r
+			SET status = 'ASKED', asked_at = now(), updated_at = now()
+			FROM due
+			WHERE r.order_id = due.order_id
+			RETURNING r.*
+		)
+		SELECT ` + ratingColumns + `
+		FROM upd r
+		JOIN orders o ON o.id = r.order_id
+		LEFT JOIN clients c ON c.tgid = r.tg_id
+	`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, fmt.Errorf("claim due ratings failed: %w", err)
+	}
+	defer rows.Close()
+
+	list := []structs.OrderRating{}
+	for rows.Next() {
+		item, err := scanRating(rows)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return nil, fmt.Errorf("scan order rating failed: %w", err)
+		}
+		list = append(list, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("order ratings rows failed: %w", err)
+	}
+	return list, nil
+}
+
+func (r repo) Unclaim(ctx context.Context, orderID string, askAt time.Time) error {
+	query := `
+		UPDATE order_ratings
+		SET status = 'PENDING', asked_at = NULL, ask_at = $2, updated_at = now()
+		WHERE order_id = $1::uuid AND status = 'ASKED'
+	`
+	if _, err := r.db.Exec(ctx, query, orderID, askAt); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("unclaim order rating failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) Get(ctx context.Context, orderID string) (structs.OrderRating, error) {
+	query := `
+		SELECT ` + ratingColumns + `
+		FROM order_ratings r
+		JOIN orders o ON o.id = r.order_id
+		LEFT JOIN clients c ON c.tgid = r.tg_id
+		WHERE r.order_id = $1::uuid
+	`
+	resp, err := scanRating(r.db.QueryRow(ctx, query, orderID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.OrderRating{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return structs.OrderRating{}, fmt.Errorf("get order rating failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) SetFood(ctx context.Context, orderID string, tgID int64, score int) (structs.OrderRating, error) {
+	// olib ketishda yetkazish bahosi yo'q -> ovqat bahosi bilan tugaydi
+	query := `
+		UPDATE order_ratings
+		SET food       = $3,
+		    status     = CASE WHEN delivery IS NOT NULL OR delivery_type = 'PICKUP' THEN 'RATED' ELSE status END,
+		    rated_at   = CASE WHEN delivery IS NOT NULL OR delivery_type = 'PICKUP' THEN COALESCE(rated_at, now()) ELSE rated_at END,
+		    updated_at = now()
+		WHERE order_id = $1::uuid AND tg_id = $2 AND status IN ('ASKED', 'RATED')
+	`
+	return r.setScore(ctx, query, orderID, tgID, score)
+}
+
+func (r repo) SetDelivery(ctx context.Context, orderID string, tgID int64, score int) (structs.OrderRating, error) {
+	query := `
+		UPDATE order_ratings
+		SET delivery   = $3,
+		    status     = CASE WHEN food IS NOT NULL THEN 'RATED' ELSE status END,
+		    rated_at   = CASE WHEN food IS NOT NULL THEN COALESCE(rated_at, now()) ELSE rated_at END,
+		    updated_at = now()
+		WHERE order_id = $1::uuid AND tg_id = $2 AND status IN ('ASKED', 'RATED')
+	`
+	return r.setScore(ctx, query, orderID, tgID, score)
+}
+
+func (r repo) setScore(ctx context.Context, query, orderID string, tgID int64, score int) (structs.OrderRating, error) {
+	res, err := r.db.Exec(ctx, query, orderID, tgID, score)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return structs.OrderRating{}, fmt.Errorf("set rating score failed: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return structs.OrderRating{}, structs.ErrNotFound
+	}
+	return r.Get(ctx, orderID)
+}
+
+func (r repo) SetComment(ctx context.Context, orderID string, tgID int64, comment string) error {
+	query := `
+		UPDATE order_ratings
+		SET comment = $3, updated_at = now()
+		WHERE order_id = $1::uuid AND tg_id = $2 AND status = 'RATED'
+	`
+	res, err := r.db.Exec(ctx, query, orderID, tgID, comment)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("set rating comment failed: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return structs.ErrNotFound
+	}
+	return nil
+}
+
+func (r repo) MarkAlerted(ctx context.Context, orderID string) (bool, error) {
+	query := `
+		UPDATE order_ratings
+		SET alerted_at = now(), updated_at = now()
+		WHERE order_id = $1::uuid AND alerted_at IS NULL
+	`
+	res, err := r.db.Exec(ctx, query, orderID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return false, fmt.Errorf("mark rating alerted failed: %w", err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (r repo) GetList(ctx context.Context, req structs.GetListRatingRequest) (structs.GetListRatingResponse, error) {
+	resp := structs.GetListRatingResponse{Ratings: []structs.OrderRating{}}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	where := " WHERE r.status = 'RATED'"
+	args := []interface{}{}
+	argIndex := 1
+
+	if strings.TrimSpace(req.CourierID) != "" {
+		where += fmt.Sprintf(" AND r.courier_id = $%d", argIndex)
+		args = append(args, strings.TrimSpace(req.CourierID))
+		argIndex++
+	}
+	if req.MaxScore > 0 {
+		where += fmt.Sprintf(" AND (r.food <= $%d OR r.delivery <= $%d)", argIndex, argIndex)
+		args = append(args, req.MaxScore)
+		argIndex++
+	}
+	if req.From != nil {
+		where += fmt.Sprintf(" AND r.rated_at >= $%d", argIndex)
+		args = append(args, *req.From)
+		argIndex++
+	}
+	if req.To != nil {
+		where += fmt.Sprintf(" AND r.rated_at < $%d", argIndex)
+		args = append(args, *req.To)
+		argIndex++
+	}
+
+	query := `
+		SELECT COUNT(*) OVER(), ` + ratingColumns + `
+		FROM order_ratings r
+		JOIN orders o ON o.id = r.order_id
+		LEFT JOIN clients c ON c.tgid = r.tg_id
+	` + where + fmt.Sprintf(" ORDER BY r.rated_at DESC OFFSET $%d LIMIT $%d", argIndex, argIndex+1)
+	args = append(args, req.Offset, req.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get order ratings failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item, err := scanRating(rows, &resp.Count)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan order rating failed: %w", err)
+		}
+		resp.Ratings = append(resp.Ratings, item)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("order ratings rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) Summary(ctx context.Context, from, to *time.Time) (structs.RatingSummary, error) {
+	resp := structs.RatingSummary{Couriers: []structs.CourierRating{}}
+
+	// GROUPING SETS: () - umumiy, (courier_id) - har kuryer
+	query := `
+		SELECT
+			GROUPING(courier_id) = 1,
+			COALESCE(courier_id, ''),
+			COALESCE(MAX(courier_name), ''),
+			COUNT(*),
+			COALESCE(AVG(food), 0)::float8,
+			COALESCE(AVG(delivery), 0)::float8
+		FROM order_ratings
+		WHERE status = 'RATED'
+		  AND ($1::timestamptz IS NULL OR rated_at >= $1)
+		  AND ($2::timestamptz IS NULL OR rated_at < $2)
+		GROUP BY GROUPING SETS ((), (courier_id))
+		HAVING GROUPING(courier_id) = 1 OR courier_id <> ''
+		ORDER BY 1 DESC, 6 DESC
+	`
+	rows, err := r.db.Query(ctx, query, from, to)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get rating summary failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			overall bool
+			item    structs.CourierRating
+		)
+		if err := rows.Scan(
+			&overall,
+			&item.CourierID,
+			&item.CourierName,
+			&item.Count,
+			&item.Food,
+			&item.Delivery,
+		); err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan rating summary failed: %w", err)
+		}
+		if overall {
+			resp.Overall = item.RatingAverage
+			continue
+		}
+		resp.Couriers = append(resp.Couriers, item)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("rating summary rows failed: %w", err)
+	}
+	return resp, nil
+}
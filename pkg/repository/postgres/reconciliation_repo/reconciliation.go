@@ -0,0 +1,266 @@
+package reconciliationrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// TryLock - barcha gateway nusxalari uchun bitta sverka (Postgres advisory lock).
+		// Band bo'lsa ok=false; unlock chaqirilguncha lock ushlab turiladi
+		TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+		// Create - RUNNING holatida yangi tekshiruv ochadi
+		Create(ctx context.Context, from, to time.Time, trigger string) (structs.Reconciliation, error)
+		AddItem(ctx context.Context, reconciliationID string, item structs.ReconciliationItem) error
+		// Finish - yakuniy sonlar bilan DONE/FAILED qiladi
+		Finish(ctx context.Context, rec structs.Reconciliation) error
+
+		GetList(ctx context.Context, req structs.GetListReconciliationRequest) (structs.GetListReconciliationResponse, error)
+		GetByID(ctx context.Context, id string) (structs.Reconciliation, error)
+		GetItems(ctx context.Context, reconciliationID string) ([]structs.ReconciliationItem, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+// runLockKey - pg_try_advisory_xact_lock kaliti (boshqa advisory lock'lar bilan to'qnashmasin)
+const runLockKey int64 = 0x7265636f6e63 // "reconc"
+
+// TryLock - lock tranzaksiyaga bog'langan: pool ulanishi qaytsa ham osilib qolmaydi,
+// jarayon o'lsa Postgres o'zi bo'shatadi
+func (r repo) TryLock(ctx context.Context) (func(), bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error(ctx, "reconciliation lock begin failed", zap.Error(err))
+		return nil, false, err
+	}
+
+	var ok bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, runLockKey).Scan(&ok); err != nil {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		r.logger.Error(ctx, "reconciliation lock failed", zap.Error(err))
+		return nil, false, err
+	}
+	if !ok {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+		return nil, false, nil
+	}
+
+	return func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }, true, nil
+}
+
+const reconciliationColumns = `
+	id,
+	period_from,
+	period_to,
+	trigger,
+	status,
+	checked,
+	fixed,
+	mismatches,
+	COALESCE(error, ''),
+	started_at,
+	finished_at
+`
+
+func scanReconciliation(row pgx.Row, extra ...any) (structs.Reconciliation, error) {
+	var rec structs.Reconciliation
+	dest := append(extra,
+		&rec.ID,
+		&rec.PeriodFrom,
+		&rec.PeriodTo,
+		&rec.Trigger,
+		&rec.Status,
+		&rec.Checked,
+		&rec.Fixed,
+		&rec.Mismatches,
+		&rec.Error,
+		&rec.StartedAt,
+		&rec.FinishedAt,
+	)
+	err := row.Scan(dest...)
+	return rec, err
+}
+
+func (r repo) Create(ctx context.Context, from, to time.Time, trigger string) (structs.Reconciliation, error) {
+	query := `
+		INSERT INTO payment_reconciliations (id, period_from, period_to, trigger)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + reconciliationColumns
+
+	rec, err := scanReconciliation(r.db.QueryRow(ctx, query, uuid.NewString(), from, to, trigger))
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return rec, fmt.Errorf("create reconciliation failed: %w", err)
+	}
+	return rec, nil
+}
+
+func (r repo) AddItem(ctx context.Context, reconciliationID string, item structs.ReconciliationItem) error {
+	query := `
+		INSERT INTO payment_reconciliation_items (
+			reconciliation_id, order_id, order_number, payment_method, kind,
+			order_status, payment_status, provider_status,
+			order_amount, provider_amount, fixed, note
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := r.db.Exec(ctx, query,
+		reconciliationID,
+		item.OrderID,
+		item.OrderNumber,
+		item.PaymentMethod,
+		item.Kind,
+		item.OrderStatus,
+		item.PaymentStatus,
+		item.ProviderStatus,
+		item.OrderAmount,
+		item.ProviderAmount,
+		item.Fixed,
+		item.Note,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("add reconciliation item failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) Finish(ctx context.Context, rec structs.Reconciliation) error {
+	query := `
+		UPDATE payment_reconciliations
+		SET status      = $2,
+		    checked     = $3,
+		    fixed       = $4,
+		    mismatches  = $5,
+		    error       = NULLIF($6, ''),
+		    finished_at = now()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, rec.ID, rec.Status, rec.Checked, rec.Fixed, rec.Mismatches, rec.Error)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("finish reconciliation failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) GetList(ctx context.Context, req structs.GetListReconciliationRequest) (structs.GetListReconciliationResponse, error) {
+	resp := structs.GetListReconciliationResponse{Reconciliations: []structs.Reconciliation{}}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	query := `SELECT COUNT(*) OVER(), ` + reconciliationColumns + `
+		FROM payment_reconciliations
+		ORDER BY started_at DESC
+		OFFSET $1 LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, req.Offset, req.Limit)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get reconciliations failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rec, err := scanReconciliation(rows, &resp.Count)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan reconciliation failed: %w", err)
+		}
+		resp.Reconciliations = append(resp.Reconciliations, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("reconciliations rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetByID(ctx context.Context, id string) (structs.Reconciliation, error) {
+	query := `SELECT ` + reconciliationColumns + ` FROM payment_reconciliations WHERE id = $1`
+
+	rec, err := scanReconciliation(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return rec, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return rec, fmt.Errorf("get reconciliation failed: %w", err)
+	}
+	return rec, nil
+}
+
+func (r repo) GetItems(ctx context.Context, reconciliationID string) ([]structs.ReconciliationItem, error) {
+	items := []structs.ReconciliationItem{}
+	query := `
+		SELECT
+			id, order_id, order_number, payment_method, kind,
+			order_status, payment_status, provider_status,
+			order_amount, provider_amount, fixed, note, created_at
+		FROM payment_reconciliation_items
+		WHERE reconciliation_id = $1
+		ORDER BY fixed, id
+	`
+	rows, err := r.db.Query(ctx, query, reconciliationID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return items, fmt.Errorf("get reconciliation items failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it structs.ReconciliationItem
+		err := rows.Scan(
+			&it.ID,
+			&it.OrderID,
+			&it.OrderNumber,
+			&it.PaymentMethod,
+			&it.Kind,
+			&it.OrderStatus,
+			&it.PaymentStatus,
+			&it.ProviderStatus,
+			&it.OrderAmount,
+			&it.ProviderAmount,
+			&it.Fixed,
+			&it.Note,
+			&it.CreatedAt,
+		)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return items, fmt.Errorf("scan reconciliation item failed: %w", err)
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,347 @@
+package referralrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// Create - referrer mavjud mijoz bo'lsa va o'zi bo'lmasa yoziladi; false - yozilmadi
+		Create(ctx context.Context, refereeTgID, referrerTgID int64) (bool, error)
+		// GetPending - do'stning hali mukofotlanmagan referali (telefon va tillar bilan)
+		GetPending(ctx context.Context, refereeTgID int64) (structs.Referral, error)
+		// PhoneTaken - shu telefon (oxirgi 9 raqam) boshqa akkauntda bormi
+		PhoneTaken(ctx context.Context, phone string, exceptTgID int64) (bool, error)
+		// Reward - PENDING -> REWARDED; false - allaqachon boshqa jarayon olgan
+		Reward(ctx context.Context, refereeTgID int64, reward structs.ReferralReward) (bool, error)
+		Reject(ctx context.Context, refereeTgID int64, reason string) error
+		// PromoAmount - mijozga berilgan referal kodining summasi; ErrNotFound - kod uniki emas,
+		// ErrPromoCodeUsed - bekor qilinmagan zakazda allaqachon ishlatilgan
+		PromoAmount(ctx context.Context, tgID int64, code string) (int64, error)
+
+		Stats(ctx context.Context, tgID int64) (structs.ReferralStats, error)
+		GetList(ctx context.Context, req structs.GetListReferralRequest) (structs.GetListReferralResponse, error)
+		Summary(ctx context.Context, from, to *time.Time) (structs.ReferralSummary, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+const referralColumns = `
+	r.referee_tg_id,
+	COALESCE(ee.name, ''),
+	COALESCE(ee.phone, ''),
+	r.referrer_tg_id,
+	COALESCE(er.name, ''),
+	COALESCE(er.phone, ''),
+	r.status,
+	r.reject_reason,
+	r.order_id,
+	r.referrer_code,
+	r.referee_code,
+	r.reward_amount,
+	r.created_at,
+	r.rewarded_at,
+	COALESCE(er.language, 'uz'),
+	COALESCE(ee.language, 'uz')
+`
+
+const referralJoins = `
+	FROM referrals r
+	LEFT JOIN clients ee ON ee.tgid = r.referee_tg_id
+	LEFT JOIN clients er ON er.tgid = r.referrer_tg_id
+`
+
+func scanReferral(row pgx.Row, extra ...any) (structs.Referral, error) {
+	var r structs.Referral
+	dest := append(extra, // COUNT(*) OVER() birinchi keladi
+		&r.RefereeTgID,
+		&r.RefereeName,
+		&r.RefereePhone,
+		&r.ReferrerTgID,
+		&r.ReferrerName,
+		&r.ReferrerPhone,
+		&r.Status,
+		&r.RejectReason,
+		&r.OrderID,
+		&r.ReferrerCode,
+		&r.RefereeCode,
+		&r.RewardAmount,
+		&r.CreatedAt,
+		&r.RewardedAt,
+		&r.ReferrerLanguage,
+		&r.RefereeLanguage,
+	)
+	err := row.Scan(dest...)
+	return r, err
+}
+
+func (r repo) Create(ctx context.Context, refereeTgID, referrerTgID int64) (bool, error) {
+	query := `
+		INSERT INTO referrals (referee_tg_id, referrer_tg_id)
+		SELECT $1, $2
+		WHERE $1 <> $2
+		  AND EXISTS (SELECT 1 FROM clients WHERE tgid = $2)
+		ON CONFLICT (referee_tg_id) DO NOTHING
+	`
+	res, err := r.db.Exec(ctx, query, refereeTgID, referrerTgID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return false, fmt.Errorf("create referral failed: %w", err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (r repo) GetPending(ctx context.Context, refereeTgID int64) (structs.Referral, error) {
+	query := `SELECT ` + referralColumns + referralJoins + `
+		WHERE r.referee_tg_id = $1 AND r.status = 'PENDING'
+	`
+	ref, err := scanReferral(r.db.QueryRow(ctx, query, refereeTgID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return ref, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return ref, fmt.Errorf("get pending referral failed: %w", err)
+	}
+	return ref, nil
+}
+
+func (r repo) PromoAmount(ctx context.Context, tgID int64, code string) (int64, error) {
+	query := `
+		SELECT
+			r.reward_amount,
+			EXISTS (
+				SELECT 1 FROM orders o
+				WHERE o.promo_code = $2 AND o.order_status NOT IN ('CANCELLED', 'REJECTED')
+			)
+		FROM referrals r
+		WHERE r.status = 'REWARDED'
+		  AND ((r.referrer_tg_id = $1 AND r.referrer_code = $2) OR (r.referee_tg_id = $1 AND r.referee_code = $2))
+	`
+	var (
+		amount int64
+		used   bool
+	)
+	if err := r.db.QueryRow(ctx, query, tgID, code).Scan(&amount, &used); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return 0, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("get promo amount failed: %w", err)
+	}
+	if used {
+		return 0, structs.ErrPromoCodeUsed
+	}
+	return amount, nil
+}
+
+func (r repo) PhoneTaken(ctx context.Context, phone string, exceptTgID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM clients
+			WHERE tgid <> $2
+			  AND RIGHT(regexp_replace(COALESCE(phone, ''), '\D', '', 'g'), 9) = RIGHT(regexp_replace($1, '\D', '', 'g'), 9)
+		)
+	`
+	var taken bool
+	if err := r.db.QueryRow(ctx, query, phone, exceptTgID).Scan(&taken); err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return false, fmt.Errorf("check phone failed: %w", err)
+	}
+	return taken, nil
+}
+
+func (r repo) Reward(ctx context.Context, refereeTgID int64, reward structs.ReferralReward) (bool, error) {
+	query := `
+		UPDATE referrals
+		SET status        = 'REWARDED',
+		    order_id      = $2,
+		    referrer_code = $3,
+		    referee_code  = $4,
+		    reward_amount = $5,
+		    rewarded_at   = now()
+		WHERE referee_tg_id = $1 AND status = 'PENDING'
+	`
+	res, err := r.db.Exec(ctx, query, refereeTgID, reward.OrderID, reward.ReferrerCode, reward.RefereeCode, reward.Amount)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return false, fmt.Errorf("reward referral failed: %w", err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (r repo) Reject(ctx context.Context, refereeTgID int64, reason string) error {
+	query := `
+		UPDATE referrals
+		SET status = 'REJECTED', reject_reason = $2
+		WHERE referee_tg_id = $1 AND status = 'PENDING'
+	`
+	if _, err := r.db.Exec(ctx, query, refereeTgID, reason); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("reject referral failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) Stats(ctx context.Context, tgID int64) (structs.ReferralStats, error) {
+	resp := structs.ReferralStats{Codes: []string{}}
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE referrer_tg_id = $1),
+			COUNT(*) FILTER (WHERE referrer_tg_id = $1 AND status = 'REWARDED'),
+			COUNT(*) FILTER (WHERE referrer_tg_id = $1 AND status = 'PENDING'),
+			COALESCE(array_agg(CASE WHEN referrer_tg_id = $1 THEN referrer_code ELSE referee_code END ORDER BY rewarded_at)
+				FILTER (WHERE status = 'REWARDED'), '{}')
+		FROM referrals
+		WHERE referrer_tg_id = $1 OR referee_tg_id = $1
+	`
+	if err := r.db.QueryRow(ctx, query, tgID).Scan(&resp.Invited, &resp.Rewarded, &resp.Pending, &resp.Codes); err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return resp, fmt.Errorf("referral stats failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) GetList(ctx context.Context, req structs.GetListReferralRequest) (structs.GetListReferralResponse, error) {
+	resp := structs.GetListReferralResponse{Referrals: []structs.Referral{}}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	var (
+		where    = " WHERE 1=1"
+		args     []any
+		argIndex = 1
+	)
+	if s := strings.ToUpper(strings.TrimSpace(req.Status)); s != "" {
+		where += fmt.Sprintf(" AND r.status = $%d", argIndex)
+		args = append(args, s)
+		argIndex++
+	}
+	if req.ReferrerTgID != 0 {
+		where += fmt.Sprintf(" AND r.referrer_tg_id = $%d", argIndex)
+		args = append(args, req.ReferrerTgID)
+		argIndex++
+	}
+	if req.From != nil {
+		where += fmt.Sprintf(" AND r.created_at >= $%d", argIndex)
+		args = append(args, *req.From)
+		argIndex++
+	}
+	if req.To != nil {
+		where += fmt.Sprintf(" AND r.created_at < $%d", argIndex)
+		args = append(args, *req.To)
+		argIndex++
+	}
+	args = append(args, req.Offset, req.Limit)
+
+	query := `SELECT COUNT(*) OVER(), ` + referralColumns + referralJoins + where +
+		fmt.Sprintf(" ORDER BY r.created_at DESC OFFSET $%d LIMIT $%d", argIndex, argIndex+1)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("get referrals failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ref, err := scanReferral(rows, &resp.Count)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan referral failed: %w", err)
+		}
+		resp.Referrals = append(resp.Referrals, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return resp, fmt.Errorf("referrals rows failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (r repo) Summary(ctx context.Context, from, to *time.Time) (structs.ReferralSummary, error) {
+	resp := structs.ReferralSummary{TopReferrers: []structs.TopReferrer{}}
+
+	totals := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'PENDING'),
+			COUNT(*) FILTER (WHERE status = 'REWARDED'),
+			COUNT(*) FILTER (WHERE status = 'REJECTED'),
+			COALESCE(SUM(reward_amount) FILTER (WHERE status = 'REWARDED'), 0)
+		FROM referrals
+		WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR created_at < $2)
+	`
+	err := r.db.QueryRow(ctx, totals, from, to).Scan(
+		&resp.Total, &resp.Pending, &resp.Rewarded, &resp.Rejected, &resp.RewardedAmount,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return resp, fmt.Errorf("referral summary failed: %w", err)
+	}
+
+	top := `
+		SELECT
+			r.referrer_tg_id,
+			COALESCE(c.name, ''),
+			COALESCE(c.phone, ''),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE r.status = 'REWARDED')
+		FROM referrals r
+		LEFT JOIN clients c ON c.tgid = r.referrer_tg_id
+		WHERE ($1::timestamptz IS NULL OR r.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR r.created_at < $2)
+		GROUP BY r.referrer_tg_id, c.name, c.phone
+		ORDER BY COUNT(*) FILTER (WHERE r.status = 'REWARDED') DESC, COUNT(*) DESC
+		LIMIT 20
+	`
+	rows, err := r.db.Query(ctx, top, from, to)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return resp, fmt.Errorf("top referrers failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t structs.TopReferrer
+		if err := rows.Scan(&t.TgID, &t.Name, &t.Phone, &t.Invited, &t.Rewarded); err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return resp, fmt.Errorf("scan top referrer failed: %w", err)
+		}
+		resp.TopReferrers = append(resp.TopReferrers, t)
+	}
+	return resp, rows.Err()
+}
@@ -0,0 +1,261 @@
+package refundrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		// CreatePending - bitta tranzaksiyada: zakaz FOR UPDATE bilan qulflanadi, qatorlar before'dan
+		// o'zgargan bo'lsa ErrOrderChanged, aks holda after yoziladi va PENDING yozuv qo'shiladi
+		CreatePending(ctx context.Context, refund structs.OrderRefund, before, after []structs.OrderProduct) (structs.OrderRefund, error)
+		// Finish - provider natijasi (DONE/MANUAL/FAILED)
+		Finish(ctx context.Context, id int64, status, note string, totalAfter int64) (structs.OrderRefund, error)
+		GetByOrderID(ctx context.Context, orderID string) ([]structs.OrderRefund, error)
+		// MarkDone - MANUAL/FAILED qaytarish qo'lda bajarildi
+		MarkDone(ctx context.Context, orderID string, id int64, note string) (structs.OrderRefund, error)
+		// SumByOrderID - qaytarilgan (yoki qaytarilishi kerak) jami summa, sverka uchun
+		SumByOrderID(ctx context.Context, orderID string) (int64, error)
+		// SumOtherRefunds - FAILED'dan tashqari qaytarishlar (exceptID hisobga olinmaydi)
+		SumOtherRefunds(ctx context.Context, orderID string, exceptID int64) (int64, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+const refundColumns = `
+	id,
+	order_id,
+	payment_method,
+	amount,
+	total_before,
+	total_after,
+	items,
+	reason,
+	status,
+	note,
+	created_by,
+	created_at,
+	updated_at
+`
+
+func scanRefund(row pgx.Row) (structs.OrderRefund, error) {
+	var (
+		ref        structs.OrderRefund
+		itemsBytes []byte
+	)
+	err := row.Scan(
+		&ref.ID,
+		&ref.OrderID,
+		&ref.PaymentMethod,
+		&ref.Amount,
+		&ref.TotalBefore,
+		&ref.TotalAfter,
+		&itemsBytes,
+		&ref.Reason,
+		&ref.Status,
+		&ref.Note,
+		&ref.CreatedBy,
+		&ref.CreatedAt,
+		&ref.UpdatedAt,
+	)
+	if err != nil {
+		return ref, err
+	}
+	ref.Items = []structs.RefundItem{}
+	if len(itemsBytes) > 0 {
+		_ = json.Unmarshal(itemsBytes, &ref.Items)
+	}
+	return ref, nil
+}
+
+func (r repo) CreatePending(ctx context.Context, refund structs.OrderRefund, before, after []structs.OrderProduct) (structs.OrderRefund, error) {
+	items, err := json.Marshal(refund.Items)
+	if err != nil {
+		return refund, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return refund, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var itemsBytes []byte
+	err = tx.QueryRow(ctx, `SELECT items FROM orders WHERE id = $1 FOR UPDATE`, refund.OrderID).Scan(&itemsBytes)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return refund, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on tx.QueryRow", zap.Error(err))
+		return refund, fmt.Errorf("lock order failed: %w", err)
+	}
+	var current []structs.OrderProduct
+	if len(itemsBytes) > 0 {
+		if err := json.Unmarshal(itemsBytes, &current); err != nil {
+			return refund, fmt.Errorf("unmarshal order items failed: %w", err)
+		}
+	}
+	// boshqa operator shu orada qator olib tashlagan
+	if !reflect.DeepEqual(current, before) {
+		return refund, structs.ErrOrderChanged
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE orders SET items = $2, updated_at = now() WHERE id = $1`, refund.OrderID, after); err != nil {
+		r.logger.Error(ctx, "err on tx.Exec", zap.Error(err))
+		return refund, fmt.Errorf("update order items failed: %w", err)
+	}
+
+	query := `
+		INSERT INTO order_refunds (
+			order_id, payment_method, amount, total_before, total_after,
+			items, reason, status, note, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + refundColumns
+
+	created, err := scanRefund(tx.QueryRow(ctx, query,
+		refund.OrderID,
+		refund.PaymentMethod,
+		refund.Amount,
+		refund.TotalBefore,
+		refund.TotalAfter,
+		items,
+		refund.Reason,
+		structs.RefundStatusPending,
+		refund.Note,
+		refund.CreatedBy,
+	))
+	if err != nil {
+		r.logger.Error(ctx, "err on tx.QueryRow", zap.Error(err))
+		return refund, fmt.Errorf("create order refund failed: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return refund, err
+	}
+	return created, nil
+}
+
+func (r repo) Finish(ctx context.Context, id int64, status, note string, totalAfter int64) (structs.OrderRefund, error) {
+	query := `
+		UPDATE order_refunds
+		SET status      = $2,
+		    note        = $3,
+		    total_after = $4,
+		    updated_at  = now()
+		WHERE id = $1
+		RETURNING ` + refundColumns
+
+	ref, err := scanRefund(r.db.QueryRow(ctx, query, id, status, note, totalAfter))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return ref, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return ref, fmt.Errorf("finish order refund failed: %w", err)
+	}
+	return ref, nil
+}
+
+func (r repo) GetByOrderID(ctx context.Context, orderID string) ([]structs.OrderRefund, error) {
+	list := []structs.OrderRefund{}
+	query := `SELECT ` + refundColumns + `
+		FROM order_refunds
+		WHERE order_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.Query(ctx, query, orderID)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return list, fmt.Errorf("get order refunds failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ref, err := scanRefund(rows)
+		if err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return list, fmt.Errorf("scan order refund failed: %w", err)
+		}
+		list = append(list, ref)
+	}
+	return list, rows.Err()
+}
+
+func (r repo) MarkDone(ctx context.Context, orderID string, id int64, note string) (structs.OrderRefund, error) {
+	query := `
+		UPDATE order_refunds
+		SET status     = 'DONE',
+		    note       = CASE WHEN $3 = '' THEN note ELSE $3 END,
+		    updated_at = now()
+		WHERE id = $1
+		  AND order_id = $2
+		RETURNING ` + refundColumns
+
+	ref, err := scanRefund(r.db.QueryRow(ctx, query, id, orderID, note))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return ref, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return ref, fmt.Errorf("mark order refund done failed: %w", err)
+	}
+	return ref, nil
+}
+
+func (r repo) SumByOrderID(ctx context.Context, orderID string) (int64, error) {
+	var sum int64
+	query := `SELECT COALESCE(SUM(amount), 0) FROM order_refunds WHERE order_id = $1`
+	if err := r.db.QueryRow(ctx, query, orderID).Scan(&sum); err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("sum order refunds failed: %w", err)
+	}
+	return sum, nil
+}
+
+func (r repo) SumOtherRefunds(ctx context.Context, orderID string, exceptID int64) (int64, error) {
+	var sum int64
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM order_refunds
+		WHERE order_id = $1
+		  AND id <> $2
+		  AND status <> $3
+	`
+	if err := r.db.QueryRow(ctx, query, orderID, exceptID, structs.RefundStatusFailed).Scan(&sum); err != nil {
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("sum order refunds failed: %w", err)
+	}
+	return sum, nil
+}
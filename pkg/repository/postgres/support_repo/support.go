@@ -0,0 +1,249 @@
+package supportrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/db"
+	"sushitana/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var Module = fx.Provide(New)
+
+type (
+	Params struct {
+		fx.In
+		Logger logger.Logger
+		DB     db.Querier
+	}
+
+	Repo interface {
+		GetThread(ctx context.Context, tgID int64) (structs.SupportThread, error)
+		SaveThread(ctx context.Context, t structs.SupportThread) error
+		TouchThread(ctx context.Context, tgID int64, orderID *string) error
+		// FindTgID - operator javobi qaysi mijozga: topic bo'yicha yoki reply qilingan xabar bo'yicha
+		FindTgIDByTopic(ctx context.Context, chatID int64, topicID int) (int64, error)
+		FindTgIDByGroupMessage(ctx context.Context, chatID int64, messageID int) (int64, error)
+
+		CreateMessage(ctx context.Context, m structs.SupportMessage) error
+		GetMessages(ctx context.Context, req structs.GetSupportMessagesRequest) ([]structs.SupportMessage, int64, error)
+	}
+
+	repo struct {
+		logger logger.Logger
+		db     db.Querier
+	}
+)
+
+func New(p Params) Repo {
+	return &repo{
+		logger: p.Logger,
+		db:     p.DB,
+	}
+}
+
+func (r repo) GetThread(ctx context.Context, tgID int64) (structs.SupportThread, error) {
+	var (
+		resp  structs.SupportThread
+		query = `
+			SELECT
+				tg_id,
+				chat_id,
+				topic_id,
+				header_message_id,
+				order_id::text,
+				last_message_at,
+				created_at,
+				updated_at
+			FROM support_threads
+			WHERE tg_id = $1
+		`
+	)
+	err := r.db.QueryRow(ctx, query, tgID).Scan(
+		&resp.TgID,
+		&resp.ChatID,
+		&resp.TopicID,
+		&resp.HeaderMessageID,
+		&resp.OrderID,
+		&resp.LastMessageAt,
+		&resp.CreatedAt,
+		&resp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return structs.SupportThread{}, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return structs.SupportThread{}, fmt.Errorf("get support thread failed: %w", err)
+	}
+	return resp, nil
+}
+
+// SaveThread - yangi thread yoki guruh/topic o'zgarganda (masalan support_chat_id almashtirilsa)
+func (r repo) SaveThread(ctx context.Context, t structs.SupportThread) error {
+	query := `
+		INSERT INTO support_threads (tg_id, chat_id, topic_id, header_message_id, order_id)
+		VALUES ($1, $2, $3, $4, $5::uuid)
+		ON CONFLICT (tg_id) DO UPDATE
+		SET chat_id           = EXCLUDED.chat_id,
+		    topic_id          = EXCLUDED.topic_id,
+		    header_message_id = EXCLUDED.header_message_id,
+		    order_id          = EXCLUDED.order_id,
+		    last_message_at   = now(),
+		    updated_at        = now()
+	`
+	if _, err := r.db.Exec(ctx, query, t.TgID, t.ChatID, t.TopicID, t.HeaderMessageID, t.OrderID); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("save support thread failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) TouchThread(ctx context.Context, tgID int64, orderID *string) error {
+	query := `
+		UPDATE support_threads
+		SET order_id        = COALESCE($2::uuid, order_id),
+		    last_message_at = now(),
+		    updated_at      = now()
+		WHERE tg_id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, tgID, orderID); err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("touch support thread failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) FindTgIDByTopic(ctx context.Context, chatID int64, topicID int) (int64, error) {
+	var tgID int64
+	query := `SELECT tg_id FROM support_threads WHERE chat_id = $1 AND topic_id = $2 LIMIT 1`
+	if err := r.db.QueryRow(ctx, query, chatID, topicID).Scan(&tgID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return 0, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("find support thread by topic failed: %w", err)
+	}
+	return tgID, nil
+}
+
+func (r repo) FindTgIDByGroupMessage(ctx context.Context, chatID int64, messageID int) (int64, error) {
+	var tgID int64
+	query := `
+		SELECT tg_id FROM support_threads
+		WHERE chat_id = $1 AND header_message_id = $2
+		UNION ALL
+		SELECT m.tg_id FROM support_messages m
+		JOIN support_threads t ON t.tg_id = m.tg_id AND t.chat_id = $1
+		WHERE m.group_message_id = $2
+		LIMIT 1
+	`
+	if err := r.db.QueryRow(ctx, query, chatID, messageID).Scan(&tgID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows) {
+			return 0, structs.ErrNotFound
+		}
+		r.logger.Error(ctx, "err on r.db.QueryRow", zap.Error(err))
+		return 0, fmt.Errorf("find support thread by message failed: %w", err)
+	}
+	return tgID, nil
+}
+
+func (r repo) CreateMessage(ctx context.Context, m structs.SupportMessage) error {
+	query := `
+		INSERT INTO support_messages (
+			tg_id,
+			direction,
+			kind,
+			text,
+			file_id,
+			client_message_id,
+			group_message_id,
+			employee_id,
+			order_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::uuid)
+	`
+	_, err := r.db.Exec(ctx, query,
+		m.TgID,
+		m.Direction,
+		m.Kind,
+		m.Text,
+		m.FileID,
+		m.ClientMessageID,
+		m.GroupMessageID,
+		m.EmployeeID,
+		m.OrderID,
+	)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Exec", zap.Error(err))
+		return fmt.Errorf("create support message failed: %w", err)
+	}
+	return nil
+}
+
+func (r repo) GetMessages(ctx context.Context, req structs.GetSupportMessagesRequest) ([]structs.SupportMessage, int64, error) {
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	query := `
+		SELECT
+			COUNT(*) OVER(),
+			id,
+			tg_id,
+			direction,
+			kind,
+			text,
+			file_id,
+			client_message_id,
+			group_message_id,
+			employee_id,
+			order_id::text,
+			created_at
+		FROM support_messages
+		WHERE tg_id = $1
+		ORDER BY created_at DESC, id DESC
+		OFFSET $2 LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, query, req.TgID, req.Offset, req.Limit)
+	if err != nil {
+		r.logger.Error(ctx, "err on r.db.Query", zap.Error(err))
+		return nil, 0, fmt.Errorf("get support messages failed: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		count int64
+		list  = []structs.SupportMessage{}
+	)
+	for rows.Next() {
+		var m structs.SupportMessage
+		if err := rows.Scan(
+			&count,
+			&m.ID,
+			&m.TgID,
+			&m.Direction,
+			&m.Kind,
+			&m.Text,
+			&m.FileID,
+			&m.ClientMessageID,
+			&m.GroupMessageID,
+			&m.EmployeeID,
+			&m.OrderID,
+			&m.CreatedAt,
+		); err != nil {
+			r.logger.Error(ctx, "err on rows.Scan", zap.Error(err))
+			return nil, 0, fmt.Errorf("scan support message failed: %w", err)
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("support messages rows failed: %w", err)
+	}
+	return list, count, nil
+}
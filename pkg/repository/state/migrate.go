@@ -0,0 +1,54 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sushitana/pkg/db"
+	"sushitana/pkg/redis"
+	"sushitana/pkg/tgrouter/interfaces"
+)
+
+const migratedMarkerKey = "bot_state:migrated_from_postgres"
+
+// migrateToRedis postgres dagi `state` jadvalini redisga bir marta ko'chiradi.
+// Marker key qo'yilgandan keyin qayta ishga tushmaydi.
+func migrateToRedis(ctx context.Context, pg db.Querier, rc redis.Client, dst interfaces.State) (int, error) {
+	if _, err := rc.Find(ctx, migratedMarkerKey); err == nil {
+		return 0, nil
+	} else if !errors.Is(err, redis.ErrNotFound) {
+		return 0, fmt.Errorf("check migration marker: %w", err)
+	}
+
+	rows, err := pg.Query(ctx, "SELECT user_id, chat_id, COALESCE(state, ''), COALESCE(data, '{}'::jsonb) FROM state")
+	if err != nil {
+		return 0, fmt.Errorf("select states: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var (
+			userId, chatId int
+			st             string
+			data           map[string]string
+		)
+		if err := rows.Scan(&userId, &chatId, &st, &data); err != nil {
+			return count, fmt.Errorf("scan state: %w", err)
+		}
+		if err := dst.Set(ctx, userId, chatId, st, data); err != nil {
+			return count, fmt.Errorf("set state %d/%d: %w", userId, chatId, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("rows iteration: %w", err)
+	}
+
+	if err := rc.Save(ctx, migratedMarkerKey, time.Now().Format(time.RFC3339), 0); err != nil {
+		return count, fmt.Errorf("save migration marker: %w", err)
+	}
+	return count, nil
+}
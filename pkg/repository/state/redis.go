@@ -0,0 +1,112 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/logger"
+	"sushitana/pkg/redis"
+	"sushitana/pkg/tgrouter/interfaces"
+)
+
+const (
+	redisStateField = "state"
+	redisDataPrefix = "d:"
+
+	defaultRedisStateTTL = 30 * 24 * time.Hour
+)
+
+// redisState - interfaces.State ning redis varianti.
+// Har bir (user, chat) uchun bitta hash: "state" field + "d:<key>" data fieldlar.
+type redisState struct {
+	logger logger.Logger
+	redis  redis.Client
+	ttl    time.Duration
+}
+
+func newRedisState(log logger.Logger, client redis.Client, ttl time.Duration) interfaces.State {
+	if ttl <= 0 {
+		ttl = defaultRedisStateTTL
+	}
+	return &redisState{
+		logger: log,
+		redis:  client,
+		ttl:    ttl,
+	}
+}
+
+func stateKey(userId, chatId int) string {
+	return fmt.Sprintf("bot_state:%d:%d", userId, chatId)
+}
+
+func toHash(state string, data map[string]string) map[string]string {
+	fields := make(map[string]string, len(data)+1)
+	fields[redisStateField] = state
+	for k, v := range data {
+		fields[redisDataPrefix+k] = v
+	}
+	return fields
+}
+
+func fromHash(fields map[string]string) (string, map[string]string) {
+	data := make(map[string]string, len(fields))
+	for k, v := range fields {
+		if strings.HasPrefix(k, redisDataPrefix) {
+			data[strings.TrimPrefix(k, redisDataPrefix)] = v
+		}
+	}
+	return fields[redisStateField], data
+}
+
+func (s *redisState) Get(ctx context.Context, userId, chatId int) (string, map[string]string, error) {
+	fields, err := s.redis.HFindAll(ctx, stateKey(userId, chatId))
+	if err != nil {
+		if errors.Is(err, redis.ErrNotFound) {
+			return "", nil, structs.ErrNotFound
+		}
+		return "", nil, fmt.Errorf("redis: failed get state: %w", err)
+	}
+	state, data := fromHash(fields)
+	return state, data, nil
+}
+
+func (s *redisState) Set(ctx context.Context, userId, chatId int, state string, data map[string]string) error {
+	if err := s.redis.HSave(ctx, stateKey(userId, chatId), toHash(state, data), s.ttl); err != nil {
+		return fmt.Errorf("redis: failed update state: %w", err)
+	}
+	return nil
+}
+
+func (s *redisState) Delete(ctx context.Context, userId, chatId int) error {
+	if err := s.redis.Delete(ctx, stateKey(userId, chatId)); err != nil {
+		return fmt.Errorf("redis: failed delete state: %w", err)
+	}
+	return nil
+}
+
+func (s *redisState) GetData(ctx context.Context, userId, chatId int, key string) (string, error) {
+	_, data, err := s.Get(ctx, userId, chatId)
+	if err != nil {
+		if errors.Is(err, structs.ErrNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("redis: failed get data: %w", err)
+	}
+	return data[key], nil
+}
+
+// UpdateData postgres dagi `data || $3` bilan bir xil: state bo'lmasa hech narsa qilmaydi.
+func (s *redisState) UpdateData(ctx context.Context, userId, chatId int, data map[string]string) error {
+	fields := make(map[string]string, len(data))
+	for k, v := range data {
+		fields[redisDataPrefix+k] = v
+	}
+	if _, err := s.redis.HUpdate(ctx, stateKey(userId, chatId), fields, s.ttl); err != nil {
+		return fmt.Errorf("redis: failed update data: %w", err)
+	}
+	return nil
+}
@@ -4,23 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 
 	"github.com/jackc/pgx/v5"
 
 	"sushitana/internal/structs"
+	"sushitana/pkg/config"
 	"sushitana/pkg/db"
 	"sushitana/pkg/logger"
+	"sushitana/pkg/redis"
 	"sushitana/pkg/tgrouter/interfaces"
 )
 
 var Module = fx.Provide(New)
 
+const (
+	StoragePostgres = "postgres"
+	StorageRedis    = "redis"
+)
+
 type Params struct {
 	fx.In
 	Logger logger.Logger
 	DB     db.Querier
+	Config config.IConfig
+	Redis  redis.Client
 }
 
 type state struct {
@@ -28,11 +39,32 @@ type state struct {
 	db     db.Querier
 }
 
+// New bot.state.storage ga qarab postgres yoki redis implementatsiyasini qaytaradi.
+// Default - postgres (eski xulq o'zgarmaydi).
 func New(params Params) interfaces.State {
-	return &state{
+	pg := &state{
 		logger: params.Logger,
 		db:     params.DB,
 	}
+
+	storage := strings.ToLower(strings.TrimSpace(params.Config.GetString("bot.state.storage")))
+	if storage != StorageRedis {
+		return pg
+	}
+
+	rs := newRedisState(params.Logger, params.Redis, params.Config.GetDuration("bot.state.ttl"))
+
+	if params.Config.GetBool("bot.state.migrate") {
+		ctx := context.Background()
+		n, err := migrateToRedis(ctx, params.DB, params.Redis, rs)
+		if err != nil {
+			params.Logger.Error(ctx, "state: postgres -> redis migration failed", zap.Error(err))
+		} else if n > 0 {
+			params.Logger.Info(ctx, "state: postgres -> redis migration done", zap.Int("count", n))
+		}
+	}
+
+	return rs
 }
 
 func pgxErr(err error) error {
@@ -2,6 +2,7 @@ package tgrouter
 
 import (
 	"log/slog"
+	"strings"
 
 	"sushitana/pkg/tgrouter/callback"
 )
@@ -52,6 +53,20 @@ func Callback(query string) Filter[CallbackFilter] {
 	}
 }
 
+// CallbackPrefix - "prefix:..." ko'rinishidagi oddiy (callback.CallbackData emas) data uchun
+func CallbackPrefix(prefix string) Filter[CallbackFilter] {
+	return func(c *Ctx) bool {
+		return c.update.CallbackQuery != nil && strings.HasPrefix(c.update.CallbackQuery.Data, prefix)
+	}
+}
+
+// Chat - faqat shu chat'dagi xabarlar (0 bo'lsa hech narsa)
+func Chat(chatID int64) Filter[MessageFilter] {
+	return func(c *Ctx) bool {
+		return chatID != 0 && c.update.Message != nil && c.update.Message.Chat.ID == chatID
+	}
+}
+
 func Sticker() Filter[StickerFilter] {
 	return func(c *Ctx) bool {
 		return c.update.Message != nil && c.update.Message.Sticker != nil
@@ -91,3 +106,9 @@ func OnPayment() Filter[any] {
 		return c.update.Message != nil && c.update.Message.SuccessfulPayment != nil
 	}
 }
+
+func InlineQuery() Filter[any] {
+	return func(c *Ctx) bool {
+		return c.update.InlineQuery != nil
+	}
+}
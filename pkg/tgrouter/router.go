@@ -169,6 +169,12 @@ func (r *Router) handle(c *Ctx) {
 	}
 }
 
+// Serve bitta update'ni sinxron ishlatadi (ListenUpdate'siz).
+// Offline replay / tgtest harness uchun.
+func (r *Router) Serve(update tgbotapi.Update) {
+	r.serveUpdate(&update)
+}
+
 func (r *Router) serveUpdate(update *tgbotapi.Update) {
 	c := r.pool.Get().(*Ctx)
 	c.update = update
@@ -0,0 +1,297 @@
+// Package tgtest - tgrouter uchun offline harness.
+// Haqiqiy router + xotiradagi State + fake Telegram endpoint:
+// skript bo'yicha update'lar yuboriladi, bot nima yuborgani/edit qilgani yozib boriladi.
+package tgtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+
+	"sushitana/pkg/logger"
+	"sushitana/pkg/tgrouter"
+)
+
+const fakeToken = "000000:offline"
+
+type Harness struct {
+	Bot      *tgbotapi.BotAPI
+	Router   *tgrouter.Router
+	State    *MemoryState
+	Telegram *FakeTelegram
+
+	mu       sync.Mutex
+	updateID int
+	msgID    int
+}
+
+// New harness yaratadi. setup ichida route'lar ro'yxatdan o'tkaziladi
+// (masalan apps/bot.RegisterRoutes).
+func New(log logger.Logger, setup func(r *tgrouter.Router)) (*Harness, error) {
+	tg := NewFakeTelegram()
+
+	bot, err := tgbotapi.NewBotAPIWithClient(fakeToken, Endpoint, tg)
+	if err != nil {
+		return nil, fmt.Errorf("tgtest: init bot: %w", err)
+	}
+
+	st := NewMemoryState()
+	r := tgrouter.NewRouterFactory(log)(bot, tgrouter.WithPoolSize(1), tgrouter.WithState(st))
+	if setup != nil {
+		setup(r)
+	}
+
+	// getMe chaqiruvi skriptga aralashmasin
+	tg.Reset()
+
+	return &Harness{
+		Bot:      bot,
+		Router:   r,
+		State:    st,
+		Telegram: tg,
+		msgID:    1,
+	}, nil
+}
+
+// Send update'ni router orqali sinxron o'tkazadi va shu vaqtda bot qilgan so'rovlarni qaytaradi.
+func (h *Harness) Send(u tgbotapi.Update) []Call {
+	h.mu.Lock()
+	h.updateID++
+	u.UpdateID = h.updateID
+	h.mu.Unlock()
+
+	n := h.Telegram.len()
+	h.Router.Serve(u)
+	return h.Telegram.since(n)
+}
+
+func (h *Harness) nextMessageID() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgID++
+	return h.msgID
+}
+
+func (h *Harness) message(userID int64) *tgbotapi.Message {
+	return &tgbotapi.Message{
+		MessageID: h.nextMessageID(),
+		From:      user(userID),
+		Chat:      tgbotapi.Chat{ID: userID, Type: "private"},
+		Date:      int(time.Now().Unix()),
+	}
+}
+
+func user(userID int64) *tgbotapi.User {
+	return &tgbotapi.User{
+		ID:        userID,
+		FirstName: fmt.Sprintf("user%d", userID),
+	}
+}
+
+// Text - oddiy matnli xabar (reply keyboard tugmalari ham shu).
+func (h *Harness) Text(userID int64, text string) []Call {
+	msg := h.message(userID)
+	msg.Text = text
+	return h.Send(tgbotapi.Update{Message: msg})
+}
+
+// Command - "/cmd payload" ko'rinishidagi buyruq.
+func (h *Harness) Command(userID int64, cmd, payload string) []Call {
+	msg := h.message(userID)
+	msg.Text = "/" + cmd
+	if payload = strings.TrimSpace(payload); payload != "" {
+		msg.Text += " " + payload
+	}
+	msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(cmd) + 1}}
+	return h.Send(tgbotapi.Update{Message: msg})
+}
+
+func (h *Harness) Contact(userID int64, phone string) []Call {
+	msg := h.message(userID)
+	msg.Contact = &tgbotapi.Contact{
+		PhoneNumber: phone,
+		FirstName:   msg.From.FirstName,
+		UserID:      userID,
+	}
+	return h.Send(tgbotapi.Update{Message: msg})
+}
+
+func (h *Harness) Location(userID int64, lat, lng float64) []Call {
+	msg := h.message(userID)
+	msg.Location = &tgbotapi.Location{Latitude: lat, Longitude: lng}
+	return h.Send(tgbotapi.Update{Message: msg})
+}
+
+// Callback - bot yuborgan messageID dagi inline tugma bosilishi.
+// Message (reply_markup bilan) fake endpoint yozuvlaridan tiklanadi.
+func (h *Harness) Callback(userID int64, messageID int, data string) []Call {
+	msg, ok := h.Telegram.Message(messageID)
+	if !ok {
+		msg = tgbotapi.Message{
+			MessageID: messageID,
+			Chat:      tgbotapi.Chat{ID: userID, Type: "private"},
+		}
+	}
+
+	h.mu.Lock()
+	h.updateID++
+	cbID := fmt.Sprintf("cb%d", h.updateID)
+	h.mu.Unlock()
+
+	return h.Send(tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      cbID,
+			From:    user(userID),
+			Message: &msg,
+			Data:    data,
+		},
+	})
+}
+
+// Press - oxirgi yuborilgan xabarlardan matni mos inline tugmani topib bosadi.
+func (h *Harness) Press(userID int64, buttonText string) ([]Call, error) {
+	calls := h.Telegram.Calls()
+	for i := len(calls) - 1; i >= 0; i-- {
+		c := calls[i]
+		if c.ChatID != userID {
+			continue
+		}
+		kb := c.InlineKeyboard()
+		if kb == nil {
+			continue
+		}
+		for _, row := range kb.InlineKeyboard {
+			for _, b := range row {
+				if b.Text == buttonText && b.CallbackData != nil {
+					return h.Callback(userID, c.MessageID, *b.CallbackData), nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("tgtest: inline button %q not found", buttonText)
+}
+
+// CurrentState - foydalanuvchining hozirgi state nomi va data'si.
+func (h *Harness) CurrentState(userID int64) (string, map[string]string) {
+	st, data, _ := h.State.Get(context.Background(), int(userID), int(userID))
+	return st, data
+}
+
+// LastSent - chatga yuborilgan oxirgi xabar.
+func (h *Harness) LastSent(chatID int64) (Call, bool) {
+	calls := h.Telegram.Calls()
+	for i := len(calls) - 1; i >= 0; i-- {
+		if calls[i].ChatID == chatID && calls[i].IsSend() {
+			return calls[i], true
+		}
+	}
+	return Call{}, false
+}
+
+// =====================
+// SCRIPT
+// =====================
+
+// Step - skriptdagi bitta qadam: update yuborish va natijani tekshirish.
+type Step struct {
+	Name   string
+	Do     func(h *Harness) []Call
+	Expect []Expectation
+}
+
+type Expectation func(h *Harness, calls []Call) error
+
+// Play qadamlarni ketma-ket o'ynaydi, birinchi xatoda to'xtaydi.
+func (h *Harness) Play(steps ...Step) error {
+	for i, s := range steps {
+		calls := s.Do(h)
+		for _, exp := range s.Expect {
+			if err := exp(h, calls); err != nil {
+				return fmt.Errorf("step %d (%s): %w", i+1, s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func SendText(userID int64, text string) func(h *Harness) []Call {
+	return func(h *Harness) []Call { return h.Text(userID, text) }
+}
+
+func SendCommand(userID int64, cmd, payload string) func(h *Harness) []Call {
+	return func(h *Harness) []Call { return h.Command(userID, cmd, payload) }
+}
+
+func SendContact(userID int64, phone string) func(h *Harness) []Call {
+	return func(h *Harness) []Call { return h.Contact(userID, phone) }
+}
+
+func SendLocation(userID int64, lat, lng float64) func(h *Harness) []Call {
+	return func(h *Harness) []Call { return h.Location(userID, lat, lng) }
+}
+
+func PressButton(userID int64, buttonText string) func(h *Harness) []Call {
+	return func(h *Harness) []Call {
+		calls, err := h.Press(userID, buttonText)
+		if err != nil {
+			return []Call{{Method: "tgtest.error", Params: map[string][]string{"error": {err.Error()}}}}
+		}
+		return calls
+	}
+}
+
+// ExpectState - qadamdan keyin foydalanuvchi state'i.
+func ExpectState(userID int64, name string) Expectation {
+	return func(h *Harness, _ []Call) error {
+		got, _ := h.CurrentState(userID)
+		if got != name {
+			return fmt.Errorf("state = %q, want %q", got, name)
+		}
+		return nil
+	}
+}
+
+// ExpectText - qadam davomida yuborilgan/edit qilingan xabarlardan birida substr bor.
+func ExpectText(substr string) Expectation {
+	return func(_ *Harness, calls []Call) error {
+		for _, c := range calls {
+			if c.Method == "tgtest.error" {
+				return fmt.Errorf("%s", c.Params.Get("error"))
+			}
+			if strings.Contains(c.Text(), substr) {
+				return nil
+			}
+		}
+		return fmt.Errorf("no message contains %q", substr)
+	}
+}
+
+// ExpectButton - qadam davomida yuborilgan keyboard'larda shu tugma bor.
+func ExpectButton(text string) Expectation {
+	return func(_ *Harness, calls []Call) error {
+		for _, c := range calls {
+			for _, b := range c.Buttons() {
+				if b == text {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("no button %q", text)
+	}
+}
+
+// ExpectMethod - qadam davomida kamida bitta shu API method chaqirilgan.
+func ExpectMethod(method string) Expectation {
+	return func(_ *Harness, calls []Call) error {
+		for _, c := range calls {
+			if c.Method == method {
+				return nil
+			}
+		}
+		return fmt.Errorf("method %q was not called", method)
+	}
+}
@@ -0,0 +1,81 @@
+package tgtest
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"sushitana/internal/structs"
+	"sushitana/pkg/tgrouter/interfaces"
+)
+
+type stateKey struct {
+	userId, chatId int
+}
+
+type stateEntry struct {
+	state string
+	data  map[string]string
+}
+
+// MemoryState - interfaces.State ning xotiradagi varianti (faqat offline replay uchun).
+type MemoryState struct {
+	mu     sync.RWMutex
+	states map[stateKey]stateEntry
+}
+
+var _ interfaces.State = (*MemoryState)(nil)
+
+func NewMemoryState() *MemoryState {
+	return &MemoryState{states: make(map[stateKey]stateEntry)}
+}
+
+func (s *MemoryState) Set(_ context.Context, userId, chatId int, state string, data map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[stateKey{userId, chatId}] = stateEntry{state: state, data: maps.Clone(data)}
+	return nil
+}
+
+func (s *MemoryState) Get(_ context.Context, userId, chatId int) (string, map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.states[stateKey{userId, chatId}]
+	if !ok {
+		return "", nil, structs.ErrNotFound
+	}
+	return e.state, maps.Clone(e.data), nil
+}
+
+func (s *MemoryState) Delete(_ context.Context, userId, chatId int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, stateKey{userId, chatId})
+	return nil
+}
+
+func (s *MemoryState) GetData(_ context.Context, userId, chatId int, key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.states[stateKey{userId, chatId}]
+	if !ok {
+		return "", structs.ErrNotFound
+	}
+	return e.data[key], nil
+}
+
+// UpdateData postgres impl bilan bir xil: state bo'lmasa hech narsa qilmaydi.
+func (s *MemoryState) UpdateData(_ context.Context, userId, chatId int, data map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.states[stateKey{userId, chatId}]
+	if !ok {
+		return nil
+	}
+	if e.data == nil {
+		e.data = make(map[string]string, len(data))
+	}
+	maps.Copy(e.data, data)
+	s.states[stateKey{userId, chatId}] = e
+	return nil
+}
@@ -0,0 +1,246 @@
+package tgtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/ilpy20/telegram-bot-api/v7"
+)
+
+// Endpoint - fake bot uchun API endpoint (hech qayerga ulanmaydi).
+const Endpoint = "http://telegram.test/bot%s/%s"
+
+// Call - bot tomonidan Telegram API ga yuborilgan bitta so'rov.
+type Call struct {
+	Method    string
+	Params    url.Values
+	ChatID    int64
+	MessageID int // send* uchun yangi message_id, edit*/delete uchun target message_id
+}
+
+// Text - sendMessage/editMessageText uchun text, media uchun caption.
+func (c Call) Text() string {
+	if t := c.Params.Get("text"); t != "" {
+		return t
+	}
+	return c.Params.Get("caption")
+}
+
+func (c Call) IsSend() bool {
+	return strings.HasPrefix(c.Method, "send") || c.Method == "copyMessage"
+}
+
+func (c Call) IsEdit() bool {
+	return strings.HasPrefix(c.Method, "editMessage")
+}
+
+// InlineKeyboard - reply_markup dagi inline tugmalar (bo'lmasa nil).
+func (c Call) InlineKeyboard() *tgbotapi.InlineKeyboardMarkup {
+	raw := c.Params.Get("reply_markup")
+	if raw == "" {
+		return nil
+	}
+	var kb tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(raw), &kb); err != nil || len(kb.InlineKeyboard) == 0 {
+		return nil
+	}
+	return &kb
+}
+
+// Buttons - reply_markup dagi barcha tugma matnlari (inline va reply keyboard).
+func (c Call) Buttons() []string {
+	raw := c.Params.Get("reply_markup")
+	if raw == "" {
+		return nil
+	}
+
+	var markup struct {
+		Keyboard       [][]tgbotapi.KeyboardButton       `json:"keyboard"`
+		InlineKeyboard [][]tgbotapi.InlineKeyboardButton `json:"inline_keyboard"`
+	}
+	if err := json.Unmarshal([]byte(raw), &markup); err != nil {
+		return nil
+	}
+
+	var out []string
+	for _, row := range markup.Keyboard {
+		for _, b := range row {
+			out = append(out, b.Text)
+		}
+	}
+	for _, row := range markup.InlineKeyboard {
+		for _, b := range row {
+			out = append(out, b.Text)
+		}
+	}
+	return out
+}
+
+// FakeTelegram - tgbotapi.HTTPClient implementatsiyasi.
+// Barcha so'rovlarni yozib boradi va Telegram'ga o'xshash javob qaytaradi.
+type FakeTelegram struct {
+	mu     sync.Mutex
+	calls  []Call
+	nextID int
+	self   tgbotapi.User
+}
+
+var _ tgbotapi.HTTPClient = (*FakeTelegram)(nil)
+
+func NewFakeTelegram() *FakeTelegram {
+	return &FakeTelegram{
+		nextID: 1000,
+		self: tgbotapi.User{
+			ID:        1,
+			IsBot:     true,
+			FirstName: "SushiTana",
+			UserName:  "sushitana_test_bot",
+		},
+	}
+}
+
+func (f *FakeTelegram) Do(req *http.Request) (*http.Response, error) {
+	method := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+
+	params, err := readParams(req)
+	if err != nil {
+		return jsonResponse(map[string]any{"ok": false, "error_code": 400, "description": err.Error()}), nil
+	}
+
+	result := f.record(method, params)
+	return jsonResponse(map[string]any{"ok": true, "result": result}), nil
+}
+
+func (f *FakeTelegram) record(method string, params url.Values) any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call := Call{Method: method, Params: params}
+	call.ChatID, _ = strconv.ParseInt(params.Get("chat_id"), 10, 64)
+
+	var result any = true
+	switch {
+	case method == "getMe":
+		result = f.self
+	case method == "getUpdates":
+		result = []tgbotapi.Update{}
+	case method == "copyMessage":
+		f.nextID++
+		call.MessageID = f.nextID
+		result = tgbotapi.MessageID{MessageID: f.nextID}
+	case call.IsSend():
+		f.nextID++
+		call.MessageID = f.nextID
+		result = f.message(call)
+	case call.IsEdit():
+		call.MessageID, _ = strconv.Atoi(params.Get("message_id"))
+		result = f.message(call)
+	case method == "deleteMessage":
+		call.MessageID, _ = strconv.Atoi(params.Get("message_id"))
+	}
+
+	f.calls = append(f.calls, call)
+	return result
+}
+
+func (f *FakeTelegram) message(call Call) tgbotapi.Message {
+	msg := tgbotapi.Message{
+		MessageID: call.MessageID,
+		From:      &f.self,
+		Date:      int(time.Now().Unix()),
+		Chat:      tgbotapi.Chat{ID: call.ChatID, Type: "private"},
+	}
+	if call.Params.Has("caption") {
+		msg.Caption = call.Params.Get("caption")
+	} else {
+		msg.Text = call.Params.Get("text")
+	}
+	msg.ReplyMarkup = call.InlineKeyboard()
+	return msg
+}
+
+// Calls - hozirgacha yozilgan barcha so'rovlar nusxasi.
+func (f *FakeTelegram) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+func (f *FakeTelegram) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func (f *FakeTelegram) since(n int) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if n >= len(f.calls) {
+		return nil
+	}
+	return append([]Call(nil), f.calls[n:]...)
+}
+
+// Message - message_id bo'yicha bot yuborgan (yoki oxirgi edit qilingan) xabar.
+func (f *FakeTelegram) Message(messageID int) (tgbotapi.Message, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.calls) - 1; i >= 0; i-- {
+		c := f.calls[i]
+		if c.MessageID == messageID && (c.IsSend() || c.IsEdit()) {
+			return f.message(c), true
+		}
+	}
+	return tgbotapi.Message{}, false
+}
+
+func (f *FakeTelegram) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func readParams(req *http.Request) (url.Values, error) {
+	if req.Body == nil {
+		return url.Values{}, nil
+	}
+	defer req.Body.Close()
+
+	ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if ct == "multipart/form-data" {
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return nil, err
+		}
+		params := url.Values{}
+		for k, v := range req.MultipartForm.Value {
+			params[k] = v
+		}
+		for k := range req.MultipartForm.File {
+			params.Set(k, "<file>")
+		}
+		return params, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return url.ParseQuery(string(body))
+}
+
+func jsonResponse(v any) *http.Response {
+	b, _ := json.Marshal(v)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(b)),
+	}
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// /start payload turlari: t.me/<bot>?start=p_<productId>
+const (
+	DeepLinkProduct  = "p"
+	DeepLinkCategory = "c"
+	DeepLinkPromo    = "promo"
+	DeepLinkRef      = "ref"
+)
+
+// telegram start parametri: 1-64 belgi, A-Z a-z 0-9 _ -
+var deepLinkRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type DeepLink struct {
+	Kind  string
+	Value string
+}
+
+// ParseDeepLink - "p_<id>", "c_<id>", "promo_<code>", "ref_<tgId>". Noma'lum payload -> false.
+func ParseDeepLink(payload string) (DeepLink, bool) {
+	payload = strings.TrimSpace(payload)
+	if !deepLinkRe.MatchString(payload) {
+		return DeepLink{}, false
+	}
+
+	kind, value, ok := strings.Cut(payload, "_")
+	if !ok || value == "" {
+		return DeepLink{}, false
+	}
+
+	switch kind {
+	case DeepLinkProduct, DeepLinkCategory, DeepLinkPromo:
+	case DeepLinkRef:
+		for _, r := range value {
+			if r < '0' || r > '9' {
+				return DeepLink{}, false
+			}
+		}
+	default:
+		return DeepLink{}, false
+	}
+	return DeepLink{Kind: kind, Value: value}, true
+}
+
+func (d DeepLink) String() string {
+	if d.Kind == "" {
+		return ""
+	}
+	return d.Kind + "_" + d.Value
+}
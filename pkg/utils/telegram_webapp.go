@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidInitData = errors.New("invalid telegram init data")
+
+// ValidateWebAppInitData - Telegram Mini App initData imzosini tekshiradi va foydalanuvchi tg id'sini qaytaradi.
+// secret = HMAC_SHA256("WebAppData", botToken), hash = hex(HMAC_SHA256(secret, data_check_string)).
+// maxAge > 0 bo'lsa auth_date eskirganlari ham rad etiladi
+func ValidateWebAppInitData(initData, botToken string, maxAge time.Duration) (int64, error) {
+	if initData == "" || botToken == "" {
+		return 0, ErrInvalidInitData
+	}
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, ErrInvalidInitData
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return 0, ErrInvalidInitData
+	}
+
+	pairs := make([]string, 0, len(values))
+	for k := range values {
+		if k == "hash" {
+			continue
+		}
+		pairs = append(pairs, k+"="+values.Get(k))
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(strings.ToLower(hash))) {
+		return 0, ErrInvalidInitData
+	}
+
+	if maxAge > 0 {
+		authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+		if err != nil || time.Since(time.Unix(authDate, 0)) > maxAge {
+			return 0, ErrInvalidInitData
+		}
+	}
+
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return 0, ErrInvalidInitData
+	}
+	return user.ID, nil
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:TEST-TOKEN"
+
+func signInitData(t *testing.T, botToken string, values url.Values) string {
+	t.Helper()
+
+	pairs := make([]string, 0, len(values))
+	for k := range values {
+		pairs = append(pairs, k+"="+values.Get(k))
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return values.Encode()
+}
+
+func initValues(authDate time.Time) url.Values {
+	return url.Values{
+		"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+		"query_id":  {"AAHdF6IQAAAAAN0XohDhrOrc"},
+		"user":      {`{"id":777000,"first_name":"Test","language_code":"uz"}`},
+	}
+}
+
+func TestValidateWebAppInitData(t *testing.T) {
+	initData := signInitData(t, testBotToken, initValues(time.Now()))
+
+	tgID, err := ValidateWebAppInitData(initData, testBotToken, time.Hour)
+	if err != nil {
+		t.Fatalf("valid init data rejected: %v", err)
+	}
+	if tgID != 777000 {
+		t.Fatalf("tgID = %d, want 777000", tgID)
+	}
+}
+
+func TestValidateWebAppInitDataRejects(t *testing.T) {
+	valid := signInitData(t, testBotToken, initValues(time.Now()))
+
+	tampered, _ := url.ParseQuery(valid)
+	tampered.Set("user", `{"id":1,"first_name":"Other"}`)
+
+	cases := map[string]struct {
+		initData string
+		token    string
+	}{
+		"empty":       {"", testBotToken},
+		"no hash":     {initValues(time.Now()).Encode(), testBotToken},
+		"other token": {valid, "654321:OTHER"},
+		"tampered":    {tampered.Encode(), testBotToken},
+		"expired":     {signInitData(t, testBotToken, initValues(time.Now().Add(-48*time.Hour))), testBotToken},
+	}
+	for name, tc := range cases {
+		if _, err := ValidateWebAppInitData(tc.initData, tc.token, 24*time.Hour); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
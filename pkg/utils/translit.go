@@ -0,0 +1,31 @@
+package utils
+
+import "strings"
+
+// cyrToLat - o'zbek (va rus) kirill harflarini lotinga o'giradi.
+// Qidiruv uchun yetarli, rasmiy imlo emas.
+var cyrToLat = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "j", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "x", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "",
+	'ы': "i", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// o'zbekcha
+	'ў': "o'", 'қ': "q", 'ғ': "g'", 'ҳ': "h",
+}
+
+// CyrillicToLatin - matndagi kirill harflarni lotinga o'giradi (natija kichik harfda).
+func CyrillicToLatin(s string) string {
+	s = strings.ToLower(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if lat, ok := cyrToLat[r]; ok {
+			b.WriteString(lat)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -67,6 +67,20 @@ func ValidPhone(phone string) bool {
 	return true
 }
 
+// NormalizePhone - faqat oxirgi 9 raqam (+998 / 998 / 8 prefikslarsiz solishtirish uchun)
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, phone)
+	if len(digits) > 9 {
+		digits = digits[len(digits)-9:]
+	}
+	return digits
+}
+
 func Marshal(value interface{}) []byte {
 	byt, _ := json.Marshal(value)
 	return byt
@@ -22,7 +22,12 @@ func NewZoneCheckerFromFiles(paths ...string) (*ZoneChecker, error) {
 		}
 		z = append(z, b)
 	}
-	return &ZoneChecker{zones: z}, nil
+	return NewZoneChecker(z...), nil
+}
+
+// NewZoneChecker - tayyor geojson'lardan (tartib filial indeksini beradi: 0 olmaliq, 1 ohangaron)
+func NewZoneChecker(zones ...[]byte) *ZoneChecker {
+	return &ZoneChecker{zones: zones}
 }
 
 func (c *ZoneChecker) ContainsAny(lat, lng float64) (bool, error) {